@@ -0,0 +1,182 @@
+package main
+
+import (
+	"crypto/sha256"
+	"strings"
+	"time"
+
+	"acars_parser/internal/decode"
+)
+
+// DedupInfo is attached to a record when duplicate suppression is enabled.
+// It lists every copy of the message that was heard, the first one included.
+type DedupInfo struct {
+	Count     int        `json:"count"`
+	Receivers []Receiver `json:"receivers"`
+}
+
+// Receiver describes one copy of a message: who heard it, where and when.
+type Receiver struct {
+	Station   string  `json:"station,omitempty"`
+	Frequency float64 `json:"frequency,omitempty"`
+	Source    string  `json:"source,omitempty"`
+	Timestamp string  `json:"timestamp,omitempty"`
+	Listener  string  `json:"listener,omitempty"`
+	File      string  `json:"file,omitempty"`
+}
+
+type dedupKey [16]byte
+
+type dedupEntry struct {
+	key     dedupKey
+	first   time.Time // message time of the first copy
+	arrived time.Time // wall-clock time the first copy was seen
+	out     ExtractOut
+}
+
+// deduper collapses copies of the same message heard by several receivers.
+// Two records are copies when their normalised (tail, label, text)
+// fingerprints are equal and their message times are at most window apart.
+//
+// In buffered mode (hold == false) the first copy is emitted at once and
+// later copies are merged into it through the shared *DedupInfo; this only
+// works when the caller serialises records after the whole input has been
+// read, and it catches copies in any order, e.g. one log file per receiver.
+//
+// In streaming mode (hold == true) each record is held back until the
+// window after its first copy has passed, measured by the newest message
+// time seen so far or, via tick, by the wall clock.  Records are released in
+// the order their first copies arrived.  Copies that arrive after their
+// record has been released start a new record.
+type deduper struct {
+	window time.Duration
+	hold   bool
+	emit   emitFunc
+
+	entries   map[dedupKey]*dedupEntry
+	queue     []*dedupEntry // held entries in arrival order
+	watermark time.Time
+	now       func() time.Time
+
+	duplicates int
+}
+
+func newDeduper(window time.Duration, hold bool, emit emitFunc) *deduper {
+	return &deduper{
+		window:  window,
+		hold:    hold,
+		emit:    emit,
+		entries: make(map[dedupKey]*dedupEntry),
+		now:     time.Now,
+	}
+}
+
+// Add is an emitFunc.
+func (d *deduper) Add(out ExtractOut) {
+	if out.Message == nil {
+		d.emit(out)
+		return
+	}
+	ts, ok := decode.ParseTime(out.Message.Timestamp)
+	if !ok {
+		// Without a time we cannot tell a copy from a repeat.
+		d.emit(out)
+		return
+	}
+
+	key := dedupFingerprint(out.Message)
+	if e, ok := d.entries[key]; ok && absDuration(ts.Sub(e.first)) <= d.window {
+		e.out.Dedup.Receivers = append(e.out.Dedup.Receivers, receiverOf(out))
+		e.out.Dedup.Count++
+		d.duplicates++
+		return
+	}
+
+	out.Dedup = &DedupInfo{Count: 1, Receivers: []Receiver{receiverOf(out)}}
+	e := &dedupEntry{key: key, first: ts, arrived: d.now(), out: out}
+	d.entries[key] = e
+	if !d.hold {
+		d.emit(out)
+		return
+	}
+
+	d.queue = append(d.queue, e)
+	if ts.After(d.watermark) {
+		d.watermark = ts
+	}
+	d.release(func(e *dedupEntry) bool { return d.watermark.Sub(e.first) > d.window })
+}
+
+// tick releases held records whose first copy arrived more than window ago
+// by the wall clock, so a quiet live feed does not hold records forever.
+func (d *deduper) tick() {
+	now := d.now()
+	d.release(func(e *dedupEntry) bool { return now.Sub(e.arrived) > d.window })
+}
+
+// Flush releases every held record.
+func (d *deduper) Flush() {
+	d.release(func(*dedupEntry) bool { return true })
+}
+
+// release emits held entries from the front of the queue while expired
+// reports true, keeping arrival order.
+func (d *deduper) release(expired func(*dedupEntry) bool) {
+	n := 0
+	for n < len(d.queue) && expired(d.queue[n]) {
+		e := d.queue[n]
+		if d.entries[e.key] == e {
+			delete(d.entries, e.key)
+		}
+		d.emit(e.out)
+		d.queue[n] = nil
+		n++
+	}
+	d.queue = d.queue[n:]
+}
+
+// dedupFingerprint hashes the tail, label and text of msg.  Tails are
+// compared without JAERO's leading dots, and runs of whitespace in the text
+// are collapsed so line-wrapping differences between decoders do not matter.
+func dedupFingerprint(msg *OutputMessage) dedupKey {
+	tail := msg.Tail
+	if tail == "" && msg.Airframe != nil {
+		tail = msg.Airframe.Tail
+	}
+	h := sha256.New()
+	h.Write([]byte(normaliseTail(tail)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToUpper(strings.TrimSpace(msg.Label))))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(strings.Fields(msg.Text), " ")))
+	var key dedupKey
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+func receiverOf(out ExtractOut) Receiver {
+	msg := out.Message
+	r := Receiver{
+		Frequency: msg.Frequency,
+		Source:    msg.Source,
+		Timestamp: msg.Timestamp,
+	}
+	if msg.Station != nil {
+		r.Station = msg.Station.Ident
+		if r.Station == "" {
+			r.Station = msg.Station.ID
+		}
+	}
+	if out.Origin != nil {
+		r.Listener = out.Origin.Listener
+		r.File = out.Origin.File
+	}
+	return r
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"acars_parser/internal/acars"
+)
+
+func dedupRecord(tail, text, ts, station string) ExtractOut {
+	return ExtractOut{Message: &OutputMessage{
+		Tail:      tail,
+		Label:     "H1",
+		Text:      text,
+		Timestamp: ts,
+		Station:   &acars.Station{Ident: station},
+	}}
+}
+
+func TestDedupFingerprint(t *testing.T) {
+	a := dedupRecord(".A6-EDA", "POSN42 E012\n  FL350", "", "").Message
+	b := dedupRecord("A6-EDA", "POSN42 E012 FL350", "", "").Message
+	b.Label = "h1"
+	if dedupFingerprint(a) != dedupFingerprint(b) {
+		t.Fatal("tail dots, label case and whitespace should not change the fingerprint")
+	}
+	b.Text = "POSN42 E012 FL360"
+	if dedupFingerprint(a) == dedupFingerprint(b) {
+		t.Fatal("different text should change the fingerprint")
+	}
+}
+
+func TestDeduperBuffered(t *testing.T) {
+	var out []ExtractOut
+	d := newDeduper(30*time.Second, false, func(o ExtractOut) { out = append(out, o) })
+
+	d.Add(dedupRecord("A6-EDA", "POSN42", "2026-05-12T16:55:03Z", "RX2"))
+	d.Add(dedupRecord(".A6-EDA", "POSN42", "2026-05-12T16:55:00Z", "RX1"))
+	d.Add(dedupRecord("A6-EDA", "POSN42", "2026-05-12T16:56:00Z", "RX1")) // outside the window
+	d.Add(dedupRecord("A6-EDA", "POSN42", "", "RX1"))                     // no time: passed through
+	d.Flush()
+
+	if len(out) != 3 || d.duplicates != 1 {
+		t.Fatalf("got %d records, %d duplicates; want 3, 1", len(out), d.duplicates)
+	}
+	info := out[0].Dedup
+	if info == nil || info.Count != 2 || info.Receivers[0].Station != "RX2" || info.Receivers[1].Station != "RX1" {
+		t.Fatalf("first record dedup = %+v", info)
+	}
+	if out[1].Dedup.Count != 1 || out[2].Dedup != nil {
+		t.Fatalf("later records dedup = %+v, %+v", out[1].Dedup, out[2].Dedup)
+	}
+}
+
+func TestDeduperHold(t *testing.T) {
+	var out []ExtractOut
+	d := newDeduper(10*time.Second, true, func(o ExtractOut) { out = append(out, o) })
+	now := time.Date(2026, 5, 12, 17, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	d.Add(dedupRecord("A6-EDA", "ONE", "2026-05-12T16:55:00Z", "RX1"))
+	d.Add(dedupRecord("D-AIMM", "TWO", "2026-05-12T16:55:02Z", "RX1"))
+	d.Add(dedupRecord("A6-EDA", "ONE", "2026-05-12T16:55:04Z", "RX2"))
+	if len(out) != 0 {
+		t.Fatalf("records released before the window passed: %d", len(out))
+	}
+
+	// Message time moving past the first record's window releases it,
+	// and only it.
+	d.Add(dedupRecord("9A-CTG", "THREE", "2026-05-12T16:55:11Z", "RX1"))
+	if len(out) != 1 || out[0].Message.Text != "ONE" || out[0].Dedup.Count != 2 {
+		t.Fatalf("after watermark: %d records, first %+v", len(out), out)
+	}
+
+	// The wall clock releases the rest of a quiet feed, in arrival order.
+	now = now.Add(11 * time.Second)
+	d.tick()
+	if len(out) != 3 || out[1].Message.Text != "TWO" || out[2].Message.Text != "THREE" {
+		t.Fatalf("after tick: %d records", len(out))
+	}
+
+	// A copy arriving after its record was released starts a new one.
+	d.Add(dedupRecord("9A-CTG", "THREE", "2026-05-12T16:55:12Z", "RX2"))
+	d.Flush()
+	if len(out) != 4 || out[3].Dedup.Count != 1 || d.duplicates != 1 {
+		t.Fatalf("late copy: %d records, %d duplicates", len(out), d.duplicates)
+	}
+}
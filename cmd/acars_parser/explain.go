@@ -0,0 +1,254 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/decode"
+	"acars_parser/internal/enrich"
+	miampkg "acars_parser/internal/parsers/miam"
+	"acars_parser/internal/patterns"
+	"acars_parser/internal/registry"
+)
+
+func runExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	label := fs.String("label", "", "ACARS label of a raw message given as text")
+	text := fs.String("text", "", "Raw message text (default: the argument, or stdin)")
+	patternsToo := fs.Bool("patterns", false, "Also print the expanded regex of every format tried")
+	var pf parserFlags
+	pf.register(fs)
+	rest := parseInterspersed(fs, args)
+
+	input := *text
+	if input == "" && len(rest) > 0 {
+		input = strings.Join(rest, " ")
+	}
+	if input == "" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Input read error: %v\n", err)
+			os.Exit(1)
+		}
+		input = string(b)
+	}
+
+	msgs, err := explainMessages(*label, input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "explain: %v\n", err)
+		os.Exit(2)
+	}
+	parsers, err := newParserRegistry(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parser setup: %v\n", err)
+		os.Exit(2)
+	}
+	enrichers, err := newEnrichChain(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid enricher setup: %v\n", err)
+		os.Exit(2)
+	}
+	if enrichers == nil {
+		enrichers = enrich.Default()
+	}
+	e := &explainer{w: os.Stdout, parsers: parsers, enrichers: enrichers, patterns: *patternsToo}
+	for i, msg := range msgs {
+		if i > 0 {
+			fmt.Fprintln(e.w)
+		}
+		e.explain(msg)
+	}
+}
+
+// explainMessages turns the explain input into messages.  With -label the
+// input is the message text as is; otherwise it must be one JSON line in any
+// format extract reads, which may yield several messages.
+func explainMessages(label, input string) ([]*acars.Message, error) {
+	if label != "" {
+		return []*acars.Message{{Label: label, Text: input}}, nil
+	}
+	line := strings.TrimSpace(input)
+	if !strings.HasPrefix(line, "{") {
+		return nil, fmt.Errorf("give -label with raw text, or one JSON line")
+	}
+	msgs, _ := decode.JSON([]byte(line))
+	var out []*acars.Message
+	for _, msg := range msgs {
+		if msg != nil && (strings.TrimSpace(msg.Label) != "" || strings.TrimSpace(msg.Text) != "") {
+			out = append(out, msg)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no label or text found in the JSON line")
+	}
+	return out, nil
+}
+
+// explainer prints a dispatch trace for each message.
+type explainer struct {
+	w         io.Writer
+	parsers   *registry.Registry
+	enrichers *enrich.Chain
+	patterns  bool // print expanded regexes
+}
+
+// explain prints how msg was dispatched, then explains the message carried
+// in any MIAM frame, then prints the fields enrichers filled in and the
+// record extract would write.
+func (e *explainer) explain(msg *acars.Message) {
+	e.enrichers.Enrich(msg, nil)
+	results := e.trace("message", msg)
+	for _, r := range results {
+		if m, ok := r.(*miampkg.Result); ok {
+			if inner := m.InnerACARS(msg); inner != nil {
+				fmt.Fprintln(e.w)
+				results = append(results, e.trace("inner message", inner)...)
+			}
+		}
+	}
+	if len(results) > 0 {
+		e.enrichers.Enrich(msg, results)
+	}
+
+	if len(msg.EnrichedBy) > 0 {
+		fmt.Fprintf(e.w, "\nenriched (chain %s):\n", strings.Join(e.enrichers.Names(), ","))
+		fields := make([]string, 0, len(msg.EnrichedBy))
+		for f := range msg.EnrichedBy {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		for _, f := range fields {
+			fmt.Fprintf(e.w, "  %-20s %-8s by %s\n", f, enrichedValue(msg, f), msg.EnrichedBy[f])
+		}
+	}
+
+	fmt.Fprintln(e.w, "\nrecord:")
+	b, err := marshalJSON(newExtractOut(msg, results), true)
+	if err != nil {
+		fmt.Fprintf(e.w, "  JSON encode error: %v\n", err)
+		return
+	}
+	fmt.Fprintln(e.w, string(b))
+}
+
+// enrichedValue returns the value of the enriched field f of msg.
+func enrichedValue(msg *acars.Message, f string) string {
+	switch f {
+	case enrich.FieldTail:
+		if msg.Airframe != nil {
+			return msg.Airframe.Tail
+		}
+		return msg.Tail
+	case enrich.FieldFlight:
+		return msg.Flight.Flight
+	case enrich.FieldDepartingAirport:
+		return msg.Flight.DepartingAirport
+	case enrich.FieldDestinationAirport:
+		return msg.Flight.DestinationAirport
+	}
+	return ""
+}
+
+// trace dispatches msg and prints one line per parser considered, with the
+// grok formats tried below it.
+func (e *explainer) trace(title string, msg *acars.Message) []registry.Result {
+	results, traces := e.parsers.DispatchWithTrace(msg)
+
+	fmt.Fprintf(e.w, "%s: label %s", title, msg.Label)
+	if msg.Tail != "" {
+		fmt.Fprintf(e.w, ", tail %s", msg.Tail)
+	}
+	if msg.Flight != nil && msg.Flight.Flight != "" {
+		fmt.Fprintf(e.w, ", flight %s", msg.Flight.Flight)
+	}
+	fmt.Fprintln(e.w)
+	for _, line := range strings.Split(msg.Text, "\n") {
+		fmt.Fprintf(e.w, "  | %s\n", line)
+	}
+
+	stage := ""
+	for _, t := range traces {
+		if t.Stage != stage {
+			stage = t.Stage
+			switch stage {
+			case "label":
+				fmt.Fprintf(e.w, "label %s parsers:\n", msg.Label)
+			case "global":
+				fmt.Fprintln(e.w, "global parsers:")
+			default:
+				fmt.Fprintln(e.w, "catch-all parsers:")
+			}
+		}
+		fmt.Fprintf(e.w, "  %-12s prio %-4d %s\n", t.Parser, t.Priority, outcome(t))
+		for _, call := range t.Calls {
+			e.printCall(call, msg.Text)
+		}
+	}
+	if stage == "" {
+		fmt.Fprintf(e.w, "no parser is registered for label %s\n", msg.Label)
+	}
+	return results
+}
+
+// outcome describes what one parser did with the message.
+func outcome(t registry.ParserTrace) string {
+	switch {
+	case !t.Ran:
+		return "skipped, another parser matched"
+	case t.Result == nil && !t.QuickCheck:
+		return "quickcheck failed"
+	case t.Result == nil:
+		return "quickcheck passed, no result"
+	}
+	if e, ok := t.Result.(*registry.ErrorResult); ok {
+		return e.Error
+	}
+	return "-> " + t.Result.Type()
+}
+
+// printCall prints the formats one grok compiler call tried.
+func (e *explainer) printCall(call patterns.CallTrace, text string) {
+	if call.Text != text {
+		fmt.Fprintf(e.w, "      on %q\n", clip(call.Text, 60))
+	}
+	upper := strings.ToUpper(call.Text)
+	for _, f := range call.Formats {
+		switch {
+		case f.Matched:
+			fmt.Fprintf(e.w, "      %-26s matched %s\n", f.Name, formatCaptures(f.Captures))
+		case f.Reached >= 0 && strings.Trim(f.Partial, "^") != "":
+			fmt.Fprintf(e.w, "      %-26s failed after `%s` at %q\n", f.Name, f.Partial, clip(upper[f.Reached:], 30))
+		default:
+			fmt.Fprintf(e.w, "      %-26s failed at the start\n", f.Name)
+		}
+		if e.patterns {
+			fmt.Fprintf(e.w, "        %s\n", f.Pattern)
+		}
+	}
+}
+
+// formatCaptures lists the non-empty captures as name=value, sorted.
+func formatCaptures(captures map[string]string) string {
+	var parts []string
+	for name, v := range captures {
+		if v != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", name, v))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// clip shortens s to n runes for display.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/registry"
+)
+
+func TestExplain(t *testing.T) {
+	registry.Default().Sort()
+	explain := func(label, input string) string {
+		msgs, err := explainMessages(label, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		e := &explainer{w: &b, parsers: registry.Default()}
+		for _, msg := range msgs {
+			e.explain(msg)
+		}
+		return b.String()
+	}
+
+	out := explain("80", "3N01 POSRPT 0210/30 KATL/FACT .N522DZ/POS S22336W006589/ALT 410")
+	for _, want := range []string{
+		"label 80 parsers:\n  label80      prio 100  -> position\n",
+		`header_format              matched dest="FACT" msg_type="POSRPT" origin="KATL"`,
+		"alt_format                 failed after `^(?P<flight>[A-Z0-9]+)` at \" POSRPT",
+		"global parsers:\n",
+		"  pdc          prio 500  quickcheck failed\n",
+		`"origin_icao": "KATL"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// A MIAM frame is followed by the message it carries.
+	text, _ := json.Marshal(miamFPNFrame)
+	out = explain("", `{"label":"MA","tail":".JY-BAJ","text":`+string(text)+`}`)
+	if !strings.Contains(out, "  miam         prio 10   -> miam_data\n") ||
+		!strings.Contains(out, "inner message: label H1") || !strings.Contains(out, "  fpn          prio 10   -> flight_plan\n") {
+		t.Errorf("MIAM frame:\n%s", out)
+	}
+
+	if _, err := explainMessages("", "POSN51234"); err == nil {
+		t.Error("raw text without -label accepted")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"acars_parser/internal/state"
+)
+
+// trackFormat returns the export format: format if given, else the one the
+// output file extension names, else GeoJSON.
+func trackFormat(format, outPath string) (string, error) {
+	if format == "" {
+		if ext := strings.ToLower(filepath.Ext(outPath)); ext == ".kml" {
+			return "kml", nil
+		}
+		return "geojson", nil
+	}
+	switch format = strings.ToLower(format); format {
+	case "geojson", "kml":
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want geojson or kml)", format)
+}
+
+// runExportTracks writes the position tracks of the flight legs in the state
+// database for GIS tools: GeoJSON for QGIS and the like, KML for Google
+// Earth.
+func runExportTracks(args []string) {
+	fs := flag.NewFlagSet("export-tracks", flag.ExitOnError)
+	dbPath := fs.String("state-db", defaultStateDB, "SQLite state database to read")
+	tail := fs.String("tail", "", "Only this aircraft, by registration or ICAO address (default: all)")
+	since := fs.String("since", "", "Only legs still going at or after this time (same formats as extract -since)")
+	until := fs.String("until", "", "Only legs begun before this time")
+	format := fs.String("format", "", "Output format: geojson or kml (default: from the -output extension, else geojson)")
+	by := fs.String("by", "leg", "One track per leg or per aircraft")
+	minPoints := fs.Int("min-points", 2, "Leave out legs with fewer positions")
+	outPath := fs.String("output", "", "Output file (default: stdout)")
+	_ = fs.Parse(args)
+
+	f, err := trackFormat(*format, *outPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-tracks: %v\n", err)
+		os.Exit(2)
+	}
+	if *by != "leg" && *by != "aircraft" {
+		fmt.Fprintf(os.Stderr, "export-tracks: -by must be leg or aircraft, not %q\n", *by)
+		os.Exit(2)
+	}
+	from, to := parseTimeRange("export-tracks", *since, *until)
+
+	t := openStateDB(*dbPath)
+	defer t.Close()
+	legs, err := t.GetLegs(*tail, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-tracks: %v\n", err)
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *outPath != "" {
+		out, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output: %v\n", err)
+			os.Exit(1)
+		}
+		defer out.Close()
+		w = out
+	}
+	bw := bufio.NewWriter(w)
+	write := state.WriteGeoJSON
+	if f == "kml" {
+		write = state.WriteKML
+	}
+	err = write(bw, legs, *minPoints, *by == "aircraft")
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Output write error: %v\n", err)
+		os.Exit(1)
+	}
+}
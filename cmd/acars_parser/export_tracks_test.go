@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestTrackFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format, output, want string
+	}{
+		{"", "", "geojson"},
+		{"", "tracks.KML", "kml"},
+		{"", "tracks.json", "geojson"},
+		{"GeoJSON", "tracks.kml", "geojson"},
+		{"kml", "", "kml"},
+	} {
+		got, err := trackFormat(tc.format, tc.output)
+		if err != nil || got != tc.want {
+			t.Errorf("trackFormat(%q, %q) = %q, %v; want %q", tc.format, tc.output, got, err, tc.want)
+		}
+	}
+	if _, err := trackFormat("gpx", ""); err == nil {
+		t.Error("trackFormat(gpx) succeeded")
+	}
+}
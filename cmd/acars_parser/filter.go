@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/airlines"
+	"acars_parser/internal/decode"
+	"acars_parser/internal/enrich"
+	"acars_parser/internal/registry"
+)
+
+// extractOptions controls which messages and results are emitted.
+type extractOptions struct {
+	includeAll   bool               // keep messages no parser matched
+	filter       *messageFilter     // nil keeps everything
+	blockTimeout time.Duration      // join multi-block messages; 0 disables
+	parsers      *registry.Registry // nil dispatches through registry.Default()
+	enrichers    *enrich.Chain      // nil runs enrich.Default()
+}
+
+// parserRegistry returns the registry messages are dispatched through.
+func (o extractOptions) parserRegistry() *registry.Registry {
+	if o.parsers == nil {
+		return registry.Default()
+	}
+	return o.parsers
+}
+
+// defaultEnrichers is the chain run when no -enrich flag was given.
+var defaultEnrichers = enrich.Default()
+
+// enrichChain returns the chain that fills in flight fields.
+func (o extractOptions) enrichChain() *enrich.Chain {
+	if o.enrichers == nil {
+		return defaultEnrichers
+	}
+	return o.enrichers
+}
+
+// filterFlags holds the raw -label/-type/... flag values of extract.
+type filterFlags struct {
+	labels string
+	types  string
+	tail   string
+	flight string
+	since  string
+	until  string
+	text   string
+}
+
+// messageFilter selects messages and results for extract.  The message
+// checks (label, tail, flight, time window, text) run before dispatch so
+// rejected messages are never parsed; the type check runs on the results.
+// A nil *messageFilter accepts everything.
+type messageFilter struct {
+	labels map[string]bool
+	types  map[string]bool
+	tail   *nameMatcher
+	flight *nameMatcher
+	since  time.Time
+	until  time.Time
+	text   *regexp.Regexp
+}
+
+// newMessageFilter builds a filter from the flag values.  It returns nil when
+// no filter flag was given.
+func newMessageFilter(ff filterFlags) (*messageFilter, error) {
+	f := &messageFilter{
+		labels: splitSet(ff.labels, strings.ToUpper),
+		types:  splitSet(ff.types, strings.ToLower),
+	}
+	var err error
+	if f.tail, err = newNameMatcher(ff.tail, normaliseTail); err != nil {
+		return nil, fmt.Errorf("-tail: %w", err)
+	}
+	if f.flight, err = newNameMatcher(ff.flight, strings.ToUpper); err != nil {
+		return nil, fmt.Errorf("-flight: %w", err)
+	}
+	if ff.since != "" {
+		t, ok := decode.ParseTime(ff.since)
+		if !ok {
+			return nil, fmt.Errorf("-since: unrecognised time %q", ff.since)
+		}
+		f.since = t
+	}
+	if ff.until != "" {
+		t, ok := decode.ParseTime(ff.until)
+		if !ok {
+			return nil, fmt.Errorf("-until: unrecognised time %q", ff.until)
+		}
+		f.until = t
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && !f.until.After(f.since) {
+		return nil, fmt.Errorf("-until must be after -since")
+	}
+	if ff.text != "" {
+		if f.text, err = regexp.Compile(ff.text); err != nil {
+			return nil, fmt.Errorf("-text: %w", err)
+		}
+	}
+
+	if f.labels == nil && f.types == nil && f.tail == nil && f.flight == nil &&
+		f.since.IsZero() && f.until.IsZero() && f.text == nil {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// matchMessage reports whether msg passes every message-level filter.
+func (f *messageFilter) matchMessage(msg *acars.Message) bool {
+	if f == nil {
+		return true
+	}
+	if f.labels != nil && !f.labels[strings.ToUpper(strings.TrimSpace(msg.Label))] {
+		return false
+	}
+	if f.tail != nil {
+		tails := []string{msg.Tail}
+		if msg.Airframe != nil {
+			tails = append(tails, msg.Airframe.Tail)
+		}
+		if !f.tail.matchAny(tails) {
+			return false
+		}
+	}
+	if f.flight != nil {
+		var flights []string
+		if msg.Flight != nil {
+			raw := strings.TrimSpace(msg.Flight.Flight)
+			flights = append(flights, raw, airlines.TranslateFlight(raw))
+		}
+		if !f.flight.matchAny(flights) {
+			return false
+		}
+	}
+	if !f.since.IsZero() || !f.until.IsZero() {
+		// A message without a usable timestamp cannot be placed in the
+		// window, so it is dropped.
+		t, ok := decode.ParseTime(msg.Timestamp)
+		if !ok || (!f.since.IsZero() && t.Before(f.since)) || (!f.until.IsZero() && !t.Before(f.until)) {
+			return false
+		}
+	}
+	if f.text != nil && !f.text.MatchString(msg.Text) {
+		return false
+	}
+	return true
+}
+
+// filterResults keeps only the results whose Type is selected by -type.  The
+// second return value is false when a type filter is set and nothing is
+// left, in which case the message is not emitted at all, even with -all.
+func (f *messageFilter) filterResults(results []registry.Result) ([]registry.Result, bool) {
+	if f == nil || f.types == nil {
+		return results, true
+	}
+	kept := results[:0:0]
+	for _, r := range results {
+		if f.types[strings.ToLower(r.Type())] {
+			kept = append(kept, r)
+		}
+	}
+	return kept, len(kept) > 0
+}
+
+// nameMatcher matches tails or flights against a comma-separated list of
+// patterns.  A pattern enclosed in slashes is a regular expression; anything
+// else is a shell glob ("9A-*", "UAE?0?").  Both are case-insensitive.
+type nameMatcher struct {
+	normalise func(string) string
+	globs     []string
+	regexps   []*regexp.Regexp
+}
+
+func newNameMatcher(spec string, normalise func(string) string) (*nameMatcher, error) {
+	m := &nameMatcher{normalise: normalise}
+	for _, p := range strings.Split(spec, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if len(p) >= 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			re, err := regexp.Compile("(?i)" + p[1:len(p)-1])
+			if err != nil {
+				return nil, err
+			}
+			m.regexps = append(m.regexps, re)
+			continue
+		}
+		glob := normalise(p)
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		m.globs = append(m.globs, glob)
+	}
+	if len(m.globs) == 0 && len(m.regexps) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+func (m *nameMatcher) matchAny(values []string) bool {
+	for _, v := range values {
+		v = m.normalise(v)
+		if v == "" {
+			continue
+		}
+		for _, g := range m.globs {
+			if ok, _ := path.Match(g, v); ok {
+				return true
+			}
+		}
+		for _, re := range m.regexps {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normaliseTail upper-cases a registration and drops the leading dots that
+// JAERO and some ground stations prepend (".A6-BND").
+func normaliseTail(s string) string {
+	return strings.ToUpper(strings.TrimLeft(strings.TrimSpace(s), "."))
+}
+
+func splitSet(spec string, normalise func(string) string) map[string]bool {
+	var set map[string]bool
+	for _, v := range strings.Split(spec, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[normalise(v)] = true
+	}
+	return set
+}
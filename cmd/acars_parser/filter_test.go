@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+func TestMessageFilterMatchMessage(t *testing.T) {
+	f, err := newMessageFilter(filterFlags{
+		labels: "h1, SA",
+		tail:   "9a-*,/^A6-E/",
+		flight: "UAE*",
+		since:  "16:54:00 12-05-26",
+		until:  "2026-05-12T17:00:00Z",
+		text:   `^RESPWI`,
+	})
+	if err != nil {
+		t.Fatalf("newMessageFilter: %v", err)
+	}
+
+	base := acars.Message{
+		Label:     "H1",
+		Tail:      ".A6-EDA",
+		Timestamp: "2026-05-12T16:55:00Z",
+		Text:      "RESPWI/AC,091",
+		Flight:    &acars.Flight{Flight: "EK0806"},
+	}
+	if !f.matchMessage(&base) {
+		t.Fatal("base message should match (EK0806 translates to UAE806)")
+	}
+
+	mutations := map[string]func(m *acars.Message){
+		"label":       func(m *acars.Message) { m.Label = "16" },
+		"tail":        func(m *acars.Message) { m.Tail = "D-AIMM" },
+		"flight":      func(m *acars.Message) { m.Flight = &acars.Flight{Flight: "LH8P"} },
+		"no flight":   func(m *acars.Message) { m.Flight = nil },
+		"too early":   func(m *acars.Message) { m.Timestamp = "1778604839" }, // 16:53:59
+		"until":       func(m *acars.Message) { m.Timestamp = "17:00:00 12-05-26 UTC" },
+		"no time":     func(m *acars.Message) { m.Timestamp = "" },
+		"text":        func(m *acars.Message) { m.Text = "POSN42" },
+		"airframe ok": nil,
+	}
+	for name, mutate := range mutations {
+		m := base
+		if mutate == nil {
+			// The tail may also come from the airframe block.
+			m.Tail = ""
+			m.Airframe = &acars.Airframe{Tail: "9A-CTG"}
+			if !f.matchMessage(&m) {
+				t.Errorf("%s: should match", name)
+			}
+			continue
+		}
+		mutate(&m)
+		if f.matchMessage(&m) {
+			t.Errorf("%s: should not match", name)
+		}
+	}
+}
+
+func TestNewMessageFilterErrors(t *testing.T) {
+	if f, err := newMessageFilter(filterFlags{}); f != nil || err != nil {
+		t.Fatalf("no flags: got %v, %v; want nil filter", f, err)
+	}
+	for name, ff := range map[string]filterFlags{
+		"tail regex": {tail: "/[/"},
+		"tail glob":  {tail: "[A"},
+		"since":      {since: "last week"},
+		"since date": {since: "20260512"},
+		"window":     {since: "2026-05-12T10:00:00Z", until: "2026-05-12T09:00:00Z"},
+		"text":       {text: "("},
+	} {
+		if _, err := newMessageFilter(ff); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestExtractTypeFilter(t *testing.T) {
+	registry.Default().Sort()
+
+	run := func(ff filterFlags) ([]ExtractOut, Stats) {
+		f, err := newMessageFilter(ff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []ExtractOut
+		st := &Stats{}
+		emit := func(o ExtractOut) { out = append(out, o) }
+		opts := extractOptions{includeAll: true, filter: f}
+		input := liveTestPayload + "\n" + strings.Replace(liveTestPayload, `"label":"16"`, `"label":"XX"`, 1)
+		if err := processInput(strings.NewReader(input), "", nil, inlineDispatcher(emit, st), opts, false, st); err != nil {
+			t.Fatal(err)
+		}
+		return out, *st
+	}
+
+	out, st := run(filterFlags{types: "waypoint_position"})
+	if len(out) != 1 || st.Filtered != 1 || st.Emitted != 1 {
+		t.Fatalf("type filter: %d records, stats %+v", len(out), st)
+	}
+
+	out, st = run(filterFlags{labels: "xx"})
+	if len(out) != 1 || out[0].Message.Label != "XX" || st.Filtered != 1 {
+		t.Fatalf("label filter: %d records, stats %+v", len(out), st)
+	}
+
+	out, _ = run(filterFlags{types: "cpdlc"})
+	if len(out) != 0 {
+		t.Fatalf("type filter without matches emitted %d records", len(out))
+	}
+}
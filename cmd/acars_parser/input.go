@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// expandInputs turns the -input value and any extra arguments into a list of
+// files.  Each entry may be a file, a directory (read recursively, hidden
+// files skipped) or a glob pattern.  The result is sorted within each entry
+// and free of duplicates; entry order is kept.
+func expandInputs(specs []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			files = append(files, p)
+		}
+	}
+
+	for _, spec := range specs {
+		var matches []string
+		if strings.ContainsAny(spec, "*?[") {
+			m, err := filepath.Glob(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", spec, err)
+			}
+			if len(m) == 0 {
+				return nil, fmt.Errorf("no files match %q", spec)
+			}
+			matches = m
+		} else {
+			matches = []string{spec}
+		}
+		sort.Strings(matches)
+
+		for _, m := range matches {
+			fi, err := os.Stat(m)
+			if err != nil {
+				return nil, err
+			}
+			if !fi.IsDir() {
+				add(m)
+				continue
+			}
+			err = filepath.WalkDir(m, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if p != m && strings.HasPrefix(d.Name(), ".") {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.Type().IsRegular() {
+					add(p)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// parseInterspersed parses args like fs.Parse but also accepts flags after
+// positional arguments, so "-input a.gz b.gz -stats" works.  It returns the
+// positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var rest []string
+	for {
+		_ = fs.Parse(args)
+		if n := len(args) - fs.NArg(); n > 0 && args[n-1] == "--" {
+			return append(rest, fs.Args()...)
+		}
+		args = fs.Args()
+		for len(args) > 0 && (args[0] == "-" || !strings.HasPrefix(args[0], "-")) {
+			rest = append(rest, args[0])
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return rest
+		}
+	}
+}
+
+// openInput opens path and transparently decompresses it when it starts with
+// a gzip or zstd header.  The file extension is ignored.
+func openInput(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decompressReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &inputFile{Reader: r, closers: []io.Closer{r, f}}, nil
+}
+
+// decompressReader sniffs the first bytes of r and wraps it in the matching
+// decompressor.  The returned ReadCloser does not close r.
+func decompressReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// inputFile closes the decompressor before the underlying file.
+type inputFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *inputFile) Close() error {
+	var first error
+	for _, c := range f.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// lineScanner is a bufio.Scanner that knows the 1-based number of the line it
+// last returned.
+type lineScanner struct {
+	*bufio.Scanner
+	line int
+}
+
+func (s *lineScanner) Scan() bool {
+	if !s.Scanner.Scan() {
+		return false
+	}
+	s.line++
+	return true
+}
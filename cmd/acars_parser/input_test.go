@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"acars_parser/internal/registry"
+)
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(data))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = zw.Write([]byte(data))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.jsonl", "a.jsonl.gz", "sub/c.txt.zst", ".hidden", ".git/x.jsonl"} {
+		writeFile(t, filepath.Join(dir, name), []byte("{}\n"))
+	}
+
+	got, err := expandInputs([]string{dir})
+	if err != nil {
+		t.Fatalf("expand dir: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "a.jsonl.gz"),
+		filepath.Join(dir, "b.jsonl"),
+		filepath.Join(dir, "sub/c.txt.zst"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dir: got %v, want %v", got, want)
+	}
+
+	got, err = expandInputs([]string{filepath.Join(dir, "*.jsonl*"), filepath.Join(dir, "b.jsonl")})
+	if err != nil {
+		t.Fatalf("expand glob: %v", err)
+	}
+	if !reflect.DeepEqual(got, want[:2]) {
+		t.Fatalf("glob: got %v, want %v", got, want[:2])
+	}
+
+	if _, err := expandInputs([]string{filepath.Join(dir, "*.none")}); err == nil {
+		t.Fatal("expected an error for a glob without matches")
+	}
+}
+
+func TestOpenInputDetectsCompression(t *testing.T) {
+	const data = "hello\nworld\n"
+	dir := t.TempDir()
+	// Extensions are deliberately misleading: only the magic bytes count.
+	files := map[string][]byte{
+		"plain.gz":  []byte(data),
+		"gzip.txt":  gzipBytes(t, data),
+		"zstd.json": zstdBytes(t, data),
+		"empty":     nil,
+	}
+	for name, b := range files {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, b)
+		r, err := openInput(path)
+		if err != nil {
+			t.Fatalf("%s: open: %v", name, err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("%s: read: %v", name, err)
+		}
+		want := data
+		if b == nil {
+			want = ""
+		}
+		if string(got) != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestProcessInputRecordsOrigin(t *testing.T) {
+	registry.Default().Sort()
+
+	run := func(input string) []ExtractOut {
+		var out []ExtractOut
+		st := &Stats{}
+		emit := func(o ExtractOut) { out = append(out, o) }
+		if err := processInput(strings.NewReader(input), "day.log", nil, inlineDispatcher(emit, st), extractOptions{includeAll: true}, false, st); err != nil {
+			t.Fatalf("processInput: %v", err)
+		}
+		return out
+	}
+
+	jsonl := run("\n" + liveTestPayload + "\n\n" + liveTestPayload + "\n")
+	if len(jsonl) != 2 {
+		t.Fatalf("jsonl: got %d records", len(jsonl))
+	}
+	for i, wantLine := range []int{2, 4} {
+		if o := jsonl[i].Origin; o == nil || o.File != "day.log" || o.Line != wantLine {
+			t.Errorf("jsonl record %d origin = %+v, want day.log:%d", i, o, wantLine)
+		}
+	}
+
+	// The same process also detects JAERO on its own.
+	jaero := run(jaeroStreamSample)
+	if len(jaero) != 3 {
+		t.Fatalf("jaero: got %d records", len(jaero))
+	}
+	for i, wantLine := range []int{1, 5, 13} {
+		if o := jaero[i].Origin; o == nil || o.Line != wantLine {
+			t.Errorf("jaero record %d origin = %+v, want line %d", i, o, wantLine)
+		}
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("extract", flag.ContinueOnError)
+	in := fs.String("input", "", "")
+	stats := fs.Bool("stats", false, "")
+
+	rest := parseInterspersed(fs, []string{"-input", "a.gz", "b.gz", "c.gz", "-stats", "--", "-d.gz"})
+	if *in != "a.gz" || !*stats {
+		t.Fatalf("input = %q, stats = %v", *in, *stats)
+	}
+	if want := []string{"b.gz", "c.gz", "-d.gz"}; !reflect.DeepEqual(rest, want) {
+		t.Fatalf("rest = %v, want %v", rest, want)
+	}
+}
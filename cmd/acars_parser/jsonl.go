@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonlWriter writes one ExtractOut per line.  Every record is flushed as soon
+// as it is written so that consumers reading the stream (tail -f, jq, a
+// downstream pipe) see results immediately instead of waiting for a buffer to
+// fill up.
+//
+// Errors are sticky: after the first failed write every further Write is a
+// no-op that returns the same error, which lets producers that cannot return
+// an error (an emitFunc) write unconditionally and check Err once at the end.
+type jsonlWriter struct {
+	bw  *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	bw := bufio.NewWriter(w)
+	return &jsonlWriter{bw: bw, enc: json.NewEncoder(bw)}
+}
+
+// Write encodes a single record followed by a newline and flushes it.
+func (j *jsonlWriter) Write(out ExtractOut) error {
+	if j.err != nil {
+		return j.err
+	}
+	if err := j.enc.Encode(out); err != nil {
+		j.err = err
+		return err
+	}
+	j.err = j.bw.Flush()
+	return j.err
+}
+
+// Err returns the first write error, if any.
+func (j *jsonlWriter) Err() error {
+	return j.err
+}
+
+// recordSink is where live and listen send their records: a JSONL writer,
+// optionally behind a streaming deduper, and the state database when one is
+// set.
+type recordSink struct {
+	w     *jsonlWriter
+	dedup *deduper
+	state *stateTracker
+}
+
+// newRecordSink writes to w, suppressing duplicates within dedupWindow when
+// it is positive.
+func newRecordSink(w *jsonlWriter, dedupWindow time.Duration) *recordSink {
+	s := &recordSink{w: w}
+	if dedupWindow > 0 {
+		s.dedup = newDeduper(dedupWindow, true, s.write)
+	}
+	return s
+}
+
+func (s *recordSink) emit(out ExtractOut) {
+	if s.dedup != nil {
+		s.dedup.Add(out)
+		return
+	}
+	s.write(out)
+}
+
+// write tracks and writes a record that has passed duplicate suppression.
+func (s *recordSink) write(out ExtractOut) {
+	if s.state != nil {
+		s.state.track(out)
+	}
+	_ = s.w.Write(out)
+}
+
+// tick releases held records whose window has passed by the wall clock.
+func (s *recordSink) tick() {
+	if s.dedup != nil {
+		s.dedup.tick()
+	}
+}
+
+// flush writes every held record.
+func (s *recordSink) flush() {
+	if s.dedup != nil {
+		s.dedup.Flush()
+	}
+}
+
+func (s *recordSink) duplicates() int {
+	if s.dedup == nil {
+		return 0
+	}
+	return s.dedup.duplicates
+}
+
+// Err returns the first write error, if any.
+func (s *recordSink) Err() error {
+	return s.w.Err()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+const jaeroStreamSample = `16:54:24 12-05-26 UTC AES:3C65AD GES:90 2 .D-AIMM ! B0 2 AIRBUS A380 841 LUFTHANSA FLIGHT LH8P
+
+	/PIKCPYA.AFN/FMHDLH8P,.D-AIMM,,165418/FRP05A3A
+
+16:54:25 12-05-26 UTC AES:75044A GES:90 2 .9M-MAC ! SA 5 AIRBUS A350 941 MALAYSIA AIRLINES FLIGHT MH3
+
+	0LV165411S/
+
+	MEDIA ADVISORY, VERSION 0:
+	 LINK VHF ACARS LOST AT 16:54:11 UTC
+	 AVAILABLE LINKS: DEFAULT SATCOM
+
+16:54:26 12-05-26 UTC AES:AB9D9E GES:90 2 .N848AN ! H1 4 FLIGHT AA91
+
+	RESPWI/AC,091/TS165418,12052627CA
+`
+
+// runJAERO feeds sample through processJAEROInput and returns the records it
+// emitted, in order.
+func runJAERO(t *testing.T, sample string, stream bool) []ExtractOut {
+	t.Helper()
+	var out []ExtractOut
+	emit := func(o ExtractOut) { out = append(out, o) }
+	st := &Stats{}
+	if err := processInput(strings.NewReader(sample), "", nil, inlineDispatcher(emit, st), extractOptions{includeAll: true}, stream, st); err != nil {
+		t.Fatalf("processInput: %v", err)
+	}
+	return out
+}
+
+func TestJAEROStreamMatchesBuffered(t *testing.T) {
+	registry.Default().Sort()
+
+	buffered := runJAERO(t, jaeroStreamSample, false)
+	streamed := runJAERO(t, jaeroStreamSample, true)
+	if len(buffered) != 3 {
+		t.Fatalf("buffered mode emitted %d records, want 3", len(buffered))
+	}
+
+	// Compare the JSON encodings, which is what both output modes write.
+	var want bytes.Buffer
+	enc := json.NewEncoder(&want)
+	for _, o := range buffered {
+		if err := enc.Encode(o); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+	}
+	var got bytes.Buffer
+	w := newJSONLWriter(&got)
+	for _, o := range streamed {
+		if err := w.Write(o); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if got.String() != want.String() {
+		t.Fatalf("stream output differs from buffered output:\n got: %s\nwant: %s", got.String(), want.String())
+	}
+}
+
+func TestJSONLWriterOneRecordPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := newJSONLWriter(&buf)
+	for i := int64(1); i <= 2; i++ {
+		if err := w.Write(ExtractOut{Message: &OutputMessage{ID: acars.FlexInt64(i)}}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if !reflect.DeepEqual(m["message"].(map[string]any)["id"], float64(i+1)) {
+			t.Fatalf("line %d = %s", i, line)
+		}
+	}
+}
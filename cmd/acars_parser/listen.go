@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"acars_parser/internal/decode"
+)
+
+// maxListenDatagram is the largest UDP datagram we accept.  acarsdec,
+// dumpvdl2 and dumphfdl send one JSON object per datagram, well below this.
+const maxListenDatagram = 64 * 1024
+
+// maxListenLine bounds a single JSON line read from a TCP connection.
+const maxListenLine = 4 * 1024 * 1024
+
+// listenSpec is one socket given on the command line as [name=]host:port.
+type listenSpec struct {
+	name  string
+	proto string
+	addr  string
+}
+
+// listenSpecs collects repeated -udp / -tcp flags.
+type listenSpecs struct {
+	proto string
+	specs *[]listenSpec
+}
+
+func (l listenSpecs) String() string {
+	if l.specs == nil {
+		return ""
+	}
+	var parts []string
+	for _, s := range *l.specs {
+		if s.proto == l.proto {
+			parts = append(parts, s.name+"="+s.addr)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l listenSpecs) Set(v string) error {
+	spec, err := parseListenSpec(l.proto, v)
+	if err != nil {
+		return err
+	}
+	*l.specs = append(*l.specs, spec)
+	return nil
+}
+
+// parseListenSpec parses "[name=]host:port".  Without a name the socket is
+// called "<proto>:<addr>".
+func parseListenSpec(proto, v string) (listenSpec, error) {
+	name, addr := "", strings.TrimSpace(v)
+	if i := strings.Index(addr, "="); i >= 0 {
+		name, addr = strings.TrimSpace(addr[:i]), strings.TrimSpace(addr[i+1:])
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return listenSpec{}, fmt.Errorf("invalid %s address %q: %v", proto, v, err)
+	}
+	if name == "" {
+		name = proto + ":" + addr
+	}
+	return listenSpec{name: name, proto: proto, addr: addr}, nil
+}
+
+// listener is a bound socket together with its counters.  packets and conns
+// are updated by the reader goroutines; st is only touched by the dispatch
+// loop.
+type listener struct {
+	spec    listenSpec
+	local   string
+	packets atomic.Int64
+	conns   atomic.Int64
+	st      Stats
+
+	pc net.PacketConn
+	ln net.Listener
+
+	mu        sync.Mutex
+	closed    bool
+	openConns map[net.Conn]struct{}
+}
+
+// listenPacket is one chunk of input handed from a reader to the dispatch
+// loop.
+type listenPacket struct {
+	l      *listener
+	remote string
+	data   []byte
+}
+
+func runListen(args []string) {
+	fs := flag.NewFlagSet("listen", flag.ExitOnError)
+	var specs []listenSpec
+	fs.Var(listenSpecs{proto: "udp", specs: &specs}, "udp", "UDP socket to listen on as [name=]host:port (repeatable)")
+	fs.Var(listenSpecs{proto: "tcp", specs: &specs}, "tcp", "TCP socket to listen on as [name=]host:port (repeatable)")
+	outPath := fs.String("output", "", "Output JSONL file, appended to (default: stdout)")
+	rotateSize := fs.Int64("rotate-size", 0, "Rotate the output file once it exceeds this many MiB (0 = never)")
+	rotateInterval := fs.Duration("rotate-interval", 0, "Rotate the output file after this long (e.g. 1h; 0 = never)")
+	rotateKeep := fs.Int("rotate-keep", 10, "Number of rotated output files to keep")
+	includeAll := fs.Bool("all", false, "Include messages even if no parser matched")
+	statsEvery := fs.Duration("stats-interval", 0, "Also print per-socket counters to stderr at this interval (0 = only on exit)")
+	profileParsers := fs.Bool("profile-parsers", false, "Print per-parser call counts and timings to stderr on exit")
+	var pf parserFlags
+	pf.register(fs)
+	dedupWindow := fs.Duration("dedup", 0, "Collapse copies of the same message (tail, label, text) heard within this window, e.g. 30s")
+	blockTimeout := fs.Duration("block-timeout", decode.DefaultBlockTimeout, "Join multi-block ACARS messages whose blocks arrive within this time of each other (0 disables)")
+	stateDB := fs.String("state-db", "", "Also update this SQLite state database with every record written (see track)")
+	_ = fs.Parse(args)
+
+	if len(specs) == 0 {
+		fmt.Fprintln(os.Stderr, "listen: at least one -udp or -tcp socket is required")
+		os.Exit(2)
+	}
+	seen := make(map[string]bool, len(specs))
+	for _, s := range specs {
+		if seen[s.name] {
+			fmt.Fprintf(os.Stderr, "listen: duplicate socket name %q\n", s.name)
+			os.Exit(2)
+		}
+		seen[s.name] = true
+	}
+	if (*rotateSize > 0 || *rotateInterval > 0) && *outPath == "" {
+		fmt.Fprintln(os.Stderr, "listen: -rotate-size and -rotate-interval require -output")
+		os.Exit(2)
+	}
+
+	parsers, err := newParserRegistry(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parser setup: %v\n", err)
+		os.Exit(2)
+	}
+	enrichers, err := newEnrichChain(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid enricher setup: %v\n", err)
+		os.Exit(2)
+	}
+	if *profileParsers {
+		parsers.EnableMetrics()
+	}
+
+	var wout io.Writer = os.Stdout
+	if *outPath != "" {
+		rf, err := openRotatingFile(*outPath, *rotateSize*1024*1024, *rotateInterval, *rotateKeep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open output: %v\n", err)
+			os.Exit(1)
+		}
+		defer rf.Close()
+		wout = rf
+	}
+
+	var tracker *stateTracker
+	if *stateDB != "" {
+		if tracker, err = openStateTracker(*stateDB); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer tracker.Close()
+	}
+
+	listeners := make([]*listener, 0, len(specs))
+	for _, s := range specs {
+		l, err := openListener(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "listen: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "listen: %s listening on %s/%s\n", s.name, s.proto, l.local)
+		listeners = append(listeners, l)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sink := newRecordSink(newJSONLWriter(wout), *dedupWindow)
+	sink.state = tracker
+	opts := extractOptions{includeAll: *includeAll, blockTimeout: *blockTimeout, parsers: parsers, enrichers: enrichers}
+	err = listenLoop(ctx, listeners, sink, opts, *statsEvery, os.Stderr)
+	writeListenStats(os.Stderr, listeners)
+	if sink.dedup != nil {
+		fmt.Fprintf(os.Stderr, "stats: duplicates=%d\n", sink.duplicates())
+	}
+	if tracker != nil {
+		tracker.writeSummary(os.Stderr, false)
+	}
+	if *profileParsers {
+		writeParserProfile(os.Stderr, parsers.Metrics())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "listen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// openListener binds the socket described by spec.
+func openListener(spec listenSpec) (*listener, error) {
+	l := &listener{spec: spec, openConns: make(map[net.Conn]struct{})}
+	switch spec.proto {
+	case "udp":
+		pc, err := net.ListenPacket("udp", spec.addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.name, err)
+		}
+		l.pc = pc
+		l.local = pc.LocalAddr().String()
+	case "tcp":
+		ln, err := net.Listen("tcp", spec.addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.name, err)
+		}
+		l.ln = ln
+		l.local = ln.Addr().String()
+	default:
+		return nil, fmt.Errorf("%s: unsupported protocol %q", spec.name, spec.proto)
+	}
+	return l, nil
+}
+
+// close shuts the socket and any accepted connections, which unblocks the
+// reader goroutines.
+func (l *listener) close() {
+	if l.pc != nil {
+		_ = l.pc.Close()
+	}
+	if l.ln != nil {
+		_ = l.ln.Close()
+	}
+	l.mu.Lock()
+	l.closed = true
+	for c := range l.openConns {
+		_ = c.Close()
+	}
+	l.mu.Unlock()
+}
+
+// serve reads from the socket until it is closed and forwards every packet
+// (UDP) or line (TCP) to ch.
+func (l *listener) serve(ctx context.Context, ch chan<- listenPacket) {
+	send := func(remote string, data []byte) bool {
+		l.packets.Add(1)
+		select {
+		case ch <- listenPacket{l: l, remote: remote, data: data}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if l.pc != nil {
+		buf := make([]byte, maxListenDatagram)
+		for {
+			n, addr, err := l.pc.ReadFrom(buf)
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					fmt.Fprintf(os.Stderr, "listen: %s: %v\n", l.spec.name, err)
+				}
+				return
+			}
+			if !send(addr.String(), append([]byte(nil), buf[:n]...)) {
+				return
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		c, err := l.ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "listen: %s: %v\n", l.spec.name, err)
+			}
+			return
+		}
+		l.conns.Add(1)
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			_ = c.Close()
+			return
+		}
+		l.openConns[c] = struct{}{}
+		l.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				l.mu.Lock()
+				delete(l.openConns, c)
+				l.mu.Unlock()
+				_ = c.Close()
+			}()
+			remote := c.RemoteAddr().String()
+			sc := bufio.NewScanner(c)
+			sc.Buffer(make([]byte, 0, 64*1024), maxListenLine)
+			for sc.Scan() {
+				if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+					continue
+				}
+				if !send(remote, append([]byte(nil), sc.Bytes()...)) {
+					return
+				}
+			}
+			if err := sc.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "listen: %s: %s: %v\n", l.spec.name, remote, err)
+			}
+		}()
+	}
+}
+
+// listenLoop reads from every listener until ctx is cancelled.  Parsing and
+// writing happen on the calling goroutine, so the per-socket Stats need no
+// locking.  When statsEvery is positive the counters are also written to
+// statsOut at that interval.  Blocks of multi-block messages are joined
+// across all sockets.  On shutdown, incomplete multi-block messages and
+// records still held for duplicate suppression are written before returning.
+func listenLoop(ctx context.Context, listeners []*listener, w *recordSink, opts extractOptions, statsEvery time.Duration, statsOut io.Writer) error {
+	ch := make(chan listenPacket, 1024)
+	readCtx, cancelRead := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, l := range listeners {
+		wg.Add(1)
+		go func(l *listener) {
+			defer wg.Done()
+			l.serve(readCtx, ch)
+		}(l)
+	}
+	shutdown := func() {
+		for _, l := range listeners {
+			l.close()
+		}
+		cancelRead()
+		wg.Wait()
+	}
+
+	var tick <-chan time.Time
+	if statsEvery > 0 {
+		t := time.NewTicker(statsEvery)
+		defer t.Stop()
+		tick = t.C
+	}
+	var blocks *decode.BlockAssembler
+	if opts.blockTimeout > 0 {
+		blocks = decode.NewBlockAssembler(opts.blockTimeout)
+	}
+	var holdTick <-chan time.Time
+	if w.dedup != nil || blocks != nil {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		holdTick = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			shutdown()
+			for {
+				select {
+				case p := <-ch:
+					if err := handleListenPacket(p, w, blocks, opts); err != nil {
+						return err
+					}
+				default:
+					if blocks != nil {
+						blocks.Flush()
+					}
+					w.flush()
+					if err := w.Err(); err != nil {
+						return fmt.Errorf("write output: %w", err)
+					}
+					return nil
+				}
+			}
+		case p := <-ch:
+			if err := handleListenPacket(p, w, blocks, opts); err != nil {
+				shutdown()
+				return err
+			}
+		case <-tick:
+			writeListenStats(statsOut, listeners)
+		case <-holdTick:
+			if blocks != nil {
+				blocks.Tick()
+			}
+			w.tick()
+		}
+	}
+}
+
+// handleListenPacket runs every JSON line in a packet through the same
+// autodetection and dispatch path as extract, tagging each record with the
+// socket it arrived on.  Only write errors are returned.
+func handleListenPacket(p listenPacket, w *recordSink, blocks *decode.BlockAssembler, opts extractOptions) error {
+	origin := &RecordOrigin{Listener: p.l.spec.name, Proto: p.l.spec.proto, Remote: p.remote}
+	emit := withOrigin(w.emit, origin)
+	for _, raw := range bytes.Split(p.data, []byte("\n")) {
+		line := strings.TrimSpace(string(raw))
+		if line == "" {
+			continue
+		}
+		p.l.st.Lines++
+		processBlockLine(line, emit, blocks, opts, &p.l.st)
+	}
+	if err := w.Err(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+func writeListenStats(w io.Writer, listeners []*listener) {
+	for _, l := range listeners {
+		st := l.st
+		conns := ""
+		if l.spec.proto == "tcp" {
+			conns = fmt.Sprintf(" conns=%d", l.conns.Load())
+		}
+		fmt.Fprintf(w,
+			"stats: %s (%s/%s) packets=%d%s lines=%d parsed(nats=%d flat=%d nested=%d) skipped(no_label_text)=%d emitted=%d matched=%d reassembled=%d partial=%d parser_errors=%d\n",
+			l.spec.name, l.spec.proto, l.local, l.packets.Load(), conns,
+			st.Lines, st.ParsedNATS, st.ParsedFlat, st.ParsedNested, st.SkippedNoLabel, st.Emitted, st.Matched, st.Reassembled, st.Partial, st.ParserErrors,
+		)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"acars_parser/internal/registry"
+)
+
+// lockedBuffer is a bytes.Buffer that can be read while listenLoop writes.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestParseListenSpec(t *testing.T) {
+	tests := []struct {
+		in   string
+		want listenSpec
+	}{
+		{"acarsdec=:5550", listenSpec{name: "acarsdec", proto: "udp", addr: ":5550"}},
+		{"127.0.0.1:5555", listenSpec{name: "udp:127.0.0.1:5555", proto: "udp", addr: "127.0.0.1:5555"}},
+	}
+	for _, tt := range tests {
+		got, err := parseListenSpec("udp", tt.in)
+		if err != nil {
+			t.Fatalf("parseListenSpec(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("parseListenSpec(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parseListenSpec("udp", "vdl2=5555"); err == nil {
+		t.Error("expected an error for an address without a port")
+	}
+}
+
+func TestListenLoopUDPAndTCP(t *testing.T) {
+	registry.Default().Sort()
+
+	udp, err := openListener(listenSpec{name: "acarsdec", proto: "udp", addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("open udp: %v", err)
+	}
+	tcp, err := openListener(listenSpec{name: "hfdl", proto: "tcp", addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("open tcp: %v", err)
+	}
+	listeners := []*listener{udp, tcp}
+
+	var out lockedBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- listenLoop(ctx, listeners, newRecordSink(newJSONLWriter(&out), 0), extractOptions{}, 0, nil)
+	}()
+
+	uc, err := net.Dial("udp", udp.local)
+	if err != nil {
+		t.Fatalf("dial udp: %v", err)
+	}
+	defer uc.Close()
+	if _, err := uc.Write([]byte(liveTestPayload)); err != nil {
+		t.Fatalf("udp write: %v", err)
+	}
+
+	tc, err := net.Dial("tcp", tcp.local)
+	if err != nil {
+		t.Fatalf("dial tcp: %v", err)
+	}
+	// Two records in one write, plus one line nothing can decode.
+	if _, err := tc.Write([]byte(liveTestPayload + "\n" + liveTestPayload + "\n{\"unrelated\":true}\n")); err != nil {
+		t.Fatalf("tcp write: %v", err)
+	}
+	tc.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for strings.Count(out.String(), "\n") < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("listenLoop: %v", err)
+	}
+
+	perListener := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var rec struct {
+			Origin  RecordOrigin     `json:"origin"`
+			Results []map[string]any `json:"results"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		if rec.Origin.Remote == "" || len(rec.Results) == 0 {
+			t.Fatalf("record missing origin or results: %s", line)
+		}
+		perListener[rec.Origin.Listener+"/"+rec.Origin.Proto]++
+	}
+	if perListener["acarsdec/udp"] != 1 || perListener["hfdl/tcp"] != 2 {
+		t.Fatalf("records per listener = %v", perListener)
+	}
+
+	if got := udp.packets.Load(); got != 1 {
+		t.Errorf("udp packets = %d, want 1", got)
+	}
+	if got := tcp.packets.Load(); got != 3 {
+		t.Errorf("tcp packets = %d, want 3", got)
+	}
+	if tcp.conns.Load() != 1 {
+		t.Errorf("tcp conns = %d, want 1", tcp.conns.Load())
+	}
+	if tcp.st.Lines != 3 || tcp.st.Emitted != 2 || tcp.st.SkippedNoLabel != 1 {
+		t.Errorf("tcp stats = %+v", tcp.st)
+	}
+
+	var stats bytes.Buffer
+	writeListenStats(&stats, listeners)
+	if !strings.Contains(stats.String(), "stats: hfdl (tcp/") || !strings.Contains(stats.String(), "conns=1") {
+		t.Errorf("unexpected stats output:\n%s", stats.String())
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"acars_parser/internal/decode"
+)
+
+// defaultLiveSubject matches every message-created event on the ingest feed.
+const defaultLiveSubject = "v1.aircraft.ingest.*.message.*.created"
+
+// liveReconnectWait is the delay between NATS reconnect attempts.  The client
+// retries forever; a feed outage should never terminate a long-running live
+// session.
+const liveReconnectWait = 2 * time.Second
+
+func runLive(args []string) {
+	fs := flag.NewFlagSet("live", flag.ExitOnError)
+	server := fs.String("server", nats.DefaultURL, "NATS server URL")
+	subject := fs.String("subject", defaultLiveSubject, "NATS subject to subscribe to")
+	creds := fs.String("creds", "", "NATS credentials file (optional)")
+	outPath := fs.String("output", "", "Output JSONL file, appended to (default: stdout)")
+	includeAll := fs.Bool("all", false, "Include messages even if no parser matched")
+	showStats := fs.Bool("stats", false, "Print basic counters to stderr on exit")
+	profileParsers := fs.Bool("profile-parsers", false, "Print per-parser call counts and timings to stderr on exit")
+	var pf parserFlags
+	pf.register(fs)
+	dedupWindow := fs.Duration("dedup", 0, "Collapse copies of the same message (tail, label, text) heard within this window, e.g. 30s")
+	blockTimeout := fs.Duration("block-timeout", decode.DefaultBlockTimeout, "Join multi-block ACARS messages whose blocks arrive within this time of each other (0 disables)")
+	stateDB := fs.String("state-db", "", "Also update this SQLite state database with every record written (see track)")
+	_ = fs.Parse(args)
+
+	parsers, err := newParserRegistry(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parser setup: %v\n", err)
+		os.Exit(2)
+	}
+	enrichers, err := newEnrichChain(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid enricher setup: %v\n", err)
+		os.Exit(2)
+	}
+	if *profileParsers {
+		parsers.EnableMetrics()
+	}
+
+	var wout io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.OpenFile(*outPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open output: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		wout = f
+	}
+
+	var tracker *stateTracker
+	if *stateDB != "" {
+		if tracker, err = openStateTracker(*stateDB); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		defer tracker.Close()
+	}
+
+	nc, err := nats.Connect(*server, liveNATSOptions(*creds, os.Stderr)...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to NATS: %v\n", err)
+		os.Exit(1)
+	}
+	defer nc.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "live: connected to %s, subscribed to %s\n", nc.ConnectedUrl(), *subject)
+
+	st := &Stats{}
+	sink := newRecordSink(newJSONLWriter(wout), *dedupWindow)
+	sink.state = tracker
+	if err := liveLoop(ctx, nc, *subject, sink, extractOptions{includeAll: *includeAll, blockTimeout: *blockTimeout, parsers: parsers, enrichers: enrichers}, st); err != nil {
+		fmt.Fprintf(os.Stderr, "live: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *showStats {
+		fmt.Fprintf(os.Stderr,
+			"stats: messages=%d parsed(nats=%d flat=%d nested=%d) skipped(no_label_text)=%d emitted=%d matched=%d duplicates=%d reassembled=%d partial=%d parser_errors=%d\n",
+			st.Lines, st.ParsedNATS, st.ParsedFlat, st.ParsedNested, st.SkippedNoLabel, st.Emitted, st.Matched, st.Duplicates, st.Reassembled, st.Partial, st.ParserErrors,
+		)
+	}
+	if tracker != nil {
+		tracker.writeSummary(os.Stderr, false)
+	}
+	if *profileParsers {
+		writeParserProfile(os.Stderr, parsers.Metrics())
+	}
+}
+
+// liveNATSOptions returns the client options of a live session: retry
+// forever and log disconnects and reconnects to logw.
+func liveNATSOptions(creds string, logw io.Writer) []nats.Option {
+	opts := []nats.Option{
+		nats.Name("acars_parser live"),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(liveReconnectWait),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				fmt.Fprintf(logw, "live: disconnected: %v\n", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			fmt.Fprintf(logw, "live: reconnected to %s\n", nc.ConnectedUrl())
+		}),
+	}
+	if creds != "" {
+		opts = append(opts, nats.UserCredentials(creds))
+	}
+	return opts
+}
+
+// liveLoop subscribes to subject and writes parsed results until ctx is
+// cancelled.  Messages are delivered through a channel and handled on the
+// calling goroutine, so Stats needs no locking.  On shutdown the
+// subscription is removed and anything already buffered is still written,
+// including incomplete multi-block messages and records held back for
+// duplicate suppression.
+func liveLoop(ctx context.Context, nc *nats.Conn, subject string, w *recordSink, opts extractOptions, st *Stats) error {
+	ch := make(chan *nats.Msg, 1024)
+	sub, err := nc.ChanSubscribe(subject, ch)
+	if err != nil {
+		return fmt.Errorf("subscribe %s: %w", subject, err)
+	}
+	if err := nc.Flush(); err != nil {
+		_ = sub.Unsubscribe()
+		return fmt.Errorf("subscribe %s: %w", subject, err)
+	}
+
+	var blocks *decode.BlockAssembler
+	if opts.blockTimeout > 0 {
+		blocks = decode.NewBlockAssembler(opts.blockTimeout)
+	}
+	var tick <-chan time.Time
+	if w.dedup != nil || blocks != nil {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		tick = t.C
+	}
+	finish := func() error {
+		if blocks != nil {
+			blocks.Flush()
+		}
+		w.flush()
+		st.Duplicates = w.duplicates()
+		if err := w.Err(); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = sub.Unsubscribe()
+			for {
+				select {
+				case m := <-ch:
+					if err := handleLiveMessage(m.Data, w, blocks, opts, st); err != nil {
+						return err
+					}
+				default:
+					return finish()
+				}
+			}
+		case m := <-ch:
+			if err := handleLiveMessage(m.Data, w, blocks, opts, st); err != nil {
+				return err
+			}
+		case <-tick:
+			if blocks != nil {
+				blocks.Tick()
+			}
+			w.tick()
+		}
+	}
+}
+
+// handleLiveMessage runs one NATS payload through the same autodetection and
+// dispatch path as extract and writes every resulting record.  Only write
+// errors are returned; undecodable payloads are counted and skipped.
+func handleLiveMessage(data []byte, w *recordSink, blocks *decode.BlockAssembler, opts extractOptions, st *Stats) error {
+	st.Lines++
+	line := strings.TrimSpace(string(data))
+	if line == "" {
+		return nil
+	}
+	processBlockLine(line, w.emit, blocks, opts, st)
+	if err := w.Err(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+
+	"acars_parser/internal/registry"
+)
+
+const liveTestPayload = `{"source":{"name":"test","application":"acars"},"airframe":{"tail":"9A-CTG","icao":"501C5A"},"message":{"id":7,"timestamp":"2026-03-13T09:26:09Z","label":"16","text":"POSA1N42851E 16405,GIS40  ,092609,380,ROTAR  ,100331,,-58, 22, 306,844","tail":"9A-CTG"}}`
+
+func TestHandleLiveMessageWritesJSONL(t *testing.T) {
+	registry.Default().Sort()
+
+	var buf bytes.Buffer
+	st := &Stats{}
+	w := newRecordSink(newJSONLWriter(&buf), 0)
+
+	if err := handleLiveMessage([]byte(liveTestPayload), w, nil, extractOptions{}, st); err != nil {
+		t.Fatalf("handleLiveMessage: %v", err)
+	}
+	if err := handleLiveMessage([]byte(`{"unrelated":true}`), w, nil, extractOptions{}, st); err != nil {
+		t.Fatalf("handleLiveMessage (unrelated): %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("got %d output lines, want 1:\n%s", len(lines), buf.String())
+	}
+
+	var out struct {
+		Message OutputMessage    `json:"message"`
+		Results []map[string]any `json:"results"`
+	}
+	if err := json.Unmarshal(lines[0], &out); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if out.Message.Tail != "9A-CTG" || out.Message.Label != "16" {
+		t.Fatalf("message = %+v, want tail 9A-CTG label 16", out.Message)
+	}
+	if len(out.Results) == 0 || out.Results[0]["waypoint"] != "POSA" {
+		t.Fatalf("results = %v, want a waypoint_position result", out.Results)
+	}
+
+	if st.Lines != 2 || st.ParsedNATS != 1 || st.SkippedNoLabel != 1 || st.Emitted != 1 || st.Matched != 1 {
+		t.Fatalf("stats = %+v", *st)
+	}
+}
+
+// TestLiveLoopAgainstServer runs the subscribe, parse and write path
+// against an embedded NATS server, restarts the server to check the session
+// reconnects and keeps its subscription, and then shuts the loop down.
+func TestLiveLoopAgainstServer(t *testing.T) {
+	registry.Default().Sort()
+
+	opts := natstest.DefaultTestOptions
+	opts.Port = -1
+	srv := natstest.RunServer(&opts)
+	defer func() { srv.Shutdown() }()
+	opts.Port = srv.Addr().(*net.TCPAddr).Port
+	url := srv.ClientURL()
+
+	var logs bytes.Buffer
+	var logMu sync.Mutex
+	nc, err := nats.Connect(url, append(liveNATSOptions("", lockedWriter{&logMu, &logs}), nats.ReconnectWait(20*time.Millisecond))...)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer nc.Close()
+
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	subject := "acars_parser.test." + nats.NewInbox()
+	st := &Stats{}
+	done := make(chan error, 1)
+	go func() {
+		done <- liveLoop(ctx, nc, subject, newRecordSink(newJSONLWriter(pw), 0), extractOptions{}, st)
+		_ = pw.Close()
+	}()
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(pr)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+
+	// Wait for the subscription to be registered before publishing.
+	deadline := time.Now().Add(5 * time.Second)
+	for nc.NumSubscriptions() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	publish := func(payload string) {
+		t.Helper()
+		pub, err := nats.Connect(url)
+		if err != nil {
+			t.Fatalf("connect publisher: %v", err)
+		}
+		defer pub.Close()
+		if err := pub.Publish(subject, []byte(payload)); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		if err := pub.Flush(); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+	}
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, want) {
+				t.Fatalf("output line lacks %s: %s", want, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+
+	publish(liveTestPayload)
+	expect(`"waypoint":"POSA"`)
+
+	// Restart the server on the same port; the client reconnects and
+	// subscribes again.
+	srv.Shutdown()
+	srv = natstest.RunServer(&opts)
+	deadline = time.Now().Add(5 * time.Second)
+	for nc.Stats().Reconnects == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if nc.Stats().Reconnects == 0 {
+		t.Fatal("client did not reconnect")
+	}
+	if err := nc.Flush(); err != nil {
+		t.Fatalf("flush after reconnect: %v", err)
+	}
+	publish(strings.Replace(liveTestPayload, `"id":7`, `"id":8`, 1))
+	expect(`"id":8`)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("liveLoop: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("liveLoop did not stop")
+	}
+	if _, ok := <-lines; ok {
+		t.Error("output after shutdown")
+	}
+	if nc.NumSubscriptions() != 0 {
+		t.Errorf("%d subscriptions left after shutdown", nc.NumSubscriptions())
+	}
+	if st.Lines != 2 || st.Emitted != 2 {
+		t.Errorf("stats = %+v", *st)
+	}
+	logMu.Lock()
+	defer logMu.Unlock()
+	if !strings.Contains(logs.String(), "live: reconnected to") {
+		t.Errorf("reconnect not logged: %q", logs.String())
+	}
+}
+
+// lockedWriter serialises writes from the NATS callback goroutines.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
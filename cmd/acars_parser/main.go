@@ -21,64 +21,65 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"regexp"
 	"sort"
-	"strconv"
 	"strings"
-	"time"
 
 	"acars_parser/internal/acars"
 	"acars_parser/internal/airlines"
-	"acars_parser/internal/airports"
+	"acars_parser/internal/decode"
 	"acars_parser/internal/flightrouteapi"
 	_ "acars_parser/internal/parsers" // register all parsers via init()
 	miampkg "acars_parser/internal/parsers/miam"
-	"acars_parser/internal/patterns"
 	"acars_parser/internal/registry"
 )
 
-var (
-	pdcFlightRe       = regexp.MustCompile(`\b([A-Z0-9]{3,8})\s+CLRD\s+TO\s+([A-Z]{4})\b`)
-	pdcOriginHeaderRe = regexp.MustCompile(`(?s)/[A-Z]+\.[A-Z0-9]+/[A-Z]+\s+\d{4}\s+\d{6}\s+([A-Z]{4})\b`)
-	pdcDestinationRe  = regexp.MustCompile(`\bCLRD\s+TO\s+([A-Z]{4})\b`)
-	fsmHeaderRe       = regexp.MustCompile(`(?s)/[A-Z]+\.[A-Z0-9]+/FSM\s+\d{4}\s+\d{6}\s+([A-Z]{4})\s+([A-Z0-9]{3,8})\b`)
-	iniMetadataRe     = regexp.MustCompile(`(?i)INI(\d{2})(\d{2})(\d{4})\s+([A-Z]{3}\d{1,4}[A-Z]?)\s*/\d{2}/([A-Z]{4})/([A-Z]{4})\b`)
-	iniIDMetadataRe   = regexp.MustCompile(`(?i)^INI/ID[0-9A-Z]+,([^,]+),[^/]*/MR\d+,[^/]*/(?:AF)?([A-Z]{4}),([A-Z]{4})/TD(\d{2})(\d{4}),`)
-	raFlightNumberRe  = regexp.MustCompile(`\bFLIGHT\s+NUMBER:\s*([A-Z0-9]{2,10}(?:/[A-Z0-9]{2,10})?)\b`)
-	raSectorRe        = regexp.MustCompile(`\bSECTOR:\s*([A-Z]{4})-([A-Z]{4})\b`)
-	raOFPInfoRe       = regexp.MustCompile(`(?is)\bOFP\s+INFO\b\s+([A-Z0-9]{2,10})\s+([A-Z]{3})-([A-Z]{3})\b`)
-	// jaeroFlightRe matches "FLIGHT <callsign>" at the end of a JAERO C-Band
-	// header line (case-insensitive).  The C-Band format appends the flight
-	// number to the aircraft description, unlike the original JAERO format
-	// which does not include flight numbers in the header.
-	// (?:^|\s+) allows the word FLIGHT to appear at the very start of the
-	// description (when there is no aircraft model text before it).
-	jaeroFlightRe = regexp.MustCompile(`(?i)(?:^|\s+)FLIGHT\s+([A-Z][A-Z0-9]*)\s*$`)
-
-	// jaeroAESRe extracts the ICAO hex address from the AES: field of a JAERO
-	// header line.  The address is used as the per-aircraft key when tracking
-	// MIAM multi-segment reassembly state.
-	jaeroAESRe = regexp.MustCompile(`\bAES:([0-9A-Fa-f]+)\b`)
-
-	// miamFirstSegRe matches the beginning-of-transfer marker in a compressed
-	// MIAM payload.  JAERO/libacars uses this prefix for single-transfer and
-	// first-segment PDUs (e.g. "T22!", "T32!", "T-2!").  Continuation segments
-	// do not start with this pattern.
-	miamFirstSegRe = regexp.MustCompile(`^T[-\d]+!`)
-)
-
-// miamReassemblyWindow is the maximum time gap permitted between consecutive
-// segments of a MIAM multi-packet transfer.  Continuation packets arriving
-// outside this window are treated as orphans (unrelated transfers).
-// Real-world logs show gaps of up to ~10 minutes between a first segment and
-// subsequent continuation frames, so 15 minutes is used as a generous bound.
-const miamReassemblyWindow = 15 * time.Minute
+// emitFunc receives each ExtractOut as soon as it has been produced.  The
+// buffered JSON/text output collects them into a slice; the JSONL output
+// writes them straight through.
+type emitFunc func(ExtractOut)
 
+// ExtractOut is one output record: a message and the results the parsers
+// produced for it.
 type ExtractOut struct {
-	Message *OutputMessage `json:"message"`
-	Results []any          `json:"results,omitempty"`
+	Message    *OutputMessage  `json:"message"`
+	Results    []any           `json:"results,omitempty"`
+	Quality    []ResultQuality `json:"quality,omitempty"`
+	Origin     *RecordOrigin   `json:"origin,omitempty"`
+	Reassembly *ReassemblyInfo `json:"reassembly,omitempty"`
+	Dedup      *DedupInfo      `json:"dedup,omitempty"`
+}
+
+// ResultQuality is the registry.Quality of one result of a record, for
+// ranking weak parses.  Result is the result's index in Results.
+type ResultQuality struct {
+	Result int    `json:"result"`
+	Type   string `json:"type"`
+	registry.Quality
 }
 
+// newExtractOut builds the record for msg and its results.
+func newExtractOut(msg *acars.Message, results []registry.Result) ExtractOut {
+	out := ExtractOut{Message: newOutputMessage(msg), Results: make([]any, 0, len(results))}
+	for i, r := range results {
+		out.Results = append(out.Results, r) // keep concrete types for JSON marshal
+		if q, ok := registry.QualityOf(r); ok {
+			out.Quality = append(out.Quality, ResultQuality{Result: i, Type: r.Type(), Quality: q})
+		}
+	}
+	return out
+}
+
+// RecordOrigin says where an ExtractOut record came from: the input file and
+// line for extract, the socket for listen.  It is omitted for stdin and NATS.
+type RecordOrigin struct {
+	File     string `json:"file,omitempty"`     // input file as given or found
+	Line     int    `json:"line,omitempty"`     // 1-based line the record starts on
+	Listener string `json:"listener,omitempty"` // configured socket name
+	Proto    string `json:"proto,omitempty"`    // "udp" or "tcp"
+	Remote   string `json:"remote,omitempty"`   // sender address
+}
+
+// OutputMessage is the message of an ExtractOut record.
 type OutputMessage struct {
 	ID          acars.FlexInt64 `json:"id"`
 	Source      string          `json:"source"`
@@ -95,31 +96,64 @@ type OutputMessage struct {
 	Frequency   float64         `json:"frequency"`
 	Airframe    *acars.Airframe `json:"airframe,omitempty"`
 	Station     *acars.Station  `json:"station,omitempty"`
+
+	// EnrichedBy names, for each field filled in from the text or the
+	// results rather than the feed, the enricher that found it.
+	EnrichedBy map[string]string `json:"enriched_by,omitempty"`
 }
 
 type Stats struct {
+	Files          int
 	Lines          int
 	ParsedJAERO    int
 	ParsedNATS     int
 	ParsedFlat     int
 	ParsedNested   int
 	SkippedNoLabel int
+	Filtered       int
 	Emitted        int
 	Matched        int
+	Duplicates     int
+	Reassembled    int // multi-block messages joined from all their blocks
+	Partial        int // multi-block messages emitted with blocks missing
+	ParserErrors   int // parser calls that panicked, reported as parser_error results
 }
 
 func usage(w io.Writer) {
 	fmt.Fprintln(w, "acars_parser (extract) - commands:")
 	fmt.Fprintln(w, "  extract  - parse JSONL or JAERO TXT file and output JSON or text")
+	fmt.Fprintln(w, "  live     - subscribe to a NATS subject and write parsed messages as JSONL")
+	fmt.Fprintln(w, "  listen   - receive acarsdec/dumpvdl2/dumphfdl JSON on UDP/TCP sockets and write JSONL")
+	fmt.Fprintln(w, "  explain  - show which parsers and grok formats one message went through")
+	fmt.Fprintln(w, "  schema   - write the JSON Schema of the output records and every result type")
+	fmt.Fprintln(w, "  track    - feed messages into the SQLite state database and report what was new")
+	fmt.Fprintln(w, "  state    - export the state database (state dump) or an aircraft's flight legs (state legs) as JSON")
+	fmt.Fprintln(w, "  export-tracks - write the position tracks of the state database as GeoJSON or KML")
 	fmt.Fprintln(w, "  routeapi - serve a local FlightRoute write/read API for the HTML viewer")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Usage:")
-	fmt.Fprintln(w, "  acars_parser extract -input messages.jsonl [-output out.json] [-pretty] [-all] [-stats] [-format json|text]")
+	fmt.Fprintln(w, "  acars_parser extract -input messages.jsonl|DIR|'GLOB' [more inputs...] [-output out.json] [-pretty] [-all] [-stats] [-format json|jsonl|text] [-stream] [-workers N]")
+	fmt.Fprintln(w, "          [-label H1,SA] [-type cpdlc,adsc] [-tail GLOB|/RE/] [-flight GLOB|/RE/] [-since T] [-until T] [-text RE] [-dedup 30s] [-block-timeout 2m]")
+	fmt.Fprintln(w, "  acars_parser live [-server nats://127.0.0.1:4222] [-subject SUBJ] [-creds FILE] [-output out.jsonl] [-all] [-dedup 30s] [-stats]")
+	fmt.Fprintln(w, "  acars_parser listen -udp acarsdec=:5550 [-udp vdl2=:5555] [-tcp hfdl=:5556] [-output out.jsonl [-rotate-size MiB] [-rotate-interval 1h] [-rotate-keep 10]] [-all] [-dedup 30s] [-stats-interval 1m]")
+	fmt.Fprintln(w, "  acars_parser explain -label H1 'TEXT' | explain '{JSON line}' [-patterns] [-enable LIST] [-disable LIST]")
+	fmt.Fprintln(w, "  acars_parser schema [-type position] [-list] [-output schema.json] [-formats DIR]")
+	fmt.Fprintln(w, "  acars_parser track -input messages.jsonl|DIR|'GLOB' [more inputs...] [-state-db acars_state.db] [-v] [-stats]")
+	fmt.Fprintln(w, "  acars_parser state dump [-state-db acars_state.db] [-output state.json] [-pretty]")
+	fmt.Fprintln(w, "  acars_parser state legs -tail REG|ICAO [-state-db acars_state.db] [-since T] [-until T] [-pretty]")
+	fmt.Fprintln(w, "  acars_parser export-tracks [-state-db acars_state.db] [-tail REG|ICAO] [-since T] [-until T] [-format geojson|kml] [-by leg|aircraft] [-min-points N] [-output tracks.geojson]")
 	fmt.Fprintln(w, "  acars_parser routeapi [-db gui/flightroute.sqb] [-port 8765]")
 	fmt.Fprintln(w, "")
 	fmt.Fprintln(w, "Notes:")
-	fmt.Fprintln(w, "  - Input may be JSONL (one JSON object per line) or a JAERO TXT log.")
+	fmt.Fprintln(w, "  - Input may be JSONL (one JSON object per line) or a JAERO TXT log, detected per file.")
+	fmt.Fprintln(w, "  - gzip and zstd input is decompressed automatically; directories are read recursively.")
 	fmt.Fprintln(w, "  - For dumpvdl2/dumphfdl logs, the tool will try to find label/text in nested paths.")
+	fmt.Fprintln(w, "  - -stream (same as -format jsonl) writes one result per line as soon as it is parsed.")
+	fmt.Fprintln(w, "  - Filters are combined with AND; -since/-until accept ISO 8601, Unix seconds or JAERO HH:MM:SS DD-MM-YY.")
+	fmt.Fprintln(w, "  - Multi-block ACARS messages (acarsdec msgno/end, dumpvdl2/dumphfdl msg_num/more) are joined before parsing.")
+	fmt.Fprintln(w, "  - -dedup keeps one record per (tail, label, text) heard within the window and lists every receiver.")
+	fmt.Fprintln(w, "  - -workers N decodes and dispatches on N goroutines; output stays in input order.")
+	fmt.Fprintln(w, "  - extract, live and listen take -state-db FILE to update the state database as track does.")
 	fmt.Fprintln(w, "  - routeapi adds CORS headers so the standalone HTML viewer can call it from file: or localhost.")
 	fmt.Fprintln(w, "")
 }
@@ -133,6 +167,20 @@ func main() {
 	switch cmd {
 	case "extract":
 		runExtract(os.Args[2:])
+	case "live":
+		runLive(os.Args[2:])
+	case "listen":
+		runListen(os.Args[2:])
+	case "explain":
+		runExplain(os.Args[2:])
+	case "schema":
+		runSchema(os.Args[2:])
+	case "track":
+		runTrack(os.Args[2:])
+	case "state":
+		runState(os.Args[2:])
+	case "export-tracks":
+		runExportTracks(os.Args[2:])
 	case "routeapi":
 		runRouteAPI(os.Args[2:])
 	case "-h", "--help", "help":
@@ -158,80 +206,173 @@ func runRouteAPI(args []string) {
 
 func runExtract(args []string) {
 	fs := flag.NewFlagSet("extract", flag.ExitOnError)
-	inPath := fs.String("input", "", "Input JSONL file (default: stdin)")
+	inPath := fs.String("input", "", "Input file, directory or glob; gzip/zstd is detected automatically (default: stdin)")
 	outPath := fs.String("output", "", "Output JSON file (default: stdout)")
 	pretty := fs.Bool("pretty", false, "Pretty-print JSON output")
-	outputFormat := fs.String("format", "json", "Output format: json or text")
+	outputFormat := fs.String("format", "json", "Output format: json, jsonl or text")
+	stream := fs.Bool("stream", false, "Write each result as a JSON line as soon as it is parsed (same as -format jsonl)")
 	includeAll := fs.Bool("all", false, "Include messages even if no parser matched")
 	showStats := fs.Bool("stats", false, "Print basic counters to stderr")
-	_ = fs.Parse(args)
-
-	if *outputFormat != "json" && *outputFormat != "text" {
+	workers := fs.Int("workers", 1, "Number of goroutines decoding and dispatching messages (output order is preserved)")
+	profileParsers := fs.Bool("profile-parsers", false, "Print per-parser call counts and timings to stderr")
+	var pf parserFlags
+	pf.register(fs)
+	var ff filterFlags
+	fs.StringVar(&ff.labels, "label", "", "Only messages with these ACARS labels (comma-separated)")
+	fs.StringVar(&ff.types, "type", "", "Only results of these parser types, e.g. cpdlc,adsc (comma-separated)")
+	fs.StringVar(&ff.tail, "tail", "", "Only these tails: comma-separated globs, or /regex/")
+	fs.StringVar(&ff.flight, "flight", "", "Only these flights: comma-separated globs, or /regex/")
+	fs.StringVar(&ff.since, "since", "", "Only messages at or after this time (ISO 8601, Unix seconds or JAERO HH:MM:SS DD-MM-YY)")
+	fs.StringVar(&ff.until, "until", "", "Only messages before this time (same formats as -since)")
+	fs.StringVar(&ff.text, "text", "", "Only messages whose text matches this regular expression")
+	dedupWindow := fs.Duration("dedup", 0, "Collapse copies of the same message (tail, label, text) heard within this window, e.g. 30s")
+	blockTimeout := fs.Duration("block-timeout", decode.DefaultBlockTimeout, "Join multi-block ACARS messages whose blocks arrive within this time of each other (0 disables)")
+	stateDB := fs.String("state-db", "", "Also update this SQLite state database with every record written (see track)")
+	extraInputs := parseInterspersed(fs, args)
+
+	if *workers < 1 {
+		fmt.Fprintf(os.Stderr, "-workers must be at least 1\n")
+		os.Exit(2)
+	}
+	filter, err := newMessageFilter(ff)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid filter: %v\n", err)
+		os.Exit(2)
+	}
+	parsers, err := newParserRegistry(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parser setup: %v\n", err)
+		os.Exit(2)
+	}
+	enrichers, err := newEnrichChain(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid enricher setup: %v\n", err)
+		os.Exit(2)
+	}
+	if *profileParsers {
+		parsers.EnableMetrics()
+	}
+	opts := extractOptions{includeAll: *includeAll, filter: filter, blockTimeout: *blockTimeout, parsers: parsers, enrichers: enrichers}
+	if *stream {
+		if *outputFormat != "json" && *outputFormat != "jsonl" {
+			fmt.Fprintf(os.Stderr, "-stream cannot be combined with -format %s\n", *outputFormat)
+			os.Exit(2)
+		}
+		*outputFormat = "jsonl"
+	}
+	if *outputFormat != "json" && *outputFormat != "jsonl" && *outputFormat != "text" {
 		fmt.Fprintf(os.Stderr, "Unsupported output format: %s\n", *outputFormat)
 		os.Exit(2)
 	}
 
-	// Ensure parsers priority ordering is stable.
-	registry.Default().Sort()
-
-	var r io.Reader = os.Stdin
+	// Extra arguments are further inputs, so a shell-expanded glob after
+	// -input works as well as a quoted one.
+	var inputs []string
 	if *inPath != "" {
-		f, err := os.Open(*inPath)
+		inputs = append(inputs, *inPath)
+	}
+	inputs = append(inputs, extraInputs...)
+	files, err := expandInputs(inputs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open input: %v\n", err)
+		os.Exit(1)
+	}
+
+	var wout io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to open input: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to create output: %v\n", err)
 			os.Exit(1)
 		}
 		defer f.Close()
-		r = f
+		wout = f
 	}
 
-	scanner := bufio.NewScanner(r)
-	// JSON lines can be long; bump buffer (20MB).
+	// JSON lines can be long; bump buffer (20MB).  It is reused for every
+	// input file.
 	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 60*1024*1024)
 
 	out := make([]ExtractOut, 0, 1024)
 	st := &Stats{}
 
-	firstLine := ""
-	for scanner.Scan() {
-		st.Lines++
-		firstLine = strings.TrimSpace(scanner.Text())
-		if firstLine != "" {
-			break
+	emit := func(item ExtractOut) { out = append(out, item) }
+	var jw *jsonlWriter
+	if *outputFormat == "jsonl" {
+		jw = newJSONLWriter(wout)
+		emit = func(item ExtractOut) { _ = jw.Write(item) }
+	}
+	var tracker *stateTracker
+	if *stateDB != "" {
+		if tracker, err = openStateTracker(*stateDB); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
 		}
+		defer tracker.Close()
+	}
+	if tracker != nil {
+		emit = tracker.wrap(emit)
 	}
 
-	if firstLine != "" {
-		if looksLikeJAEROHeader(firstLine) {
-			out = processJAEROInput(scanner, firstLine, out, *includeAll, st)
-		} else {
-			out = processJSONLInput(scanner, firstLine, out, *includeAll, st)
-		}
+	// Duplicate suppression sits between dispatch and output.  Buffered
+	// output can merge late copies into records already collected; JSONL
+	// output has to hold records back for the window instead.
+	var dedup *deduper
+	if *dedupWindow > 0 {
+		dedup = newDeduper(*dedupWindow, *outputFormat == "jsonl", emit)
+		emit = dedup.Add
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Input read error: %v\n", err)
-		os.Exit(1)
+	// With more than one worker, decoding and dispatch run on a pool; the
+	// pool hands the records back to emit in input order.
+	dispatch := inlineDispatcher(emit, st)
+	var pool *orderedPool
+	if *workers > 1 {
+		pool = newOrderedPool(*workers, emit)
+		dispatch = pool.Dispatch
 	}
 
-	var wout io.Writer = os.Stdout
-	if *outPath != "" {
-		f, err := os.Create(*outPath)
+	streamJAERO := *outputFormat == "jsonl"
+	if len(files) == 0 {
+		if err := processInput(os.Stdin, "", buf, dispatch, opts, streamJAERO, st); err != nil {
+			fmt.Fprintf(os.Stderr, "Input read error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, path := range files {
+		r, err := openInput(path)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to create output: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to open input: %v\n", err)
+			os.Exit(1)
+		}
+		st.Files++
+		err = processInput(r, path, buf, dispatch, opts, streamJAERO, st)
+		r.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Input read error: %s: %v\n", path, err)
 			os.Exit(1)
 		}
-		defer f.Close()
-		wout = f
+	}
+	if pool != nil {
+		pool.Close(st)
+	}
+	if dedup != nil {
+		dedup.Flush()
+		st.Duplicates = dedup.duplicates
 	}
 
-	if *outputFormat == "text" {
+	switch *outputFormat {
+	case "jsonl":
+		if err := jw.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Output write error: %v\n", err)
+			os.Exit(1)
+		}
+	case "text":
 		_, _ = io.WriteString(wout, formatExtractText(out))
 		if wout == os.Stdout {
 			_, _ = wout.Write([]byte("\n"))
 		}
-	} else {
+	default:
 		enc, err := marshalJSON(out, *pretty)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "JSON encode error: %v\n", err)
@@ -245,75 +386,154 @@ func runExtract(args []string) {
 
 	if *showStats {
 		fmt.Fprintf(os.Stderr,
-			"stats: lines=%d parsed(jaero=%d nats=%d flat=%d nested=%d) skipped(no_label_text)=%d emitted=%d matched=%d\n",
-			st.Lines, st.ParsedJAERO, st.ParsedNATS, st.ParsedFlat, st.ParsedNested, st.SkippedNoLabel, st.Emitted, st.Matched,
+			"stats: files=%d lines=%d parsed(jaero=%d nats=%d flat=%d nested=%d) skipped(no_label_text)=%d filtered=%d emitted=%d matched=%d duplicates=%d reassembled=%d partial=%d parser_errors=%d\n",
+			st.Files, st.Lines, st.ParsedJAERO, st.ParsedNATS, st.ParsedFlat, st.ParsedNested, st.SkippedNoLabel, st.Filtered, st.Emitted, st.Matched, st.Duplicates, st.Reassembled, st.Partial, st.ParserErrors,
 		)
 	}
+	if tracker != nil {
+		tracker.writeSummary(os.Stderr, false)
+	}
+	if *profileParsers {
+		writeParserProfile(os.Stderr, parsers.Metrics())
+	}
 }
 
-func processJSONLInput(scanner *bufio.Scanner, firstLine string, out []ExtractOut, includeAll bool, st *Stats) []ExtractOut {
-	out = processJSONLLine(firstLine, out, includeAll, st)
+// processInput reads one input stream and decides from its first non-empty
+// line whether it is a JAERO log or JSONL, so every file in a multi-file run
+// is detected on its own.  When file is non-empty every record is tagged
+// with the file name and the line it started on.
+func processInput(r io.Reader, file string, buf []byte, dispatch dispatcher, opts extractOptions, streamJAERO bool, st *Stats) error {
+	scanner := &lineScanner{Scanner: bufio.NewScanner(r)}
+	scanner.Buffer(buf[:0], 60*1024*1024)
+
+	firstLine := ""
+	for scanner.Scan() {
+		st.Lines++
+		firstLine = strings.TrimSpace(scanner.Text())
+		if firstLine != "" {
+			break
+		}
+	}
+
+	if firstLine != "" {
+		if decode.IsJAEROHeader(firstLine) {
+			processJAEROInput(scanner, firstLine, file, dispatch, opts, streamJAERO, st)
+		} else {
+			processJSONLInput(scanner, firstLine, file, dispatch, opts, st)
+		}
+	}
+	return scanner.Err()
+}
+
+// processJSONLInput dispatches every line of a JSONL input.  Blocks of
+// multi-block ACARS messages are joined first, within each file; a joined
+// message carries the origin of the block that opened its set.
+func processJSONLInput(scanner *lineScanner, firstLine string, file string, dispatch dispatcher, opts extractOptions, st *Stats) {
+	var blocks *decode.BlockAssembler
+	if opts.blockTimeout > 0 {
+		blocks = decode.NewBlockAssembler(opts.blockTimeout)
+	}
+	dispatchJSONLLine(dispatch, blocks, firstLine, fileOrigin(file, scanner.line), opts, st)
 	for scanner.Scan() {
 		st.Lines++
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
 		}
-		out = processJSONLLine(line, out, includeAll, st)
+		dispatchJSONLLine(dispatch, blocks, line, fileOrigin(file, scanner.line), opts, st)
+	}
+	if blocks != nil {
+		blocks.Flush()
 	}
-	return out
 }
 
-func processJSONLLine(line string, out []ExtractOut, includeAll bool, st *Stats) []ExtractOut {
-	b := []byte(line)
+// dispatchJSONLLine hands one line to the dispatcher.  Lines that may be
+// ACARS blocks are decoded here, since joining blocks has to happen in input
+// order; the rest are decoded by the dispatcher.
+func dispatchJSONLLine(dispatch dispatcher, blocks *decode.BlockAssembler, line string, origin *RecordOrigin, opts extractOptions, st *Stats) {
+	if blocks != nil && decode.MayCarryBlock(line) {
+		done := func(msg *acars.Message, info *ReassemblyInfo) {
+			dispatch(func(emit emitFunc, st *Stats) {
+				emitAssembled(withOrigin(emit, origin), msg, info, opts, st)
+			})
+		}
+		msgs, kind, taken := blocks.TakeLine(line, done)
+		if taken {
+			st.countKind(kind)
+		} else {
+			dispatch(func(emit emitFunc, st *Stats) {
+				emitMessages(msgs, kind, withOrigin(emit, origin), opts, st)
+			})
+		}
+		return
+	}
+	dispatch(func(emit emitFunc, st *Stats) {
+		processJSONLLine(line, withOrigin(emit, origin), opts, st)
+	})
+}
 
-	msgs, kind := decodeToMessage(b)
-	if len(msgs) == 0 {
-		st.SkippedNoLabel++
-		return out
+// fileOrigin returns the origin for a record starting on line of file, or
+// nil when reading from stdin.
+func fileOrigin(file string, line int) *RecordOrigin {
+	if file == "" {
+		return nil
 	}
+	return &RecordOrigin{File: file, Line: line}
+}
 
-	switch kind {
-	case "nats":
-		st.ParsedNATS++
-	case "flat":
-		st.ParsedFlat++
-	case "nested":
-		st.ParsedNested++
+// withOrigin tags every record passed to emit with origin.
+func withOrigin(emit emitFunc, origin *RecordOrigin) emitFunc {
+	if origin == nil {
+		return emit
+	}
+	return func(out ExtractOut) {
+		out.Origin = origin
+		emit(out)
 	}
+}
+
+func processJSONLLine(line string, emit emitFunc, opts extractOptions, st *Stats) {
+	msgs, kind := decode.JSON([]byte(line))
+	emitMessages(msgs, kind, emit, opts, st)
+}
 
+// emitMessages dispatches the messages decoded from one JSON line.
+func emitMessages(msgs []*acars.Message, kind string, emit emitFunc, opts extractOptions, st *Stats) {
+	if len(msgs) == 0 {
+		st.SkippedNoLabel++
+		return
+	}
+
+	st.countKind(kind)
 	for _, msg := range msgs {
 		if msg == nil || (strings.TrimSpace(msg.Label) == "" && strings.TrimSpace(msg.Text) == "") {
 			continue
 		}
-		var appended bool
-		var matched bool
-		out, appended, matched = appendOut(out, msg, includeAll)
-		if appended {
-			st.Emitted++
-		}
-		if matched {
-			st.Matched++
-		}
+		st.count(emitOut(emit, msg, opts, st))
 	}
-
-	return out
 }
 
-func processJAEROInput(scanner *bufio.Scanner, firstHeader string, out []ExtractOut, includeAll bool, st *Stats) []ExtractOut {
-	// Phase 1: collect every block (header + body) into a slice.  This
-	// allows sorting by timestamp so that reassembly works correctly even
-	// when the input file is in reverse chronological order (e.g. JAERO
-	// C-Band logs that are newest-first).
-	type rawBlock struct {
-		header string
-		body   []string
-		ts     time.Time
-		valid  bool // false when the timestamp field cannot be parsed
-	}
+func processJAEROInput(scanner *lineScanner, firstHeader string, file string, dispatch dispatcher, opts extractOptions, stream bool, st *Stats) {
+	// Phase 1: split the input into blocks (header + body).  In the default
+	// buffered mode every block is collected first so that they can be sorted
+	// by timestamp; this makes reassembly work correctly even when the input
+	// file is in reverse chronological order (e.g. JAERO C-Band logs that are
+	// newest-first).  In stream mode each block is handed to the assembler as
+	// soon as the next header closes it, so the input must be chronological.
+	asm := decode.NewJAEROAssembler(func(blk decode.JAEROBlock, continuations []string) {
+		origin := fileOrigin(file, blk.Line)
+		dispatch(func(emit emitFunc, st *Stats) {
+			o := emitJAEROBlock(withOrigin(emit, origin), blk.Header, blk.Body, opts, continuations, st)
+			if o == outcomeSkipped {
+				st.SkippedNoLabel++
+			}
+			st.count(o)
+		})
+	})
 
-	var blocks []rawBlock
+	var blocks []decode.JAEROBlock
 	currentHeader := strings.TrimSpace(firstHeader)
+	currentLine := scanner.line
 	currentBody := make([]string, 0, 8)
 
 	addBlock := func() {
@@ -321,25 +541,24 @@ func processJAEROInput(scanner *bufio.Scanner, firstHeader string, out []Extract
 			return
 		}
 		st.ParsedJAERO++
-		fields := strings.Fields(currentHeader)
-		blk := rawBlock{header: currentHeader, body: append([]string{}, currentBody...)}
-		if len(fields) >= 3 {
-			if t, err := time.Parse("15:04:05 02-01-06 MST", fields[0]+" "+fields[1]+" "+fields[2]); err == nil {
-				blk.ts = t.UTC()
-				blk.valid = true
-			}
+		blk := decode.NewJAEROBlock(currentHeader, append([]string{}, currentBody...), currentLine)
+		currentBody = currentBody[:0]
+		if stream {
+			asm.Expire(blk)
+			asm.Add(blk)
+			return
 		}
 		blocks = append(blocks, blk)
-		currentBody = currentBody[:0]
 	}
 
 	for scanner.Scan() {
 		st.Lines++
 		line := scanner.Text()
 		trimmed := strings.TrimSpace(line)
-		if looksLikeJAEROHeader(trimmed) {
+		if decode.IsJAEROHeader(trimmed) {
 			addBlock()
 			currentHeader = trimmed
+			currentLine = scanner.line
 			continue
 		}
 		if currentHeader == "" {
@@ -353,139 +572,36 @@ func processJAEROInput(scanner *bufio.Scanner, firstHeader string, out []Extract
 	// parseable timestamp are placed at the end, preserving their relative
 	// order (stable sort).
 	sort.SliceStable(blocks, func(i, j int) bool {
-		if !blocks[i].valid {
+		ti, iok := blocks[i].Time()
+		tj, jok := blocks[j].Time()
+		if !iok {
 			return false
 		}
-		if !blocks[j].valid {
+		if !jok {
 			return true
 		}
-		return blocks[i].ts.Before(blocks[j].ts)
+		return ti.Before(tj)
 	})
 
-	// Phase 3: process blocks in chronological order, tracking per-ICAO
-	// MIAM assembly state for multi-segment transfers.
-	//
-	// The MIAM protocol can split a large compressed payload across multiple
-	// consecutive ACARS frames.  The first frame starts with a "T<n>!"
-	// marker (miamFirstSegRe); subsequent frames do not.  JAERO/libacars
-	// outputs a decoded MIAM block only when it can fully reassemble the
-	// transfer.  When it cannot (e.g. the file starts mid-transfer), those
-	// frames appear with no decoded block.
-	//
-	// Strategy:
-	//   T<n>! frame + decoded MIAM block → single transfer, emit immediately.
-	//   T<n>! frame + no decoded block   → first segment of a multi-packet
-	//                                       transfer; start a per-ICAO
-	//                                       assembly.
-	//   Non-T frame + no decoded block   → continuation segment: append to
-	//                                       the active assembly for this ICAO
-	//                                       if within miamReassemblyWindow,
-	//                                       otherwise treat as an orphan.
-	type miamAssembly struct {
-		header string
-		body   []string
-		ts     time.Time // timestamp of the first segment
-		lastTS time.Time // timestamp of the most recently appended segment
-		conts  []string  // continuation payloads in chronological order
-	}
-	miamState := make(map[string]*miamAssembly)
-
-	emitStats := func(appended, matched bool) {
-		if appended {
-			st.Emitted++
-		}
-		if matched {
-			st.Matched++
-		}
-		if !appended {
-			st.SkippedNoLabel++
-		}
-	}
-
-	flushAssembly := func(icao string) {
-		asm, ok := miamState[icao]
-		if !ok {
-			return
-		}
-		delete(miamState, icao)
-		var appended, matched bool
-		out, appended, matched = appendJAEROBlock(out, asm.header, asm.body, includeAll, asm.conts)
-		emitStats(appended, matched)
-	}
-
+	// Phase 3: process blocks in chronological order.
 	for _, blk := range blocks {
-		// The MIAM reassembly only applies to MA-label blocks.  A quick
-		// substring check on the header avoids a full parse for every block.
-		if !strings.Contains(blk.header, " ! MA ") {
-			var appended, matched bool
-			out, appended, matched = appendJAEROBlock(out, blk.header, blk.body, includeAll, nil)
-			emitStats(appended, matched)
-			continue
-		}
-
-		// Extract the ICAO hex address (AES field) for per-aircraft tracking.
-		icao := ""
-		if m := jaeroAESRe.FindStringSubmatch(blk.header); len(m) == 2 {
-			icao = m[1]
-		}
-
-		payload := extractJAEROPayload(blk.body)
-		hasMIAMBlock := extractJAEROMIAMBlock(blk.body) != ""
-		isFirstSeg := miamFirstSegRe.MatchString(payload) && !hasMIAMBlock
-
-		switch {
-		case hasMIAMBlock:
-			// Fully decoded by JAERO (single transfer or last segment).
-			// Close any active assembly for this aircraft and emit normally.
-			flushAssembly(icao)
-			var appended, matched bool
-			out, appended, matched = appendJAEROBlock(out, blk.header, blk.body, includeAll, nil)
-			emitStats(appended, matched)
-
-		case isFirstSeg:
-			// Start a new multi-segment assembly.  Any previous assembly for
-			// this ICAO is flushed first (it timed out or was interrupted).
-			flushAssembly(icao)
-			miamState[icao] = &miamAssembly{
-				header: blk.header,
-				body:   blk.body,
-				ts:     blk.ts,
-				lastTS: blk.ts,
-			}
-
-		default:
-			// Continuation or orphan (no T<n>! prefix, no decoded block).
-			if asm, ok := miamState[icao]; ok && blk.valid && blk.ts.Sub(asm.lastTS) <= miamReassemblyWindow {
-				// Within the window: append this payload to the active assembly.
-				asm.conts = append(asm.conts, payload)
-				asm.lastTS = blk.ts
-			} else {
-				// Orphan: either no active assembly for this ICAO, the time
-				// window has expired, or the timestamp is unparseable.
-				if asm, ok := miamState[icao]; ok && blk.valid && blk.ts.After(asm.lastTS) {
-					// Assembly window has expired; flush the stale assembly.
-					flushAssembly(icao)
-				}
-				var appended, matched bool
-				out, appended, matched = appendJAEROBlock(out, blk.header, blk.body, includeAll, nil)
-				emitStats(appended, matched)
-			}
-		}
+		asm.Add(blk)
 	}
 
 	// Flush all assemblies that were not closed by a subsequent T<n>! block
 	// or a fully-decoded block (e.g. the transfer spans the end of the file).
-	for icao := range miamState {
-		flushAssembly(icao)
-	}
-
-	return out
+	asm.Flush()
 }
 
-func appendJAEROBlock(out []ExtractOut, header string, body []string, includeAll bool, continuationPayloads []string) ([]ExtractOut, bool, bool) {
-	msg := parseJAEROBlock(header, body)
+func emitJAEROBlock(emit emitFunc, header string, body []string, opts extractOptions, continuationPayloads []string, st *Stats) emitOutcome {
+	msg := decode.ParseJAEROBlock(header, body)
 	if msg == nil {
-		return out, false, false
+		return outcomeSkipped
+	}
+	enrichers := opts.enrichChain()
+	enrichers.Enrich(msg, nil)
+	if !opts.filter.matchMessage(msg) {
+		return outcomeFiltered
 	}
 
 	// For MIAM messages (label MA), JAERO/libacars writes a decoded block below
@@ -493,592 +609,131 @@ func appendJAEROBlock(out []ExtractOut, header string, body []string, includeAll
 	// decoded block so the viewer can expand it like CPDLC/ADS-C, while keeping
 	// the original compressed payload in message.text for the default table view.
 	if msg.Label == "MA" {
-		miamText := extractJAEROMIAMBlock(body)
+		miamText := decode.JAEROMIAMBlock(body)
 		if miamText != "" {
-			enrichMessageFromText(msg)
 			miamMsg := *msg
 			miamMsg.Text = miamText
-			results := registry.Default().Dispatch(&miamMsg)
-			if !includeAll && len(results) == 0 {
+			results := dispatchMessage(&miamMsg, opts, st)
+			if !opts.includeAll && len(results) == 0 {
 				// No parser matched the decoded block; fall back to normal dispatch
 				// against the compressed payload so -all still emits the message.
-				return appendOut(out, msg, includeAll)
+				return emitOut(emit, msg, opts, st)
+			}
+			enrichers.Enrich(msg, results)
+			results, ok := opts.filter.filterResults(results)
+			if !ok {
+				return outcomeFiltered
 			}
-			rany := make([]any, 0, len(results))
-			for _, r := range results {
-				rany = append(rany, r)
+			emit(newExtractOut(msg, results))
+			if len(results) > 0 {
+				return outcomeMatched
 			}
-			out = append(out, ExtractOut{Message: newOutputMessage(msg), Results: rany})
-			return out, true, len(results) > 0
+			return outcomeUnmatched
 		}
 
 		// No decoded MIAM block: check whether continuation payloads have been
-		// assembled by the reassembly logic in processJAEROInput.  When present,
-		// emit a minimal miam_assembled result that exposes the full concatenated
-		// compressed payload for future decoding.
+		// assembled by jaeroAssembler.  When present, emit a miam_assembled
+		// result that exposes the full concatenated payload, decoded natively
+		// where possible, plus the results for the message it carries.
 		if len(continuationPayloads) > 0 {
-			enrichMessageFromText(msg)
-			var sb strings.Builder
-			sb.WriteString(msg.Text)
-			for _, cp := range continuationPayloads {
-				sb.WriteString(cp)
-			}
-			result := &miampkg.Result{
-				Timestamp:        msg.Timestamp,
-				MessageType:      "miam_assembled",
-				SegmentCount:     1 + len(continuationPayloads),
-				AssembledPayload: sb.String(),
-			}
-			out = append(out, ExtractOut{Message: newOutputMessage(msg), Results: []any{result}})
-			return out, true, true
-		}
-	}
-
-	return appendOut(out, msg, includeAll)
-}
-
-func parseJAEROBlock(header string, body []string) *acars.Message {
-	timestamp, tail, label, flight, airframe, ok := parseJAEROHeader(header)
-	if !ok {
-		return nil
-	}
-
-	text := extractJAEROPayload(body)
-	if text == "" {
-		return nil
-	}
-
-	msg := &acars.Message{
-		Source:    "jaero",
-		Timestamp: timestamp,
-		Tail:      tail,
-		Text:      text,
-		Label:     label,
-		Airframe:  airframe,
-	}
-	if flight != "" {
-		msg.Flight = &acars.Flight{Flight: flight}
-	}
-	return msg
-}
-
-// extractJAEROMIAMBlock scans the body lines of a JAERO message block for the
-// MIAM-decoded section that JAERO/libacars appends after the raw payload line.
-// The decoded section begins with a tab-indented "MIAM:" line.  All lines from
-// that point are collected, the single leading tab is stripped from each, and
-// the result is returned as a trimmed string.  An empty string is returned when
-// no MIAM block is found.
-func extractJAEROMIAMBlock(body []string) string {
-	miamStart := -1
-	for i, rawLine := range body {
-		if strings.TrimSpace(rawLine) == "MIAM:" {
-			miamStart = i
-			break
-		}
-	}
-	if miamStart < 0 {
-		return ""
-	}
-
-	var sb strings.Builder
-	for i, rawLine := range body[miamStart:] {
-		if i > 0 {
-			sb.WriteByte('\n')
-		}
-		// Strip exactly one leading tab: JAERO indents the whole decoded block
-		// by one tab relative to the rest of the message body.
-		if len(rawLine) > 0 && rawLine[0] == '\t' {
-			sb.WriteString(rawLine[1:])
-		} else {
-			sb.WriteString(rawLine)
-		}
-	}
-
-	return strings.TrimRight(sb.String(), "\n\t ")
-}
-
-// parseJAEROHeader parses a single JAERO header line.  It returns
-// (timestamp, tail, label, flight, airframe, ok).  The flight field is
-// populated when the C-Band format includes a "FLIGHT <callsign>" suffix in
-// the aircraft description; it is empty for the original JAERO format.
-func parseJAEROHeader(header string) (string, string, string, string, *acars.Airframe, bool) {
-	fields := strings.Fields(strings.TrimSpace(header))
-	if len(fields) < 8 || !looksLikeJAEROHeader(header) {
-		return "", "", "", "", nil, false
-	}
-
-	parsedTime, err := time.Parse("15:04:05 02-01-06 MST", fields[0]+" "+fields[1]+" "+fields[2])
-	if err != nil {
-		return "", "", "", "", nil, false
-	}
-
-	bangIdx := strings.Index(header, " ! ")
-	if bangIdx < 0 {
-		return "", "", "", "", nil, false
-	}
-
-	leftFields := strings.Fields(strings.TrimSpace(header[:bangIdx]))
-	if len(leftFields) == 0 {
-		return "", "", "", "", nil, false
-	}
-	tail := strings.TrimSpace(leftFields[len(leftFields)-1])
-	if tail == "" {
-		return "", "", "", "", nil, false
-	}
-
-	aes := ""
-	for _, field := range leftFields {
-		if strings.HasPrefix(field, "AES:") {
-			aes = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(field, "AES:")))
-			break
-		}
-	}
-
-	rightFields := strings.Fields(strings.TrimSpace(header[bangIdx+3:]))
-	if len(rightFields) < 2 {
-		return "", "", "", "", nil, false
-	}
-	label := strings.TrimSpace(rightFields[0])
-	if label == "" {
-		return "", "", "", "", nil, false
-	}
-
-	airframeDescription := ""
-	if len(rightFields) > 2 {
-		airframeDescription = strings.TrimSpace(strings.Join(rightFields[2:], " "))
-	}
-
-	// Extract the flight number from the C-Band "FLIGHT <callsign>" suffix.
-	flight := ""
-	if match := jaeroFlightRe.FindStringSubmatch(airframeDescription); len(match) == 2 {
-		flight = strings.ToUpper(strings.TrimSpace(match[1]))
-		// Strip " FLIGHT <callsign>" from the aircraft description.
-		airframeDescription = strings.TrimSpace(airframeDescription[:len(airframeDescription)-len(match[0])])
-	}
-
-	var airframe *acars.Airframe
-	if aes != "" || airframeDescription != "" || tail != "" {
-		airframe = &acars.Airframe{
-			Tail:              tail,
-			ICAO:              aes,
-			ManufacturerModel: airframeDescription,
-		}
-	}
-
-	return parsedTime.UTC().Format(time.RFC3339), tail, label, flight, airframe, true
-}
-
-func extractJAEROPayload(body []string) string {
-	payloadLines := make([]string, 0, len(body))
-	started := false
-
-	for _, rawLine := range body {
-		trimmed := strings.TrimSpace(rawLine)
-		if !started {
-			if trimmed == "" {
-				continue
+			result := miampkg.DecodeAssembled(msg, continuationPayloads)
+			results := []registry.Result{result}
+			if inner := result.InnerACARS(msg); inner != nil {
+				results = append(results, dispatchMessage(inner, opts, st)...)
 			}
-			if trimmed == "-" {
-				return ""
-			}
-			if looksLikeJAERODecoderCommentaryLine(trimmed) {
-				continue
+			enrichers.Enrich(msg, results)
+			results, ok := opts.filter.filterResults(results)
+			if !ok {
+				return outcomeFiltered
 			}
-			started = true
-		}
-
-		if trimmed == "" {
-			break
-		}
-		if !looksLikeJAEROPayloadLine(trimmed) {
-			break
+			emit(newExtractOut(msg, results))
+			return outcomeMatched
 		}
-
-		payloadLines = append(payloadLines, trimmed)
-	}
-
-	if len(payloadLines) == 0 {
-		return ""
 	}
 
-	joined := joinJAEROPayloadLines(payloadLines)
-	return normaliseJAEROPayload(joined)
+	return emitOut(emit, msg, opts, st)
 }
 
-func looksLikeJAEROPayloadLine(line string) bool {
-	line = strings.TrimSpace(line)
-	if line == "" || line == "-" {
-		return false
-	}
-	if looksLikeJAEROHeader(line) || looksLikeJAERODecoderCommentaryLine(line) {
-		return false
-	}
-	return true
-}
-
-func looksLikeJAERODecoderCommentaryLine(line string) bool {
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return false
-	}
-
-	commentaryPrefixes := []string{
-		"FANS-1/A ",
-		"CPDLC ",
-		"CPDLC Uplink Message:",
-		"CPDLC Downlink Message:",
-		"ADS-C message:",
-		"Header:",
-		"Message data:",
-		"Msg ID:",
-		"Timestamp:",
-		"Facility designation:",
-		"Flight level:",
-		"Fix:",
-		"Position:",
-		"ATC CLEARANCE",
-		"REQUEST POSITION REPORT",
-	}
-	for _, prefix := range commentaryPrefixes {
-		if strings.HasPrefix(line, prefix) {
-			return true
-		}
-	}
+// emitOutcome says what happened to one message on its way to emit.
+type emitOutcome int
 
-	return false
-}
-
-func joinJAEROPayloadLines(lines []string) string {
-	if len(lines) == 0 {
-		return ""
-	}
+const (
+	outcomeSkipped   emitOutcome = iota // nothing emitted: no parser matched and -all is off
+	outcomeFiltered                     // rejected by an extract filter
+	outcomeUnmatched                    // emitted without results (-all)
+	outcomeMatched                      // emitted with at least one result
+)
 
-	var builder strings.Builder
-	builder.WriteString(lines[0])
-	for i := 1; i < len(lines); i++ {
-		current := strings.TrimSpace(lines[i])
-		previous := strings.TrimSpace(lines[i-1])
-		if shouldJoinJAEROPayloadInline(previous, current) {
-			builder.WriteString(current)
-			continue
-		}
-		builder.WriteByte('\n')
-		builder.WriteString(current)
+// countKind counts one JSON line decoded as kind.
+func (s *Stats) countKind(kind string) {
+	switch kind {
+	case "nats":
+		s.ParsedNATS++
+	case "flat":
+		s.ParsedFlat++
+	case "nested":
+		s.ParsedNested++
 	}
-
-	return builder.String()
 }
 
-func shouldJoinJAEROPayloadInline(previous string, current string) bool {
-	if current == "" {
-		return false
-	}
-	if strings.HasPrefix(current, "/") || strings.HasPrefix(current, "#") || strings.HasPrefix(current, "- #") {
-		return true
+// count adds one message with outcome o to the counters.
+func (s *Stats) count(o emitOutcome) {
+	switch o {
+	case outcomeFiltered:
+		s.Filtered++
+	case outcomeUnmatched:
+		s.Emitted++
+	case outcomeMatched:
+		s.Emitted++
+		s.Matched++
 	}
-	return !strings.ContainsAny(previous, " \t") && !strings.ContainsAny(current, " \t")
-}
-
-func normaliseJAEROPayload(payload string) string {
-	payload = strings.TrimSpace(payload)
-	payload = strings.ReplaceAll(payload, "- #MD", "")
-	payload = strings.ReplaceAll(payload, "- #M1", "")
-	payload = strings.TrimPrefix(payload, "- ")
-	return strings.TrimSpace(payload)
 }
 
-func looksLikeJAEROHeader(line string) bool {
-	fields := strings.Fields(strings.TrimSpace(line))
-	if len(fields) < 8 {
-		return false
+// emitOut enriches, filters and dispatches msg and emits the result.
+// Message filters run before dispatch so rejected messages are not parsed;
+// enrichers run again after it, to fill in fields from the results.  Parser
+// panics are counted in st.
+func emitOut(emit emitFunc, msg *acars.Message, opts extractOptions, st *Stats) emitOutcome {
+	enrichers := opts.enrichChain()
+	enrichers.Enrich(msg, nil)
+	if !opts.filter.matchMessage(msg) {
+		return outcomeFiltered
 	}
-	if fields[2] != "UTC" {
-		return false
+	results := dispatchMessage(msg, opts, st)
+	if !opts.includeAll && len(results) == 0 {
+		return outcomeSkipped
 	}
-	if !strings.HasPrefix(fields[3], "AES:") || !strings.HasPrefix(fields[4], "GES:") {
-		return false
+	enrichers.Enrich(msg, results)
+	results, ok := opts.filter.filterResults(results)
+	if !ok {
+		return outcomeFiltered
 	}
-	if _, err := time.Parse("15:04:05 02-01-06 MST", fields[0]+" "+fields[1]+" "+fields[2]); err != nil {
-		return false
+	emit(newExtractOut(msg, results))
+	if len(results) > 0 {
+		return outcomeMatched
 	}
-	return strings.Contains(line, " ! ")
+	return outcomeUnmatched
 }
 
-func appendOut(out []ExtractOut, msg *acars.Message, includeAll bool) ([]ExtractOut, bool, bool) {
-	enrichMessageFromText(msg)
-	results := registry.Default().Dispatch(msg)
-	if !includeAll && len(results) == 0 {
-		return out, false, false
-	}
-	rany := make([]any, 0, len(results))
+// dispatchMessage runs the selected registry over msg.  A MIAM frame decoded from the
+// raw text is followed by the results for the ACARS message it carries, which
+// is dispatched under its own label.  Parsers that panicked are counted in st.
+func dispatchMessage(msg *acars.Message, opts extractOptions, st *Stats) []registry.Result {
+	reg := opts.parserRegistry()
+	results := reg.Dispatch(msg)
 	for _, r := range results {
-		rany = append(rany, r) // keep concrete types for JSON marshal
-	}
-	out = append(out, ExtractOut{Message: newOutputMessage(msg), Results: rany})
-	return out, true, len(results) > 0
-}
-
-func enrichMessageFromText(msg *acars.Message) {
-	if msg == nil {
-		return
-	}
-
-	flightNumber, cleanTail, destinationAirport := parseAFNMetadataFromText(msg.Text)
-	departingAirport := ""
-	if flightNumber == "" {
-		flightNumber = parseFPNFlightFromText(msg.Text)
-	}
-	if flightNumber == "" || destinationAirport == "" || departingAirport == "" {
-		iniFlightNumber, iniDepartingAirport, iniDestinationAirport := parseINIMetadataFromText(msg.Text)
-		if flightNumber == "" {
-			flightNumber = iniFlightNumber
-		}
-		if departingAirport == "" {
-			departingAirport = iniDepartingAirport
-		}
-		if destinationAirport == "" {
-			destinationAirport = iniDestinationAirport
-		}
-	}
-	if strings.EqualFold(strings.TrimSpace(msg.Label), "RA") && (flightNumber == "" || destinationAirport == "" || departingAirport == "") {
-		raFlightNumber, raDepartingAirport, raDestinationAirport := parseRAFlightMetadataFromText(msg.Text)
-		if flightNumber == "" {
-			flightNumber = raFlightNumber
-		}
-		if departingAirport == "" {
-			departingAirport = raDepartingAirport
-		}
-		if destinationAirport == "" {
-			destinationAirport = raDestinationAirport
-		}
-	}
-	if flightNumber == "" || destinationAirport == "" || departingAirport == "" {
-		pdcFlightNumber, pdcDepartingAirport, pdcDestinationAirport := parsePDCMetadataFromText(msg.Text)
-		if flightNumber == "" {
-			flightNumber = pdcFlightNumber
-		}
-		if departingAirport == "" {
-			departingAirport = pdcDepartingAirport
-		}
-		if destinationAirport == "" {
-			destinationAirport = pdcDestinationAirport
-		}
-	}
-	if flightNumber == "" || departingAirport == "" {
-		fsmFlightNumber, fsmDepartingAirport := parseFSMMetadataFromText(msg.Text)
-		if flightNumber == "" {
-			flightNumber = fsmFlightNumber
-		}
-		if departingAirport == "" {
-			departingAirport = fsmDepartingAirport
-		}
-	}
-	if flightNumber == "" && cleanTail == "" && destinationAirport == "" && departingAirport == "" {
-		return
-	}
-
-	if cleanTail != "" {
-		if msg.Airframe == nil {
-			msg.Airframe = &acars.Airframe{}
-		}
-		if strings.TrimSpace(msg.Airframe.Tail) == "" || strings.HasPrefix(strings.TrimSpace(msg.Airframe.Tail), ".") {
-			msg.Airframe.Tail = cleanTail
-		}
-		if strings.TrimSpace(msg.Tail) == "" {
-			msg.Tail = cleanTail
-		}
-	}
-
-	if flightNumber == "" && destinationAirport == "" && departingAirport == "" {
-		return
-	}
-
-	if msg.Flight == nil {
-		msg.Flight = &acars.Flight{}
-	}
-	if strings.TrimSpace(msg.Flight.Flight) == "" && flightNumber != "" {
-		msg.Flight.Flight = airlines.TranslateFlight(strings.TrimSpace(flightNumber))
-	}
-	if strings.TrimSpace(msg.Flight.DepartingAirport) == "" && departingAirport != "" {
-		msg.Flight.DepartingAirport = airports.NormaliseCode(departingAirport)
-	}
-	if strings.TrimSpace(msg.Flight.DestinationAirport) == "" && destinationAirport != "" {
-		msg.Flight.DestinationAirport = airports.NormaliseCode(destinationAirport)
-	}
-	normaliseMessageFlight(msg)
-}
-
-func parseAFNMetadataFromText(text string) (flightNumber string, cleanTail string, destinationAirport string) {
-	text = strings.TrimSpace(text)
-	if !strings.Contains(text, ".AFN/") {
-		return "", "", ""
-	}
-
-	fmhIdx := strings.Index(text, "/FMH")
-	if fmhIdx >= 0 {
-		rest := text[fmhIdx+4:]
-		commaIdx := strings.IndexByte(rest, ',')
-		if commaIdx > 0 {
-			flightNumber = strings.TrimSpace(rest[:commaIdx])
-			tailAndRest := rest[commaIdx+1:]
-			fields := strings.Split(tailAndRest, ",")
-			if len(fields) > 0 {
-				cleanTail = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(fields[0]), "."))
+		if m, ok := r.(*miampkg.Result); ok {
+			if inner := m.InnerACARS(msg); inner != nil {
+				results = append(results, reg.Dispatch(inner)...)
 			}
 		}
 	}
-
-	fakIdx := strings.Index(text, "/FAK0,")
-	if fakIdx >= 0 {
-		rest := text[fakIdx+6:]
-		endIdx := strings.IndexByte(rest, '/')
-		if endIdx >= 0 {
-			destinationAirport = strings.TrimSpace(rest[:endIdx])
-		} else {
-			destinationAirport = strings.TrimSpace(rest)
-		}
-	}
-
-	if len(destinationAirport) != 4 {
-		destinationAirport = ""
-	}
-
-	return strings.TrimSpace(flightNumber), strings.TrimSpace(cleanTail), destinationAirport
-}
-
-func parseFPNFlightFromText(text string) string {
-	text = strings.TrimSpace(strings.ToUpper(text))
-	if !strings.HasPrefix(text, "FPN/") {
-		return ""
-	}
-
-	match := patterns.FPNFlightPattern.FindStringSubmatch(text)
-	if len(match) < 2 {
-		return ""
-	}
-
-	return strings.TrimSpace(match[1])
-}
-
-func parsePDCMetadataFromText(text string) (flightNumber string, departingAirport string, destinationAirport string) {
-	text = strings.TrimSpace(strings.ToUpper(text))
-	if text == "" {
-		return "", "", ""
-	}
-	if !strings.Contains(text, "PDC") && !strings.Contains(text, "CLRD TO") {
-		return "", "", ""
-	}
-
-	if match := pdcFlightRe.FindStringSubmatch(text); len(match) == 3 {
-		flightNumber = strings.TrimSpace(match[1])
-		destinationAirport = strings.TrimSpace(match[2])
-	}
-	if match := pdcOriginHeaderRe.FindStringSubmatch(text); len(match) == 2 {
-		departingAirport = strings.TrimSpace(match[1])
-	}
-	if destinationAirport == "" {
-		if match := pdcDestinationRe.FindStringSubmatch(text); len(match) == 2 {
-			destinationAirport = strings.TrimSpace(match[1])
-		}
-	}
-	if flightNumber == "" {
-		tokens := strings.Fields(text)
-		flightNumber = strings.TrimSpace(patterns.ExtractFlightNumber(text, tokens))
-	}
-	return flightNumber, strings.TrimSpace(departingAirport), strings.TrimSpace(destinationAirport)
-}
-
-func parseFSMMetadataFromText(text string) (flightNumber string, departingAirport string) {
-	text = strings.TrimSpace(strings.ToUpper(text))
-	if text == "" || !strings.Contains(text, "FS1/FSM") {
-		return "", ""
-	}
-
-	match := fsmHeaderRe.FindStringSubmatch(text)
-	if len(match) != 3 {
-		return "", ""
-	}
-
-	departingAirport = strings.TrimSpace(match[1])
-	flightNumber = strings.TrimSpace(match[2])
-	if len(departingAirport) != 4 || flightNumber == "" {
-		return "", ""
-	}
-
-	return flightNumber, departingAirport
-}
-
-func parseINIMetadataFromText(text string) (flightNumber string, departingAirport string, destinationAirport string) {
-	text = strings.TrimSpace(strings.ToUpper(text))
-	if text == "" || (!strings.Contains(text, "INI01") && !strings.Contains(text, "INI/ID")) {
-		return "", "", ""
-	}
-
-	if match := iniIDMetadataRe.FindStringSubmatch(text); len(match) == 6 {
-		flightNumber = strings.TrimSpace(match[1])
-		departingAirport = strings.TrimSpace(match[2])
-		destinationAirport = strings.TrimSpace(match[3])
-		if len(departingAirport) == 4 && len(destinationAirport) == 4 && flightNumber != "" {
-			return flightNumber, departingAirport, destinationAirport
-		}
-	}
-
-	match := iniMetadataRe.FindStringSubmatch(text)
-	if len(match) != 7 {
-		return "", "", ""
-	}
-
-	flightNumber = strings.TrimSpace(match[4])
-	departingAirport = strings.TrimSpace(match[5])
-	destinationAirport = strings.TrimSpace(match[6])
-	if len(departingAirport) != 4 || len(destinationAirport) != 4 || flightNumber == "" {
-		return "", "", ""
-	}
-
-	return flightNumber, departingAirport, destinationAirport
-}
-
-func parseRAFlightMetadataFromText(text string) (flightNumber string, departingAirport string, destinationAirport string) {
-	text = strings.TrimSpace(strings.ToUpper(text))
-	if text == "" {
-		return "", "", ""
-	}
-
-	if strings.Contains(text, "FLIGHT NUMBER:") {
-		if match := raFlightNumberRe.FindStringSubmatch(text); len(match) == 2 {
-			flightNumber = strings.TrimSpace(match[1])
-			if strings.Contains(flightNumber, "/") {
-				parts := strings.SplitN(flightNumber, "/", 2)
-				if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
-					flightNumber = strings.TrimSpace(parts[1])
-				} else {
-					flightNumber = strings.TrimSpace(parts[0])
-				}
-			}
-		}
-
-		if match := raSectorRe.FindStringSubmatch(text); len(match) == 3 {
-			departingAirport = strings.TrimSpace(match[1])
-			destinationAirport = strings.TrimSpace(match[2])
-		}
-
-		if flightNumber == "" {
-			return "", "", ""
+	for _, r := range results {
+		if _, ok := r.(*registry.ErrorResult); ok {
+			st.ParserErrors++
 		}
-
-		return flightNumber, departingAirport, destinationAirport
-	}
-
-	if !strings.Contains(text, "OFP INFO") {
-		return "", "", ""
-	}
-
-	match := raOFPInfoRe.FindStringSubmatch(text)
-	if len(match) != 4 {
-		return "", "", ""
 	}
-
-	return strings.TrimSpace(match[1]), strings.TrimSpace(match[2]), strings.TrimSpace(match[3])
+	return results
 }
 
 func newOutputMessage(msg *acars.Message) *OutputMessage {
@@ -1086,7 +741,7 @@ func newOutputMessage(msg *acars.Message) *OutputMessage {
 		return nil
 	}
 
-	normaliseMessageFlight(msg)
+	decode.NormaliseFlight(msg)
 
 	out := &OutputMessage{
 		ID:        msg.ID,
@@ -1108,6 +763,7 @@ func newOutputMessage(msg *acars.Message) *OutputMessage {
 		out.Departing = strings.TrimSpace(msg.Flight.DepartingAirport)
 		out.Destination = strings.TrimSpace(msg.Flight.DestinationAirport)
 	}
+	out.EnrichedBy = msg.EnrichedBy
 
 	return out
 }
@@ -1187,442 +843,3 @@ func collectHumanReadableResults(results []any) []string {
 
 	return formatted
 }
-
-func decodeToMessage(b []byte) ([]*acars.Message, string) {
-	// 1) NATS wrapper
-	var w acars.NATSWrapper
-	if err := json.Unmarshal(b, &w); err == nil && w.Message != nil {
-		if msg := w.ToMessage(); msg != nil && (msg.Label != "" || msg.Text != "") {
-			normaliseMessageFlight(msg)
-			return []*acars.Message{msg}, "nats"
-		}
-	}
-
-	// 2) Flat message (only accept if it actually contains label/text)
-	var m acars.Message
-	if err := json.Unmarshal(b, &m); err == nil {
-		var flatRoot map[string]any
-		if err := json.Unmarshal(b, &flatRoot); err == nil {
-			if nestedMsgs := buildMessagesFromNested(flatRoot); len(nestedMsgs) > 0 {
-				return nestedMsgs, "nested"
-			}
-			enrichMessageFlight(&m, flatRoot)
-		}
-		if strings.TrimSpace(m.Label) != "" || strings.TrimSpace(m.Text) != "" {
-			normaliseMessageFlight(&m)
-			return []*acars.Message{&m}, "flat"
-		}
-	}
-
-	// 3) Nested formats (dumpvdl2/dumphfdl, etc.)
-	var anyObj any
-	if err := json.Unmarshal(b, &anyObj); err != nil {
-		return nil, ""
-	}
-	msgs := buildMessagesFromNested(anyObj)
-	if len(msgs) > 0 {
-		return msgs, "nested"
-	}
-	return nil, ""
-}
-
-// buildMessagesFromNested tries common paths used by dumpvdl2 / dumphfdl logs.
-// It returns multiple messages if MIAM decoded content is present (both outer and inner).
-func buildMessagesFromNested(obj any) []*acars.Message {
-	root, ok := obj.(map[string]any)
-	if !ok {
-		return nil
-	}
-
-	var msgs []*acars.Message
-
-	// First, try to extract outer ACARS message (e.g., MA with compressed text)
-	outerLabel := firstString(root,
-		"label",
-		"message.label",
-		"acars.label",
-		"vdl2.avlc.acars.label",
-		"vdl2.avlc.acars.lbl",
-		"hfdl.lpdu.hfnpdu.acars.label",
-		"hfdl.lpdu.hfnpdu.acars.acars_label",
-	)
-
-	outerText := firstString(root,
-		"text",
-		"message.text",
-		"msg_text",
-		"message.msg_text",
-		"acars.text",
-		"acars.message.text",
-		"vdl2.avlc.acars.text",
-		"vdl2.avlc.acars.msg_text",
-		"vdl2.avlc.acars.message.text",
-		"hfdl.lpdu.hfnpdu.acars.text",
-		"hfdl.lpdu.hfnpdu.acars.msg_text",
-	)
-
-	// Check if MIAM decoded content exists
-	miamLabel := firstString(root,
-		"vdl2.avlc.acars.miam.single_transfer.miam_core.data.acars.label",
-		"hfdl.lpdu.hfnpdu.acars.miam.single_transfer.miam_core.data.acars.label",
-	)
-
-	miamText := firstString(root,
-		"vdl2.avlc.acars.miam.single_transfer.miam_core.data.acars.message.text",
-		"hfdl.lpdu.hfnpdu.acars.miam.single_transfer.miam_core.data.acars.message.text",
-	)
-
-	// If we have both outer and MIAM content, create both messages
-	if strings.TrimSpace(outerLabel) != "" || strings.TrimSpace(outerText) != "" {
-		meta := extractNestedMessageMetadata(root)
-
-		// Create outer message (e.g., MA with compressed text)
-		outerMsg := &acars.Message{
-			Label:     outerLabel,
-			Text:      outerText,
-			Tail:      meta.tail,
-			Timestamp: meta.timestamp,
-			Frequency: meta.frequency,
-			Source:    meta.source,
-			Airframe:  meta.airframe,
-			Flight:    extractFlight(root),
-		}
-		normaliseMessageFlight(outerMsg)
-		msgs = append(msgs, outerMsg)
-
-		// If MIAM decoded content exists, create second message with decoded content
-		// Use label "MB" for MIAM decoded messages to distinguish from outer "MA" message
-		if strings.TrimSpace(miamLabel) != "" || strings.TrimSpace(miamText) != "" {
-			miamMsg := &acars.Message{
-				Label:     "MB",
-				Text:      miamText,
-				Tail:      meta.tail,
-				Timestamp: meta.timestamp,
-				Frequency: meta.frequency,
-				Source:    meta.source,
-				Airframe:  meta.airframe,
-				Flight:    extractFlight(root),
-			}
-			normaliseMessageFlight(miamMsg)
-			msgs = append(msgs, miamMsg)
-		}
-	}
-
-	if syntheticATNCM := buildSyntheticATNCMMessage(root); syntheticATNCM != nil {
-		msgs = append(msgs, syntheticATNCM)
-	}
-
-	if len(msgs) == 0 {
-		if synthetic := buildSyntheticHFDLDataMessage(root); synthetic != nil {
-			msgs = append(msgs, synthetic)
-		}
-	}
-
-	return msgs
-}
-
-func buildSyntheticATNCMMessage(root map[string]any) *acars.Message {
-	departingAirport := strings.TrimSpace(firstString(root,
-		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.departure_airport",
-	))
-	destinationAirport := strings.TrimSpace(firstString(root,
-		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.destination_airport",
-	))
-	if departingAirport == "" || destinationAirport == "" {
-		return nil
-	}
-
-	flight := extractFlight(root)
-	if flight == nil {
-		return nil
-	}
-
-	meta := extractNestedMessageMetadata(root)
-	textParts := []string{"ATN CM LOGON"}
-	if flightID := strings.TrimSpace(flight.ID); flightID != "" {
-		textParts = append(textParts, flightID)
-	}
-	textParts = append(textParts, strings.ToUpper(departingAirport)+"-"+strings.ToUpper(destinationAirport))
-
-	msg := &acars.Message{
-		Label:     "ATNCM",
-		Text:      strings.Join(textParts, " "),
-		Tail:      meta.tail,
-		Timestamp: meta.timestamp,
-		Frequency: meta.frequency,
-		Source:    meta.source,
-		Airframe:  meta.airframe,
-		Flight:    flight,
-	}
-	normaliseMessageFlight(msg)
-	return msg
-}
-
-type nestedMessageMeta struct {
-	tail      string
-	timestamp string
-	frequency float64
-	source    string
-	airframe  *acars.Airframe
-}
-
-func extractNestedMessageMetadata(root map[string]any) nestedMessageMeta {
-	tail := firstString(root,
-		"tail",
-		"airframe.tail",
-		"vdl2.avlc.acars.reg",
-		"vdl2.avlc.acars.tail",
-		"hfdl.lpdu.hfnpdu.acars.reg",
-	)
-
-	ts := firstString(root,
-		"timestamp",
-		"message.timestamp",
-	)
-	if ts == "" {
-		sec := firstInt64(root,
-			"vdl2.t.sec",
-			"hfdl.t.sec",
-			"t.sec",
-		)
-		usec := firstInt64(root,
-			"vdl2.t.usec",
-			"hfdl.t.usec",
-			"t.usec",
-		)
-		if sec > 0 {
-			t := time.Unix(sec, usec*1000).UTC()
-			ts = t.Format(time.RFC3339Nano)
-		}
-	}
-
-	freq := firstFloat64(root,
-		"frequency",
-		"message.frequency",
-		"vdl2.freq",
-		"hfdl.freq",
-	)
-	if freq > 1_000_000 {
-		freq = freq / 1_000_000.0
-	}
-
-	src := firstString(root,
-		"source",
-		"vdl2.app.name",
-		"hfdl.app.name",
-		"app.name",
-	)
-
-	icao := firstString(root,
-		"airframe.icao",
-		"hfdl.lpdu.ac_info.icao",
-	)
-
-	var airframe *acars.Airframe
-	if strings.TrimSpace(tail) != "" || strings.TrimSpace(icao) != "" {
-		airframe = &acars.Airframe{
-			Tail: strings.TrimSpace(tail),
-			ICAO: strings.ToUpper(strings.TrimSpace(icao)),
-		}
-	}
-
-	return nestedMessageMeta{
-		tail:      tail,
-		timestamp: ts,
-		frequency: freq,
-		source:    src,
-		airframe:  airframe,
-	}
-}
-
-func buildSyntheticHFDLDataMessage(root map[string]any) *acars.Message {
-	hfnpduType := strings.TrimSpace(firstString(root, "hfdl.lpdu.hfnpdu.type.name"))
-	if hfnpduType == "" || !strings.HasSuffix(strings.ToLower(hfnpduType), "data") {
-		return nil
-	}
-
-	flight := extractFlight(root)
-	if flight == nil || strings.TrimSpace(flight.ID) == "" {
-		return nil
-	}
-	if flight.Latitude == 0 && flight.Longitude == 0 {
-		return nil
-	}
-
-	meta := extractNestedMessageMetadata(root)
-	text := fmt.Sprintf("HFDL %s", hfnpduType)
-	if gsID := firstString(root, "hfdl.lpdu.dst.id"); strings.TrimSpace(gsID) != "" {
-		text = fmt.Sprintf("HFDL %s GS %s", hfnpduType, strings.TrimSpace(gsID))
-	}
-
-	msg := &acars.Message{
-		Label:     "HFDL",
-		Text:      text,
-		Tail:      meta.tail,
-		Timestamp: meta.timestamp,
-		Frequency: meta.frequency,
-		Source:    meta.source,
-		Airframe:  meta.airframe,
-		Flight:    flight,
-	}
-	normaliseMessageFlight(msg)
-	return msg
-}
-
-func enrichMessageFlight(msg *acars.Message, root map[string]any) {
-	if msg == nil || root == nil {
-		return
-	}
-
-	if flight := extractFlight(root); flight != nil {
-		msg.Flight = flight
-	}
-}
-
-func extractFlight(root map[string]any) *acars.Flight {
-	flightNumber := firstString(root,
-		"flight",
-		"message.flight",
-		"acars.flight",
-		"vdl2.avlc.acars.flight",
-		"hfdl.lpdu.hfnpdu.acars.flight",
-	)
-
-	flightID := firstString(root,
-		"flight_id",
-		"message.flight_id",
-		"hfdl.lpdu.hfnpdu.flight_id",
-		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.flight_id",
-	)
-
-	departureAirport := firstString(root,
-		"departure_airport",
-		"message.departure_airport",
-		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.departure_airport",
-	)
-
-	destinationAirport := firstString(root,
-		"destination_airport",
-		"message.destination_airport",
-		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.destination_airport",
-	)
-
-	latitude := firstFloat64(root,
-		"latitude",
-		"message.latitude",
-		"hfdl.lpdu.hfnpdu.pos.lat",
-	)
-	longitude := firstFloat64(root,
-		"longitude",
-		"message.longitude",
-		"hfdl.lpdu.hfnpdu.pos.lon",
-	)
-
-	if strings.TrimSpace(flightNumber) == "" && strings.TrimSpace(flightID) == "" &&
-		strings.TrimSpace(departureAirport) == "" && strings.TrimSpace(destinationAirport) == "" &&
-		latitude == 0 && longitude == 0 {
-		return nil
-	}
-
-	return &acars.Flight{
-		ID:                 strings.TrimSpace(flightID),
-		Flight:             airlines.TranslateFlight(strings.TrimSpace(flightNumber)),
-		DepartingAirport:   strings.TrimSpace(departureAirport),
-		DestinationAirport: strings.TrimSpace(destinationAirport),
-		Latitude:           latitude,
-		Longitude:          longitude,
-	}
-}
-
-func normaliseMessageFlight(msg *acars.Message) {
-	if msg == nil || msg.Flight == nil {
-		return
-	}
-
-	msg.Flight = &acars.Flight{
-		ID:                 strings.TrimSpace(msg.Flight.ID),
-		Flight:             airlines.TranslateFlight(strings.TrimSpace(msg.Flight.Flight)),
-		Status:             msg.Flight.Status,
-		DepartingAirport:   strings.TrimSpace(msg.Flight.DepartingAirport),
-		DestinationAirport: strings.TrimSpace(msg.Flight.DestinationAirport),
-		Latitude:           msg.Flight.Latitude,
-		Longitude:          msg.Flight.Longitude,
-		Altitude:           msg.Flight.Altitude,
-	}
-}
-
-func firstString(root map[string]any, paths ...string) string {
-	for _, p := range paths {
-		if v, ok := deepGet(root, p); ok {
-			switch t := v.(type) {
-			case string:
-				if strings.TrimSpace(t) != "" {
-					return t
-				}
-			case float64:
-				// Sometimes labels are numeric; preserve as int string where possible.
-				if t == float64(int64(t)) {
-					return strconv.FormatInt(int64(t), 10)
-				}
-				return strconv.FormatFloat(t, 'f', -1, 64)
-			case bool:
-				if t {
-					return "true"
-				}
-				return "false"
-			}
-		}
-	}
-	return ""
-}
-
-func firstInt64(root map[string]any, paths ...string) int64 {
-	for _, p := range paths {
-		if v, ok := deepGet(root, p); ok {
-			switch t := v.(type) {
-			case float64:
-				return int64(t)
-			case string:
-				if i, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64); err == nil {
-					return i
-				}
-			}
-		}
-	}
-	return 0
-}
-
-func firstFloat64(root map[string]any, paths ...string) float64 {
-	for _, p := range paths {
-		if v, ok := deepGet(root, p); ok {
-			switch t := v.(type) {
-			case float64:
-				return t
-			case string:
-				if f, err := strconv.ParseFloat(strings.TrimSpace(t), 64); err == nil {
-					return f
-				}
-			}
-		}
-	}
-	return 0
-}
-
-// deepGet walks a map[string]any using a dotted path: "a.b.c".
-func deepGet(root map[string]any, dotted string) (any, bool) {
-	parts := strings.Split(dotted, ".")
-	var cur any = root
-	for _, part := range parts {
-		switch node := cur.(type) {
-		case map[string]any:
-			v, ok := node[part]
-			if !ok {
-				return nil, false
-			}
-			cur = v
-		default:
-			return nil, false
-		}
-	}
-	return cur, true
-}
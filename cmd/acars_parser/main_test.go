@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/parsers/cpdlc"
+	"acars_parser/internal/registry"
+)
+
+// miamFPNFrame is a MIAM single-transfer frame carrying the H1 flight plan
+// of fpnBlocks, uncompressed.
+const miamFPNFrame = `T-2!<<+a/kT7u6:"3\!!#PE9poic|FPN/FNRJA111/RP:DA:OJAI:AA:EGLL:F:MUVIN,N31490E035327.L53..TAPUZ,N32020E034314.W13..VELOX,N33490E034050.N71..DESPO,N34269E034229`
+
+func TestMIAMInnerMessageDispatched(t *testing.T) {
+	registry.Default().Sort()
+
+	types := func(line string) ([]string, int) {
+		var out []ExtractOut
+		st := &Stats{}
+		processJSONLLine(line, func(o ExtractOut) { out = append(out, o) }, extractOptions{includeAll: true}, st)
+		var got []string
+		for _, o := range out {
+			for _, r := range o.Results {
+				got = append(got, registryType(r))
+			}
+		}
+		return got, len(out)
+	}
+
+	text, _ := json.Marshal(miamFPNFrame)
+	acarsdec := `{"timestamp":1778604860.5,"station_id":"RX1","label":"MA","tail":".JY-BAJ","text":` + string(text) + `}`
+	got, records := types(acarsdec)
+	if records != 1 || len(got) != 2 || got[0] != "miam_data" || got[1] != "flight_plan" {
+		t.Fatalf("acarsdec: %d records, result types %v", records, got)
+	}
+
+	// libacars' own decode is not dispatched a second time as label MB.
+	dumpvdl2 := `{"vdl2":{"t":{"sec":1778604860},"avlc":{"src":{"addr":"4B1803"},"acars":{"reg":".JY-BAJ","label":"MA","msg_text":` + string(text) +
+		`,"miam":{"single_transfer":{"miam_core":{"data":{"acars":{"label":"H1","message":{"text":"FPN/FNRJA111"}}}}}}}}}}`
+	got, records = types(dumpvdl2)
+	if records != 1 || len(got) != 2 || got[1] != "flight_plan" {
+		t.Fatalf("dumpvdl2: %d records, result types %v", records, got)
+	}
+}
+
+func TestATNCPDLCSyntheticMessage(t *testing.T) {
+	registry.Default().Sort()
+
+	// A downlink send PDU carrying dM0 WILCO, msg ID 1, ref 0.
+	line := `{"vdl2":{"t":{"sec":1778604860},"avlc":{"src":{"addr":"3C6586","type":"Aircraft"},"dst":{"addr":"1131E4","type":"Ground station"},` +
+		`"x25":{"clnp":{"cotp":{"x225_spdu":{"x227_apdu":{"data":[100,238,4,3,210,247,10,240,0,0]}}}}}}}}`
+	var out []ExtractOut
+	processJSONLLine(line, func(o ExtractOut) { out = append(out, o) }, extractOptions{includeAll: true}, &Stats{})
+	if len(out) != 1 || out[0].Message.Label != "ATNCPDLC" || len(out[0].Results) != 1 {
+		t.Fatalf("got %+v", out)
+	}
+	r, ok := out[0].Results[0].(*cpdlc.Result)
+	if !ok || r.MessageType != "atn_cpdlc" || r.Direction != "downlink" || len(r.Elements) != 1 || r.Elements[0].Text != "WILCO" {
+		t.Fatalf("result = %+v", out[0].Results[0])
+	}
+}
+
+type panicParser struct{}
+
+func (panicParser) Name() string                             { return "panics" }
+func (panicParser) Labels() []string                         { return []string{"ZZ"} }
+func (panicParser) QuickCheck(string) bool                   { return true }
+func (panicParser) Priority() int                            { return 0 }
+func (panicParser) Parse(msg *acars.Message) registry.Result { panic("malformed payload") }
+
+func TestParserPanicCounted(t *testing.T) {
+	// Register on a clone, so the default registry stays as shipped.
+	reg, err := registry.Default().Clone(registry.Selection{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg.Register(panicParser{})
+	reg.Sort()
+
+	line := `{"timestamp":1778604860.5,"station_id":"RX1","label":"ZZ","tail":".D-AIXL","text":"BOGUS"}`
+	var out []ExtractOut
+	st := &Stats{}
+	processJSONLLine(line, func(o ExtractOut) { out = append(out, o) }, extractOptions{parsers: reg}, st)
+	if st.ParserErrors != 1 || len(out) != 1 || len(out[0].Results) == 0 {
+		t.Fatalf("parser_errors=%d, records %+v", st.ParserErrors, out)
+	}
+	e, ok := out[0].Results[0].(*registry.ErrorResult)
+	if !ok || e.Parser != "panics" || e.Error != "panic: malformed payload" {
+		t.Fatalf("result = %+v", out[0].Results[0])
+	}
+}
+
+func TestRecordQuality(t *testing.T) {
+	registry.Default().Sort()
+
+	record := func(line string) ExtractOut {
+		var out []ExtractOut
+		processJSONLLine(line, func(o ExtractOut) { out = append(out, o) }, extractOptions{}, &Stats{})
+		if len(out) != 1 {
+			t.Fatalf("%s: %d records", line, len(out))
+		}
+		return out[0]
+	}
+
+	// Only the inner flight plan reports its quality; Result indexes it.
+	text, _ := json.Marshal(miamFPNFrame)
+	o := record(`{"timestamp":1778604860.5,"label":"MA","tail":".JY-BAJ","text":` + string(text) + `}`)
+	if len(o.Quality) != 1 || o.Quality[0].Result != 1 || o.Quality[0].Type != "flight_plan" ||
+		o.Quality[0].Confidence != 1 || len(o.Quality[0].Missing) != 0 {
+		t.Errorf("flight plan quality = %+v", o.Quality)
+	}
+
+	o = record(`{"timestamp":1778604860.5,"label":"80","text":"3N01 POSRPT 0210/30 KATL/FACT .N522DZ/POS S22336W006589"}`)
+	if len(o.Quality) != 1 {
+		t.Fatalf("position quality = %+v", o.Quality)
+	}
+	q := o.Quality[0]
+	if len(q.Missing) != 1 || q.Missing[0] != "altitude" || len(q.Expected) != 5 || q.Confidence != 6.0/7 {
+		t.Errorf("position quality = %+v", q)
+	}
+	b, _ := json.Marshal(q)
+	if want := `{"result":0,"type":"position","expected":["origin_icao","dest_icao","latitude","longitude","altitude"],"missing":["altitude"],"confidence":0.8571428571428571}`; string(b) != want {
+		t.Errorf("JSON = %s", b)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import "sync"
+
+// workFunc is one unit of output-producing work: decoding and dispatching a
+// JSONL line, or a complete (possibly reassembled) JAERO block.  It reports
+// its records through emit and its counters through st.
+type workFunc func(emit emitFunc, st *Stats)
+
+// dispatcher runs a workFunc.  Everything that has to happen in input order
+// (reading, MIAM reassembly) stays with the caller; only the work handed to
+// the dispatcher may run elsewhere.
+type dispatcher func(workFunc)
+
+// inlineDispatcher runs each workFunc immediately on the calling goroutine.
+func inlineDispatcher(emit emitFunc, st *Stats) dispatcher {
+	return func(w workFunc) { w(emit, st) }
+}
+
+// orderedPool runs work on a fixed number of goroutines while still emitting
+// the results in the order the work was submitted.
+//
+// Each submitted job gets a slot that is queued in submission order.  A
+// worker fills the slot's records and counters; the collector goroutine
+// waits for the slots one by one and passes their records to emit.  The
+// queues are bounded, so a slow writer eventually blocks the reader instead
+// of buffering the whole input.
+type orderedPool struct {
+	jobs    chan poolJob
+	pending chan *poolSlot
+	workers sync.WaitGroup
+	done    chan struct{}
+	emit    emitFunc
+	st      Stats // collector-owned; merged into the caller's Stats by Close
+}
+
+type poolSlot struct {
+	outs  []ExtractOut
+	st    Stats
+	ready chan struct{}
+}
+
+type poolJob struct {
+	slot *poolSlot
+	work workFunc
+}
+
+func newOrderedPool(workers int, emit emitFunc) *orderedPool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &orderedPool{
+		jobs:    make(chan poolJob, workers*16),
+		pending: make(chan *poolSlot, workers*16),
+		done:    make(chan struct{}),
+		emit:    emit,
+	}
+	for i := 0; i < workers; i++ {
+		p.workers.Add(1)
+		go func() {
+			defer p.workers.Done()
+			for j := range p.jobs {
+				slot := j.slot
+				j.work(func(out ExtractOut) { slot.outs = append(slot.outs, out) }, &slot.st)
+				close(slot.ready)
+			}
+		}()
+	}
+	go func() {
+		defer close(p.done)
+		for slot := range p.pending {
+			<-slot.ready
+			for _, out := range slot.outs {
+				p.emit(out)
+			}
+			p.st.add(slot.st)
+		}
+	}()
+	return p
+}
+
+// Dispatch queues w.  It must not be called after Close.
+func (p *orderedPool) Dispatch(w workFunc) {
+	slot := &poolSlot{ready: make(chan struct{})}
+	p.pending <- slot
+	p.jobs <- poolJob{slot: slot, work: w}
+}
+
+// Close waits for all queued work to be emitted and adds the counters it
+// produced to st.
+func (p *orderedPool) Close(st *Stats) {
+	close(p.jobs)
+	close(p.pending)
+	p.workers.Wait()
+	<-p.done
+	st.add(p.st)
+}
+
+// add accumulates the counters of o into s.
+func (s *Stats) add(o Stats) {
+	s.Files += o.Files
+	s.Lines += o.Lines
+	s.ParsedJAERO += o.ParsedJAERO
+	s.ParsedNATS += o.ParsedNATS
+	s.ParsedFlat += o.ParsedFlat
+	s.ParsedNested += o.ParsedNested
+	s.SkippedNoLabel += o.SkippedNoLabel
+	s.Filtered += o.Filtered
+	s.Emitted += o.Emitted
+	s.Matched += o.Matched
+	s.Duplicates += o.Duplicates
+	s.Reassembled += o.Reassembled
+	s.Partial += o.Partial
+	s.ParserErrors += o.ParserErrors
+}
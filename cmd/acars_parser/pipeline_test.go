@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+func TestOrderedPoolPreservesOrder(t *testing.T) {
+	var got []int64
+	pool := newOrderedPool(4, func(out ExtractOut) { got = append(got, int64(out.Message.ID)) })
+	for i := 0; i < 200; i++ {
+		id := int64(i)
+		pool.Dispatch(func(emit emitFunc, st *Stats) {
+			// Later jobs finish first.
+			time.Sleep(time.Duration(200-id) * time.Microsecond)
+			emit(ExtractOut{Message: &OutputMessage{ID: acars.FlexInt64(id)}})
+			st.Emitted++
+		})
+	}
+	st := &Stats{Lines: 200}
+	pool.Close(st)
+
+	if len(got) != 200 {
+		t.Fatalf("emitted %d records, want 200", len(got))
+	}
+	for i, id := range got {
+		if id != int64(i) {
+			t.Fatalf("record %d has id %d; output is out of order", i, id)
+		}
+	}
+	if st.Lines != 200 || st.Emitted != 200 {
+		t.Fatalf("stats = %+v", *st)
+	}
+}
+
+func TestExtractWorkersMatchSequential(t *testing.T) {
+	registry.Default().Sort()
+
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, liveTestPayload, `{"unrelated":true}`, "")
+	}
+	input := strings.Join(lines, "\n")
+
+	run := func(workers int) ([]ExtractOut, Stats) {
+		st := &Stats{}
+		var out []ExtractOut
+		emit := func(o ExtractOut) { out = append(out, o) }
+		if workers == 1 {
+			_ = processInput(strings.NewReader(input), "", nil, inlineDispatcher(emit, st), extractOptions{}, false, st)
+			return out, *st
+		}
+		pool := newOrderedPool(workers, emit)
+		_ = processInput(strings.NewReader(input), "", nil, pool.Dispatch, extractOptions{}, false, st)
+		pool.Close(st)
+		return out, *st
+	}
+
+	wantOut, wantSt := run(1)
+	gotOut, gotSt := run(8)
+	if gotSt != wantSt {
+		t.Fatalf("stats with workers = %+v, sequential = %+v", gotSt, wantSt)
+	}
+	if wantSt.Emitted != 50 || wantSt.SkippedNoLabel != 50 {
+		t.Fatalf("unexpected sequential stats %+v", wantSt)
+	}
+	if !reflect.DeepEqual(gotOut, wantOut) {
+		t.Fatal("records with workers differ from sequential run")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"acars_parser/internal/registry"
+)
+
+// writeParserProfile prints the -profile-parsers report: one row per parser,
+// most expensive first.  fp% is the share of QuickCheck hits where Parse
+// found nothing, so a high value marks a QuickCheck that is too broad.
+func writeParserProfile(w io.Writer, metrics []registry.ParserMetrics) {
+	var total time.Duration
+	for _, m := range metrics {
+		total += m.Duration
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "parser\tcalls\thits\tfalse_pos\tfp%\tparsed\tpanics\ttime\ttime%\tper_call")
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			m.Name, m.Calls, m.Hits, m.Misses, percent(m.Misses, m.Hits), m.Parsed, m.Panics,
+			m.Duration.Round(time.Microsecond), percent(int64(m.Duration), int64(total)), perCall(m))
+	}
+	fmt.Fprintf(tw, "total\t\t\t\t\t\t\t%s\n", total.Round(time.Microsecond))
+	_ = tw.Flush()
+}
+
+func percent(n, of int64) string {
+	if of == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", 100*float64(n)/float64(of))
+}
+
+func perCall(m registry.ParserMetrics) string {
+	if m.Calls == 0 {
+		return "-"
+	}
+	return (m.Duration / time.Duration(m.Calls)).String()
+}
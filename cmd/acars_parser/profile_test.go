@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"acars_parser/internal/registry"
+)
+
+func TestWriteParserProfile(t *testing.T) {
+	var b strings.Builder
+	writeParserProfile(&b, []registry.ParserMetrics{
+		{Name: "pdc", Calls: 1000, Hits: 40, Misses: 30, Parsed: 10, Duration: 3 * time.Millisecond},
+		{Name: "label16", Calls: 0},
+	})
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines:\n%s", len(lines), b.String())
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "pdc 1000 40 30 75.0 10 0 3ms 100.0 3µs" {
+		t.Errorf("pdc row = %q", got)
+	}
+	if got := strings.Fields(lines[2]); got[4] != "-" || got[len(got)-1] != "-" {
+		t.Errorf("idle parser row = %q", got)
+	}
+	if got := strings.Fields(lines[3]); len(got) != 2 || got[1] != "3ms" {
+		t.Errorf("total row = %q", got)
+	}
+}
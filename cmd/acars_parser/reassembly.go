@@ -0,0 +1,41 @@
+package main
+
+import (
+	"acars_parser/internal/acars"
+	"acars_parser/internal/decode"
+)
+
+// ReassemblyInfo is attached to a record whose text was joined from several
+// ACARS blocks.
+type ReassemblyInfo = decode.ReassemblyInfo
+
+// emitAssembled dispatches a joined message and tags its record with info.
+func emitAssembled(emit emitFunc, msg *acars.Message, info *ReassemblyInfo, opts extractOptions, st *Stats) {
+	if info.Complete {
+		st.Reassembled++
+	} else {
+		st.Partial++
+	}
+	st.count(emitOut(func(out ExtractOut) {
+		out.Reassembly = info
+		emit(out)
+	}, msg, opts, st))
+}
+
+// processBlockLine is processJSONLLine for the live and listen commands,
+// which handle one line at a time: blocks of multi-block messages are
+// joined by blocks first when it is non-nil.
+func processBlockLine(line string, emit emitFunc, blocks *decode.BlockAssembler, opts extractOptions, st *Stats) {
+	if blocks == nil || !decode.MayCarryBlock(line) {
+		processJSONLLine(line, emit, opts, st)
+		return
+	}
+	done := func(msg *acars.Message, info *ReassemblyInfo) {
+		emitAssembled(emit, msg, info, opts, st)
+	}
+	if msgs, kind, taken := blocks.TakeLine(line, done); taken {
+		st.countKind(kind)
+	} else {
+		emitMessages(msgs, kind, emit, opts, st)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"acars_parser/internal/registry"
+)
+
+// fpnBlocks is an H1 flight plan split into three ACARS blocks.
+var fpnBlocks = []string{
+	"FPN/FNRJA111/RP:DA:OJAI:AA:EGLL:F:MUVIN,N31490E035327",
+	".L53..TAPUZ,N32020E034314.W13..VELOX,N33490E034050",
+	".N71..DESPO,N34269E034229",
+}
+
+func acarsdecBlock(seq byte, end bool, ts int, text string) string {
+	return fmt.Sprintf(`{"timestamp":%d.5,"station_id":"RX1","freq":131.525,"label":"H1","block_id":"%d","tail":".JY-BAJ","flight":"RJ0111","msgno":"D05%c","text":%q,"end":%v}`,
+		ts, int(seq-'A')+1, seq, text, end)
+}
+
+func TestProcessInputJoinsBlocks(t *testing.T) {
+	registry.Default().Sort()
+
+	run := func(lines []string, timeout time.Duration) ([]ExtractOut, Stats) {
+		var out []ExtractOut
+		st := &Stats{}
+		emit := func(o ExtractOut) { out = append(out, o) }
+		opts := extractOptions{includeAll: true, blockTimeout: timeout}
+		input := strings.Join(lines, "\n") + "\n"
+		if err := processInput(strings.NewReader(input), "rx1.jsonl", nil, inlineDispatcher(emit, st), opts, false, st); err != nil {
+			t.Fatalf("processInput: %v", err)
+		}
+		return out, *st
+	}
+
+	// Blocks out of order, a repeated block and an unrelated message in
+	// between.
+	lines := []string{
+		acarsdecBlock('A', false, 1778604860, fpnBlocks[0]),
+		acarsdecBlock('C', true, 1778604864, fpnBlocks[2]),
+		liveTestPayload,
+		acarsdecBlock('B', false, 1778604862, fpnBlocks[1]),
+		acarsdecBlock('C', true, 1778604865, fpnBlocks[2]),
+	}
+	out, st := run(lines, time.Minute)
+	if len(out) != 2 || st.Reassembled != 1 || st.Partial != 0 || st.ParsedNested != 4 {
+		t.Fatalf("got %d records, stats %+v", len(out), st)
+	}
+	joined := out[1]
+	if joined.Message.Text != strings.Join(fpnBlocks, "") {
+		t.Fatalf("joined text = %q", joined.Message.Text)
+	}
+	if r := joined.Reassembly; r == nil || !r.Complete || r.Blocks != 3 || r.MsgNo != "D05" {
+		t.Fatalf("reassembly = %+v", joined.Reassembly)
+	}
+	if joined.Origin.Line != 1 {
+		t.Errorf("joined origin line = %d, want 1", joined.Origin.Line)
+	}
+	if len(joined.Results) == 0 || registryType(joined.Results[0]) != "flight_plan" {
+		t.Fatalf("joined results = %v", joined.Results)
+	}
+
+	// Without reassembly every block is parsed on its own.
+	out, _ = run(lines, 0)
+	if len(out) != 5 {
+		t.Fatalf("disabled: got %d records", len(out))
+	}
+
+	// A lost middle block gives a partial result when the input ends.
+	out, st = run([]string{lines[0], lines[1]}, time.Minute)
+	if len(out) != 1 || st.Partial != 1 {
+		t.Fatalf("partial: got %d records, stats %+v", len(out), st)
+	}
+	if r := out[0].Reassembly; r.Complete || !reflect.DeepEqual(r.Missing, []string{"B"}) {
+		t.Fatalf("partial reassembly = %+v", r)
+	}
+}
+
+func registryType(r any) string {
+	if res, ok := r.(registry.Result); ok {
+		return res.Type()
+	}
+	return ""
+}
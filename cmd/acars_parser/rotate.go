@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// rotatingFile is an append-only file that is rotated once it grows past
+// maxBytes or has been open for longer than interval (either limit may be
+// zero to disable it).  On rotation path is renamed to path.1, path.1 to
+// path.2 and so on; files beyond keep are removed.
+//
+// Rotation only ever happens between lines, so a JSONL record is never split
+// across two files even when a buffered writer hands it over in pieces.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	interval time.Duration
+	keep     int
+
+	f         *os.File
+	size      int64
+	opened    time.Time
+	lineStart bool
+	now       func() time.Time
+}
+
+func openRotatingFile(path string, maxBytes int64, interval time.Duration, keep int) (*rotatingFile, error) {
+	if keep < 1 {
+		keep = 1
+	}
+	r := &rotatingFile{path: path, maxBytes: maxBytes, interval: interval, keep: keep, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = fi.Size()
+	r.opened = r.now()
+	r.lineStart = true
+	return nil
+}
+
+func (r *rotatingFile) due(n int) bool {
+	if !r.lineStart || r.size == 0 {
+		return false
+	}
+	if r.maxBytes > 0 && r.size+int64(n) > r.maxBytes {
+		return true
+	}
+	return r.interval > 0 && r.now().Sub(r.opened) >= r.interval
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+	for i := r.keep - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if r.due(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, fmt.Errorf("rotate %s: %w", r.path, err)
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	if n > 0 {
+		r.lineStart = p[n-1] == '\n'
+	}
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	rf, err := openRotatingFile(path, 10, 0, 2)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	// A record handed over in two pieces must not be split by a rotation.
+	for _, chunk := range []string{"aaaaaa", "aaa\n", "bbbbbbbbb\n", "cccc", "cccc\n", "ddd\n"} {
+		if _, err := rf.Write([]byte(chunk)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	want := map[string]string{
+		path:        "ddd\n",
+		path + ".1": "cccccccc\n",
+		path + ".2": "bbbbbbbbb\n",
+	}
+	for p, w := range want {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read %s: %v", p, err)
+		}
+		if string(b) != w {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), b, w)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only %d rotated files to be kept", 2)
+	}
+}
+
+func TestRotatingFileByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.jsonl")
+	rf, err := openRotatingFile(path, 0, time.Hour, 3)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	now := time.Date(2026, 5, 12, 10, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time { return now }
+	rf.opened = now
+
+	_, _ = rf.Write([]byte("first\n"))
+	now = now.Add(30 * time.Minute)
+	_, _ = rf.Write([]byte("second\n"))
+	now = now.Add(31 * time.Minute)
+	_, _ = rf.Write([]byte("third\n"))
+	_ = rf.Close()
+
+	old, _ := os.ReadFile(path + ".1")
+	cur, _ := os.ReadFile(path)
+	if strings.Count(string(old), "\n") != 2 || string(cur) != "third\n" {
+		t.Fatalf("rotated = %q, current = %q", old, cur)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"acars_parser/internal/registry"
+	"acars_parser/internal/schema"
+)
+
+// schemaVersion is the version of the schema the schema command writes.  Bump
+// it whenever a result or record field changes; TestSchemaVersion fails until
+// you do.
+const schemaVersion = 4
+
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	typ := fs.String("type", "", "Only write the schema of this result type")
+	list := fs.Bool("list", false, "List the result types instead of writing a schema")
+	outPath := fs.String("output", "", "Output file (default: stdout)")
+	var pf parserFlags
+	pf.register(fs)
+	_ = fs.Parse(args)
+
+	parsers, err := newParserRegistry(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parser setup: %v\n", err)
+		os.Exit(2)
+	}
+	doc, err := buildSchema(parsers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Output create error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *list {
+		for _, t := range schema.ResultTypes(doc) {
+			fmt.Fprintln(w, t)
+		}
+		return
+	}
+	if *typ != "" {
+		if doc, err = schema.ForType(doc, *typ); err != nil {
+			fmt.Fprintf(os.Stderr, "schema: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if err := writeSchema(w, doc); err != nil {
+		fmt.Fprintf(os.Stderr, "Output write error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// buildSchema returns the schema of an ExtractOut record holding the results
+// of reg's parsers.
+func buildSchema(reg *registry.Registry) (*schema.Schema, error) {
+	doc, err := schema.Build(reg, ExtractOut{}, "results")
+	if err != nil {
+		return nil, err
+	}
+	doc.Title = "ExtractOut"
+	doc.Version = schemaVersion
+	return doc, nil
+}
+
+func writeSchema(w io.Writer, doc *schema.Schema) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"acars_parser/internal/registry"
+)
+
+var updateSchema = flag.Bool("update-schema", false, "Write testdata/schema.json after a schemaVersion bump")
+
+// TestSchemaVersion compares the schema with testdata/schema.json, so a
+// changed result or record field cannot ship without a version bump.
+func TestSchemaVersion(t *testing.T) {
+	doc, err := buildSchema(registry.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := writeSchema(&got, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "schema.json")
+	want, err := os.ReadFile(golden)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got.Bytes(), want) {
+		return
+	}
+	var old struct {
+		Version int `json:"x-schema-version"`
+	}
+	if want != nil {
+		if err := json.Unmarshal(want, &old); err != nil {
+			t.Fatalf("%s: %v", golden, err)
+		}
+		if old.Version >= schemaVersion {
+			t.Fatalf("the schema changed but schemaVersion is still %d: bump it, then run go test -run TestSchemaVersion -update-schema", schemaVersion)
+		}
+	}
+	if !*updateSchema {
+		t.Fatalf("%s holds version %d; run go test -run TestSchemaVersion -update-schema to write version %d", golden, old.Version, schemaVersion)
+	}
+	if err := os.MkdirAll("testdata", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"acars_parser/internal/enrich"
+	"acars_parser/internal/parsers/grokfile"
+	"acars_parser/internal/registry"
+)
+
+// parserFlags holds the -enable/-disable/-parser-config/-formats/-enrich flag
+// values shared by extract, live, listen and explain.
+type parserFlags struct {
+	enable  string
+	disable string
+	config  string
+	formats string
+	enrich  string
+}
+
+func (pf *parserFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&pf.enable, "enable", "", "Only run these parsers, by parser name or result type (comma-separated)")
+	fs.StringVar(&pf.disable, "disable", "", "Do not run these parsers, by parser name or result type (comma-separated)")
+	fs.StringVar(&pf.config, "parser-config", "", "JSON file with enable/disable lists and per-parser priority overrides")
+	fs.StringVar(&pf.formats, "formats", "", "Directory of YAML/JSON grok format files to register as extra parsers")
+	fs.StringVar(&pf.enrich, "enrich", "", "Enrichers filling in flight, tail and airports, in order (comma-separated, or none; default: "+strings.Join(enrich.Names(), ",")+")")
+}
+
+// newParserRegistry returns the registry to dispatch with.  Without any
+// parser flag this is the default registry; otherwise it is a clone of it
+// with the -formats parsers added and the config file's selection and the
+// flag lists applied.
+func newParserRegistry(pf parserFlags) (*registry.Registry, error) {
+	var sel registry.Selection
+	if pf.config != "" {
+		data, err := os.ReadFile(pf.config)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&sel); err != nil {
+			return nil, fmt.Errorf("%s: %w", pf.config, err)
+		}
+	}
+	sel.Enable = append(sel.Enable, splitList(pf.enable)...)
+	sel.Disable = append(sel.Disable, splitList(pf.disable)...)
+
+	reg := registry.Default()
+	reg.Sort()
+	if pf.formats != "" {
+		extra, err := grokfile.Load(pf.formats)
+		if err != nil {
+			return nil, err
+		}
+		if reg, err = reg.Clone(registry.Selection{}); err != nil {
+			return nil, err
+		}
+		for _, p := range extra {
+			if reg.Has(p.Name()) {
+				return nil, fmt.Errorf("%s: parser name %q is already registered", pf.formats, p.Name())
+			}
+			reg.Register(p)
+		}
+		reg.Sort()
+	}
+	if len(sel.Enable) == 0 && len(sel.Disable) == 0 && len(sel.Priority) == 0 {
+		return reg, nil
+	}
+	return reg.Clone(sel)
+}
+
+// newEnrichChain returns the enricher chain of -enrich, or nil for the
+// default chain.
+func newEnrichChain(pf parserFlags) (*enrich.Chain, error) {
+	if pf.enrich == "" {
+		return nil, nil
+	}
+	return enrich.ByName(splitList(pf.enrich)...)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
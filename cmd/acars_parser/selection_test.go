@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/registry"
+)
+
+func TestParserSelection(t *testing.T) {
+	if reg, err := newParserRegistry(parserFlags{}); err != nil || reg != registry.Default() {
+		t.Fatalf("no flags: %v, %v", reg, err)
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "parsers.json")
+	if err := os.WriteFile(config, []byte(`{"disable": ["envelope"], "priority": {"fpn": 1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	text, _ := json.Marshal(miamFPNFrame)
+	line := `{"timestamp":1778604860.5,"station_id":"RX1","label":"MA","tail":".JY-BAJ","text":` + string(text) + `}`
+	types := func(pf parserFlags) []string {
+		reg, err := newParserRegistry(pf)
+		if err != nil {
+			t.Fatalf("%+v: %v", pf, err)
+		}
+		var got []string
+		processJSONLLine(line, func(o ExtractOut) {
+			for _, r := range o.Results {
+				got = append(got, registryType(r))
+			}
+		}, extractOptions{parsers: reg}, &Stats{})
+		return got
+	}
+
+	if got := strings.Join(types(parserFlags{config: config, enable: "miam,flight_plan"}), ","); got != "miam_data,flight_plan" {
+		t.Errorf("miam and flight_plan enabled: %s", got)
+	}
+	// Without the MIAM parser the inner flight plan is never reached.
+	if got := types(parserFlags{config: config, disable: "miam"}); len(got) != 0 {
+		t.Errorf("miam disabled: %v", got)
+	}
+
+	if err := os.WriteFile(config, []byte(`{"disabled": ["envelope"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newParserRegistry(parserFlags{config: config}); err == nil {
+		t.Error("unknown config field accepted")
+	}
+	if _, err := newParserRegistry(parserFlags{enable: "positon"}); err == nil {
+		t.Error("unknown parser name accepted")
+	}
+
+	formats := filepath.Join(dir, "formats")
+	if err := os.Mkdir(formats, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(formats, "jy.yaml"), []byte(`
+name: jy_miam
+labels: ["MA"]
+priority: 1
+formats:
+  - name: frame
+    pattern: '^(?P<version>T-\d)'
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(types(parserFlags{formats: formats, enable: "jy_miam,miam"}), ","); got != "jy_miam,miam_data" {
+		t.Errorf("format file parser: %s", got)
+	}
+	if registry.Default().Has("jy_miam") {
+		t.Error("format file parser registered in the default registry")
+	}
+	if err := os.WriteFile(filepath.Join(formats, "jy.yaml"), []byte("name: miam\nformats:\n  - name: f\n    pattern: '(?P<x>X)'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newParserRegistry(parserFlags{formats: formats}); err == nil {
+		t.Error("format file reusing a built-in parser name accepted")
+	}
+}
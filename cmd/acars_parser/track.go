@@ -0,0 +1,335 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/decode"
+	"acars_parser/internal/registry"
+	"acars_parser/internal/state"
+)
+
+// defaultStateDB is the state database track and state dump use without
+// -state-db.
+const defaultStateDB = "acars_state.db"
+
+// stateTracker feeds records through state.ExtractAndUpdate and keeps what
+// the tracker learned that it did not know before.
+type stateTracker struct {
+	path string
+	t    *state.Tracker
+
+	mu        sync.Mutex
+	aircraft  []*state.Aircraft
+	routes    []*state.Route
+	waypoints []*state.Waypoint
+}
+
+// openStateTracker opens, or creates, the state database at path.
+func openStateTracker(path string) (*stateTracker, error) {
+	t, err := state.NewTracker(path)
+	if err != nil {
+		return nil, fmt.Errorf("state DB %s: %w", path, err)
+	}
+	s := &stateTracker{path: path, t: t}
+	t.OnAircraftNew(func(a *state.Aircraft) {
+		s.mu.Lock()
+		s.aircraft = append(s.aircraft, a)
+		s.mu.Unlock()
+	})
+	t.OnRouteNew(func(r *state.Route) {
+		s.mu.Lock()
+		s.routes = append(s.routes, r)
+		s.mu.Unlock()
+	})
+	t.OnWaypointNew(func(w *state.Waypoint) {
+		s.mu.Lock()
+		s.waypoints = append(s.waypoints, w)
+		s.mu.Unlock()
+	})
+	return s, nil
+}
+
+// track updates the state with one record.
+func (s *stateTracker) track(out ExtractOut) {
+	if out.Message == nil {
+		return
+	}
+	results := make([]registry.Result, 0, len(out.Results))
+	for _, r := range out.Results {
+		if res, ok := r.(registry.Result); ok {
+			results = append(results, res)
+		}
+	}
+	state.ExtractAndUpdate(s.t, out.Message.acarsMessage(), results)
+}
+
+// wrap returns an emitFunc that tracks each record before passing it on.
+func (s *stateTracker) wrap(emit emitFunc) emitFunc {
+	return func(out ExtractOut) {
+		s.track(out)
+		emit(out)
+	}
+}
+
+// writeSummary writes what was learned and the totals in the database.  With
+// list, every new aircraft, route and waypoint follows.
+func (s *stateTracker) writeSummary(w io.Writer, list bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.t.GetStats()
+	fmt.Fprintf(w, "state: new aircraft=%d routes=%d waypoints=%d; %s holds aircraft=%d routes=%d waypoints=%d\n",
+		len(s.aircraft), len(s.routes), len(s.waypoints),
+		s.path, stats.TotalAircraft, stats.TotalRoutes, stats.TotalWaypoints)
+	if !list {
+		return
+	}
+	for _, a := range s.aircraft {
+		fmt.Fprintf(w, "  aircraft %-8s %-8s %s\n", a.ICAOHex, a.Registration, strings.TrimSpace(a.TypeCode+" "+a.Operator))
+	}
+	for _, r := range s.routes {
+		fmt.Fprintf(w, "  route    %-8s %s-%s\n", r.FlightPattern, r.OriginICAO, r.DestICAO)
+	}
+	for _, wp := range s.waypoints {
+		fmt.Fprintf(w, "  waypoint %-8s %.4f %.4f\n", wp.Name, wp.Latitude, wp.Longitude)
+	}
+}
+
+func (s *stateTracker) Close() error {
+	return s.t.Close()
+}
+
+// acarsMessage turns an output message back into the acars.Message the
+// state extractor reads.
+func (m *OutputMessage) acarsMessage() *acars.Message {
+	msg := &acars.Message{
+		ID:        m.ID,
+		Source:    m.Source,
+		Timestamp: m.Timestamp,
+		Tail:      m.Tail,
+		Text:      m.Text,
+		Label:     m.Label,
+		Frequency: m.Frequency,
+		Airframe:  m.Airframe,
+		Station:   m.Station,
+	}
+	if m.Flight != "" || m.FlightID != "" || m.Departing != "" || m.Destination != "" || m.Latitude != 0 || m.Longitude != 0 {
+		msg.Flight = &acars.Flight{
+			ID:                 m.FlightID,
+			Flight:             m.Flight,
+			DepartingAirport:   m.Departing,
+			DestinationAirport: m.Destination,
+			Latitude:           m.Latitude,
+			Longitude:          m.Longitude,
+		}
+	}
+	return msg
+}
+
+// runTrack reads inputs like extract, but only feeds the state database and
+// prints what it learned.
+func runTrack(args []string) {
+	fs := flag.NewFlagSet("track", flag.ExitOnError)
+	inPath := fs.String("input", "", "Input file, directory or glob (default: stdin)")
+	dbPath := fs.String("state-db", defaultStateDB, "SQLite state database to update")
+	list := fs.Bool("v", false, "List every new aircraft, route and waypoint")
+	showStats := fs.Bool("stats", false, "Print basic counters to stderr")
+	blockTimeout := fs.Duration("block-timeout", decode.DefaultBlockTimeout, "Join multi-block ACARS messages whose blocks arrive within this time of each other (0 disables)")
+	var pf parserFlags
+	pf.register(fs)
+	extraInputs := parseInterspersed(fs, args)
+
+	parsers, err := newParserRegistry(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid parser setup: %v\n", err)
+		os.Exit(2)
+	}
+	enrichers, err := newEnrichChain(pf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid enricher setup: %v\n", err)
+		os.Exit(2)
+	}
+	var inputs []string
+	if *inPath != "" {
+		inputs = append(inputs, *inPath)
+	}
+	inputs = append(inputs, extraInputs...)
+	files, err := expandInputs(inputs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open input: %v\n", err)
+		os.Exit(1)
+	}
+
+	tracker, err := openStateTracker(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer tracker.Close()
+
+	// Messages no parser matched still carry airframe identities, so all of
+	// them are tracked.
+	opts := extractOptions{includeAll: true, blockTimeout: *blockTimeout, parsers: parsers, enrichers: enrichers}
+	st := &Stats{}
+	dispatch := inlineDispatcher(tracker.track, st)
+	buf := make([]byte, 0, 1024*1024)
+	if len(files) == 0 {
+		if err := processInput(os.Stdin, "", buf, dispatch, opts, true, st); err != nil {
+			fmt.Fprintf(os.Stderr, "Input read error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, path := range files {
+		r, err := openInput(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open input: %v\n", err)
+			os.Exit(1)
+		}
+		st.Files++
+		err = processInput(r, path, buf, dispatch, opts, false, st)
+		r.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Input read error: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+
+	tracker.writeSummary(os.Stdout, *list)
+	if *showStats {
+		fmt.Fprintf(os.Stderr, "stats: files=%d lines=%d messages=%d matched=%d parser_errors=%d\n",
+			st.Files, st.Lines, st.Emitted, st.Matched, st.ParserErrors)
+	}
+}
+
+// runState runs the state subcommands.
+func runState(args []string) {
+	if len(args) == 0 {
+		stateUsage()
+	}
+	switch args[0] {
+	case "dump":
+		runStateDump(args[1:])
+	case "legs":
+		runStateLegs(args[1:])
+	default:
+		stateUsage()
+	}
+}
+
+func stateUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: acars_parser state dump [-state-db FILE] [-output FILE] [-pretty]")
+	fmt.Fprintln(os.Stderr, "       acars_parser state legs -tail REG|ICAO [-state-db FILE] [-since T] [-until T] [-output FILE] [-pretty]")
+	os.Exit(2)
+}
+
+// openStateDB opens an existing state database.  NewTracker would create a
+// missing one, but reading from nothing is more likely a wrong path.
+func openStateDB(path string) *state.Tracker {
+	if _, err := os.Stat(path); err != nil {
+		fmt.Fprintf(os.Stderr, "state DB: %v\n", err)
+		os.Exit(1)
+	}
+	t, err := state.NewTracker(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "state DB %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	return t
+}
+
+func runStateDump(args []string) {
+	fs := flag.NewFlagSet("state dump", flag.ExitOnError)
+	dbPath := fs.String("state-db", defaultStateDB, "SQLite state database to read")
+	outPath := fs.String("output", "", "Output JSON file (default: stdout)")
+	pretty := fs.Bool("pretty", false, "Pretty-print JSON output")
+	_ = fs.Parse(args)
+
+	t := openStateDB(*dbPath)
+	defer t.Close()
+	d, err := t.Dump()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "state dump: %v\n", err)
+		os.Exit(1)
+	}
+	writeStateJSON(*outPath, d, *pretty)
+}
+
+func runStateLegs(args []string) {
+	fs := flag.NewFlagSet("state legs", flag.ExitOnError)
+	dbPath := fs.String("state-db", defaultStateDB, "SQLite state database to read")
+	tail := fs.String("tail", "", "Registration or ICAO address of the aircraft")
+	since := fs.String("since", "", "Only legs still going at or after this time (same formats as extract -since)")
+	until := fs.String("until", "", "Only legs begun before this time")
+	outPath := fs.String("output", "", "Output JSON file (default: stdout)")
+	pretty := fs.Bool("pretty", false, "Pretty-print JSON output")
+	_ = fs.Parse(args)
+
+	if *tail == "" {
+		fmt.Fprintln(os.Stderr, "state legs: -tail is required")
+		os.Exit(2)
+	}
+	from, to := parseTimeRange("state legs", *since, *until)
+
+	t := openStateDB(*dbPath)
+	defer t.Close()
+	legs, err := t.GetLegs(*tail, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "state legs: %v\n", err)
+		os.Exit(1)
+	}
+	if legs == nil {
+		legs = []*state.FlightLeg{}
+	}
+	writeStateJSON(*outPath, legs, *pretty)
+}
+
+// parseTimeRange parses the -since and -until flags of cmd; empty leaves
+// that end of the range open.
+func parseTimeRange(cmd, since, until string) (from, to time.Time) {
+	for _, v := range []struct {
+		name, value string
+		t           *time.Time
+	}{{"-since", since, &from}, {"-until", until, &to}} {
+		if v.value == "" {
+			continue
+		}
+		ts, ok := decode.ParseTime(v.value)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s: invalid %s time %q\n", cmd, v.name, v.value)
+			os.Exit(2)
+		}
+		*v.t = ts
+	}
+	return from, to
+}
+
+// writeStateJSON writes v as JSON to path, or stdout.
+func writeStateJSON(path string, v any, pretty bool) {
+	b, err := marshalJSON(v, pretty)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "JSON encode error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create output: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "Output write error: %v\n", err)
+		os.Exit(1)
+	}
+}
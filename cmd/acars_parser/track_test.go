@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+func TestStateTrackerSummary(t *testing.T) {
+	registry.Default().Sort()
+
+	tracker, err := openStateTracker(":memory:")
+	if err != nil {
+		t.Fatalf("openStateTracker: %v", err)
+	}
+	defer tracker.Close()
+
+	for _, out := range runJAERO(t, jaeroStreamSample, false) {
+		tracker.track(out)
+	}
+	tracker.track(ExtractOut{Message: &OutputMessage{
+		Tail:        "EI-DEO",
+		Flight:      "EIN123",
+		Departing:   "EIDW",
+		Destination: "EGLL",
+		Airframe:    &acars.Airframe{ICAO: "4CA7B5", Tail: "EI-DEO"},
+	}})
+
+	var b bytes.Buffer
+	tracker.writeSummary(&b, true)
+	got := b.String()
+	if !strings.HasPrefix(got, "state: new aircraft=4 routes=1 waypoints=0; :memory: holds aircraft=4 routes=1 waypoints=0\n") {
+		t.Fatalf("summary:\n%s", got)
+	}
+	for _, want := range []string{"aircraft 3C65AD   D-AIMM", "aircraft 4CA7B5   EI-DEO", "route    EIN123   EIDW-EGLL"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary lacks %q:\n%s", want, got)
+		}
+	}
+
+	// Known aircraft and routes are not new the second time.
+	tracker.aircraft, tracker.routes = nil, nil
+	for _, out := range runJAERO(t, jaeroStreamSample, false) {
+		tracker.track(out)
+	}
+	b.Reset()
+	tracker.writeSummary(&b, false)
+	if !strings.HasPrefix(b.String(), "state: new aircraft=0 routes=0 waypoints=0;") {
+		t.Errorf("second pass: %s", b.String())
+	}
+}
+
+func TestRecordSinkTracksWrittenRecords(t *testing.T) {
+	tracker, err := openStateTracker(":memory:")
+	if err != nil {
+		t.Fatalf("openStateTracker: %v", err)
+	}
+	defer tracker.Close()
+
+	var b bytes.Buffer
+	sink := newRecordSink(newJSONLWriter(&b), time.Minute)
+	sink.state = tracker
+	rec := func() ExtractOut {
+		return ExtractOut{Message: &OutputMessage{Timestamp: "2026-05-12T16:54:24Z", Tail: "EI-DEO", Label: "H1", Text: "X", Airframe: &acars.Airframe{ICAO: "4CA7B5", Tail: "EI-DEO"}}}
+	}
+	sink.emit(rec())
+	sink.emit(rec())
+	if len(tracker.aircraft) != 0 {
+		t.Fatalf("held record was tracked before it was written")
+	}
+	sink.flush()
+	if len(tracker.aircraft) != 1 || tracker.t.GetStats().TotalAircraft != 1 {
+		t.Errorf("tracked aircraft = %v", tracker.aircraft)
+	}
+}
+
+// TestPositionResultsReportPositions keeps the state tracker and the track
+// export from missing a parser: every result with a latitude field has to
+// report its position through registry.PositionReporter, or through
+// registry.StationPositionReporter when it is a ground station's.
+func TestPositionResultsReportPositions(t *testing.T) {
+	for _, p := range registry.Default().Parsers() {
+		r := registry.NewResult(p)
+		if r == nil {
+			continue
+		}
+		rt := reflect.TypeOf(r).Elem()
+		for i := 0; i < rt.NumField(); i++ {
+			if strings.Split(rt.Field(i).Tag.Get("json"), ",")[0] != "latitude" {
+				continue
+			}
+			_, aircraft := r.(registry.PositionReporter)
+			_, station := r.(registry.StationPositionReporter)
+			if aircraft == station {
+				t.Errorf("%s: %T has a latitude but is not exactly one of registry.PositionReporter and registry.StationPositionReporter", p.Name(), r)
+			}
+		}
+	}
+}
@@ -53,6 +53,10 @@ type Message struct {
 	Airframe *Airframe `json:"airframe,omitempty"`
 	Flight   *Flight   `json:"flight,omitempty"`
 	Station  *Station  `json:"station,omitempty"`
+
+	// EnrichedBy maps each field the enrich package filled in to the
+	// enricher that found it.
+	EnrichedBy map[string]string `json:"-"`
 }
 
 // Airframe contains aircraft identification data.
@@ -123,4 +123,33 @@ func VerifyArincBinaryRaw(textPrefix string, payload []byte) bool {
 
 	// Verify: CRC of entire buffer should equal GoodValue16Arinc.
 	return CRC16Arinc(buf, 0xFFFF) == GoodValue16Arinc
-}
\ No newline at end of file
+}
+
+// table32Arinc is the lookup table for the ARINC 665 CRC-32 (poly 0x04C11DB7,
+// MSB-first).
+var table32Arinc = func() (t [256]uint32) {
+	for i := range t {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+		t[i] = crc
+	}
+	return t
+}()
+
+// CRC32Arinc calculates the ARINC 665 CRC-32 (poly 0x04C11DB7, MSB-first, no
+// reflection).  Used for MIAM CORE version 1 PDUs.
+//
+// For calculation: checksum = CRC32Arinc(message, 0xFFFFFFFF) ^ 0xFFFFFFFF
+func CRC32Arinc(data []byte, init uint32) uint32 {
+	crc := init
+	for _, b := range data {
+		crc = crc<<8 ^ table32Arinc[byte(crc>>24)^b]
+	}
+	return crc
+}
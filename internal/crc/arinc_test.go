@@ -142,4 +142,10 @@ func TestCRC16ArincRoundTrip(t *testing.T) {
 	if Verify16Arinc([]byte(message), badChecksum) {
 		t.Error("Bad checksum should not verify")
 	}
-}
\ No newline at end of file
+}
+func TestCRC32Arinc(t *testing.T) {
+	// Standard check value of CRC-32/BZIP2, which uses the same parameters.
+	if got := CRC32Arinc([]byte("123456789"), 0xFFFFFFFF) ^ 0xFFFFFFFF; got != 0xFC891918 {
+		t.Errorf("CRC32Arinc(123456789) = %08X, want FC891918", got)
+	}
+}
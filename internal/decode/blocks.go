@@ -0,0 +1,299 @@
+package decode
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"acars_parser/internal/acars"
+)
+
+// DefaultBlockTimeout is how long a multi-block message may wait for its
+// next block.  The blocks of one downlink are normally sent back to back,
+// each after the previous one has been acknowledged, so a gap of more than a
+// couple of minutes means the rest was lost.
+const DefaultBlockTimeout = 2 * time.Minute
+
+// ReassemblyInfo is attached to a record whose text was joined from several
+// ACARS blocks.
+type ReassemblyInfo struct {
+	MsgNo    string   `json:"msgno"`
+	Blocks   int      `json:"blocks"`
+	Complete bool     `json:"complete"`
+	Missing  []string `json:"missing,omitempty"` // sequence letters of known gaps
+}
+
+// acarsBlock is the block framing of one ACARS message as reported by the
+// decoder.
+type acarsBlock struct {
+	msgNo    string // message number without the sequence letter, e.g. "D05"
+	seq      byte   // block sequence letter, 'A' for the first block
+	final    bool   // last block (ETX rather than ETB)
+	finalSet bool   // the decoder said whether more blocks follow
+}
+
+// multiBlock reports whether the block belongs to a message that spans more
+// than one block.  A first block without an end/more flag is taken to be a
+// complete message on its own.
+func (b acarsBlock) multiBlock() bool {
+	return b.seq != 'A' || (b.finalSet && !b.final)
+}
+
+// MayCarryBlock is a cheap check for the message number fields used by
+// acarsdec, dumpvdl2 and dumphfdl, so lines without them are not decoded
+// twice.
+func MayCarryBlock(line string) bool {
+	return strings.Contains(line, `"msgno"`) || strings.Contains(line, `"msg_num"`)
+}
+
+// decodeBlock extracts the block framing from a decoder JSON line:
+//
+//	acarsdec:           msgno "D05A" (sequence letter last), end
+//	dumpvdl2/dumphfdl:  msg_num "D05", msg_num_seq "A", more
+//
+// Lines that a decoder has already reassembled itself (libacars assstat)
+// report false.
+func decodeBlock(b []byte) (acarsBlock, bool) {
+	var root map[string]any
+	if err := json.Unmarshal(b, &root); err != nil {
+		return acarsBlock{}, false
+	}
+	for _, prefix := range []string{"", "message.", "vdl2.avlc.acars.", "hfdl.lpdu.hfnpdu.acars."} {
+		msgNo := strings.TrimSpace(FirstString(root, prefix+"msgno", prefix+"msg_num"))
+		if msgNo == "" {
+			continue
+		}
+		if FirstString(root, prefix+"assstat") != "" {
+			return acarsBlock{}, false
+		}
+		blk := acarsBlock{msgNo: strings.ToUpper(msgNo)}
+		if seq := strings.TrimSpace(FirstString(root, prefix+"msg_num_seq")); len(seq) == 1 {
+			blk.seq = strings.ToUpper(seq)[0]
+		} else if len(blk.msgNo) == 4 {
+			blk.seq = blk.msgNo[3]
+			blk.msgNo = blk.msgNo[:3]
+		}
+		if blk.seq < 'A' || blk.seq > 'Z' {
+			return acarsBlock{}, false
+		}
+		if v, ok := Lookup(root, prefix+"more"); ok {
+			if more, ok := v.(bool); ok {
+				blk.final, blk.finalSet = !more, true
+			}
+		} else if v, ok := Lookup(root, prefix+"end"); ok {
+			if end, ok := v.(bool); ok {
+				blk.final, blk.finalSet = end, true
+			}
+		}
+		return blk, true
+	}
+	return acarsBlock{}, false
+}
+
+// BlockDone receives a joined message once its set is complete, has timed
+// out or the input has ended.
+type BlockDone func(msg *acars.Message, info *ReassemblyInfo)
+
+type blockKey struct {
+	tail, label, msgNo string
+}
+
+type blockSet struct {
+	key     blockKey
+	blocks  map[byte]*acars.Message
+	last    byte // sequence letter of the final block, 0 until it is seen
+	lastTS  time.Time
+	arrived time.Time // wall-clock time of the most recent block
+	done    BlockDone
+}
+
+func (s *blockSet) complete() bool {
+	if s.last == 0 {
+		return false
+	}
+	for c := byte('A'); c <= s.last; c++ {
+		if s.blocks[c] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// join concatenates the block texts in sequence order.  The joined message
+// takes its metadata from the lowest block that arrived.
+func (s *blockSet) join() (*acars.Message, *ReassemblyInfo) {
+	seqs := make([]byte, 0, len(s.blocks))
+	for c := range s.blocks {
+		seqs = append(seqs, c)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	var text strings.Builder
+	for _, c := range seqs {
+		text.WriteString(s.blocks[c].Text)
+	}
+	msg := *s.blocks[seqs[0]]
+	msg.Text = text.String()
+
+	info := &ReassemblyInfo{MsgNo: s.key.msgNo, Blocks: len(seqs), Complete: s.complete()}
+	if !info.Complete {
+		top := seqs[len(seqs)-1]
+		if s.last > top {
+			top = s.last
+		}
+		for c := byte('A'); c < top; c++ {
+			if s.blocks[c] == nil {
+				info.Missing = append(info.Missing, string(c))
+			}
+		}
+	}
+	return &msg, info
+}
+
+// BlockAssembler joins the blocks of multi-block ACARS messages before they
+// are dispatched, so parsers see an FPN, loadsheet or PWI message whole.
+// Blocks are grouped by tail, label and message number and ordered by their
+// sequence letter.  A set is handed on as soon as every block up to the
+// final one has arrived; a set that receives no block for timeout, by
+// message time or by the wall clock (Tick), is handed on with what it has
+// and marked incomplete.
+//
+// Copies of a block, e.g. a retransmission or the same block heard by a
+// second receiver, are dropped, also for a while after their set has been
+// completed.
+type BlockAssembler struct {
+	timeout   time.Duration
+	open      map[blockKey]*blockSet
+	order     []*blockSet // open sets in arrival order
+	recent    map[blockKey]*blockSet
+	watermark time.Time
+	now       func() time.Time
+}
+
+// NewBlockAssembler returns a BlockAssembler that waits up to timeout for
+// the next block of a message.
+func NewBlockAssembler(timeout time.Duration) *BlockAssembler {
+	return &BlockAssembler{
+		timeout: timeout,
+		open:    make(map[blockKey]*blockSet),
+		recent:  make(map[blockKey]*blockSet),
+		now:     time.Now,
+	}
+}
+
+// TakeLine decodes line and, when it is one block of a multi-block message,
+// adds it to a and reports true.  Otherwise the decoded messages and their
+// kind, as JSON returns them, are returned for the caller to process as
+// usual.
+func (a *BlockAssembler) TakeLine(line string, done BlockDone) ([]*acars.Message, string, bool) {
+	b := []byte(line)
+	msgs, kind := JSON(b)
+	if len(msgs) != 1 {
+		return msgs, kind, false
+	}
+	blk, ok := decodeBlock(b)
+	if !ok || !blk.multiBlock() {
+		return msgs, kind, false
+	}
+	a.add(msgs[0], blk, done)
+	return nil, kind, true
+}
+
+// add files msg as block blk.  done is called for the set the first block
+// opened.
+func (a *BlockAssembler) add(msg *acars.Message, blk acarsBlock, done BlockDone) {
+	tail := msg.Tail
+	if tail == "" && msg.Airframe != nil {
+		tail = msg.Airframe.Tail
+	}
+	key := blockKey{
+		tail:  strings.ToUpper(strings.TrimLeft(strings.TrimSpace(tail), ".")),
+		label: strings.ToUpper(strings.TrimSpace(msg.Label)),
+		msgNo: blk.msgNo,
+	}
+	ts, hasTS := ParseTime(msg.Timestamp)
+	if hasTS && ts.After(a.watermark) {
+		a.watermark = ts
+	}
+
+	if s := a.recent[key]; s != nil && s.blocks[blk.seq] != nil && !a.expired(s, a.watermark) {
+		return
+	}
+	s := a.open[key]
+	if s != nil && hasTS && !s.lastTS.IsZero() && ts.Sub(s.lastTS) > a.timeout {
+		// Too late to belong to the open set: it is a new message that
+		// reuses the message number.
+		a.finish(s)
+		s = nil
+	}
+	if s == nil {
+		s = &blockSet{key: key, blocks: make(map[byte]*acars.Message), done: done}
+		a.open[key] = s
+		a.order = append(a.order, s)
+	}
+	if s.blocks[blk.seq] == nil {
+		s.blocks[blk.seq] = msg
+	}
+	if blk.finalSet && blk.final {
+		s.last = blk.seq
+	}
+	if hasTS {
+		s.lastTS = ts
+	}
+	s.arrived = a.now()
+
+	if s.complete() {
+		a.finish(s)
+	}
+	a.expire(func(s *blockSet) bool { return a.expired(s, a.watermark) })
+}
+
+func (a *BlockAssembler) expired(s *blockSet, now time.Time) bool {
+	return !s.lastTS.IsZero() && now.Sub(s.lastTS) > a.timeout
+}
+
+// Tick hands on sets that have not received a block for timeout by the wall
+// clock, so a quiet live feed does not hold them forever.
+func (a *BlockAssembler) Tick() {
+	now := a.now()
+	a.expire(func(s *blockSet) bool { return now.Sub(s.arrived) > a.timeout })
+}
+
+// Flush hands on every open set in arrival order.
+func (a *BlockAssembler) Flush() {
+	a.expire(func(*blockSet) bool { return true })
+	a.recent = make(map[blockKey]*blockSet)
+}
+
+func (a *BlockAssembler) expire(expired func(*blockSet) bool) {
+	for _, s := range append([]*blockSet(nil), a.order...) {
+		if expired(s) {
+			a.finish(s)
+		}
+	}
+	for key, s := range a.recent {
+		if a.expired(s, a.watermark) {
+			delete(a.recent, key)
+		}
+	}
+}
+
+// finish removes s from the open sets and hands its joined message on.
+func (a *BlockAssembler) finish(s *blockSet) {
+	if a.open[s.key] != s {
+		return
+	}
+	delete(a.open, s.key)
+	for i, o := range a.order {
+		if o == s {
+			a.order = append(a.order[:i], a.order[i+1:]...)
+			break
+		}
+	}
+	if s.complete() {
+		a.recent[s.key] = s
+	}
+	msg, info := s.join()
+	s.done(msg, info)
+}
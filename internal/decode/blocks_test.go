@@ -0,0 +1,56 @@
+package decode
+
+import (
+	"testing"
+	"time"
+
+	"acars_parser/internal/acars"
+)
+
+func TestDecodeBlock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want acarsBlock
+		ok   bool
+	}{
+		{`{"timestamp":0.5,"label":"H1","tail":".JY-BAJ","msgno":"D05B","text":"x","end":false}`, acarsBlock{msgNo: "D05", seq: 'B', finalSet: true}, true},
+		{`{"msgno":"d05a","text":"x"}`, acarsBlock{msgNo: "D05", seq: 'A'}, true},
+		{`{"vdl2":{"avlc":{"acars":{"msg_num":"D05","msg_num_seq":"C","more":false}}}}`, acarsBlock{msgNo: "D05", seq: 'C', final: true, finalSet: true}, true},
+		{`{"hfdl":{"lpdu":{"hfnpdu":{"acars":{"msg_num":"D05","msg_num_seq":"A","more":true}}}}}`, acarsBlock{msgNo: "D05", seq: 'A', finalSet: true}, true},
+		// Already reassembled by libacars.
+		{`{"vdl2":{"avlc":{"acars":{"msg_num":"D05","msg_num_seq":"A","more":false,"assstat":"complete"}}}}`, acarsBlock{}, false},
+		{`{"msgno":"D05","text":"x"}`, acarsBlock{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := decodeBlock([]byte(tt.in))
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("decodeBlock(%s) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBlockAssemblerTimeout(t *testing.T) {
+	a := NewBlockAssembler(time.Minute)
+	now := time.Date(2026, 5, 12, 17, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	var got []*ReassemblyInfo
+	done := func(_ *acars.Message, info *ReassemblyInfo) { got = append(got, info) }
+	block := func(tail, ts string) *acars.Message {
+		return &acars.Message{Tail: tail, Label: "H1", Timestamp: ts, Text: "x"}
+	}
+
+	a.add(block("A6-EDA", "2026-05-12T16:55:00Z"), acarsBlock{msgNo: "D01", seq: 'A', finalSet: true}, done)
+	// Message time passing the timeout closes the first set.
+	a.add(block("D-AIMM", "2026-05-12T16:56:30Z"), acarsBlock{msgNo: "M02", seq: 'A', finalSet: true}, done)
+	if len(got) != 1 || got[0].MsgNo != "D01" || got[0].Complete {
+		t.Fatalf("after watermark: %+v", got)
+	}
+
+	// The wall clock closes a set on a quiet feed.
+	now = now.Add(2 * time.Minute)
+	a.Tick()
+	if len(got) != 2 || got[1].MsgNo != "M02" {
+		t.Fatalf("after tick: %+v", got)
+	}
+}
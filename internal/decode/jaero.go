@@ -0,0 +1,293 @@
+package decode
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"acars_parser/internal/acars"
+)
+
+var (
+	// jaeroFlightRe matches "FLIGHT <callsign>" at the end of a JAERO C-Band
+	// header line (case-insensitive).  The C-Band format appends the flight
+	// number to the aircraft description, unlike the original JAERO format
+	// which does not include flight numbers in the header.
+	// (?:^|\s+) allows the word FLIGHT to appear at the very start of the
+	// description (when there is no aircraft model text before it).
+	jaeroFlightRe = regexp.MustCompile(`(?i)(?:^|\s+)FLIGHT\s+([A-Z][A-Z0-9]*)\s*$`)
+)
+
+// ParseJAEROBlock builds the message of one JAERO block: a header line and
+// the body lines up to the next header.  It returns nil when the header is
+// malformed or the block has no payload.
+func ParseJAEROBlock(header string, body []string) *acars.Message {
+	timestamp, tail, label, flight, airframe, ok := ParseJAEROHeader(header)
+	if !ok {
+		return nil
+	}
+
+	text := JAEROPayload(body)
+	if text == "" {
+		return nil
+	}
+
+	msg := &acars.Message{
+		Source:    "jaero",
+		Timestamp: timestamp,
+		Tail:      tail,
+		Text:      text,
+		Label:     label,
+		Airframe:  airframe,
+	}
+	if flight != "" {
+		msg.Flight = &acars.Flight{Flight: flight}
+	}
+	return msg
+}
+
+// JAEROMIAMBlock scans the body lines of a JAERO message block for the
+// MIAM-decoded section that JAERO/libacars appends after the raw payload line.
+// The decoded section begins with a tab-indented "MIAM:" line.  All lines from
+// that point are collected, the single leading tab is stripped from each, and
+// the result is returned as a trimmed string.  An empty string is returned when
+// no MIAM block is found.
+func JAEROMIAMBlock(body []string) string {
+	miamStart := -1
+	for i, rawLine := range body {
+		if strings.TrimSpace(rawLine) == "MIAM:" {
+			miamStart = i
+			break
+		}
+	}
+	if miamStart < 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for i, rawLine := range body[miamStart:] {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		// Strip exactly one leading tab: JAERO indents the whole decoded block
+		// by one tab relative to the rest of the message body.
+		if len(rawLine) > 0 && rawLine[0] == '\t' {
+			sb.WriteString(rawLine[1:])
+		} else {
+			sb.WriteString(rawLine)
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n\t ")
+}
+
+// ParseJAEROHeader parses a single JAERO header line.  It returns
+// (timestamp, tail, label, flight, airframe, ok).  The flight field is
+// populated when the C-Band format includes a "FLIGHT <callsign>" suffix in
+// the aircraft description; it is empty for the original JAERO format.
+func ParseJAEROHeader(header string) (string, string, string, string, *acars.Airframe, bool) {
+	fields := strings.Fields(strings.TrimSpace(header))
+	if len(fields) < 8 || !IsJAEROHeader(header) {
+		return "", "", "", "", nil, false
+	}
+
+	parsedTime, err := time.Parse("15:04:05 02-01-06 MST", fields[0]+" "+fields[1]+" "+fields[2])
+	if err != nil {
+		return "", "", "", "", nil, false
+	}
+
+	bangIdx := strings.Index(header, " ! ")
+	if bangIdx < 0 {
+		return "", "", "", "", nil, false
+	}
+
+	leftFields := strings.Fields(strings.TrimSpace(header[:bangIdx]))
+	if len(leftFields) == 0 {
+		return "", "", "", "", nil, false
+	}
+	tail := strings.TrimSpace(leftFields[len(leftFields)-1])
+	if tail == "" {
+		return "", "", "", "", nil, false
+	}
+
+	aes := ""
+	for _, field := range leftFields {
+		if strings.HasPrefix(field, "AES:") {
+			aes = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(field, "AES:")))
+			break
+		}
+	}
+
+	rightFields := strings.Fields(strings.TrimSpace(header[bangIdx+3:]))
+	if len(rightFields) < 2 {
+		return "", "", "", "", nil, false
+	}
+	label := strings.TrimSpace(rightFields[0])
+	if label == "" {
+		return "", "", "", "", nil, false
+	}
+
+	airframeDescription := ""
+	if len(rightFields) > 2 {
+		airframeDescription = strings.TrimSpace(strings.Join(rightFields[2:], " "))
+	}
+
+	// Extract the flight number from the C-Band "FLIGHT <callsign>" suffix.
+	flight := ""
+	if match := jaeroFlightRe.FindStringSubmatch(airframeDescription); len(match) == 2 {
+		flight = strings.ToUpper(strings.TrimSpace(match[1]))
+		// Strip " FLIGHT <callsign>" from the aircraft description.
+		airframeDescription = strings.TrimSpace(airframeDescription[:len(airframeDescription)-len(match[0])])
+	}
+
+	var airframe *acars.Airframe
+	if aes != "" || airframeDescription != "" || tail != "" {
+		airframe = &acars.Airframe{
+			Tail:              tail,
+			ICAO:              aes,
+			ManufacturerModel: airframeDescription,
+		}
+	}
+
+	return parsedTime.UTC().Format(time.RFC3339), tail, label, flight, airframe, true
+}
+
+// JAEROPayload returns the ACARS text of a JAERO block body, without the
+// decoder's commentary and with wrapped lines joined.
+func JAEROPayload(body []string) string {
+	payloadLines := make([]string, 0, len(body))
+	started := false
+
+	for _, rawLine := range body {
+		trimmed := strings.TrimSpace(rawLine)
+		if !started {
+			if trimmed == "" {
+				continue
+			}
+			if trimmed == "-" {
+				return ""
+			}
+			if looksLikeJAERODecoderCommentaryLine(trimmed) {
+				continue
+			}
+			started = true
+		}
+
+		if trimmed == "" {
+			break
+		}
+		if !looksLikeJAEROPayloadLine(trimmed) {
+			break
+		}
+
+		payloadLines = append(payloadLines, trimmed)
+	}
+
+	if len(payloadLines) == 0 {
+		return ""
+	}
+
+	joined := joinJAEROPayloadLines(payloadLines)
+	return normaliseJAEROPayload(joined)
+}
+
+func looksLikeJAEROPayloadLine(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" || line == "-" {
+		return false
+	}
+	if IsJAEROHeader(line) || looksLikeJAERODecoderCommentaryLine(line) {
+		return false
+	}
+	return true
+}
+
+func looksLikeJAERODecoderCommentaryLine(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+
+	commentaryPrefixes := []string{
+		"FANS-1/A ",
+		"CPDLC ",
+		"CPDLC Uplink Message:",
+		"CPDLC Downlink Message:",
+		"ADS-C message:",
+		"Header:",
+		"Message data:",
+		"Msg ID:",
+		"Timestamp:",
+		"Facility designation:",
+		"Flight level:",
+		"Fix:",
+		"Position:",
+		"ATC CLEARANCE",
+		"REQUEST POSITION REPORT",
+	}
+	for _, prefix := range commentaryPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinJAEROPayloadLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(lines[0])
+	for i := 1; i < len(lines); i++ {
+		current := strings.TrimSpace(lines[i])
+		previous := strings.TrimSpace(lines[i-1])
+		if shouldJoinJAEROPayloadInline(previous, current) {
+			builder.WriteString(current)
+			continue
+		}
+		builder.WriteByte('\n')
+		builder.WriteString(current)
+	}
+
+	return builder.String()
+}
+
+func shouldJoinJAEROPayloadInline(previous string, current string) bool {
+	if current == "" {
+		return false
+	}
+	if strings.HasPrefix(current, "/") || strings.HasPrefix(current, "#") || strings.HasPrefix(current, "- #") {
+		return true
+	}
+	return !strings.ContainsAny(previous, " \t") && !strings.ContainsAny(current, " \t")
+}
+
+func normaliseJAEROPayload(payload string) string {
+	payload = strings.TrimSpace(payload)
+	payload = strings.ReplaceAll(payload, "- #MD", "")
+	payload = strings.ReplaceAll(payload, "- #M1", "")
+	payload = strings.TrimPrefix(payload, "- ")
+	return strings.TrimSpace(payload)
+}
+
+// IsJAEROHeader reports whether line is the header line of a JAERO block,
+// such as "17:08:39 12-05-26 UTC AES:39CF09 GES:90 2 .F-HTYJ ! B6 5 ...".
+func IsJAEROHeader(line string) bool {
+	fields := strings.Fields(strings.TrimSpace(line))
+	if len(fields) < 8 {
+		return false
+	}
+	if fields[2] != "UTC" {
+		return false
+	}
+	if !strings.HasPrefix(fields[3], "AES:") || !strings.HasPrefix(fields[4], "GES:") {
+		return false
+	}
+	if _, err := time.Parse("15:04:05 02-01-06 MST", fields[0]+" "+fields[1]+" "+fields[2]); err != nil {
+		return false
+	}
+	return strings.Contains(line, " ! ")
+}
@@ -0,0 +1,186 @@
+package decode
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// jaeroAESRe extracts the ICAO hex address from the AES: field of a JAERO
+	// header line.  The address is used as the per-aircraft key when tracking
+	// MIAM multi-segment reassembly state.
+	jaeroAESRe = regexp.MustCompile(`\bAES:([0-9A-Fa-f]+)\b`)
+
+	// miamFirstSegRe matches the beginning-of-transfer marker in a compressed
+	// MIAM payload.  JAERO/libacars uses this prefix for single-transfer and
+	// first-segment PDUs (e.g. "T22!", "T32!", "T-2!").  Continuation segments
+	// do not start with this pattern.
+	miamFirstSegRe = regexp.MustCompile(`^T[-\d]+!`)
+)
+
+// miamReassemblyWindow is the maximum time gap permitted between consecutive
+// segments of a MIAM multi-packet transfer.  Continuation packets arriving
+// outside this window are treated as orphans (unrelated transfers).
+// Real-world logs show gaps of up to ~10 minutes between a first segment and
+// subsequent continuation frames, so 15 minutes is used as a generous bound.
+const miamReassemblyWindow = 15 * time.Minute
+
+// JAEROBlock is one block of a JAERO log: a header line and the body lines
+// up to the next header.
+type JAEROBlock struct {
+	Header string
+	Body   []string
+	Line   int // input line of the header, 0 when not known
+
+	ts    time.Time
+	valid bool // false when the timestamp field cannot be parsed
+}
+
+// NewJAEROBlock returns the block of header and body, with the time its
+// header gives.
+func NewJAEROBlock(header string, body []string, line int) JAEROBlock {
+	blk := JAEROBlock{Header: header, Body: body, Line: line}
+	if fields := strings.Fields(header); len(fields) >= 3 {
+		if t, err := time.Parse("15:04:05 02-01-06 MST", fields[0]+" "+fields[1]+" "+fields[2]); err == nil {
+			blk.ts = t.UTC()
+			blk.valid = true
+		}
+	}
+	return blk
+}
+
+// Time returns the time of the block's header, ok false when it cannot be
+// parsed.
+func (b JAEROBlock) Time() (t time.Time, ok bool) {
+	return b.ts, b.valid
+}
+
+// miamAssembly is an in-progress MIAM multi-segment transfer for one
+// aircraft.
+type miamAssembly struct {
+	first  JAEROBlock
+	lastTS time.Time // timestamp of the most recently appended segment
+	conts  []string  // continuation payloads in chronological order
+}
+
+// JAEROAssembler hands on JAERO blocks, tracking per-ICAO MIAM assembly state
+// for multi-segment transfers.
+//
+// The MIAM protocol can split a large compressed payload across multiple
+// consecutive ACARS frames.  The first frame starts with a "T<n>!" marker
+// (miamFirstSegRe); subsequent frames do not.  JAERO/libacars outputs a
+// decoded MIAM block only when it can fully reassemble the transfer.  When it
+// cannot (e.g. the file starts mid-transfer), those frames appear with no
+// decoded block.
+//
+// Strategy:
+//
+//	T<n>! frame + decoded MIAM block → single transfer, emit immediately.
+//	T<n>! frame + no decoded block   → first segment of a multi-packet
+//	                                    transfer; start a per-ICAO
+//	                                    assembly.
+//	Non-T frame + no decoded block   → continuation segment: append to
+//	                                    the active assembly for this ICAO
+//	                                    if within miamReassemblyWindow,
+//	                                    otherwise treat as an orphan.
+type JAEROAssembler struct {
+	emit  func(blk JAEROBlock, continuations []string)
+	state map[string]*miamAssembly
+}
+
+// NewJAEROAssembler returns a JAEROAssembler that hands every block to emit,
+// a first MIAM segment with the payloads of the continuation segments
+// joined to it.
+func NewJAEROAssembler(emit func(blk JAEROBlock, continuations []string)) *JAEROAssembler {
+	return &JAEROAssembler{emit: emit, state: make(map[string]*miamAssembly)}
+}
+
+func (a *JAEROAssembler) flush(icao string) {
+	asm, ok := a.state[icao]
+	if !ok {
+		return
+	}
+	delete(a.state, icao)
+	a.emit(asm.first, asm.conts)
+}
+
+// Flush emits every assembly that is still open, in first-segment order.
+func (a *JAEROAssembler) Flush() {
+	icaos := make([]string, 0, len(a.state))
+	for icao := range a.state {
+		icaos = append(icaos, icao)
+	}
+	sort.Slice(icaos, func(i, j int) bool {
+		return a.state[icaos[i]].first.ts.Before(a.state[icaos[j]].first.ts)
+	})
+	for _, icao := range icaos {
+		a.flush(icao)
+	}
+}
+
+// Expire flushes every assembly whose last segment is further than
+// miamReassemblyWindow behind blk.  It is only needed when blocks are
+// processed as they arrive: no later continuation could still join such an
+// assembly, so holding it open would only delay its output.
+func (a *JAEROAssembler) Expire(blk JAEROBlock) {
+	if !blk.valid {
+		return
+	}
+	for icao, asm := range a.state {
+		if blk.ts.Sub(asm.lastTS) > miamReassemblyWindow {
+			a.flush(icao)
+		}
+	}
+}
+
+// Add takes the next block in time order.
+func (a *JAEROAssembler) Add(blk JAEROBlock) {
+	// The MIAM reassembly only applies to MA-label blocks.  A quick
+	// substring check on the header avoids a full parse for every block.
+	if !strings.Contains(blk.Header, " ! MA ") {
+		a.emit(blk, nil)
+		return
+	}
+
+	// Extract the ICAO hex address (AES field) for per-aircraft tracking.
+	icao := ""
+	if m := jaeroAESRe.FindStringSubmatch(blk.Header); len(m) == 2 {
+		icao = m[1]
+	}
+
+	payload := JAEROPayload(blk.Body)
+	hasMIAMBlock := JAEROMIAMBlock(blk.Body) != ""
+	isFirstSeg := miamFirstSegRe.MatchString(payload) && !hasMIAMBlock
+
+	switch {
+	case hasMIAMBlock:
+		// Fully decoded by JAERO (single transfer or last segment).
+		// Close any active assembly for this aircraft and emit normally.
+		a.flush(icao)
+		a.emit(blk, nil)
+
+	case isFirstSeg:
+		// Start a new multi-segment assembly.  Any previous assembly for
+		// this ICAO is flushed first (it timed out or was interrupted).
+		a.flush(icao)
+		a.state[icao] = &miamAssembly{first: blk, lastTS: blk.ts}
+
+	default:
+		// Continuation or orphan (no T<n>! prefix, no decoded block).
+		if asm, ok := a.state[icao]; ok && blk.valid && blk.ts.Sub(asm.lastTS) <= miamReassemblyWindow {
+			// Within the window: append this payload to the active assembly.
+			asm.conts = append(asm.conts, payload)
+			asm.lastTS = blk.ts
+			return
+		}
+		// Orphan: either no active assembly for this ICAO, the time
+		// window has expired, or the timestamp is unparseable.
+		if asm, ok := a.state[icao]; ok && blk.valid && blk.ts.After(asm.lastTS) {
+			// Assembly window has expired; flush the stale assembly.
+			a.flush(icao)
+		}
+		a.emit(blk, nil)
+	}
+}
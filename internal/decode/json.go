@@ -0,0 +1,564 @@
+// Package decode turns the input formats acars_parser reads into
+// acars.Messages: flat acarsdec JSON, the NATS wrapper, nested dumpvdl2 and
+// dumphfdl JSON, and JAERO text logs.
+package decode
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/airlines"
+	miampkg "acars_parser/internal/parsers/miam"
+)
+
+// JSON decodes one JSON line in any of the supported layouts.  It returns
+// the messages found, several for a dumpvdl2 or dumphfdl frame that carries a
+// MIAM message or an ATN payload, and the layout: "nats", "flat" or
+// "nested".  It returns nil and "" when the line has no ACARS message.
+func JSON(b []byte) ([]*acars.Message, string) {
+	// 1) NATS wrapper
+	var w acars.NATSWrapper
+	if err := json.Unmarshal(b, &w); err == nil && w.Message != nil {
+		if msg := w.ToMessage(); msg != nil && (msg.Label != "" || msg.Text != "") {
+			NormaliseFlight(msg)
+			return []*acars.Message{msg}, "nats"
+		}
+	}
+
+	// 2) Flat message (only accept if it actually contains label/text)
+	var m acars.Message
+	if err := json.Unmarshal(b, &m); err == nil {
+		var flatRoot map[string]any
+		if err := json.Unmarshal(b, &flatRoot); err == nil {
+			if nestedMsgs := buildMessagesFromNested(flatRoot); len(nestedMsgs) > 0 {
+				return nestedMsgs, "nested"
+			}
+			enrichMessageFlight(&m, flatRoot)
+		}
+		if strings.TrimSpace(m.Label) != "" || strings.TrimSpace(m.Text) != "" {
+			NormaliseFlight(&m)
+			return []*acars.Message{&m}, "flat"
+		}
+	}
+
+	// 3) Nested formats (dumpvdl2/dumphfdl, etc.)
+	var anyObj any
+	if err := json.Unmarshal(b, &anyObj); err != nil {
+		return nil, ""
+	}
+	msgs := buildMessagesFromNested(anyObj)
+	if len(msgs) > 0 {
+		return msgs, "nested"
+	}
+	return nil, ""
+}
+
+// buildMessagesFromNested tries common paths used by dumpvdl2 / dumphfdl logs.
+// It returns multiple messages if MIAM decoded content is present (both outer and inner).
+func buildMessagesFromNested(obj any) []*acars.Message {
+	root, ok := obj.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var msgs []*acars.Message
+
+	// First, try to extract outer ACARS message (e.g., MA with compressed text)
+	outerLabel := FirstString(root,
+		"label",
+		"message.label",
+		"acars.label",
+		"vdl2.avlc.acars.label",
+		"vdl2.avlc.acars.lbl",
+		"hfdl.lpdu.hfnpdu.acars.label",
+		"hfdl.lpdu.hfnpdu.acars.acars_label",
+	)
+
+	outerText := FirstString(root,
+		"text",
+		"message.text",
+		"msg_text",
+		"message.msg_text",
+		"acars.text",
+		"acars.message.text",
+		"vdl2.avlc.acars.text",
+		"vdl2.avlc.acars.msg_text",
+		"vdl2.avlc.acars.message.text",
+		"hfdl.lpdu.hfnpdu.acars.text",
+		"hfdl.lpdu.hfnpdu.acars.msg_text",
+	)
+
+	// Check if MIAM decoded content exists
+	miamLabel := FirstString(root,
+		"vdl2.avlc.acars.miam.single_transfer.miam_core.data.acars.label",
+		"hfdl.lpdu.hfnpdu.acars.miam.single_transfer.miam_core.data.acars.label",
+	)
+
+	miamText := FirstString(root,
+		"vdl2.avlc.acars.miam.single_transfer.miam_core.data.acars.message.text",
+		"hfdl.lpdu.hfnpdu.acars.miam.single_transfer.miam_core.data.acars.message.text",
+	)
+
+	// If we have both outer and MIAM content, create both messages
+	if strings.TrimSpace(outerLabel) != "" || strings.TrimSpace(outerText) != "" {
+		meta := extractNestedMessageMetadata(root)
+
+		// Create outer message (e.g., MA with compressed text)
+		outerMsg := &acars.Message{
+			Label:     outerLabel,
+			Text:      outerText,
+			Tail:      meta.tail,
+			Timestamp: meta.timestamp,
+			Frequency: meta.frequency,
+			Source:    meta.source,
+			Airframe:  meta.airframe,
+			Station:   meta.station,
+			Flight:    extractFlight(root),
+		}
+		NormaliseFlight(outerMsg)
+		msgs = append(msgs, outerMsg)
+
+		// If MIAM decoded content exists, create second message with decoded content
+		// Use label "MB" for MIAM decoded messages to distinguish from outer "MA" message
+		// Skip it when the outer frame decodes natively: its results then
+		// already include those for the inner message.
+		if (strings.TrimSpace(miamLabel) != "" || strings.TrimSpace(miamText) != "") && !decodesNatively(outerText) {
+			miamMsg := &acars.Message{
+				Label:     "MB",
+				Text:      miamText,
+				Tail:      meta.tail,
+				Timestamp: meta.timestamp,
+				Frequency: meta.frequency,
+				Source:    meta.source,
+				Airframe:  meta.airframe,
+				Station:   meta.station,
+				Flight:    extractFlight(root),
+			}
+			NormaliseFlight(miamMsg)
+			msgs = append(msgs, miamMsg)
+		}
+	}
+
+	if syntheticATNCM := buildSyntheticATNCMMessage(root); syntheticATNCM != nil {
+		msgs = append(msgs, syntheticATNCM)
+	}
+	if syntheticCPDLC := buildSyntheticATNCPDLCMessage(root); syntheticCPDLC != nil {
+		msgs = append(msgs, syntheticCPDLC)
+	}
+
+	if len(msgs) == 0 {
+		if synthetic := buildSyntheticHFDLDataMessage(root); synthetic != nil {
+			msgs = append(msgs, synthetic)
+		}
+	}
+
+	return msgs
+}
+
+// decodesNatively reports whether text is a MIAM frame that miampkg decodes
+// intact, CRC included.
+func decodesNatively(text string) bool {
+	r, err := miampkg.DecodeFrame(text)
+	return err == nil && r.Intact()
+}
+
+func buildSyntheticATNCMMessage(root map[string]any) *acars.Message {
+	departingAirport := strings.TrimSpace(FirstString(root,
+		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.departure_airport",
+	))
+	destinationAirport := strings.TrimSpace(FirstString(root,
+		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.destination_airport",
+	))
+	if departingAirport == "" || destinationAirport == "" {
+		return nil
+	}
+
+	flight := extractFlight(root)
+	if flight == nil {
+		return nil
+	}
+
+	meta := extractNestedMessageMetadata(root)
+	textParts := []string{"ATN CM LOGON"}
+	if flightID := strings.TrimSpace(flight.ID); flightID != "" {
+		textParts = append(textParts, flightID)
+	}
+	textParts = append(textParts, strings.ToUpper(departingAirport)+"-"+strings.ToUpper(destinationAirport))
+
+	msg := &acars.Message{
+		Label:     "ATNCM",
+		Text:      strings.Join(textParts, " "),
+		Tail:      meta.tail,
+		Timestamp: meta.timestamp,
+		Frequency: meta.frequency,
+		Source:    meta.source,
+		Airframe:  meta.airframe,
+		Station:   meta.station,
+		Flight:    flight,
+	}
+	NormaliseFlight(msg)
+	return msg
+}
+
+// buildSyntheticATNCPDLCMessage wraps the undecoded user data of an X.227
+// APDU, given as a hex string or an array of octets in its "data" field, in
+// an ATNCPDLC message for the ATN B1 CPDLC parser.  The direction comes from
+// the AVLC source address type.  The path follows the one the ATN CM logon
+// fields are read from; it has not been checked against captured dumpvdl2
+// output of a CPDLC frame.
+func buildSyntheticATNCPDLCMessage(root map[string]any) *acars.Message {
+	const apduPath = "vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu"
+	if _, ok := Lookup(root, apduPath+".context_mgmt"); ok {
+		return nil
+	}
+	v, ok := Lookup(root, apduPath+".data")
+	if !ok {
+		return nil
+	}
+	payload := octetStringHex(v)
+	if payload == "" {
+		return nil
+	}
+
+	var direction string
+	switch strings.ToLower(FirstString(root, "vdl2.avlc.src.type")) {
+	case "aircraft":
+		direction = "DOWNLINK"
+	case "ground station":
+		direction = "UPLINK"
+	default:
+		return nil
+	}
+
+	meta := extractNestedMessageMetadata(root)
+	msg := &acars.Message{
+		Label:     "ATNCPDLC",
+		Text:      "ATN CPDLC " + direction + " " + payload,
+		Tail:      meta.tail,
+		Timestamp: meta.timestamp,
+		Frequency: meta.frequency,
+		Source:    meta.source,
+		Airframe:  meta.airframe,
+		Station:   meta.station,
+		Flight:    extractFlight(root),
+	}
+	NormaliseFlight(msg)
+	return msg
+}
+
+// octetStringHex returns v, a hex string or a JSON array of octets, as upper
+// case hex, or "" when it is neither.
+func octetStringHex(v any) string {
+	switch t := v.(type) {
+	case string:
+		h := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(t), " ", ""))
+		if _, err := hex.DecodeString(h); err != nil {
+			return ""
+		}
+		return h
+	case []any:
+		b := make([]byte, 0, len(t))
+		for _, e := range t {
+			n, ok := e.(float64)
+			if !ok || n < 0 || n > 255 || n != float64(int(n)) {
+				return ""
+			}
+			b = append(b, byte(n))
+		}
+		return strings.ToUpper(hex.EncodeToString(b))
+	}
+	return ""
+}
+
+type nestedMessageMeta struct {
+	tail      string
+	timestamp string
+	frequency float64
+	source    string
+	airframe  *acars.Airframe
+	station   *acars.Station
+}
+
+func extractNestedMessageMetadata(root map[string]any) nestedMessageMeta {
+	tail := FirstString(root,
+		"tail",
+		"airframe.tail",
+		"vdl2.avlc.acars.reg",
+		"vdl2.avlc.acars.tail",
+		"hfdl.lpdu.hfnpdu.acars.reg",
+	)
+
+	ts := FirstString(root,
+		"timestamp",
+		"message.timestamp",
+	)
+	if ts == "" {
+		sec := firstInt64(root,
+			"vdl2.t.sec",
+			"hfdl.t.sec",
+			"t.sec",
+		)
+		usec := firstInt64(root,
+			"vdl2.t.usec",
+			"hfdl.t.usec",
+			"t.usec",
+		)
+		if sec > 0 {
+			t := time.Unix(sec, usec*1000).UTC()
+			ts = t.Format(time.RFC3339Nano)
+		}
+	}
+
+	freq := firstFloat64(root,
+		"frequency",
+		"message.frequency",
+		"vdl2.freq",
+		"hfdl.freq",
+	)
+	if freq > 1_000_000 {
+		freq = freq / 1_000_000.0
+	}
+
+	src := FirstString(root,
+		"source",
+		"vdl2.app.name",
+		"hfdl.app.name",
+		"app.name",
+	)
+
+	icao := FirstString(root,
+		"airframe.icao",
+		"hfdl.lpdu.ac_info.icao",
+	)
+
+	// Receiver identity, as set with acarsdec -i / dumpvdl2 and dumphfdl
+	// --station-id.
+	var station *acars.Station
+	if id := strings.TrimSpace(FirstString(root,
+		"station.ident",
+		"station_id",
+		"vdl2.station",
+		"hfdl.station",
+	)); id != "" {
+		station = &acars.Station{Ident: id}
+	}
+
+	var airframe *acars.Airframe
+	if strings.TrimSpace(tail) != "" || strings.TrimSpace(icao) != "" {
+		airframe = &acars.Airframe{
+			Tail: strings.TrimSpace(tail),
+			ICAO: strings.ToUpper(strings.TrimSpace(icao)),
+		}
+	}
+
+	return nestedMessageMeta{
+		tail:      tail,
+		timestamp: ts,
+		frequency: freq,
+		source:    src,
+		airframe:  airframe,
+		station:   station,
+	}
+}
+
+func buildSyntheticHFDLDataMessage(root map[string]any) *acars.Message {
+	hfnpduType := strings.TrimSpace(FirstString(root, "hfdl.lpdu.hfnpdu.type.name"))
+	if hfnpduType == "" || !strings.HasSuffix(strings.ToLower(hfnpduType), "data") {
+		return nil
+	}
+
+	flight := extractFlight(root)
+	if flight == nil || strings.TrimSpace(flight.ID) == "" {
+		return nil
+	}
+	if flight.Latitude == 0 && flight.Longitude == 0 {
+		return nil
+	}
+
+	meta := extractNestedMessageMetadata(root)
+	text := fmt.Sprintf("HFDL %s", hfnpduType)
+	if gsID := FirstString(root, "hfdl.lpdu.dst.id"); strings.TrimSpace(gsID) != "" {
+		text = fmt.Sprintf("HFDL %s GS %s", hfnpduType, strings.TrimSpace(gsID))
+	}
+
+	msg := &acars.Message{
+		Label:     "HFDL",
+		Text:      text,
+		Tail:      meta.tail,
+		Timestamp: meta.timestamp,
+		Frequency: meta.frequency,
+		Source:    meta.source,
+		Airframe:  meta.airframe,
+		Station:   meta.station,
+		Flight:    flight,
+	}
+	NormaliseFlight(msg)
+	return msg
+}
+
+func enrichMessageFlight(msg *acars.Message, root map[string]any) {
+	if msg == nil || root == nil {
+		return
+	}
+
+	if flight := extractFlight(root); flight != nil {
+		msg.Flight = flight
+	}
+}
+
+func extractFlight(root map[string]any) *acars.Flight {
+	flightNumber := FirstString(root,
+		"flight",
+		"message.flight",
+		"acars.flight",
+		"vdl2.avlc.acars.flight",
+		"hfdl.lpdu.hfnpdu.acars.flight",
+	)
+
+	flightID := FirstString(root,
+		"flight_id",
+		"message.flight_id",
+		"hfdl.lpdu.hfnpdu.flight_id",
+		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.flight_id",
+	)
+
+	departureAirport := FirstString(root,
+		"departure_airport",
+		"message.departure_airport",
+		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.departure_airport",
+	)
+
+	destinationAirport := FirstString(root,
+		"destination_airport",
+		"message.destination_airport",
+		"vdl2.avlc.x25.clnp.cotp.x225_spdu.x227_apdu.context_mgmt.cm_aircraft_message.data.atn_context_mgmt_logon_request.destination_airport",
+	)
+
+	latitude := firstFloat64(root,
+		"latitude",
+		"message.latitude",
+		"hfdl.lpdu.hfnpdu.pos.lat",
+	)
+	longitude := firstFloat64(root,
+		"longitude",
+		"message.longitude",
+		"hfdl.lpdu.hfnpdu.pos.lon",
+	)
+
+	if strings.TrimSpace(flightNumber) == "" && strings.TrimSpace(flightID) == "" &&
+		strings.TrimSpace(departureAirport) == "" && strings.TrimSpace(destinationAirport) == "" &&
+		latitude == 0 && longitude == 0 {
+		return nil
+	}
+
+	return &acars.Flight{
+		ID:                 strings.TrimSpace(flightID),
+		Flight:             airlines.TranslateFlight(strings.TrimSpace(flightNumber)),
+		DepartingAirport:   strings.TrimSpace(departureAirport),
+		DestinationAirport: strings.TrimSpace(destinationAirport),
+		Latitude:           latitude,
+		Longitude:          longitude,
+	}
+}
+
+// NormaliseFlight trims the flight fields of msg and translates an IATA
+// flight number to ICAO.
+func NormaliseFlight(msg *acars.Message) {
+	if msg == nil || msg.Flight == nil {
+		return
+	}
+
+	msg.Flight = &acars.Flight{
+		ID:                 strings.TrimSpace(msg.Flight.ID),
+		Flight:             airlines.TranslateFlight(strings.TrimSpace(msg.Flight.Flight)),
+		Status:             msg.Flight.Status,
+		DepartingAirport:   strings.TrimSpace(msg.Flight.DepartingAirport),
+		DestinationAirport: strings.TrimSpace(msg.Flight.DestinationAirport),
+		Latitude:           msg.Flight.Latitude,
+		Longitude:          msg.Flight.Longitude,
+		Altitude:           msg.Flight.Altitude,
+	}
+}
+
+// FirstString returns the first non-empty value found at paths in root, as
+// text.  Paths are dotted, as for Lookup.
+func FirstString(root map[string]any, paths ...string) string {
+	for _, p := range paths {
+		if v, ok := Lookup(root, p); ok {
+			switch t := v.(type) {
+			case string:
+				if strings.TrimSpace(t) != "" {
+					return t
+				}
+			case float64:
+				// Sometimes labels are numeric; preserve as int string where possible.
+				if t == float64(int64(t)) {
+					return strconv.FormatInt(int64(t), 10)
+				}
+				return strconv.FormatFloat(t, 'f', -1, 64)
+			case bool:
+				if t {
+					return "true"
+				}
+				return "false"
+			}
+		}
+	}
+	return ""
+}
+
+func firstInt64(root map[string]any, paths ...string) int64 {
+	for _, p := range paths {
+		if v, ok := Lookup(root, p); ok {
+			switch t := v.(type) {
+			case float64:
+				return int64(t)
+			case string:
+				if i, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64); err == nil {
+					return i
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func firstFloat64(root map[string]any, paths ...string) float64 {
+	for _, p := range paths {
+		if v, ok := Lookup(root, p); ok {
+			switch t := v.(type) {
+			case float64:
+				return t
+			case string:
+				if f, err := strconv.ParseFloat(strings.TrimSpace(t), 64); err == nil {
+					return f
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// Lookup walks a map[string]any using a dotted path: "a.b.c".
+func Lookup(root map[string]any, dotted string) (any, bool) {
+	parts := strings.Split(dotted, ".")
+	var cur any = root
+	for _, part := range parts {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
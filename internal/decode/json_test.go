@@ -0,0 +1,38 @@
+package decode
+
+import (
+	"os"
+	"testing"
+)
+
+// TestJSONATNCPDLC reads a hand-written frame laid out like dumpvdl2's nested
+// output, with the user data of an X.227 APDU as an array of octets in
+// "data".  It is not a capture: no dumpvdl2 output carrying ATN CPDLC is
+// available to check the layout against.
+func TestJSONATNCPDLC(t *testing.T) {
+	b, err := os.ReadFile("testdata/synthetic_atn_cpdlc.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, layout := JSON(b)
+	if layout != "nested" || len(msgs) != 1 {
+		t.Fatalf("JSON = %d messages, layout %q", len(msgs), layout)
+	}
+	m := msgs[0]
+	if m.Label != "ATNCPDLC" || m.Text != "ATN CPDLC UPLINK 32B0187A5E9A4710BA348D41F4" ||
+		m.Timestamp != "2026-10-16T09:41:07.25Z" || m.Frequency != 136.975 || m.Source != "dumpvdl2" ||
+		m.Station == nil || m.Station.Ident != "EBBR-RX1" {
+		t.Fatalf("message = %+v", m)
+	}
+
+	// The same user data given as a hex string, and a frame from an aircraft.
+	for in, want := range map[string]string{
+		`{"vdl2":{"avlc":{"src":{"addr":"1131E4","type":"Ground station"},"x25":{"clnp":{"cotp":{"x225_spdu":{"x227_apdu":{"data":"32b0 187a"}}}}}}}}`: "ATN CPDLC UPLINK 32B0187A",
+		`{"vdl2":{"avlc":{"src":{"addr":"3C6586","type":"Aircraft"},"x25":{"clnp":{"cotp":{"x225_spdu":{"x227_apdu":{"data":[100,238]}}}}}}}}`:         "ATN CPDLC DOWNLINK 64EE",
+	} {
+		msgs, _ := JSON([]byte(in))
+		if len(msgs) != 1 || msgs[0].Text != want {
+			t.Errorf("%s: got %+v, want %q", in, msgs, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package decode
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// messageTimeLayouts are the textual timestamp styles seen in our inputs:
+// ISO 8601 from NATS and dumpvdl2/dumphfdl, and the JAERO header style.
+// Layouts without a zone are taken as UTC.
+var messageTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"15:04:05 02-01-06 MST",
+	"15:04:05 02-01-06",
+}
+
+// Unix times outside [minUnixSeconds, maxUnixSeconds) are not taken as
+// timestamps: they lie before 2001 or after 2286, and come from a number
+// that is not one, such as a bare year or date.
+const (
+	minUnixSeconds = 1e9
+	maxUnixSeconds = 1e10
+)
+
+// ParseTime parses a message timestamp, or a time given on the command line.
+// It accepts ISO 8601, Unix seconds as acarsdec writes them
+// ("1777761826.271997"; values this large in milliseconds are also
+// recognised) and JAERO's "HH:MM:SS DD-MM-YY [UTC]".
+func ParseTime(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if f > 1e12 {
+			f /= 1000
+		}
+		if math.IsNaN(f) || f < minUnixSeconds || f >= maxUnixSeconds {
+			return time.Time{}, false
+		}
+		sec := int64(f)
+		return time.Unix(sec, int64((f-float64(sec))*1e9)).UTC(), true
+	}
+	for _, layout := range messageTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,37 @@
+package decode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2026, 5, 12, 16, 54, 24, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-05-12T16:54:24Z", want},
+		{"2026-05-12T18:54:24+02:00", want},
+		{"2026-05-12T16:54:24", want},
+		{"2026-05-12 16:54:24", want},
+		{"16:54:24 12-05-26 UTC", want},
+		{"16:54:24 12-05-26", want},
+		{"1778604864", want},
+		{"1778604864000", want},
+		{"1778604864.5", want.Add(500 * time.Millisecond)},
+		{"2026-05-12", time.Date(2026, 5, 12, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.in)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", tt.in, got, ok, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "yesterday", "16:54 12/05/26",
+		"NaN", "Inf", "-Inf", "-1", "0", "2026", "20260512", "99999999999", "1e300"} {
+		if _, ok := ParseTime(bad); ok {
+			t.Errorf("ParseTime(%q) unexpectedly succeeded", bad)
+		}
+	}
+}
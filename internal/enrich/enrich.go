@@ -0,0 +1,179 @@
+// Package enrich fills in the flight number, tail and airports of a message
+// for messages whose feed did not carry them.  Enrichers find the values in
+// the text or in the results parsed from it; a Chain runs them in order and
+// records on the message which enricher set each field.
+package enrich
+
+import (
+	"fmt"
+	"strings"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/airlines"
+	"acars_parser/internal/airports"
+	"acars_parser/internal/decode"
+	"acars_parser/internal/registry"
+)
+
+// The fields an enricher can set, as keys of acars.Message.EnrichedBy.  They
+// are named like the fields of an extract record.
+const (
+	FieldFlight             = "flight"
+	FieldTail               = "tail"
+	FieldDepartingAirport   = "departing_airport"
+	FieldDestinationAirport = "destination_airport"
+)
+
+// Values are what an enricher found for a message.  Empty fields were not
+// found.
+type Values struct {
+	Flight             string
+	Tail               string
+	DepartingAirport   string
+	DestinationAirport string
+}
+
+// Enricher finds flight fields for a message, in its text or in the results
+// parsed from it.  results is nil when the chain runs before dispatch.
+type Enricher interface {
+	Name() string
+	Enrich(msg *acars.Message, results []registry.Result) Values
+}
+
+// builtin are the built-in enrichers in their default order.
+var builtin = []Enricher{AFN, FPN, INI, RA, PDC, FSM, Results}
+
+// Names returns the names of the built-in enrichers in their default order.
+func Names() []string {
+	names := make([]string, len(builtin))
+	for i, e := range builtin {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// Chain runs enrichers in order.  The first enricher to find a field sets it;
+// fields the feed already carried are never changed.
+type Chain struct {
+	enrichers []Enricher
+}
+
+// NewChain returns a chain running enrichers in the order given.
+func NewChain(enrichers ...Enricher) *Chain {
+	return &Chain{enrichers: enrichers}
+}
+
+// Default returns a chain of all built-in enrichers: the text ones first, then
+// Results.
+func Default() *Chain {
+	return NewChain(builtin...)
+}
+
+// ByName returns a chain of the named built-in enrichers, in the order
+// given.  "none" alone gives an empty chain.
+func ByName(names ...string) (*Chain, error) {
+	if len(names) == 1 && names[0] == "none" {
+		return NewChain(), nil
+	}
+	c := NewChain()
+	for _, name := range names {
+		e := lookup(name)
+		if e == nil {
+			return nil, fmt.Errorf("unknown enricher %q (have %s)", name, strings.Join(Names(), ", "))
+		}
+		c.enrichers = append(c.enrichers, e)
+	}
+	return c, nil
+}
+
+func lookup(name string) Enricher {
+	for _, e := range builtin {
+		if e.Name() == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Names returns the names of c's enrichers in order.
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.enrichers))
+	for i, e := range c.enrichers {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// Enrich runs the chain over msg and fills in the fields found, recording
+// the enricher of each in msg.EnrichedBy.  It can run once before dispatch,
+// with nil results, and again after: fields set by the first run stay.
+func (c *Chain) Enrich(msg *acars.Message, results []registry.Result) {
+	if msg == nil || c == nil {
+		return
+	}
+
+	var found Values
+	var by [4]string // enricher of each field of found, in Values order
+	take := func(dst *string, i int, v string, name string) {
+		if *dst == "" && v != "" {
+			*dst, by[i] = v, name
+		}
+	}
+	for _, e := range c.enrichers {
+		v := e.Enrich(msg, results)
+		take(&found.Flight, 0, strings.TrimSpace(v.Flight), e.Name())
+		take(&found.Tail, 1, strings.TrimSpace(v.Tail), e.Name())
+		take(&found.DepartingAirport, 2, strings.TrimSpace(v.DepartingAirport), e.Name())
+		take(&found.DestinationAirport, 3, strings.TrimSpace(v.DestinationAirport), e.Name())
+	}
+	if found == (Values{}) {
+		return
+	}
+
+	if found.Tail != "" {
+		if msg.Airframe == nil {
+			msg.Airframe = &acars.Airframe{}
+		}
+		set := false
+		if strings.TrimSpace(msg.Airframe.Tail) == "" || strings.HasPrefix(strings.TrimSpace(msg.Airframe.Tail), ".") {
+			msg.Airframe.Tail = found.Tail
+			set = true
+		}
+		if strings.TrimSpace(msg.Tail) == "" {
+			msg.Tail = found.Tail
+			set = true
+		}
+		if set {
+			record(msg, FieldTail, by[1])
+		}
+	}
+
+	if found.Flight == "" && found.DepartingAirport == "" && found.DestinationAirport == "" {
+		return
+	}
+
+	if msg.Flight == nil {
+		msg.Flight = &acars.Flight{}
+	}
+	if strings.TrimSpace(msg.Flight.Flight) == "" && found.Flight != "" {
+		msg.Flight.Flight = airlines.TranslateFlight(found.Flight)
+		record(msg, FieldFlight, by[0])
+	}
+	if strings.TrimSpace(msg.Flight.DepartingAirport) == "" && found.DepartingAirport != "" {
+		msg.Flight.DepartingAirport = airports.NormaliseCode(found.DepartingAirport)
+		record(msg, FieldDepartingAirport, by[2])
+	}
+	if strings.TrimSpace(msg.Flight.DestinationAirport) == "" && found.DestinationAirport != "" {
+		msg.Flight.DestinationAirport = airports.NormaliseCode(found.DestinationAirport)
+		record(msg, FieldDestinationAirport, by[3])
+	}
+	decode.NormaliseFlight(msg)
+}
+
+// record notes that enricher set field of msg.
+func record(msg *acars.Message, field, enricher string) {
+	if msg.EnrichedBy == nil {
+		msg.EnrichedBy = make(map[string]string)
+	}
+	msg.EnrichedBy[field] = enricher
+}
@@ -0,0 +1,105 @@
+package enrich
+
+import (
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+type routeResult struct {
+	FlightNumber string `json:"flight_number,omitempty"`
+	Origin       string `json:"origin,omitempty"`
+	Destination  string `json:"destination,omitempty"`
+}
+
+func (r *routeResult) Type() string     { return "route" }
+func (r *routeResult) MessageID() int64 { return 0 }
+
+type fixed struct {
+	name string
+	v    Values
+}
+
+func (f fixed) Name() string                                    { return f.name }
+func (f fixed) Enrich(*acars.Message, []registry.Result) Values { return f.v }
+
+func TestChainFirstWins(t *testing.T) {
+	msg := &acars.Message{Flight: &acars.Flight{DepartingAirport: "EGLL"}}
+	NewChain(
+		fixed{"a", Values{DestinationAirport: "KJFK"}},
+		fixed{"b", Values{Flight: "BA117", DepartingAirport: "EGKK", DestinationAirport: "KBOS"}},
+	).Enrich(msg, nil)
+
+	f := msg.Flight
+	if f.Flight != "BAW117" || f.DepartingAirport != "EGLL" || f.DestinationAirport != "KJFK" {
+		t.Fatalf("flight = %+v", f)
+	}
+	want := map[string]string{FieldFlight: "b", FieldDestinationAirport: "a"}
+	if len(msg.EnrichedBy) != len(want) {
+		t.Fatalf("enriched by %v, want %v", msg.EnrichedBy, want)
+	}
+	for k, v := range want {
+		if msg.EnrichedBy[k] != v {
+			t.Errorf("enriched by %v, want %v", msg.EnrichedBy, want)
+		}
+	}
+}
+
+func TestDefaultTextAndResults(t *testing.T) {
+	c := Default()
+	msg := &acars.Message{Label: "B0", Tail: ".D-AIMM", Text: "/PIKCPYA.AFN/FMHDLH8P,.D-AIMM,,165418/FRP05A3A"}
+	c.Enrich(msg, nil)
+	if msg.Airframe == nil || msg.Airframe.Tail != "D-AIMM" || msg.Flight == nil || msg.Flight.Flight != "DLH8P" {
+		t.Fatalf("afn: airframe %+v, flight %+v", msg.Airframe, msg.Flight)
+	}
+	if msg.EnrichedBy[FieldTail] != "afn" || msg.EnrichedBy[FieldFlight] != "afn" {
+		t.Errorf("afn: enriched by %v", msg.EnrichedBy)
+	}
+
+	// After dispatch the results fill in what the text did not name.
+	c.Enrich(msg, []registry.Result{
+		&registry.ErrorResult{Parser: "broken"},
+		&routeResult{FlightNumber: "LH9", Origin: "EDDF", Destination: "not an airport"},
+		&routeResult{Destination: "KORD"},
+	})
+	f := msg.Flight
+	if f.Flight != "DLH8P" || f.DepartingAirport != "EDDF" || f.DestinationAirport != "KORD" {
+		t.Fatalf("results: flight = %+v", f)
+	}
+	if msg.EnrichedBy[FieldFlight] != "afn" || msg.EnrichedBy[FieldDepartingAirport] != "results" || msg.EnrichedBy[FieldDestinationAirport] != "results" {
+		t.Errorf("results: enriched by %v", msg.EnrichedBy)
+	}
+}
+
+func TestRAOnlyForLabelRA(t *testing.T) {
+	text := "OFP INFO\nBA117 LHR-JFK"
+	for label, want := range map[string]string{"RA": "BAW117", "H1": ""} {
+		msg := &acars.Message{Label: label, Text: text}
+		NewChain(RA).Enrich(msg, nil)
+		got := ""
+		if msg.Flight != nil {
+			got = msg.Flight.Flight
+		}
+		if got != want {
+			t.Errorf("label %s: flight %q, want %q", label, got, want)
+		}
+	}
+}
+
+func TestByName(t *testing.T) {
+	c, err := ByName("pdc", "afn")
+	if err != nil || strings.Join(c.Names(), ",") != "pdc,afn" {
+		t.Fatalf("ByName = %v, %v", c, err)
+	}
+	if c, err := ByName("none"); err != nil || len(c.Names()) != 0 {
+		t.Errorf("none = %v, %v", c, err)
+	}
+	if _, err := ByName("afn", "nope"); err == nil {
+		t.Error("ByName accepted an unknown enricher")
+	}
+	if got := strings.Join(Names(), ","); got != "afn,fpn,ini,ra,pdc,fsm,results" {
+		t.Errorf("Names = %s", got)
+	}
+}
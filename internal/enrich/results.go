@@ -0,0 +1,97 @@
+package enrich
+
+import (
+	"encoding/json"
+	"strings"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+// Results takes the flight number and airports from the parsed results, such
+// as those of PDCs, flight plans and INI and DIS reports.  The first result
+// naming a field wins.
+var Results Enricher = resultsEnricher{}
+
+// resultFields are the JSON fields of results read by Results, by Values
+// field, in order of preference.
+var (
+	resultFlightFields      = []string{"flight_number", "flight_num", "flight"}
+	resultDepartingFields   = []string{"origin", "origin_icao"}
+	resultDestinationFields = []string{"destination", "dest_icao"}
+)
+
+type resultsEnricher struct{}
+
+func (resultsEnricher) Name() string { return "results" }
+
+func (resultsEnricher) Enrich(_ *acars.Message, results []registry.Result) Values {
+	var v Values
+	for _, r := range results {
+		if _, ok := r.(*registry.ErrorResult); ok {
+			continue
+		}
+		// Convert the result to a map for generic field access, as the
+		// state extractor does.
+		b, err := json.Marshal(r)
+		if err != nil {
+			continue
+		}
+		var m map[string]any
+		if json.Unmarshal(b, &m) != nil {
+			continue
+		}
+		if v.Flight == "" {
+			v.Flight = firstField(m, resultFlightFields, isFlightNumber)
+		}
+		if v.DepartingAirport == "" {
+			v.DepartingAirport = firstField(m, resultDepartingFields, isAirportCode)
+		}
+		if v.DestinationAirport == "" {
+			v.DestinationAirport = firstField(m, resultDestinationFields, isAirportCode)
+		}
+	}
+	return v
+}
+
+// firstField returns the first of fields in m that is a string passing ok.
+func firstField(m map[string]any, fields []string, ok func(string) bool) string {
+	for _, f := range fields {
+		if s, _ := m[f].(string); ok(strings.TrimSpace(s)) {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// isAirportCode reports whether s looks like an ICAO airport code.
+func isAirportCode(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isFlightNumber reports whether s looks like a flight number: three to eight
+// capital letters and digits, at least one of them a digit.
+func isFlightNumber(s string) bool {
+	if len(s) < 3 || len(s) > 8 {
+		return false
+	}
+	digits := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c >= 'A' && c <= 'Z':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
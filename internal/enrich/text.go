@@ -0,0 +1,238 @@
+package enrich
+
+import (
+	"regexp"
+	"strings"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/patterns"
+	"acars_parser/internal/registry"
+)
+
+var (
+	pdcFlightRe       = regexp.MustCompile(`\b([A-Z0-9]{3,8})\s+CLRD\s+TO\s+([A-Z]{4})\b`)
+	pdcOriginHeaderRe = regexp.MustCompile(`(?s)/[A-Z]+\.[A-Z0-9]+/[A-Z]+\s+\d{4}\s+\d{6}\s+([A-Z]{4})\b`)
+	pdcDestinationRe  = regexp.MustCompile(`\bCLRD\s+TO\s+([A-Z]{4})\b`)
+	fsmHeaderRe       = regexp.MustCompile(`(?s)/[A-Z]+\.[A-Z0-9]+/FSM\s+\d{4}\s+\d{6}\s+([A-Z]{4})\s+([A-Z0-9]{3,8})\b`)
+	iniMetadataRe     = regexp.MustCompile(`(?i)INI(\d{2})(\d{2})(\d{4})\s+([A-Z]{3}\d{1,4}[A-Z]?)\s*/\d{2}/([A-Z]{4})/([A-Z]{4})\b`)
+	iniIDMetadataRe   = regexp.MustCompile(`(?i)^INI/ID[0-9A-Z]+,([^,]+),[^/]*/MR\d+,[^/]*/(?:AF)?([A-Z]{4}),([A-Z]{4})/TD(\d{2})(\d{4}),`)
+	raFlightNumberRe  = regexp.MustCompile(`\bFLIGHT\s+NUMBER:\s*([A-Z0-9]{2,10}(?:/[A-Z0-9]{2,10})?)\b`)
+	raSectorRe        = regexp.MustCompile(`\bSECTOR:\s*([A-Z]{4})-([A-Z]{4})\b`)
+	raOFPInfoRe       = regexp.MustCompile(`(?is)\bOFP\s+INFO\b\s+([A-Z0-9]{2,10})\s+([A-Z]{3})-([A-Z]{3})\b`)
+)
+
+// The text enrichers, one per message format that names its flight.  Each
+// reads only msg.Text (and the label, for RA).
+var (
+	AFN Enricher = textEnricher{"afn", func(msg *acars.Message) Values {
+		flight, tail, destination := parseAFNMetadataFromText(msg.Text)
+		return Values{Flight: flight, Tail: tail, DestinationAirport: destination}
+	}}
+	FPN Enricher = textEnricher{"fpn", func(msg *acars.Message) Values {
+		return Values{Flight: parseFPNFlightFromText(msg.Text)}
+	}}
+	INI Enricher = textEnricher{"ini", func(msg *acars.Message) Values {
+		flight, departing, destination := parseINIMetadataFromText(msg.Text)
+		return Values{Flight: flight, DepartingAirport: departing, DestinationAirport: destination}
+	}}
+	RA Enricher = textEnricher{"ra", func(msg *acars.Message) Values {
+		if !strings.EqualFold(strings.TrimSpace(msg.Label), "RA") {
+			return Values{}
+		}
+		flight, departing, destination := parseRAFlightMetadataFromText(msg.Text)
+		return Values{Flight: flight, DepartingAirport: departing, DestinationAirport: destination}
+	}}
+	PDC Enricher = textEnricher{"pdc", func(msg *acars.Message) Values {
+		flight, departing, destination := parsePDCMetadataFromText(msg.Text)
+		return Values{Flight: flight, DepartingAirport: departing, DestinationAirport: destination}
+	}}
+	FSM Enricher = textEnricher{"fsm", func(msg *acars.Message) Values {
+		flight, departing := parseFSMMetadataFromText(msg.Text)
+		return Values{Flight: flight, DepartingAirport: departing}
+	}}
+)
+
+// textEnricher is an Enricher that ignores the results.
+type textEnricher struct {
+	name string
+	find func(msg *acars.Message) Values
+}
+
+func (e textEnricher) Name() string { return e.name }
+
+func (e textEnricher) Enrich(msg *acars.Message, _ []registry.Result) Values {
+	return e.find(msg)
+}
+
+func parseAFNMetadataFromText(text string) (flightNumber string, cleanTail string, destinationAirport string) {
+	text = strings.TrimSpace(text)
+	if !strings.Contains(text, ".AFN/") {
+		return "", "", ""
+	}
+
+	fmhIdx := strings.Index(text, "/FMH")
+	if fmhIdx >= 0 {
+		rest := text[fmhIdx+4:]
+		commaIdx := strings.IndexByte(rest, ',')
+		if commaIdx > 0 {
+			flightNumber = strings.TrimSpace(rest[:commaIdx])
+			tailAndRest := rest[commaIdx+1:]
+			fields := strings.Split(tailAndRest, ",")
+			if len(fields) > 0 {
+				cleanTail = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(fields[0]), "."))
+			}
+		}
+	}
+
+	fakIdx := strings.Index(text, "/FAK0,")
+	if fakIdx >= 0 {
+		rest := text[fakIdx+6:]
+		endIdx := strings.IndexByte(rest, '/')
+		if endIdx >= 0 {
+			destinationAirport = strings.TrimSpace(rest[:endIdx])
+		} else {
+			destinationAirport = strings.TrimSpace(rest)
+		}
+	}
+
+	if len(destinationAirport) != 4 {
+		destinationAirport = ""
+	}
+
+	return strings.TrimSpace(flightNumber), strings.TrimSpace(cleanTail), destinationAirport
+}
+
+func parseFPNFlightFromText(text string) string {
+	text = strings.TrimSpace(strings.ToUpper(text))
+	if !strings.HasPrefix(text, "FPN/") {
+		return ""
+	}
+
+	match := patterns.FPNFlightPattern.FindStringSubmatch(text)
+	if len(match) < 2 {
+		return ""
+	}
+
+	return strings.TrimSpace(match[1])
+}
+
+func parsePDCMetadataFromText(text string) (flightNumber string, departingAirport string, destinationAirport string) {
+	text = strings.TrimSpace(strings.ToUpper(text))
+	if text == "" {
+		return "", "", ""
+	}
+	if !strings.Contains(text, "PDC") && !strings.Contains(text, "CLRD TO") {
+		return "", "", ""
+	}
+
+	if match := pdcFlightRe.FindStringSubmatch(text); len(match) == 3 {
+		flightNumber = strings.TrimSpace(match[1])
+		destinationAirport = strings.TrimSpace(match[2])
+	}
+	if match := pdcOriginHeaderRe.FindStringSubmatch(text); len(match) == 2 {
+		departingAirport = strings.TrimSpace(match[1])
+	}
+	if destinationAirport == "" {
+		if match := pdcDestinationRe.FindStringSubmatch(text); len(match) == 2 {
+			destinationAirport = strings.TrimSpace(match[1])
+		}
+	}
+	if flightNumber == "" {
+		tokens := strings.Fields(text)
+		flightNumber = strings.TrimSpace(patterns.ExtractFlightNumber(text, tokens))
+	}
+	return flightNumber, strings.TrimSpace(departingAirport), strings.TrimSpace(destinationAirport)
+}
+
+func parseFSMMetadataFromText(text string) (flightNumber string, departingAirport string) {
+	text = strings.TrimSpace(strings.ToUpper(text))
+	if text == "" || !strings.Contains(text, "FS1/FSM") {
+		return "", ""
+	}
+
+	match := fsmHeaderRe.FindStringSubmatch(text)
+	if len(match) != 3 {
+		return "", ""
+	}
+
+	departingAirport = strings.TrimSpace(match[1])
+	flightNumber = strings.TrimSpace(match[2])
+	if len(departingAirport) != 4 || flightNumber == "" {
+		return "", ""
+	}
+
+	return flightNumber, departingAirport
+}
+
+func parseINIMetadataFromText(text string) (flightNumber string, departingAirport string, destinationAirport string) {
+	text = strings.TrimSpace(strings.ToUpper(text))
+	if text == "" || (!strings.Contains(text, "INI01") && !strings.Contains(text, "INI/ID")) {
+		return "", "", ""
+	}
+
+	if match := iniIDMetadataRe.FindStringSubmatch(text); len(match) == 6 {
+		flightNumber = strings.TrimSpace(match[1])
+		departingAirport = strings.TrimSpace(match[2])
+		destinationAirport = strings.TrimSpace(match[3])
+		if len(departingAirport) == 4 && len(destinationAirport) == 4 && flightNumber != "" {
+			return flightNumber, departingAirport, destinationAirport
+		}
+	}
+
+	match := iniMetadataRe.FindStringSubmatch(text)
+	if len(match) != 7 {
+		return "", "", ""
+	}
+
+	flightNumber = strings.TrimSpace(match[4])
+	departingAirport = strings.TrimSpace(match[5])
+	destinationAirport = strings.TrimSpace(match[6])
+	if len(departingAirport) != 4 || len(destinationAirport) != 4 || flightNumber == "" {
+		return "", "", ""
+	}
+
+	return flightNumber, departingAirport, destinationAirport
+}
+
+func parseRAFlightMetadataFromText(text string) (flightNumber string, departingAirport string, destinationAirport string) {
+	text = strings.TrimSpace(strings.ToUpper(text))
+	if text == "" {
+		return "", "", ""
+	}
+
+	if strings.Contains(text, "FLIGHT NUMBER:") {
+		if match := raFlightNumberRe.FindStringSubmatch(text); len(match) == 2 {
+			flightNumber = strings.TrimSpace(match[1])
+			if strings.Contains(flightNumber, "/") {
+				parts := strings.SplitN(flightNumber, "/", 2)
+				if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+					flightNumber = strings.TrimSpace(parts[1])
+				} else {
+					flightNumber = strings.TrimSpace(parts[0])
+				}
+			}
+		}
+
+		if match := raSectorRe.FindStringSubmatch(text); len(match) == 3 {
+			departingAirport = strings.TrimSpace(match[1])
+			destinationAirport = strings.TrimSpace(match[2])
+		}
+
+		if flightNumber == "" {
+			return "", "", ""
+		}
+
+		return flightNumber, departingAirport, destinationAirport
+	}
+
+	if !strings.Contains(text, "OFP INFO") {
+		return "", "", ""
+	}
+
+	match := raOFPInfoRe.FindStringSubmatch(text)
+	if len(match) != 4 {
+		return "", "", ""
+	}
+
+	return strings.TrimSpace(match[1]), strings.TrimSpace(match[2]), strings.TrimSpace(match[3])
+}
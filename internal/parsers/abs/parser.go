@@ -47,6 +47,41 @@ type Result struct {
 func (r *Result) Type() string     { return "abs" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	if positions := r.ReportedPositions(); len(positions) > 0 {
+		return positions[0], true
+	}
+	return registry.Position{Latitude: r.Latitude, Longitude: r.Longitude, Altitude: r.AltitudeFt,
+		Temperature: registry.Temperature(float64(r.Temperature))}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// ReportedPositions implements registry.MultiPositionReporter with the
+// position rows of the block.
+func (r *Result) ReportedPositions() []registry.Position {
+	positions := make([]registry.Position, 0, len(r.Positions))
+	for _, p := range r.Positions {
+		if p.Latitude == 0 && p.Longitude == 0 {
+			continue
+		}
+		positions = append(positions, registry.Position{Latitude: p.Latitude, Longitude: p.Longitude, Altitude: p.AltitudeFt,
+			Temperature: registry.Temperature(float64(p.TemperatureC))})
+	}
+	return positions
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	p, _ := r.Position()
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: p.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: p.Longitude != 0, Weight: 2},
+		registry.Field{Name: "altitude_ft", Present: p.Altitude != 0},
+		registry.Field{Name: "origin", Present: r.Origin != ""},
+		registry.Field{Name: "destination", Present: r.Destination != ""},
+	)
+}
+
 // Parser extracts route hints from ABS0 blocks in H1 messages.
 type Parser struct{}
 
@@ -54,9 +89,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "abs" }
-func (p *Parser) Labels() []string { return []string{"H1"} }
-func (p *Parser) Priority() int    { return 40 }
+func (p *Parser) Name() string               { return "abs" }
+func (p *Parser) Labels() []string           { return []string{"H1"} }
+func (p *Parser) Priority() int              { return 40 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(strings.ToUpper(text), "ABS0")
@@ -123,6 +123,14 @@ func TestABSParsesMultiPositionBlock(t *testing.T) {
 	if result.Positions[5].TemperatureC != -23 {
 		t.Fatalf("Last TemperatureC = %d, want %d", result.Positions[5].TemperatureC, -23)
 	}
+
+	positions := result.ReportedPositions()
+	if len(positions) != 6 || positions[5].Altitude != 18264 || positions[5].Temperature == nil || *positions[5].Temperature != -23 {
+		t.Fatalf("ReportedPositions = %+v", positions)
+	}
+	if p, ok := result.Position(); !ok || p.Latitude != positions[0].Latitude || p.Altitude != 6127 {
+		t.Fatalf("Position = %+v, %v; want the first row", p, ok)
+	}
 }
 
 func TestABSReturnsNilWithoutRouteOrPosition(t *testing.T) {
@@ -136,6 +136,44 @@ type Result struct {
 func (r *Result) Type() string     { return "adsc" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.  The report time is in
+// seconds past the hour, so it is left to the message time.
+func (r *Result) Position() (registry.Position, bool) {
+	p := registry.Position{Latitude: r.Latitude, Longitude: r.Longitude, Altitude: r.Altitude}
+	if r.EarthRef != nil {
+		p.GroundSpeed = int(math.Round(r.EarthRef.GroundSpeed))
+		if !r.EarthRef.TrackInvalid {
+			p.Track = int(math.Round(r.EarthRef.Track))
+		}
+	}
+	if p.Track == 0 && r.AirRef != nil && !r.AirRef.HeadingInvalid {
+		p.Track = int(math.Round(r.AirRef.Heading))
+	}
+	if m := r.Meteo; m != nil {
+		if !m.TemperatureInvalid {
+			p.Temperature = &m.Temperature
+		}
+		if !m.WindDirInvalid {
+			p.Wind = registry.NewWind(int(math.Round(m.WindDirection)), int(math.Round(m.WindSpeed)))
+		}
+	}
+	return p, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter for position reports; other
+// messages, such as contract requests, have nothing to assess.
+func (r *Result) Quality() registry.Quality {
+	switch r.MessageType {
+	case "basic", "emergency", "lateral_deviation", "vert_rate_change", "altitude_range", "waypoint_change":
+		return registry.Assess(
+			registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+			registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+			registry.Field{Name: "altitude", Present: r.Altitude != 0},
+		)
+	}
+	return registry.Quality{}
+}
+
 // Parser parses ADS-C B6 messages.
 type Parser struct{}
 
@@ -143,9 +181,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "adsc" }
-func (p *Parser) Labels() []string { return []string{"B6", "A6"} }
-func (p *Parser) Priority() int    { return 10 }
+func (p *Parser) Name() string               { return "adsc" }
+func (p *Parser) Labels() []string           { return []string{"B6", "A6"} }
+func (p *Parser) Priority() int              { return 10 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(text, ".ADS")
@@ -594,3 +594,16 @@ func TestDecodeMeteo(t *testing.T) {
 	})
 }
 
+
+// TestPositionZeroTemperature checks that a valid 0 °C reading reaches the
+// position, while an invalid one does not.
+func TestPositionZeroTemperature(t *testing.T) {
+	r := &Result{Latitude: 51.5, Longitude: -0.5, Meteo: &MeteoData{WindDirInvalid: true}}
+	if p, _ := r.Position(); p.Temperature == nil || *p.Temperature != 0 {
+		t.Errorf("Position().Temperature = %v, want 0", p.Temperature)
+	}
+	r.Meteo.TemperatureInvalid = true
+	if p, _ := r.Position(); p.Temperature != nil {
+		t.Errorf("Position().Temperature = %v with TemperatureInvalid, want nil", *p.Temperature)
+	}
+}
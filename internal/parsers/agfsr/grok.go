@@ -7,25 +7,34 @@ import "acars_parser/internal/patterns"
 var Formats = []patterns.Format{
 	// AGFSR flight status format.
 	// Example: AGFSR AC1234/29/29/YULMIA/1234Z/110/3457.3N07711.0W/350/CRUISE/1234/0567/M37/248095/1234/GS450/UNK/1530/1600
-	// Groups: flight, day1, day2, route, time, unknown1, position, fl, phase, fuel_remain, fuel_used, mach, wind, heading, field1, field2, eta, sched
+	// The temperature is M37 for -37 °C and the wind 248095 for 248° at 95 kt.
+	// Groups: flight, day1, day2, route, time, unknown1, position, fl, phase, fuel_remain, fuel_used, temperature,
+	// wind_dir, wind_speed, heading, field1, field2, eta, sched
 	{
 		Name: "agfsr_status",
 		Pattern: `^AGFSR\s+(?P<flight>[A-Z]{2}\d{4})/` +
-			`(?P<day1>\d{2})/(?P<day2>\d{2})/(?P<route>[A-Z]{6})/(?P<time>\d{4}Z)/` +
-			`(?P<unknown1>[^/]+)/(?P<position>[^/]+)/(?P<fl>{HEADING})/(?P<phase>[A-Z]+)/` +
-			`(?P<fuel_remain>\d{4})/(?P<fuel_used>\d{4})/(?P<mach>M\d{2})/` +
-			`(?P<wind>\d{6})/(?P<heading>{TIME4})/(?P<field1>[^/]+)/(?P<field2>[^/]+)/` +
+			`{NUM2:day1:int}/{NUM2:day2:int}/(?P<route>[A-Z]{6})/(?P<time>\d{4}Z)/` +
+			`{FIELD:unknown1}/{FIELD:position}/{HEADING:fl:int}/(?P<phase>[A-Z]+)/` +
+			`{NUM4:fuel_remain:int}/{NUM4:fuel_used:int}/M{NUM2:temperature:int}/` +
+			`{WIND_DIR:wind_dir:int}{NUM3:wind_speed:int}/(?P<heading>{TIME4})/{FIELD:field1:int}/{FIELD:field2:int}/` +
 			`(?P<eta>\d{4}|\-{4}|\*{4})/(?P<sched>\d{4}|\-{4}|\*{4})`,
 		Fields: []string{"flight", "day1", "day2", "route", "time", "unknown1", "position",
-			"fl", "phase", "fuel_remain", "fuel_used", "mach", "wind", "heading",
+			"fl", "phase", "fuel_remain", "fuel_used", "temperature", "wind_dir", "wind_speed", "heading",
 			"field1", "field2", "eta", "sched"},
 	},
 	// Position extraction pattern (for parsing position field).
 	// Example: 3457.3N07711.0W
-	// Groups: lat, lat_dir, lon, lon_dir
+	// Groups: lat, lon
 	{
 		Name:    "position",
-		Pattern: `(?P<lat>\d{4}\.\d)(?P<lat_dir>{LAT_DIR})(?P<lon>\d{5}\.\d)(?P<lon_dir>{LON_DIR})`,
-		Fields:  []string{"lat", "lat_dir", "lon", "lon_dir"},
+		Pattern: `{POS_LAT:lat:coord}{POS_LON:lon:coord}`,
+		Fields:  []string{"lat", "lon"},
 	},
 }
+
+// localPatterns are the placeholders only the AGFSR formats use.
+var localPatterns = map[string]string{
+	"FIELD":   `[^/]+`,             // One non-empty slash-delimited field
+	"POS_LAT": `{LAT_DM}{LAT_DIR}`, // 3457.3N
+	"POS_LON": `{LON_DM}{LON_DIR}`, // 07711.0W
+}
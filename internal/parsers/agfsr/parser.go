@@ -2,7 +2,6 @@
 package agfsr
 
 import (
-	"strconv"
 	"strings"
 	"sync"
 
@@ -21,7 +20,7 @@ var (
 
 func getCompiler() (*patterns.Compiler, error) {
 	grokOnce.Do(func() {
-		grokCompiler = patterns.NewCompiler(Formats, nil)
+		grokCompiler = patterns.NewCompiler(Formats, localPatterns)
 		grokErr = grokCompiler.Compile()
 	})
 	return grokCompiler, grokErr
@@ -59,6 +58,32 @@ type Result struct {
 func (r *Result) Type() string     { return "agfsr" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		GroundSpeed: r.GroundSpeed,
+		Track:       r.Heading,
+		Temperature: registry.Temperature(float64(r.Temperature)),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeed),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+		registry.Field{Name: "origin", Present: r.Origin != ""},
+		registry.Field{Name: "destination", Present: r.Destination != ""},
+	)
+}
+
 // Parser parses AGFSR flight status messages.
 type Parser struct{}
 
@@ -66,9 +91,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "agfsr" }
-func (p *Parser) Labels() []string { return []string{"4T"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "agfsr" }
+func (p *Parser) Labels() []string           { return []string{"4T"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(text, "AGFSR")
@@ -115,10 +141,7 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 		result.Route = origin + "-" + destination
 	}
 
-	// Parse day.
-	if day, err := strconv.Atoi(match.Captures["day1"]); err == nil {
-		result.DayOfMonth = day
-	}
+	result.DayOfMonth, _ = match.Int("day1")
 
 	// Parse position using the secondary pattern.
 	if lat, lon, ok := parsePosition(compiler, match.Captures["position"]); ok {
@@ -126,49 +149,18 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 		result.Longitude = lon
 	}
 
-	// Parse flight level.
-	if fl, err := strconv.Atoi(match.Captures["fl"]); err == nil {
-		result.FlightLevel = fl
-	}
-
-	// Parse fuel remaining.
-	if fuel, err := strconv.Atoi(match.Captures["fuel_remain"]); err == nil {
-		result.FuelRemain = fuel
-	}
-
-	// Parse fuel used.
-	if fuel, err := strconv.Atoi(match.Captures["fuel_used"]); err == nil {
-		result.FuelUsed = fuel
-	}
-
-	// Parse temperature (M60 = -60°C)
-	machStr := match.Captures["mach"]
-	if strings.HasPrefix(machStr, "M") {
-		if temp, err := strconv.Atoi(machStr[1:]); err == nil {
-			result.Temperature = -temp
-		}
-	}
-
-	// Parse wind (248095 = 248° at 95kt).
-	windStr := match.Captures["wind"]
-	if len(windStr) == 6 {
-		if dir, err := strconv.Atoi(windStr[0:3]); err == nil {
-			result.WindDir = dir
-		}
-		if spd, err := strconv.Atoi(windStr[3:6]); err == nil {
-			result.WindSpeed = spd
-		}
+	result.FlightLevel, _ = match.Int("fl")
+	result.FuelRemain, _ = match.Int("fuel_remain")
+	result.FuelUsed, _ = match.Int("fuel_used")
+	if temp, ok := match.Int("temperature"); ok {
+		result.Temperature = -temp
 	}
+	result.WindDir, _ = match.Int("wind_dir")
+	result.WindSpeed, _ = match.Int("wind_speed")
 
 	// Fix: heading should use field1, ground_speed should use field2
-	field1 := match.Captures["field1"]
-	field2 := match.Captures["field2"]
-	if hdg, err := strconv.Atoi(field1); err == nil {
-		result.Heading = hdg
-	}
-	if gs, err := strconv.Atoi(field2); err == nil {
-		result.GroundSpeed = gs
-	}
+	result.Heading, _ = match.Int("field1")
+	result.GroundSpeed, _ = match.Int("field2")
 
 	// Parse ETA and scheduled.
 	eta := match.Captures["eta"]
@@ -190,11 +182,8 @@ func parsePosition(compiler *patterns.Compiler, s string) (lat, lon float64, ok
 		return 0, 0, false
 	}
 
-	// Parse latitude (format: DDMM.M - 2 degree digits, decimal minutes).
-	lat = patterns.ParseLatitude(match.Captures["lat"], match.Captures["lat_dir"])
-
-	// Parse longitude (format: DDDMM.M - 3 degree digits, decimal minutes).
-	lon = patterns.ParseLongitude(match.Captures["lon"], match.Captures["lon_dir"])
-
+	// DDMM.M and DDDMM.M, in decimal minutes.
+	lat, _ = match.Float("lat")
+	lon, _ = match.Float("lon")
 	return lat, lon, true
 }
@@ -41,9 +41,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "atis" }
-func (p *Parser) Labels() []string { return []string{"A9"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "atis" }
+func (p *Parser) Labels() []string           { return []string{"A9"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 // Patterns for ATIS parsing.
 var (
@@ -40,6 +40,9 @@ func (p *Parser) Name() string     { return "atncm" }
 func (p *Parser) Labels() []string { return []string{"ATNCM"} }
 func (p *Parser) Priority() int    { return 40 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"atn_cm"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(strings.ToUpper(text), "ATN CM LOGON")
 }
@@ -0,0 +1,940 @@
+package cpdlc
+
+// ATN B1 CPDLC, the European LINK2000+ service carried over VDL2 X.25/CLNP
+// (ICAO Doc 9705 / EUROCAE ED-110B, protected mode CPDLC version 1).
+//
+// The APDU is unaligned PER, like FANS-1/A, but the message set and most
+// parameter types differ:
+//
+//	ProtectedGroundPDUs ::= CHOICE {          ProtectedAircraftPDUs ::= CHOICE {
+//	  abortUser, abortProvider,                 abortUser, abortProvider,
+//	  startup  ProtectedUplinkMessage,          startdown ProtectedStartDownMessage,
+//	  send     ProtectedUplinkMessage,          send      ProtectedDownlinkMessage,
+//	  forward, forwardresponse, ... }           ... }
+//
+//	ProtectedUplinkMessage ::= SEQUENCE {
+//	  algorithmIdentifier RELATIVE-OID OPTIONAL,
+//	  protectedMessage    BIT STRING OPTIONAL,  -- ATCUplinkMessage
+//	  integrityCheck      BIT STRING, ... }
+//
+//	ATCUplinkMessage ::= SEQUENCE {
+//	  header      ATCMessageHeader,           -- msg ID, ref, date/time, logical ack
+//	  messageData SEQUENCE {
+//	    elementIds      SEQUENCE SIZE (1..5) OF ATCUplinkMsgElementId,
+//	    constrainedData SEQUENCE { routeClearanceData ..., ... } OPTIONAL } }
+//
+// Element parameters are decoded for the LINK2000+ subset of the message set;
+// any other element stops the decode with an error.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ATN CPDLC APDU types, as reported in Result.PDUType.
+const (
+	atnPDUUserAbort       = "user_abort"
+	atnPDUProviderAbort   = "provider_abort"
+	atnPDUStart           = "start"
+	atnPDUSend            = "send"
+	atnPDUForward         = "forward"
+	atnPDUForwardResponse = "forward_response"
+)
+
+// Root alternative counts of the extensible element ID CHOICEs (uM0-uM236,
+// dM0-dM113).  uM237 is the first uplink extension addition.
+const (
+	atnUplinkElements   = 237
+	atnDownlinkElements = 114
+)
+
+var atnUserAbortReasons = []string{
+	"undefined",
+	"no-message-identification-numbers-available",
+	"duplicate-message-identification-numbers",
+	"no-longer-next-data-authority",
+	"current-data-authority-abort",
+	"commanded-termination",
+	"invalid-response",
+	"time-out-of-synchronisation",
+	"unknown-integrity-check",
+	"validation-failure",
+	"unable-to-decode-message",
+	"invalid-pdu",
+	"invalid-CPDLC-message",
+}
+
+var atnProviderAbortReasons = []string{
+	"timer-expired",
+	"undefined-error",
+	"invalid-PDU",
+	"protocol-error",
+	"communication-service-error",
+	"communication-service-failure",
+	"invalid-QOS-parameter",
+	"expected-PDU-missing",
+}
+
+var atnErrorInformation = []string{
+	"unrecognizedMsgReferenceNumber",
+	"logicalAcknowledgmentNotAccepted",
+	"insufficientResources",
+	"invalidMessageElementCombination",
+	"invalidMessageElement",
+}
+
+var atnFacilityFunctions = []string{
+	"center", "approach", "tower", "final", "ground",
+	"clearance", "departure", "control", "radio",
+}
+
+var atnDirections = []string{
+	"left", "right", "either side", "north", "south", "east", "west",
+	"north east", "north west", "south east", "south west",
+}
+
+// ATNPDU is a decoded ATN B1 CPDLC APDU.
+type ATNPDU struct {
+	Type        string   // One of the atnPDU* values.
+	AbortReason string   // Abort PDUs only.
+	Message     *Message // Nil for aborts and for a start without a message.
+}
+
+// ATNDecoder decodes ATN B1 CPDLC APDUs.  It shares the FANS-1/A helpers for
+// the encodings both message sets have in common.
+type ATNDecoder struct {
+	Decoder
+}
+
+// NewATNDecoder creates a new ATN B1 CPDLC decoder.
+func NewATNDecoder(data []byte, direction MessageDirection) *ATNDecoder {
+	return &ATNDecoder{Decoder: Decoder{br: NewBitReader(data), direction: direction}}
+}
+
+// Decode decodes the APDU.  When the CPDLC message itself fails to decode
+// part way, the PDU is returned together with the error, holding the header
+// and the elements read so far.
+func (d *ATNDecoder) Decode() (*ATNPDU, error) {
+	_ = d.br.SetOffset(0)
+
+	// Both PDU CHOICEs are extensible: six ground and four aircraft
+	// alternatives.
+	root := 4
+	if d.direction == DirectionUplink {
+		root = 6
+	}
+	choice, ext, err := d.readChoice(root)
+	if err != nil {
+		return nil, fmt.Errorf("pdu: %w", err)
+	}
+	if ext {
+		return nil, fmt.Errorf("pdu: unknown extension %d", choice)
+	}
+
+	pdu := &ATNPDU{}
+	switch {
+	case choice == 0:
+		pdu.Type = atnPDUUserAbort
+		pdu.AbortReason, err = d.readEnumerated(atnUserAbortReasons)
+	case choice == 1:
+		pdu.Type = atnPDUProviderAbort
+		pdu.AbortReason, err = d.readEnumerated(atnProviderAbortReasons)
+	case choice == 2 && d.direction == DirectionDownlink:
+		// ProtectedStartDownMessage: mode DEFAULT cpdlc, then the message.
+		pdu.Type = atnPDUStart
+		var hasMode bool
+		if hasMode, err = d.br.ReadBit(); err == nil && hasMode {
+			_, err = d.br.ReadConstrainedInt(0, 1)
+		}
+		if err == nil {
+			pdu.Message, err = d.decodeProtectedMessage()
+		}
+	case choice == 2 || choice == 3:
+		pdu.Type = atnPDUStart
+		if choice == 3 {
+			pdu.Type = atnPDUSend
+		}
+		pdu.Message, err = d.decodeProtectedMessage()
+	case choice == 4:
+		pdu.Type = atnPDUForward
+	case choice == 5:
+		pdu.Type = atnPDUForwardResponse
+	}
+	return pdu, err
+}
+
+// decodeProtectedMessage decodes a ProtectedUplinkMessage or
+// ProtectedDownlinkMessage and the CPDLC message it carries.  The integrity
+// check is not verified.
+func (d *ATNDecoder) decodeProtectedMessage() (*Message, error) {
+	if _, err := d.br.ReadBit(); err != nil { // Extension bit.
+		return nil, err
+	}
+	hasAlgorithm, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	hasMessage, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if hasAlgorithm {
+		n, err := d.br.ReadLength()
+		if err != nil {
+			return nil, fmt.Errorf("algorithm identifier: %w", err)
+		}
+		if err := d.br.SetOffset(d.br.Offset() + n*8); err != nil {
+			return nil, fmt.Errorf("algorithm identifier: %w", ErrInsufficientBits)
+		}
+	}
+	if !hasMessage {
+		return nil, nil
+	}
+
+	// The message is a BIT STRING holding the PER encoding of the
+	// ATCUplinkMessage/ATCDownlinkMessage, padded to whole octets.
+	nbits, err := d.br.ReadLength()
+	if err != nil {
+		return nil, fmt.Errorf("message length: %w", err)
+	}
+	start := d.br.Offset()
+	if nbits > d.br.Remaining() {
+		return nil, fmt.Errorf("message length: %w", ErrInsufficientBits)
+	}
+	msg, err := d.decodeATCMessage()
+	if err != nil {
+		return msg, err
+	}
+	if d.br.Offset() > start+nbits {
+		return msg, errors.New("message overruns its bit string")
+	}
+	return msg, nil
+}
+
+// decodeATCMessage decodes an ATCUplinkMessage or ATCDownlinkMessage.
+func (d *ATNDecoder) decodeATCMessage() (*Message, error) {
+	msg := &Message{Direction: d.direction}
+
+	header, err := d.decodeATNHeader()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	msg.Header = *header
+
+	hasConstrained, err := d.br.ReadBit()
+	if err != nil {
+		return msg, err
+	}
+	count, err := d.br.ReadConstrainedInt(1, 5)
+	if err != nil {
+		return msg, fmt.Errorf("element count: %w", err)
+	}
+	for i := 0; i < count; i++ {
+		elem, err := d.decodeATNElement()
+		if err != nil {
+			return msg, fmt.Errorf("element[%d]: %w", i, err)
+		}
+		msg.Elements = append(msg.Elements, *elem)
+	}
+	if hasConstrained {
+		return msg, errors.New("route clearance data not decoded")
+	}
+	return msg, nil
+}
+
+// decodeATNHeader decodes an ATCMessageHeader.  Unlike FANS-1/A the date and
+// time are mandatory and a logical acknowledgement requirement follows.
+func (d *ATNDecoder) decodeATNHeader() (*MessageHeader, error) {
+	header := &MessageHeader{}
+
+	hasRef, err := d.br.ReadBit()
+	if err != nil {
+		return nil, fmt.Errorf("hasRef: %w", err)
+	}
+	hasLogicalAck, err := d.br.ReadBit()
+	if err != nil {
+		return nil, fmt.Errorf("hasLogicalAck: %w", err)
+	}
+
+	if header.MsgID, err = d.br.ReadConstrainedInt(0, 63); err != nil {
+		return nil, fmt.Errorf("msgID: %w", err)
+	}
+	if hasRef {
+		ref, err := d.br.ReadConstrainedInt(0, 63)
+		if err != nil {
+			return nil, fmt.Errorf("msgRef: %w", err)
+		}
+		header.MsgRef = &ref
+	}
+
+	// DateTimeGroup: year 1996-2095, month, day, then hh:mm:ss.
+	year, err := d.br.ReadConstrainedInt(1996, 2095)
+	if err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+	month, err := d.br.ReadConstrainedInt(1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+	day, err := d.br.ReadConstrainedInt(1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("date: %w", err)
+	}
+	header.Date = fmt.Sprintf("%04d-%02d-%02d", year, month, day)
+	if header.Timestamp, err = d.decodeHeaderTimestamp(); err != nil {
+		return nil, fmt.Errorf("timestamp: %w", err)
+	}
+
+	// LogicalAck ::= ENUMERATED { required, notRequired } DEFAULT required.
+	header.LogicalAck = "required"
+	if hasLogicalAck {
+		v, err := d.br.ReadConstrainedInt(0, 1)
+		if err != nil {
+			return nil, fmt.Errorf("logicalAck: %w", err)
+		}
+		if v == 1 {
+			header.LogicalAck = "not_required"
+		}
+	}
+	return header, nil
+}
+
+// decodeATNElement decodes a single uplink or downlink message element.
+func (d *ATNDecoder) decodeATNElement() (*MessageElement, error) {
+	root := atnDownlinkElements
+	if d.direction == DirectionUplink {
+		root = atnUplinkElements
+	}
+	id, ext, err := d.readChoice(root)
+	if err != nil {
+		return nil, fmt.Errorf("element ID: %w", err)
+	}
+
+	elem := &MessageElement{ID: id}
+	var ok bool
+	if d.direction == DirectionUplink {
+		elem.Label, ok = atnUplinkLabels[id]
+	} else {
+		elem.Label, ok = atnDownlinkLabels[id]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s is not in the LINK2000+ message set", atnElementName(d.direction, id))
+	}
+
+	if ext {
+		// Extension additions are open types; the ones we know carry NULL.
+		n, err := d.br.ReadLength()
+		if err == nil {
+			err = d.br.SetOffset(d.br.Offset() + n*8)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("element data: %w", ErrInsufficientBits)
+		}
+	} else {
+		if d.direction == DirectionUplink {
+			elem.Data, err = d.decodeATNUplinkData(id)
+		} else {
+			elem.Data, err = d.decodeATNDownlinkData(id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s data: %w", atnElementName(d.direction, id), err)
+		}
+	}
+
+	elem.Text = d.formatATNElementText(elem)
+	return elem, nil
+}
+
+func atnElementName(direction MessageDirection, id int) string {
+	if direction == DirectionUplink {
+		return "uM" + strconv.Itoa(id)
+	}
+	return "dM" + strconv.Itoa(id)
+}
+
+// decodeATNUplinkData decodes the parameters of a LINK2000+ uplink element.
+func (d *ATNDecoder) decodeATNUplinkData(id int) (interface{}, error) {
+	switch id {
+	case 19, 20, 23, 148:
+		return d.decodeLevel()
+	case 26, 28:
+		return d.decodeLevelTime()
+	case 27, 29:
+		return d.decodeLevelPosition()
+	case 46, 47, 48:
+		return d.decodePositionLevel()
+	case 51, 52, 53:
+		return d.decodePositionTime(false)
+	case 54:
+		return d.decodePositionTime(true)
+	case 55:
+		return d.decodePositionSpeed()
+	case 74:
+		return d.decodeATNPosition()
+	case 94, 215:
+		return d.decodeDirectionDegrees()
+	case 106, 108, 109:
+		return d.decodeATNSpeed()
+	case 117, 120:
+		return d.decodeATNUnitNameFrequency()
+	case 123:
+		return d.decodeBeaconCode()
+	case 157:
+		return d.decodeATNFrequency()
+	case 159:
+		return d.decodeATNErrorInfo()
+	case 160:
+		return d.decodeATNFacility()
+	case 171, 172, 173, 174:
+		return d.decodeATNVerticalRate()
+	case 183, 196, 203, 205:
+		return d.decodeFreeText()
+	case 190:
+		return d.decodeATNDegrees()
+	}
+	return nil, nil
+}
+
+// decodeATNDownlinkData decodes the parameters of a LINK2000+ downlink element.
+func (d *ATNDecoder) decodeATNDownlinkData(id int) (interface{}, error) {
+	switch id {
+	case 6, 9, 10, 32, 82, 106:
+		return d.decodeLevel()
+	case 18:
+		return d.decodeATNSpeed()
+	case 22:
+		return d.decodeATNPosition()
+	case 62:
+		return d.decodeATNErrorInfo()
+	case 81:
+		return d.decodeLevelTime()
+	case 89:
+		return d.decodeATNUnitNameFrequency()
+	case 98:
+		return d.decodeFreeText()
+	case 109:
+		return d.decodeTime()
+	}
+	return nil, nil
+}
+
+// readChoice reads the index of an extensible CHOICE with root alternatives
+// 0..root-1.  An extension addition n is returned as root+n.
+func (d *ATNDecoder) readChoice(root int) (int, bool, error) {
+	ext, err := d.br.ReadBit()
+	if err != nil {
+		return 0, false, err
+	}
+	if ext {
+		n, err := d.br.ReadNormallySmallNonNegative()
+		return root + n, true, err
+	}
+	v, err := d.br.ReadConstrainedInt(0, root-1)
+	return v, false, err
+}
+
+// readEnumerated reads an extensible ENUMERATED with the given root values.
+func (d *ATNDecoder) readEnumerated(values []string) (string, error) {
+	ext, err := d.br.ReadBit()
+	if err != nil {
+		return "", err
+	}
+	if ext {
+		n, err := d.br.ReadNormallySmallNonNegative()
+		return fmt.Sprintf("extension-%d", n), err
+	}
+	v, err := d.br.ReadConstrainedInt(0, len(values)-1)
+	if err != nil {
+		return "", err
+	}
+	return values[v], nil
+}
+
+// decodeLevel decodes a Level: a single level as *Altitude, or a block level
+// as a map holding altitude1 and altitude2.
+func (d *ATNDecoder) decodeLevel() (interface{}, error) {
+	block, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	lower, err := d.decodeLevelType()
+	if err != nil {
+		return nil, err
+	}
+	if !block {
+		return lower, nil
+	}
+	upper, err := d.decodeLevelType()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"altitude1": lower, "altitude2": upper}, nil
+}
+
+func (d *ATNDecoder) decodeLevelType() (*Altitude, error) {
+	// LevelType is a CHOICE with 4 alternatives (0-3), 2 bits.
+	// 0: levelFeet (-60..7000, ft x10)
+	// 1: levelMeters (-30..25000, m)
+	// 2: levelFlightLevel (30..700)
+	// 3: levelFlightLevelMetric (100..2500, m x10)
+	choice, err := d.br.ReadConstrainedInt(0, 3)
+	if err != nil {
+		return nil, err
+	}
+	alt := &Altitude{}
+	switch choice {
+	case 0:
+		v, err := d.br.ReadConstrainedInt(-60, 7000)
+		if err != nil {
+			return nil, err
+		}
+		alt.Type, alt.Value = "feet", v*10
+	case 1:
+		v, err := d.br.ReadConstrainedInt(-30, 25000)
+		if err != nil {
+			return nil, err
+		}
+		alt.Type, alt.Value = "meters", v
+	case 2:
+		v, err := d.br.ReadConstrainedInt(30, 700)
+		if err != nil {
+			return nil, err
+		}
+		alt.Type, alt.Value = "flight_level", v
+	case 3:
+		v, err := d.br.ReadConstrainedInt(100, 2500)
+		if err != nil {
+			return nil, err
+		}
+		alt.Type, alt.Value = "flight_level_metric", v
+	}
+	return alt, nil
+}
+
+// withLevel adds a decoded Level to data under the keys formatElementText
+// substitutes.
+func withLevel(data map[string]interface{}, level interface{}) map[string]interface{} {
+	if block, ok := level.(map[string]interface{}); ok {
+		data["altitude1"] = block["altitude1"]
+		data["altitude2"] = block["altitude2"]
+		return data
+	}
+	alt := level.(*Altitude)
+	data["altitude"] = alt
+	return withPrimaryAltitudeFields(data, alt)
+}
+
+func (d *ATNDecoder) decodeLevelTime() (map[string]interface{}, error) {
+	level, err := d.decodeLevel()
+	if err != nil {
+		return nil, err
+	}
+	t, err := d.decodeTime()
+	if err != nil {
+		return nil, err
+	}
+	return withLevel(map[string]interface{}{"time": t}, level), nil
+}
+
+func (d *ATNDecoder) decodeLevelPosition() (map[string]interface{}, error) {
+	level, err := d.decodeLevel()
+	if err != nil {
+		return nil, err
+	}
+	pos, err := d.decodeATNPosition()
+	if err != nil {
+		return nil, err
+	}
+	return withLevel(map[string]interface{}{"position": pos}, level), nil
+}
+
+func (d *ATNDecoder) decodePositionLevel() (map[string]interface{}, error) {
+	pos, err := d.decodeATNPosition()
+	if err != nil {
+		return nil, err
+	}
+	level, err := d.decodeLevel()
+	if err != nil {
+		return nil, err
+	}
+	return withLevel(map[string]interface{}{"position": pos}, level), nil
+}
+
+// decodePositionTime decodes PositionTime, or PositionTimeTime (uM54) when
+// between is set.
+func (d *ATNDecoder) decodePositionTime(between bool) (map[string]interface{}, error) {
+	pos, err := d.decodeATNPosition()
+	if err != nil {
+		return nil, err
+	}
+	t, err := d.decodeTime()
+	if err != nil {
+		return nil, err
+	}
+	if !between {
+		return map[string]interface{}{"position": pos, "time": t}, nil
+	}
+	t2, err := d.decodeTime()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"position": pos, "time1": t, "time2": t2}, nil
+}
+
+func (d *ATNDecoder) decodePositionSpeed() (map[string]interface{}, error) {
+	pos, err := d.decodeATNPosition()
+	if err != nil {
+		return nil, err
+	}
+	spd, err := d.decodeATNSpeed()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"position": pos, "speed1": spd}, nil
+}
+
+func (d *ATNDecoder) decodeATNPosition() (*Position, error) {
+	// Position is a CHOICE with 5 alternatives (0-4), 3 bits.
+	// 0: fixName, 1: navaid, 2: airport, 3: latitudeLongitude,
+	// 4: placeBearingDistance (not decoded).
+	choice, err := d.br.ReadConstrainedInt(0, 4)
+	if err != nil {
+		return nil, err
+	}
+	pos := &Position{}
+	switch choice {
+	case 0:
+		pos.Type = "fix"
+		pos.Name, err = d.decodeFixName()
+	case 1:
+		pos.Type = "navaid"
+		pos.Name, err = d.decodeNavaid()
+	case 2:
+		pos.Type = "airport"
+		pos.Name, err = d.decodeAirport()
+	case 3:
+		pos.Type = "latlon"
+		pos.Latitude, pos.Longitude, err = d.decodeATNLatLon()
+	default:
+		return nil, errors.New("place/bearing/distance position not decoded")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pos, nil
+}
+
+// decodeATNLatLon decodes a LatitudeLongitude, in which either coordinate
+// may be absent.
+func (d *ATNDecoder) decodeATNLatLon() (*float64, *float64, error) {
+	hasLat, err := d.br.ReadBit()
+	if err != nil {
+		return nil, nil, err
+	}
+	hasLon, err := d.br.ReadBit()
+	if err != nil {
+		return nil, nil, err
+	}
+	var lat, lon *float64
+	if hasLat {
+		v, err := d.decodeATNCoordinate(90)
+		if err != nil {
+			return nil, nil, err
+		}
+		lat = &v
+	}
+	if hasLon {
+		v, err := d.decodeATNCoordinate(180)
+		if err != nil {
+			return nil, nil, err
+		}
+		lon = &v
+	}
+	return lat, lon, nil
+}
+
+// decodeATNCoordinate decodes a Latitude or Longitude (maxDegrees 90 or 180).
+// The value is a CHOICE of degrees (units of 0.001), degrees and minutes
+// (units of 0.01), or degrees, minutes and seconds, then north/east (0) or
+// south/west (1).
+func (d *ATNDecoder) decodeATNCoordinate(maxDegrees int) (float64, error) {
+	choice, err := d.br.ReadConstrainedInt(0, 2)
+	if err != nil {
+		return 0, err
+	}
+	var value float64
+	switch choice {
+	case 0:
+		v, err := d.br.ReadConstrainedInt(0, maxDegrees*1000)
+		if err != nil {
+			return 0, err
+		}
+		value = float64(v) / 1000
+	case 1, 2:
+		deg, err := d.br.ReadConstrainedInt(0, maxDegrees-1)
+		if err != nil {
+			return 0, err
+		}
+		if choice == 1 {
+			minutes, err := d.br.ReadConstrainedInt(0, 5999)
+			if err != nil {
+				return 0, err
+			}
+			value = float64(deg) + float64(minutes)/100/60
+			break
+		}
+		minutes, err := d.br.ReadConstrainedInt(0, 59)
+		if err != nil {
+			return 0, err
+		}
+		seconds, err := d.br.ReadConstrainedInt(0, 59)
+		if err != nil {
+			return 0, err
+		}
+		value = float64(deg) + float64(minutes)/60 + float64(seconds)/3600
+	default:
+		return 0, errors.New("invalid coordinate type")
+	}
+	direction, err := d.br.ReadConstrainedInt(0, 1)
+	if err != nil {
+		return 0, err
+	}
+	if direction == 1 {
+		value = -value
+	}
+	return value, nil
+}
+
+func (d *ATNDecoder) decodeATNSpeed() (*Speed, error) {
+	// Speed is a CHOICE with 7 alternatives (0-6), 3 bits.
+	// 0: speedIndicated (0..400 kt)        1: speedIndicatedMetric (0..800 km/h)
+	// 2: speedTrue (0..2000 kt)            3: speedTrueMetric (0..4000 km/h)
+	// 4: speedGround (-50..2000 kt)        5: speedGroundMetric (-100..4000 km/h)
+	// 6: speedMach (500..4000, Mach/1000)
+	choice, err := d.br.ReadConstrainedInt(0, 6)
+	if err != nil {
+		return nil, err
+	}
+	ranges := [][2]int{{0, 400}, {0, 800}, {0, 2000}, {0, 4000}, {-50, 2000}, {-100, 4000}, {500, 4000}}
+	v, err := d.br.ReadConstrainedInt(ranges[choice][0], ranges[choice][1])
+	if err != nil {
+		return nil, err
+	}
+	switch choice {
+	case 0, 2, 4:
+		return &Speed{Type: "knots", Value: v}, nil
+	case 1, 3, 5:
+		return &Speed{Type: "kph", Value: v}, nil
+	default:
+		return &Speed{Type: "mach", Value: v / 10}, nil // Stored as Mach x100.
+	}
+}
+
+func (d *ATNDecoder) decodeATNDegrees() (*Degrees, error) {
+	// Degrees is a CHOICE of degreesMagnetic and degreesTrue, both 1..360.
+	trueNorth, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	v, err := d.br.ReadConstrainedInt(1, 360)
+	if err != nil {
+		return nil, err
+	}
+	return &Degrees{Magnetic: !trueNorth, Value: v}, nil
+}
+
+func (d *ATNDecoder) decodeDirectionDegrees() (map[string]interface{}, error) {
+	dir, err := d.br.ReadConstrainedInt(0, len(atnDirections)-1)
+	if err != nil {
+		return nil, err
+	}
+	deg, err := d.decodeATNDegrees()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"direction": atnDirections[dir], "degrees": deg}, nil
+}
+
+func (d *ATNDecoder) decodeATNFrequency() (*Frequency, error) {
+	// Frequency is a CHOICE with 4 alternatives (0-3), 2 bits.
+	// 0: frequencyhf (2850..28000 kHz)
+	// 1: frequencyvhf (23600..27398, units of 5 kHz)
+	// 2: frequencyuhf (9000..15999, units of 25 kHz)
+	// 3: frequencysatchannel (NumericString SIZE(12))
+	choice, err := d.br.ReadConstrainedInt(0, 3)
+	if err != nil {
+		return nil, err
+	}
+	freq := &Frequency{}
+	switch choice {
+	case 0:
+		v, err := d.br.ReadConstrainedInt(2850, 28000)
+		if err != nil {
+			return nil, err
+		}
+		freq.Type, freq.Value = "hf", float64(v)
+	case 1:
+		v, err := d.br.ReadConstrainedInt(23600, 27398)
+		if err != nil {
+			return nil, err
+		}
+		freq.Type, freq.Value = "vhf", float64(v)*5/1000
+	case 2:
+		v, err := d.br.ReadConstrainedInt(9000, 15999)
+		if err != nil {
+			return nil, err
+		}
+		freq.Type, freq.Value = "uhf", float64(v)*25/1000
+	case 3:
+		// NumericString characters are 4-bit indexes into " 0123456789".
+		channel := 0
+		for i := 0; i < 12; i++ {
+			c, err := d.br.ReadConstrainedInt(0, 10)
+			if err != nil {
+				return nil, err
+			}
+			if c > 0 {
+				channel = channel*10 + c - 1
+			}
+		}
+		freq.Type, freq.Value = "satcom", float64(channel)
+	}
+	return freq, nil
+}
+
+// decodeATNUnitNameFrequency decodes a UnitNameFrequency into the same map
+// keys as the FANS-1/A ICAOUnitNameFrequency.
+func (d *ATNDecoder) decodeATNUnitNameFrequency() (map[string]interface{}, error) {
+	// UnitName ::= SEQUENCE {
+	//   facilityDesignation IA5String (SIZE (4..8)),
+	//   facilityName        IA5String (SIZE (3..18)) OPTIONAL,
+	//   facilityFunction    ENUMERATED { center .. radio } }
+	hasName, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	designation, err := d.decodeFacilityDesignation()
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{"unit": designation}
+	if hasName {
+		n, err := d.br.ReadConstrainedInt(3, 18)
+		if err != nil {
+			return nil, err
+		}
+		name, err := d.decodeIA5String(n)
+		if err != nil {
+			return nil, err
+		}
+		data["unit_name"] = name
+	}
+	fn, err := d.br.ReadConstrainedInt(0, len(atnFacilityFunctions)-1)
+	if err != nil {
+		return nil, err
+	}
+	data["unit_type"] = atnFacilityFunctions[fn]
+
+	freq, err := d.decodeATNFrequency()
+	if err != nil {
+		return nil, err
+	}
+	data["frequency"] = freq
+	return data, nil
+}
+
+func (d *ATNDecoder) decodeFacilityDesignation() (string, error) {
+	n, err := d.br.ReadConstrainedInt(4, 8)
+	if err != nil {
+		return "", err
+	}
+	return d.decodeIA5String(n)
+}
+
+// decodeATNFacility decodes a Facility: noFacility, or a facility designation.
+func (d *ATNDecoder) decodeATNFacility() (string, error) {
+	designated, err := d.br.ReadBit()
+	if err != nil || !designated {
+		return "", err
+	}
+	return d.decodeFacilityDesignation()
+}
+
+func (d *ATNDecoder) decodeATNErrorInfo() (*ErrorInfo, error) {
+	ext, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if ext {
+		n, err := d.br.ReadNormallySmallNonNegative()
+		if err != nil {
+			return nil, err
+		}
+		return &ErrorInfo{Code: len(atnErrorInformation) + n}, nil
+	}
+	code, err := d.br.ReadConstrainedInt(0, len(atnErrorInformation)-1)
+	if err != nil {
+		return nil, err
+	}
+	return &ErrorInfo{Code: code, Desc: atnErrorInformation[code]}, nil
+}
+
+func (d *ATNDecoder) decodeATNVerticalRate() (*VerticalRate, error) {
+	// VerticalRate is a CHOICE of verticalRateEnglish (0..3000, ft/min x10)
+	// and verticalRateMetric (0..1000, m/min x10).
+	metric, err := d.br.ReadBit()
+	if err != nil {
+		return nil, err
+	}
+	if !metric {
+		v, err := d.br.ReadConstrainedInt(0, 3000)
+		if err != nil {
+			return nil, err
+		}
+		return &VerticalRate{Value: v * 10}, nil
+	}
+	v, err := d.br.ReadConstrainedInt(0, 1000)
+	if err != nil {
+		return nil, err
+	}
+	return &VerticalRate{Value: int(float64(v) * 10 * 3.28084)}, nil
+}
+
+// formatATNElementText fills in the parameters formatElementText does not
+// know about, block levels and the two times of uM54, then hands over.
+func (d *ATNDecoder) formatATNElementText(elem *MessageElement) string {
+	data, ok := elem.Data.(map[string]interface{})
+	if !ok {
+		return d.formatElementText(elem)
+	}
+	label := elem.Label
+	lower, okLower := data["altitude1"].(*Altitude)
+	upper, okUpper := data["altitude2"].(*Altitude)
+	if okLower && okUpper {
+		label = substituteFirst(label, "[altitude]", lower.String()+" TO "+upper.String())
+	}
+	if t, ok := data["time1"].(*Time); ok {
+		label = substituteFirst(label, "[time]", t.String())
+	}
+	if t, ok := data["time2"].(*Time); ok {
+		label = substituteFirst(label, "[time]", t.String())
+	}
+	return d.formatElementText(&MessageElement{ID: elem.ID, Label: label, Data: elem.Data})
+}
+
+// formatATNPDU renders pdu in the libacars style of formatMessage.
+func formatATNPDU(pdu *ATNPDU) string {
+	if pdu.Message != nil && len(pdu.Message.Elements) > 0 {
+		return formatMessageAs("ATN B1 CPDLC MESSAGE:", pdu.Message)
+	}
+	switch pdu.Type {
+	case atnPDUUserAbort:
+		return "ATN B1 CPDLC MESSAGE:\n CPDLC USER ABORT: " + pdu.AbortReason
+	case atnPDUProviderAbort:
+		return "ATN B1 CPDLC MESSAGE:\n CPDLC PROVIDER ABORT: " + pdu.AbortReason
+	case atnPDUStart:
+		return "ATN B1 CPDLC MESSAGE:\n CPDLC START"
+	}
+	return ""
+}
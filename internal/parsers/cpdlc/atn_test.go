@@ -0,0 +1,299 @@
+package cpdlc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+)
+
+// bitWriter builds unaligned PER test vectors.
+type bitWriter struct {
+	buf []byte
+	n   int
+}
+
+func (w *bitWriter) put(v, nbits int) {
+	for i := nbits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if v>>i&1 == 1 {
+			w.buf[w.n/8] |= 0x80 >> (w.n % 8)
+		}
+		w.n++
+	}
+}
+
+func (w *bitWriter) constrained(v, lower, upper int) { w.put(v-lower, bitsNeeded(upper-lower)) }
+
+func (w *bitWriter) ia5(s string) {
+	for _, c := range []byte(s) {
+		w.put(int(c), 7)
+	}
+}
+
+// bitString appends inner as a BIT STRING.
+func (w *bitWriter) bitString(inner *bitWriter) {
+	if inner.n < 128 {
+		w.put(inner.n, 8)
+	} else {
+		w.put(0x8000|inner.n, 16)
+	}
+	for i := 0; i < inner.n; i++ {
+		w.put(int(inner.buf[i/8]>>(7-i%8)&1), 1)
+	}
+}
+
+// atnHeader writes an ATCMessageHeader dated 2026-10-16 14:05:30.
+func atnHeader(w *bitWriter, msgID int, ref int, logicalAck bool) {
+	w.put(boolBit(ref >= 0), 1)
+	w.put(boolBit(!logicalAck), 1)
+	w.constrained(msgID, 0, 63)
+	if ref >= 0 {
+		w.constrained(ref, 0, 63)
+	}
+	w.constrained(2026, 1996, 2095)
+	w.constrained(10, 1, 12)
+	w.constrained(16, 1, 31)
+	w.constrained(14, 0, 23)
+	w.constrained(5, 0, 59)
+	w.constrained(30, 0, 59)
+	if !logicalAck {
+		w.put(1, 1) // notRequired
+	}
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// atnSend wraps an ATC message in a send PDU with no algorithm identifier.
+func atnSend(dir MessageDirection, inner *bitWriter) []byte {
+	w := &bitWriter{}
+	w.put(0, 1)
+	if dir == DirectionUplink {
+		w.constrained(3, 0, 5)
+	} else {
+		w.constrained(3, 0, 3)
+	}
+	w.put(0b001, 3) // no extension, no algorithm, message present
+	w.bitString(inner)
+	return w.buf
+}
+
+func TestATNDecodeUplink(t *testing.T) {
+	msg := &bitWriter{}
+	atnHeader(msg, 12, 5, true)
+	msg.put(0, 1)            // no constrained data
+	msg.constrained(2, 1, 5) // two elements
+	msg.put(0, 1)            // uM20 CLIMB TO
+	msg.constrained(20, 0, 236)
+	msg.put(0, 1)            // single level
+	msg.constrained(2, 0, 3) // flight level
+	msg.constrained(350, 30, 700)
+	msg.put(0, 1) // uM117 CONTACT
+	msg.constrained(117, 0, 236)
+	msg.put(0, 1) // no facility name
+	msg.constrained(4, 4, 8)
+	msg.ia5("EDYY")
+	msg.constrained(0, 0, 8) // center
+	msg.constrained(1, 0, 3) // VHF
+	msg.constrained(132355/5, 23600, 27398)
+
+	pdu, err := NewATNDecoder(atnSend(DirectionUplink, msg), DirectionUplink).Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if pdu.Type != "send" || pdu.Message == nil {
+		t.Fatalf("pdu = %+v", pdu)
+	}
+	h := pdu.Message.Header
+	if h.MsgID != 12 || h.MsgRef == nil || *h.MsgRef != 5 || h.Date != "2026-10-16" ||
+		h.Timestamp.String() != "14:05:30" || h.LogicalAck != "required" {
+		t.Fatalf("header = %+v", h)
+	}
+	var texts []string
+	for _, e := range pdu.Message.Elements {
+		texts = append(texts, e.Text)
+	}
+	if got := strings.Join(texts, " / "); got != "CLIMB TO FL350 / CONTACT EDYY 132.355 MHz" {
+		t.Fatalf("elements = %q", got)
+	}
+	if !strings.HasPrefix(formatATNPDU(pdu), "ATN B1 CPDLC MESSAGE:\n CPDLC UPLINK MESSAGE:") {
+		t.Fatalf("formatted = %q", formatATNPDU(pdu))
+	}
+}
+
+func TestATNDecodeDownlink(t *testing.T) {
+	msg := &bitWriter{}
+	atnHeader(msg, 3, 12, false)
+	msg.put(0, 1)
+	msg.constrained(2, 1, 5)
+	msg.put(0, 1) // dM100 LOGICAL ACKNOWLEDGEMENT
+	msg.constrained(100, 0, 113)
+	msg.put(0, 1) // dM6 REQUEST, block level
+	msg.constrained(6, 0, 113)
+	msg.put(1, 1)
+	msg.constrained(2, 0, 3)
+	msg.constrained(330, 30, 700)
+	msg.constrained(2, 0, 3)
+	msg.constrained(350, 30, 700)
+
+	pdu, err := NewATNDecoder(atnSend(DirectionDownlink, msg), DirectionDownlink).Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	els := pdu.Message.Elements
+	if pdu.Message.Header.LogicalAck != "not_required" || len(els) != 2 ||
+		els[0].Text != "LOGICAL ACKNOWLEDGEMENT" || els[1].Text != "REQUEST FL330 TO FL350" {
+		t.Fatalf("message = %+v", pdu.Message)
+	}
+}
+
+func TestATNDecodeStartAndAbort(t *testing.T) {
+	// CPDLC-start from the aircraft, without a message.
+	w := &bitWriter{}
+	w.put(0, 1)
+	w.constrained(2, 0, 3)
+	w.put(0, 1)     // default mode
+	w.put(0b000, 3) // no extension, no algorithm, no message
+	pdu, err := NewATNDecoder(w.buf, DirectionDownlink).Decode()
+	if err != nil || pdu.Type != "start" || pdu.Message != nil || formatATNPDU(pdu) != "ATN B1 CPDLC MESSAGE:\n CPDLC START" {
+		t.Fatalf("start: %+v, %v", pdu, err)
+	}
+
+	w = &bitWriter{}
+	w.put(0, 1)
+	w.constrained(0, 0, 5)
+	w.put(0, 1)
+	w.constrained(5, 0, 12)
+	pdu, err = NewATNDecoder(w.buf, DirectionUplink).Decode()
+	if err != nil || pdu.Type != "user_abort" || pdu.AbortReason != "commanded-termination" {
+		t.Fatalf("abort: %+v, %v", pdu, err)
+	}
+}
+
+func TestATNDecodeUnsupportedElement(t *testing.T) {
+	msg := &bitWriter{}
+	atnHeader(msg, 7, -1, true)
+	msg.put(0, 1)
+	msg.constrained(1, 1, 5)
+	msg.put(0, 1) // uM80, outside LINK2000+
+	msg.constrained(80, 0, 236)
+
+	pdu, err := NewATNDecoder(atnSend(DirectionUplink, msg), DirectionUplink).Decode()
+	if err == nil || !strings.Contains(err.Error(), "uM80") {
+		t.Fatalf("expected an uM80 error, got %v", err)
+	}
+	if pdu == nil || pdu.Message == nil || pdu.Message.Header.MsgID != 7 {
+		t.Fatalf("header not kept: %+v", pdu)
+	}
+}
+
+func TestATNParser(t *testing.T) {
+	msg := &bitWriter{}
+	atnHeader(msg, 1, 0, true)
+	msg.put(0, 1)
+	msg.constrained(1, 1, 5)
+	msg.put(0, 1) // dM0 WILCO
+	msg.constrained(0, 0, 113)
+	payload := strings.ToUpper(hex.EncodeToString(atnSend(DirectionDownlink, msg)))
+
+	p := &ATNParser{}
+	text := "ATN CPDLC DOWNLINK " + payload
+	if !p.QuickCheck(text) {
+		t.Fatal("QuickCheck rejected a synthetic message")
+	}
+	r, ok := p.Parse(&acars.Message{ID: 9, Label: "ATNCPDLC", Tail: ".D-AIXL", Text: text}).(*Result)
+	if !ok || r.Type() != "cpdlc" || r.MessageType != "atn_cpdlc" || r.Direction != "downlink" ||
+		r.PDUType != "send" || r.Registration != "D-AIXL" || r.Error != "" {
+		t.Fatalf("Parse = %+v", r)
+	}
+	if len(r.Elements) != 1 || r.Elements[0].Text != "WILCO" || !strings.Contains(r.FormattedText, "DATE: 2026-10-16") {
+		t.Fatalf("elements = %+v, formatted %q", r.Elements, r.FormattedText)
+	}
+
+	if r := p.Parse(&acars.Message{Label: "ATNCPDLC", Text: "ATN CPDLC SIDEWAYS 00"}); r != nil {
+		t.Fatalf("Parse accepted a bad direction: %+v", r)
+	}
+}
+
+// atnReferenceFrames are APDUs given as octets, checked without bitWriter so
+// the decoder is not only tested against its own reading of the ASN.1.  They
+// are hand-encoded, not captured: none has been checked against libacars.
+// The synthetic frame in internal/decode/testdata/synthetic_atn_cpdlc.json
+// carries the first one.  Frames captured from live traffic belong here,
+// with the elements libacars decodes from them.
+var atnReferenceFrames = []struct {
+	name      string
+	direction MessageDirection
+	hex       string
+	header    string // msg ID, date and time
+	elements  []string
+}{
+	{
+		// Encoded apart from bitWriter, from the ASN.1 of ICAO Doc 9705
+		// sub-volume II:
+		//   0 011 | 0 0 1   send, no algorithm ID, message present
+		//   01011000        message bit string length 88
+		//   0 0             no msg ref, logical ack default (required)
+		//   001100          msg ID 12
+		//   0011110 1001 01111 01001 101001 000111   2026-10-16 09:41:07
+		//   0 001           no constrained data, 2 elements
+		//   0 00010111 0 10 0011010010   uM23, single level, FL240
+		//   0 01101010 000 011111010     uM106, indicated speed, 250 kt
+		name:      "descend and speed uplink",
+		direction: DirectionUplink,
+		hex:       "32B0187A5E9A4710BA348D41F4",
+		header:    "12 2026-10-16 09:41:07",
+		elements:  []string{"DESCEND TO FL240", "MAINTAIN 250 kt"},
+	},
+	{
+		// The downlink of TestATNCPDLCSyntheticMessage in cmd/acars_parser:
+		//   0 11 | 0 0 1    send, no algorithm ID, message present
+		//   00111011        message bit string length 59
+		//   1 0 000001 000000   msg ref present, logical ack default, msg ID 1, ref 0
+		//   0011110 1001 01111 01110 000101 011110   2026-10-16 14:05:30
+		//   0 000 0 0000000     no constrained data, 1 element, dM0
+		name:      "wilco downlink",
+		direction: DirectionDownlink,
+		hex:       "64EE0403D2F70AF00000",
+		header:    "1 2026-10-16 14:05:30",
+		elements:  []string{"WILCO"},
+	},
+}
+
+func TestATNReferenceFrames(t *testing.T) {
+	for _, f := range atnReferenceFrames {
+		data, err := hex.DecodeString(f.hex)
+		if err != nil {
+			t.Fatalf("%s: %v", f.name, err)
+		}
+		pdu, err := NewATNDecoder(data, f.direction).Decode()
+		if err != nil {
+			t.Errorf("%s: Decode: %v", f.name, err)
+			continue
+		}
+		if pdu.Type != "send" || pdu.Message == nil {
+			t.Errorf("%s: pdu = %+v", f.name, pdu)
+			continue
+		}
+		h := pdu.Message.Header
+		if got := fmt.Sprintf("%d %s %s", h.MsgID, h.Date, h.Timestamp); got != f.header {
+			t.Errorf("%s: header = %q, want %q", f.name, got, f.header)
+		}
+		var texts []string
+		for _, e := range pdu.Message.Elements {
+			texts = append(texts, e.Text)
+		}
+		if got, want := strings.Join(texts, " / "), strings.Join(f.elements, " / "); got != want {
+			t.Errorf("%s: elements = %q, want %q", f.name, got, want)
+		}
+	}
+}
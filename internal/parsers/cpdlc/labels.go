@@ -289,3 +289,94 @@ func GetDownlinkLabel(msgID int) string {
 	}
 	return "(reserved)"
 }
+
+// ATN B1 uplink message labels: the LINK2000+ subset of the ICAO Doc 9705
+// message set.  Placeholders use the FANS-1/A names where the parameter is
+// rendered the same way, so [altitude] stands for an ATN level.
+var atnUplinkLabels = map[int]string{
+	0:   "UNABLE",
+	1:   "STANDBY",
+	3:   "ROGER",
+	4:   "AFFIRM",
+	5:   "NEGATIVE",
+	19:  "MAINTAIN [altitude]",
+	20:  "CLIMB TO [altitude]",
+	23:  "DESCEND TO [altitude]",
+	26:  "CLIMB TO REACH [altitude] BY [time]",
+	27:  "CLIMB TO REACH [altitude] BY [position]",
+	28:  "DESCEND TO REACH [altitude] BY [time]",
+	29:  "DESCEND TO REACH [altitude] BY [position]",
+	46:  "CROSS [position] AT [altitude]",
+	47:  "CROSS [position] AT OR ABOVE [altitude]",
+	48:  "CROSS [position] AT OR BELOW [altitude]",
+	51:  "CROSS [position] AT [time]",
+	52:  "CROSS [position] AT OR BEFORE [time]",
+	53:  "CROSS [position] AT OR AFTER [time]",
+	54:  "CROSS [position] BETWEEN [time] AND [time]",
+	55:  "CROSS [position] AT [speed]",
+	72:  "RESUME OWN NAVIGATION",
+	74:  "PROCEED DIRECT TO [position]",
+	94:  "TURN [direction] HEADING [degrees]",
+	96:  "CONTINUE PRESENT HEADING",
+	106: "MAINTAIN [speed]",
+	107: "MAINTAIN PRESENT SPEED",
+	108: "MAINTAIN [speed] OR GREATER",
+	109: "MAINTAIN [speed] OR LESS",
+	116: "RESUME NORMAL SPEED",
+	117: "CONTACT [icaounitname] [frequency]",
+	120: "MONITOR [icaounitname] [frequency]",
+	123: "SQUAWK [beaconcode]",
+	133: "REPORT PRESENT LEVEL",
+	148: "WHEN CAN YOU ACCEPT [altitude]",
+	157: "CHECK STUCK MICROPHONE [frequency]",
+	159: "ERROR [errorinformation]",
+	160: "NEXT DATA AUTHORITY [icaofacilitydesignation]",
+	162: "SERVICE UNAVAILABLE",
+	165: "THEN",
+	171: "CLIMB AT [verticalrate] MINIMUM",
+	172: "CLIMB AT [verticalrate] MAXIMUM",
+	173: "DESCEND AT [verticalrate] MINIMUM",
+	174: "DESCEND AT [verticalrate] MAXIMUM",
+	179: "SQUAWK IDENT",
+	183: "[freetext]",
+	190: "FLY HEADING [degrees]",
+	196: "[freetext]",
+	203: "[freetext]",
+	205: "[freetext]",
+	211: "REQUEST FORWARDED",
+	215: "TURN [direction] [degrees] DEGREES",
+	222: "NO SPEED RESTRICTION",
+	227: "LOGICAL ACKNOWLEDGEMENT",
+	231: "STATE PREFERRED LEVEL",
+	232: "STATE TOP OF DESCENT",
+	237: "REQUEST AGAIN WITH NEXT UNIT",
+}
+
+// ATN B1 downlink message labels (LINK2000+ subset).
+var atnDownlinkLabels = map[int]string{
+	0:   "WILCO",
+	1:   "UNABLE",
+	2:   "STANDBY",
+	3:   "ROGER",
+	4:   "AFFIRM",
+	5:   "NEGATIVE",
+	6:   "REQUEST [altitude]",
+	9:   "REQUEST CLIMB TO [altitude]",
+	10:  "REQUEST DESCENT TO [altitude]",
+	18:  "REQUEST [speed]",
+	22:  "REQUEST DIRECT TO [position]",
+	32:  "PRESENT LEVEL [altitude]",
+	62:  "ERROR [errorinformation]",
+	63:  "NOT CURRENT DATA AUTHORITY",
+	65:  "DUE TO WEATHER",
+	66:  "DUE TO AIRCRAFT PERFORMANCE",
+	81:  "WE CAN ACCEPT [altitude] AT [time]",
+	82:  "WE CANNOT ACCEPT [altitude]",
+	89:  "MONITORING [icaounitname] [frequency]",
+	98:  "[freetext]",
+	99:  "CURRENT DATA AUTHORITY",
+	100: "LOGICAL ACKNOWLEDGEMENT",
+	106: "PREFERRED LEVEL [altitude]",
+	107: "NOT AUTHORIZED NEXT DATA AUTHORITY",
+	109: "TOP OF DESCENT [time]",
+}
@@ -3,6 +3,7 @@ package cpdlc
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"strings"
 
 	"acars_parser/internal/acars"
@@ -25,7 +26,7 @@ const (
 type Result struct {
 	MsgID         int64            `json:"message_id"`
 	Timestamp     string           `json:"timestamp"`
-	MessageType   string           `json:"message_type"` // "cpdlc", "connect_request", "connect_confirm", "disconnect".
+	MessageType   string           `json:"message_type"` // "cpdlc", "connect_request", "connect_confirm", "disconnect", "atn_cpdlc".
 	Direction     string           `json:"direction"`    // "uplink" or "downlink".
 	GroundStation string           `json:"ground_station,omitempty"`
 	Registration  string           `json:"registration,omitempty"`
@@ -37,6 +38,10 @@ type Result struct {
 	Destination   string           `json:"destination,omitempty"`
 	Header        *MessageHeader   `json:"header,omitempty"`
 	Elements      []MessageElement `json:"elements,omitempty"`
+	// PDUType and AbortReason are set for ATN B1 messages only: the APDU
+	// ("start", "send", "user_abort", ...) and the reason of an abort.
+	PDUType       string           `json:"pdu_type,omitempty"`
+	AbortReason   string           `json:"abort_reason,omitempty"`
 	FormattedText string           `json:"formatted_text,omitempty"` // Human-readable message.
 	RawHex        string           `json:"raw_hex,omitempty"`
 	Error         string           `json:"error,omitempty"`
@@ -44,6 +49,92 @@ type Result struct {
 
 func (r *Result) Type() string     { return "cpdlc" }
 func (r *Result) MessageID() int64 { return r.MsgID }
+
+// Position implements registry.PositionReporter with the current position
+// of the first POSITION REPORT element (dM48).
+func (r *Result) Position() (registry.Position, bool) {
+	for _, e := range r.Elements {
+		pr, ok := e.Data.(*PositionReport)
+		if !ok || pr.PosCurrent == nil || pr.PosCurrent.Latitude == nil || pr.PosCurrent.Longitude == nil {
+			continue
+		}
+		p := registry.Position{
+			Latitude:   *pr.PosCurrent.Latitude,
+			Longitude:  *pr.PosCurrent.Longitude,
+			Altitude:   pr.Alt.feet(),
+			ReportTime: registry.ReportTime(pr.TimeAtPosCurrent.String()),
+		}
+		if s := pr.SpeedGround; s != nil {
+			switch s.Type {
+			case "knots":
+				p.GroundSpeed = s.Value
+			case "kph":
+				p.GroundSpeed = int(math.Round(float64(s.Value) / 1.852))
+			}
+		}
+		if pr.TrackAngle != nil {
+			p.Track = pr.TrackAngle.Value
+		} else if pr.TrueHeading != nil {
+			p.Track = pr.TrueHeading.Value
+		}
+		if t := pr.Temp; t != nil {
+			c := t.Value
+			if t.Type == "F" {
+				c = (c - 32) * 5 / 9
+			}
+			p.Temperature = &c
+		}
+		if w := pr.Winds; w != nil && w.Speed != nil {
+			speed := w.Speed.Value
+			if w.Speed.Type == "kmh" {
+				speed = int(math.Round(float64(speed) / 1.852))
+			}
+			p.Wind = &registry.Wind{Direction: w.Direction, Speed: speed}
+		}
+		return p, true
+	}
+	return registry.Position{}, false
+}
+
+// Quality implements registry.QualityReporter for messages with a POSITION
+// REPORT element (dM48) or a route clearance; other messages have nothing
+// to assess.
+func (r *Result) Quality() registry.Quality {
+	var fields []registry.Field
+	var position, route bool
+	for _, e := range r.Elements {
+		if pr, ok := e.Data.(*PositionReport); ok && pr != nil && !position {
+			position = true
+			p, _ := r.Position()
+			fields = append(fields,
+				registry.Field{Name: "latitude", Present: p.Latitude != 0, Weight: 2},
+				registry.Field{Name: "longitude", Present: p.Longitude != 0, Weight: 2},
+				registry.Field{Name: "altitude", Present: p.Altitude != 0},
+				registry.Field{Name: "report_time", Present: p.ReportTime != ""})
+		}
+		if rc := routeClearance(e.Data); rc != nil && !route {
+			route = true
+			fields = append(fields,
+				registry.Field{Name: "origin", Present: rc.AirportDeparture != "", Weight: 2},
+				registry.Field{Name: "destination", Present: rc.AirportDestination != "", Weight: 2},
+				registry.Field{Name: "route_information", Present: len(rc.RouteInformation) > 0})
+		}
+	}
+	return registry.Assess(fields...)
+}
+
+// routeClearance returns the route clearance of an element's data, alone or
+// with a position, or nil.
+func routeClearance(data any) *RouteClearance {
+	switch d := data.(type) {
+	case *RouteClearance:
+		return d
+	case map[string]interface{}:
+		rc, _ := d["route_clearance"].(*RouteClearance)
+		return rc
+	}
+	return nil
+}
 func (r *Result) HumanReadableText() string {
 	return strings.TrimSpace(r.FormattedText)
 }
@@ -53,11 +144,13 @@ type Parser struct{}
 
 func init() {
 	registry.Register(&Parser{})
+	registry.Register(&ATNParser{})
 }
 
-func (p *Parser) Name() string     { return "cpdlc" }
-func (p *Parser) Labels() []string { return []string{"AA", "BA"} }
-func (p *Parser) Priority() int    { return 50 } // Higher priority than generic parsers.
+func (p *Parser) Name() string               { return "cpdlc" }
+func (p *Parser) Labels() []string           { return []string{"AA", "BA"} }
+func (p *Parser) Priority() int              { return 50 } // Higher priority than generic parsers.
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 // QuickCheck checks if the message contains CPDLC markers.
 func (p *Parser) QuickCheck(text string) bool {
@@ -170,6 +263,71 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 	return result
 }
 
+// ATNParser parses ATN B1 CPDLC APDUs that the extractor lifts out of VDL2
+// X.25/CLNP frames into synthetic "ATNCPDLC" messages, with the text
+// "ATN CPDLC UPLINK <hex>" or "ATN CPDLC DOWNLINK <hex>".
+type ATNParser struct{}
+
+func (p *ATNParser) Name() string     { return "atn_cpdlc" }
+func (p *ATNParser) Labels() []string { return []string{"ATNCPDLC"} }
+func (p *ATNParser) Priority() int    { return 50 }
+
+func (p *ATNParser) ResultTypes() []string      { return []string{"cpdlc"} }
+func (p *ATNParser) NewResult() registry.Result { return &Result{} }
+
+// QuickCheck checks for the synthetic message prefix.
+func (p *ATNParser) QuickCheck(text string) bool {
+	return strings.HasPrefix(text, "ATN CPDLC ")
+}
+
+// Parse decodes an ATN B1 CPDLC APDU.
+func (p *ATNParser) Parse(msg *acars.Message) registry.Result {
+	fields := strings.Fields(msg.Text)
+	if len(fields) != 4 || fields[0] != "ATN" || fields[1] != "CPDLC" {
+		return nil
+	}
+
+	result := &Result{
+		MsgID:        int64(msg.ID),
+		Timestamp:    msg.Timestamp,
+		MessageType:  "atn_cpdlc",
+		Registration: strings.TrimLeft(msg.Tail, "."),
+		RawHex:       fields[3],
+	}
+
+	var direction MessageDirection
+	switch fields[2] {
+	case "UPLINK":
+		direction = DirectionUplink
+	case "DOWNLINK":
+		direction = DirectionDownlink
+	default:
+		return nil
+	}
+	result.Direction = direction.String()
+
+	data, err := hex.DecodeString(fields[3])
+	if err != nil {
+		result.Error = "invalid hex data: " + err.Error()
+		return result
+	}
+
+	pdu, err := NewATNDecoder(data, direction).Decode()
+	if pdu != nil {
+		result.PDUType = pdu.Type
+		result.AbortReason = pdu.AbortReason
+		if pdu.Message != nil {
+			result.Header = &pdu.Message.Header
+			result.Elements = pdu.Message.Elements
+		}
+		result.FormattedText = formatATNPDU(pdu)
+	}
+	if err != nil {
+		result.Error = "decode error: " + err.Error()
+	}
+	return result
+}
+
 // splitRegistrationAndData extracts the aircraft registration and hex data from the payload.
 // Registration formats vary: N12345, VH-ABC, F-GSQC, TC-LLH, etc.
 // Registrations are typically 5-7 characters including hyphens.
@@ -271,13 +429,18 @@ func findCPDLCPayloadStart(text string) (imiStart int, payloadStart int, message
 // followed by a hierarchical block of route details. All other elements display the
 // substituted text (elem.Text), keeping the output concise.
 func formatMessage(msg *Message) string {
+	return formatMessageAs("FANS-1/A CPDLC MESSAGE:", msg)
+}
+
+// formatMessageAs is formatMessage with the given title line.
+func formatMessageAs(title string, msg *Message) string {
 	if msg == nil || len(msg.Elements) == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
 
-	sb.WriteString("FANS-1/A CPDLC MESSAGE:\n")
+	sb.WriteString(title + "\n")
 	switch msg.Direction {
 	case DirectionDownlink:
 		sb.WriteString(" CPDLC DOWNLINK MESSAGE:\n")
@@ -291,9 +454,15 @@ func formatMessage(msg *Message) string {
 	if msg.Header.MsgRef != nil {
 		sb.WriteString(fmt.Sprintf("   MSG REF: %d\n", *msg.Header.MsgRef))
 	}
+	if msg.Header.Date != "" {
+		sb.WriteString(fmt.Sprintf("   DATE: %s\n", msg.Header.Date))
+	}
 	if msg.Header.Timestamp != nil {
 		sb.WriteString(fmt.Sprintf("   TIMESTAMP: %s\n", msg.Header.Timestamp.String()))
 	}
+	if msg.Header.LogicalAck != "" {
+		sb.WriteString(fmt.Sprintf("   LOGICAL ACK: %s\n", strings.ToUpper(strings.ReplaceAll(msg.Header.LogicalAck, "_", " "))))
+	}
 	sb.WriteString("  MESSAGE DATA:\n")
 
 	for _, elem := range msg.Elements {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
 )
 
 func TestQuickCheck(t *testing.T) {
@@ -1174,3 +1175,33 @@ func TestParseBADownlinkAssignedRouteWithAdditionalConstraints(t *testing.T) {
 	t.Logf("RouteClearance JSON:\n%s", string(rawJSON))
 	t.Logf("Formatted text:\n%s", r.FormattedText)
 }
+
+func TestQuality(t *testing.T) {
+	parser := &Parser{}
+	route := parser.Parse(&acars.Message{
+		Label: "AA",
+		Text:  "/FIHCAYA.AT1.A6-ECQA0A3A093C4A926641A00180052E3C90213C913B093A0CC9F4EB2E4CEA7220D383D471952374A2D09F4AA208B4EA20971E4A0974E0A0833A220A926641A000207",
+	}).(*Result)
+	q, ok := registry.QualityOf(route)
+	if !ok || strings.Join(q.Expected, ",") != "origin,destination,route_information" || strings.Join(q.Missing, ",") != "origin,destination" {
+		t.Errorf("route clearance quality = %+v, %v", q, ok)
+	}
+
+	b, err := hex.DecodeString("20B2C90C3D903BAE2D1141ECCB325824E8B4A249686255AD06655B3041390B6B09360D693499564B009A26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := NewDecoder(b[:len(b)-2], DirectionDownlink).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, ok = registry.QualityOf(&Result{Elements: msg.Elements})
+	if !ok || strings.Join(q.Expected, ",") != "latitude,longitude,altitude,report_time" || len(q.Missing) != 0 {
+		t.Errorf("position report quality = %+v, %v", q, ok)
+	}
+
+	// A message without a position report or route clearance is not assessed.
+	if _, ok := registry.QualityOf(&Result{Elements: []MessageElement{{ID: 0, Data: nil}}}); ok {
+		t.Error("a WILCO reported a quality")
+	}
+}
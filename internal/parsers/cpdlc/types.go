@@ -34,6 +34,9 @@ type MessageHeader struct {
 	MsgID     int   `json:"msg_id"`              // Message identification number.
 	MsgRef    *int  `json:"msg_ref,omitempty"`   // Reference number (optional).
 	Timestamp *Time `json:"timestamp,omitempty"` // Timestamp (optional).
+	// ATN B1 only: message date (YYYY-MM-DD) and "required"/"not_required".
+	Date       string `json:"date,omitempty"`
+	LogicalAck string `json:"logical_ack,omitempty"`
 }
 
 // Time represents a FANS timestamp (hours, minutes).
@@ -77,6 +80,24 @@ func (a *Altitude) String() string {
 	}
 }
 
+// feet returns the altitude in feet, or 0 if a is nil or of an unknown type.
+func (a *Altitude) feet() int {
+	if a == nil {
+		return 0
+	}
+	switch a.Type {
+	case "flight_level":
+		return a.Value * 100
+	case "flight_level_metric":
+		return int(math.Round(float64(a.Value) * 10 / 0.3048))
+	case "feet":
+		return a.Value
+	case "meters":
+		return int(math.Round(float64(a.Value) / 0.3048))
+	}
+	return 0
+}
+
 // Speed represents a speed value with its type.
 type Speed struct {
 	Type  string `json:"type"`  // "knots", "mach", etc.
@@ -46,9 +46,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "dis" }
-func (p *Parser) Labels() []string { return []string{"RA"} }
-func (p *Parser) Priority() int    { return 45 }
+func (p *Parser) Name() string               { return "dis" }
+func (p *Parser) Labels() []string           { return []string{"RA"} }
+func (p *Parser) Priority() int              { return 45 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"acars_parser/internal/acars"
@@ -30,6 +31,28 @@ type Result struct {
 func (r *Result) Type() string     { return "envelope" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	p := registry.Position{Latitude: r.Latitude, Longitude: r.Longitude}
+	if fl, err := strconv.Atoi(strings.TrimPrefix(r.Altitude, "FL")); err == nil {
+		p.Altitude = fl * 100
+	}
+	return p, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter for ADS-C envelopes; the
+// others only carry a tail number.
+func (r *Result) Quality() registry.Quality {
+	if r.MessageType != "ADS" {
+		return registry.Quality{}
+	}
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "altitude", Present: r.Altitude != ""},
+	)
+}
+
 // Parser extracts tail numbers from envelope headers.
 type Parser struct{}
 
@@ -37,9 +60,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "envelope" }
-func (p *Parser) Labels() []string { return []string{"AA", "A6"} }
-func (p *Parser) Priority() int    { return 100 } // Run early.
+func (p *Parser) Name() string               { return "envelope" }
+func (p *Parser) Labels() []string           { return []string{"AA", "A6"} }
+func (p *Parser) Priority() int              { return 100 } // Run early.
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 // tailPatterns for different registration formats.
 // Order matters - more specific patterns first.
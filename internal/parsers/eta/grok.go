@@ -11,7 +11,7 @@ var Formats = []patterns.Format{
 	{
 		Name: "et_exp_time",
 		Pattern: `/ET\s+EXP\s+TIME\s+/\s*(?P<origin>{ICAO})\s+(?P<dest>{ICAO})\s+` +
-			`(?P<day>\d{2})\s+(?P<time>{TIME6})/EON\s+(?P<eta>{TIME4})(?:\s+(?P<mode>\w+))?`,
+			`{NUM2:day:int}\s+(?P<time>{TIME6})/EON\s+(?P<eta>{TIME4})(?:\s+(?P<mode>\w+))?`,
 		Fields: []string{"origin", "dest", "day", "time", "eta", "mode"},
 	},
 	// IR format.
@@ -2,7 +2,6 @@
 package eta
 
 import (
-	"strconv"
 	"strings"
 	"sync"
 
@@ -53,9 +52,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "eta" }
-func (p *Parser) Labels() []string { return []string{"5Z"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "eta" }
+func (p *Parser) Labels() []string           { return []string{"5Z"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 
 func (p *Parser) QuickCheck(text string) bool {
@@ -96,9 +96,7 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 		result.ReportTime = match.Captures["time"]
 		result.ETA = match.Captures["eta"]
 		result.Mode = match.Captures["mode"]
-		if day, err := strconv.Atoi(match.Captures["day"]); err == nil {
-			result.DayOfMonth = day
-		}
+		result.DayOfMonth, _ = match.Int("day")
 
 	case "ir_format":
 		result.MessageType = "IR"
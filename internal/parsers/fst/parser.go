@@ -41,6 +41,33 @@ type Result struct {
 func (r *Result) Type() string     { return "fst" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	p := registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		GroundSpeed: r.GroundSpeedKts,
+		Track:       r.Track,
+		Temperature: registry.Temperature(float64(r.Temperature)),
+		Wind:        registry.NewWind(r.WindDirection, r.WindSpeedKts),
+	}
+	if p.Track == 0 {
+		p.Track = r.Heading
+	}
+	return p, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+		registry.Field{Name: "route", Present: r.Route != ""},
+	)
+}
+
 // Parser parses FST flight status messages.
 type Parser struct{}
 
@@ -63,9 +90,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "fst" }
-func (p *Parser) Labels() []string { return []string{"15"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "fst" }
+func (p *Parser) Labels() []string           { return []string{"15"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.HasPrefix(text, "FST")
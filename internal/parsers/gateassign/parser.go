@@ -36,6 +36,9 @@ func (p *Parser) Name() string     { return "gateassign" }
 func (p *Parser) Labels() []string { return []string{"RA"} }
 func (p *Parser) Priority() int    { return 60 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"gate_assignment"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 // QuickCheck looks for gate assignment keywords.
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
@@ -0,0 +1,352 @@
+// Package grokfile builds parsers from grok format files, so new message
+// variants can be added without changing Go code.
+//
+// A format file is YAML (.yaml, .yml) or JSON (.json):
+//
+//	name: acme_pos            # parser name, unique across all parsers
+//	labels: ["16"]            # labels to run on; empty runs on every label
+//	priority: 90              # lower runs first (default 100)
+//	type: acme_position       # result type (default: name)
+//	quick_check: ["POS"]      # the text must contain one of these (default: always run)
+//	patterns:                 # local placeholders, may override the global ones
+//	  ACME_SEP: '[/,]'
+//	formats:                  # tried in order, first match wins
+//	  - name: acme_v1
+//	    pattern: '^POS{LAT:lat:coord}{LON:lon:coord}/F{NUM:fl:int}'
+//	    fields:               # output field: capture name (default: every capture)
+//	      latitude: lat
+//	      longitude: lon
+//	      flight_level: fl
+//
+// Typed captures are written converted, as numbers or "HH:MM:SS" times;
+// other captures as strings.  A typed capture that does not convert is left out.
+//
+// A result's quality expects every output field of its format.  A result
+// with latitude and longitude fields reports its position like the
+// built-in position parsers, with altitude_ft (or flight_level), report_time,
+// ground_speed, track (or heading), temperature, wind_dir and wind_speed
+// read when present.
+package grokfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/patterns"
+	"acars_parser/internal/registry"
+)
+
+// File is the content of one format file.
+type File struct {
+	Name       string            `json:"name" yaml:"name"`
+	Labels     []string          `json:"labels" yaml:"labels"`
+	Priority   *int              `json:"priority" yaml:"priority"`
+	Type       string            `json:"type" yaml:"type"`
+	QuickCheck []string          `json:"quick_check" yaml:"quick_check"`
+	Patterns   map[string]string `json:"patterns" yaml:"patterns"`
+	Formats    []FormatDef       `json:"formats" yaml:"formats"`
+}
+
+// FormatDef is one grok format of a file.
+type FormatDef struct {
+	Name    string            `json:"name" yaml:"name"`
+	Pattern string            `json:"pattern" yaml:"pattern"`
+	Fields  map[string]string `json:"fields" yaml:"fields"` // Output field -> capture name.
+}
+
+// Result is what a format file parser produces.
+type Result struct {
+	MsgID     int64          `json:"message_id"`
+	Timestamp string         `json:"timestamp"`
+	Tail      string         `json:"tail,omitempty"`
+	Format    string         `json:"format"`
+	Fields    map[string]any `json:"fields"`
+
+	typ      string
+	expected []string // Output fields of the format, sorted
+}
+
+func (r *Result) Type() string     { return r.typ }
+func (r *Result) MessageID() int64 { return r.MsgID }
+
+// Quality implements registry.QualityReporter: the format's output fields
+// are expected, with latitude and longitude weighing double.
+func (r *Result) Quality() registry.Quality {
+	fields := make([]registry.Field, 0, len(r.expected))
+	for _, name := range r.expected {
+		f := registry.Field{Name: name}
+		_, f.Present = r.Fields[name]
+		if name == "latitude" || name == "longitude" {
+			f.Weight = 2
+		}
+		fields = append(fields, f)
+	}
+	return registry.Assess(fields...)
+}
+
+// Position implements registry.PositionReporter from the conventional field
+// names listed in the package comment.
+func (r *Result) Position() (registry.Position, bool) {
+	lat, _ := r.number("latitude")
+	lon, _ := r.number("longitude")
+	p := registry.Position{Latitude: lat, Longitude: lon}
+	if v, ok := r.number("altitude_ft"); ok {
+		p.Altitude = int(v)
+	} else if v, ok := r.number("flight_level"); ok {
+		p.Altitude = int(v * 100)
+	}
+	if v, ok := r.Fields["report_time"].(string); ok {
+		p.ReportTime = registry.ReportTime(v)
+	}
+	if v, ok := r.number("ground_speed"); ok {
+		p.GroundSpeed = int(v)
+	}
+	if v, ok := r.number("track"); ok {
+		p.Track = int(v)
+	} else if v, ok := r.number("heading"); ok {
+		p.Track = int(v)
+	}
+	if v, ok := r.number("temperature"); ok {
+		p.Temperature = &v
+	}
+	dir, _ := r.number("wind_dir")
+	speed, _ := r.number("wind_speed")
+	p.Wind = registry.NewWind(int(dir), int(speed))
+	return p, lat != 0 || lon != 0
+}
+
+// number returns a numeric field, converting a string capture.
+func (r *Result) number(field string) (float64, bool) {
+	switch v := r.Fields[field].(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Parser runs the formats of one file.
+type Parser struct {
+	name       string
+	labels     []string
+	priority   int
+	typ        string
+	quickCheck []string
+	compiler   *patterns.Compiler
+	fields     map[string]map[string]string // Format name -> field mapping.
+	types      map[string]map[string]string // Format name -> typed captures.
+}
+
+func (p *Parser) Name() string               { return p.name }
+func (p *Parser) Labels() []string           { return p.labels }
+func (p *Parser) Priority() int              { return p.priority }
+func (p *Parser) ResultTypes() []string      { return []string{p.typ} }
+func (p *Parser) NewResult() registry.Result { return &Result{typ: p.typ} }
+
+func (p *Parser) QuickCheck(text string) bool {
+	if len(p.quickCheck) == 0 {
+		return true
+	}
+	for _, s := range p.quickCheck {
+		if strings.Contains(text, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) Parse(msg *acars.Message) registry.Result {
+	if msg.Text == "" {
+		return nil
+	}
+	match := p.compiler.Parse(strings.TrimSpace(msg.Text))
+	if match == nil {
+		return nil
+	}
+
+	result := &Result{
+		MsgID:     int64(msg.ID),
+		Timestamp: msg.Timestamp,
+		Tail:      msg.Tail,
+		Format:    match.FormatName,
+		Fields:    make(map[string]any),
+		typ:       p.typ,
+	}
+	mapping := p.fields[match.FormatName]
+	if len(mapping) == 0 {
+		mapping = make(map[string]string)
+		for capture := range match.Captures {
+			mapping[capture] = capture
+		}
+	}
+	for field := range mapping {
+		result.expected = append(result.expected, field)
+	}
+	sort.Strings(result.expected)
+	typed := p.types[match.FormatName]
+	for field, capture := range mapping {
+		if typed[capture] != "" {
+			if v, ok := match.Values[capture]; ok {
+				if d, ok := v.(time.Duration); ok {
+					v = time.Time{}.Add(d).Format("15:04:05")
+				}
+				result.Fields[field] = v
+			}
+		} else if v := match.Captures[capture]; v != "" {
+			result.Fields[field] = v
+		}
+	}
+	return result
+}
+
+// Load reads the format files in dir, or the single file dir names, and
+// returns one parser per file.  Every bad file is reported, each error
+// prefixed with its path.
+func Load(dir string) ([]*Parser, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{dir}
+	if info.IsDir() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		paths = paths[:0]
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !e.IsDir() {
+					paths = append(paths, filepath.Join(dir, e.Name()))
+				}
+			}
+		}
+		sort.Strings(paths)
+	}
+
+	var parsers []*Parser
+	var errs []error
+	seen := make(map[string]string)
+	for _, path := range paths {
+		p, err := LoadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[p.name]; ok {
+			errs = append(errs, fmt.Errorf("%s: parser name %q is already used by %s", path, p.name, other))
+			continue
+		}
+		seen[p.name] = path
+		parsers = append(parsers, p)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return parsers, nil
+}
+
+// LoadFile reads and compiles one format file.
+func LoadFile(path string) (*Parser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&f)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p, err := New(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// New checks and compiles f.
+func New(f File) (*Parser, error) {
+	if f.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(f.Formats) == 0 {
+		return nil, errors.New("at least one format is required")
+	}
+	p := &Parser{
+		name:       f.Name,
+		labels:     f.Labels,
+		priority:   100,
+		typ:        f.Type,
+		quickCheck: f.QuickCheck,
+		fields:     make(map[string]map[string]string),
+		types:      make(map[string]map[string]string),
+	}
+	if f.Priority != nil {
+		p.priority = *f.Priority
+	}
+	if p.typ == "" {
+		p.typ = f.Name
+	}
+
+	formats := make([]patterns.Format, 0, len(f.Formats))
+	for i, def := range f.Formats {
+		if def.Name == "" {
+			return nil, fmt.Errorf("format %d: name is required", i+1)
+		}
+		if _, dup := p.fields[def.Name]; dup {
+			return nil, fmt.Errorf("format %s: defined twice", def.Name)
+		}
+		if def.Pattern == "" {
+			return nil, fmt.Errorf("format %s: pattern is required", def.Name)
+		}
+		p.fields[def.Name] = def.Fields
+		formats = append(formats, patterns.Format{Name: def.Name, Pattern: def.Pattern})
+	}
+
+	p.compiler = patterns.NewCompiler(formats, f.Patterns)
+	if err := p.compiler.Compile(); err != nil {
+		return nil, err
+	}
+	for _, format := range p.compiler.Formats() {
+		p.types[format.Name] = format.Types
+		captures := make(map[string]bool)
+		for _, name := range format.Compiled.SubexpNames() {
+			if name != "" {
+				captures[name] = true
+			}
+		}
+		if len(captures) == 0 {
+			return nil, fmt.Errorf("format %s: pattern has no named captures (?P<name>...)", format.Name)
+		}
+		for field, capture := range p.fields[format.Name] {
+			if !captures[capture] {
+				return nil, fmt.Errorf("format %s: field %s maps to capture %q, which the pattern does not define", format.Name, field, capture)
+			}
+		}
+	}
+	return p, nil
+}
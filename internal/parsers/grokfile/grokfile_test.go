@@ -0,0 +1,114 @@
+package grokfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "acme.yaml", `
+name: acme_pos
+labels: ["16"]
+priority: 90
+type: acme_position
+quick_check: ["POS"]
+patterns:
+  ACME_FL: 'F{NUM}'
+formats:
+  - name: acme_v1
+    pattern: '^POS{LAT:lat:coord}{LON:lon:coord}/(?P<fl>{ACME_FL})(?:/ETA{TIME4:eta:time})?'
+    fields:
+      latitude: lat
+      longitude: lon
+      flight_level: fl
+      eta: eta
+`)
+	writeFile(t, dir, "eta.json", `{"name": "acme_eta", "formats": [{"name": "eta", "pattern": "^ETA (?P<eta>{TIME4})"}]}`)
+	writeFile(t, dir, "README.txt", "not a format file")
+
+	parsers, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsers) != 2 {
+		t.Fatalf("got %d parsers", len(parsers))
+	}
+	pos, eta := parsers[0], parsers[1]
+	if pos.Name() != "acme_pos" || pos.Priority() != 90 || pos.Labels()[0] != "16" || pos.ResultTypes()[0] != "acme_position" {
+		t.Errorf("acme_pos = %+v", pos)
+	}
+	if eta.Priority() != 100 || len(eta.Labels()) != 0 || eta.ResultTypes()[0] != "acme_eta" || !eta.QuickCheck("anything") {
+		t.Errorf("acme_eta defaults = %+v", eta)
+	}
+	if pos.QuickCheck("ETA 1200") {
+		t.Error("acme_pos quick check passed without POS")
+	}
+
+	r, ok := pos.Parse(&acars.Message{ID: 3, Label: "16", Tail: "N123", Text: "posN45120W073300/F350/ETA2510"}).(*Result)
+	if !ok {
+		t.Fatal("acme_pos did not parse")
+	}
+	if r.Type() != "acme_position" || r.MessageID() != 3 || r.Tail != "N123" || r.Format != "acme_v1" ||
+		r.Fields["latitude"] != 45.2 || r.Fields["longitude"] != -73.5 || r.Fields["flight_level"] != "F350" || len(r.Fields) != 3 {
+		t.Errorf("acme_pos result = %+v", r)
+	}
+	if q := r.Quality(); strings.Join(q.Expected, ",") != "eta,flight_level,latitude,longitude" || strings.Join(q.Missing, ",") != "eta" {
+		t.Errorf("acme_pos quality = %+v", q)
+	}
+	r, ok = pos.Parse(&acars.Message{Text: "POSN45120W073300/F350/ETA1245"}).(*Result)
+	if !ok || r.Fields["eta"] != "12:45:00" {
+		t.Errorf("acme_pos eta = %+v", r)
+	}
+	if r, ok := eta.Parse(&acars.Message{Text: "ETA 1245"}).(*Result); !ok || r.Fields["eta"] != "1245" {
+		t.Errorf("acme_eta result = %+v", r)
+	}
+	if eta.Parse(&acars.Message{Text: "ETA"}) != nil {
+		t.Error("acme_eta parsed a message its pattern does not match")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name, file, content, want string
+	}{
+		{"bad regex", "a.yaml", "name: a\nformats:\n  - name: f1\n    pattern: '^POS(?P<lat>\\d+'\n",
+			"a.yaml: format f1: error parsing regexp: missing closing )"},
+		{"unknown placeholder", "a.yaml", "name: a\nformats:\n  - name: f1\n    pattern: '^(?P<lat>{LATT})'\n",
+			"a.yaml: format f1: unknown placeholder {LATT}"},
+		{"unknown capture", "a.yaml", "name: a\nformats:\n  - name: f1\n    pattern: '^(?P<lat>\\d+)'\n    fields: {latitude: lattitude}\n",
+			`a.yaml: format f1: field latitude maps to capture "lattitude"`},
+		{"no captures", "a.json", `{"name": "a", "formats": [{"name": "f1", "pattern": "^POS"}]}`,
+			"a.json: format f1: pattern has no named captures"},
+		{"unknown key", "a.json", `{"name": "a", "label": ["16"], "formats": []}`,
+			`a.json: json: unknown field "label"`},
+		{"no formats", "a.yaml", "name: a\n", "a.yaml: at least one format is required"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFile(t, dir, tt.file, tt.content)
+		_, err := Load(dir)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yml"} {
+		writeFile(t, dir, name, "name: dup\nformats:\n  - name: f1\n    pattern: '(?P<x>X)'\n")
+	}
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), `b.yml: parser name "dup" is already used by`) {
+		t.Errorf("duplicate name: %v", err)
+	}
+}
@@ -64,6 +64,16 @@ type FPNResult struct {
 func (r *FPNResult) Type() string     { return "flight_plan" }
 func (r *FPNResult) MessageID() int64 { return r.MsgID }
 
+// Quality implements registry.QualityReporter.
+func (r *FPNResult) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "flight_num", Present: r.FlightNum != ""},
+		registry.Field{Name: "origin", Present: r.Origin != "", Weight: 2},
+		registry.Field{Name: "destination", Present: r.Destination != "", Weight: 2},
+		registry.Field{Name: "waypoints", Present: len(r.Waypoints) > 0, Weight: 2},
+	)
+}
+
 // FPNParser parses H1 FPN flight plan messages.
 type FPNParser struct{}
 
@@ -75,6 +85,9 @@ func (p *FPNParser) Name() string     { return "fpn" }
 func (p *FPNParser) Labels() []string { return []string{"H1", "4A", "HX"} }
 func (p *FPNParser) Priority() int    { return 10 }
 
+func (p *FPNParser) ResultTypes() []string      { return []string{"flight_plan"} }
+func (p *FPNParser) NewResult() registry.Result { return &FPNResult{} }
+
 func (p *FPNParser) QuickCheck(text string) bool {
 	return strings.Contains(text, "FPN") && strings.Contains(text, ":DA:")
 }
@@ -502,6 +515,29 @@ type H1PosResult struct {
 func (r *H1PosResult) Type() string     { return "h1_position" }
 func (r *H1PosResult) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *H1PosResult) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		GroundSpeed: r.GroundSpeed,
+		Temperature: registry.Temperature(float64(r.Temperature)),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeed),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *H1PosResult) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+	)
+}
+
 // H1PosParser parses H1 POS position messages.
 type H1PosParser struct{}
 
@@ -513,6 +549,9 @@ func (p *H1PosParser) Name() string     { return "h1pos" }
 func (p *H1PosParser) Labels() []string { return []string{"H1"} }
 func (p *H1PosParser) Priority() int    { return 20 }
 
+func (p *H1PosParser) ResultTypes() []string      { return []string{"h1_position"} }
+func (p *H1PosParser) NewResult() registry.Result { return &H1PosResult{} }
+
 func (p *H1PosParser) QuickCheck(text string) bool {
 	// Starts with POS but not POS/ (which is part of other messages).
 	return strings.HasPrefix(text, "POS") && !strings.HasPrefix(text, "POS/")
@@ -666,9 +705,10 @@ func init() {
 	registry.Register(&PWIParser{})
 }
 
-func (p *PWIParser) Name() string     { return "pwi" }
-func (p *PWIParser) Labels() []string { return []string{"H1"} }
-func (p *PWIParser) Priority() int    { return 30 }
+func (p *PWIParser) Name() string               { return "pwi" }
+func (p *PWIParser) Labels() []string           { return []string{"H1"} }
+func (p *PWIParser) Priority() int              { return 30 }
+func (p *PWIParser) NewResult() registry.Result { return &PWIResult{} }
 
 func (p *PWIParser) QuickCheck(text string) bool {
 	return strings.Contains(text, "PWI/")
@@ -8,20 +8,21 @@ var Formats = []patterns.Format{
 	// Header: 02A (climb/descend)
 	// Example (from logs):
 	//   02A251038BKPRLOWWN42333E021013251018 ...
+	// Coordinates are thousandths of a degree.
 	// Groups: time, origin, dest, lat_dir, lat, lon_dir, lon, datetime
 	{
 		Name: "h2_header_02A",
 		Pattern: `^02A(?P<time>{TIME6})(?P<origin>{ICAO})(?P<dest>{ICAO})` +
-			`(?P<lat_dir>{LAT_DIR})(?P<lat>{LAT_5D})` +
-			`(?P<lon_dir>{LON_DIR})(?P<lon>{LON_6D})(?P<datetime>{TIME6})`,
+			`(?P<lat_dir>{LAT_DIR}){LAT_5D:lat:int}` +
+			`(?P<lon_dir>{LON_DIR}){LON_6D:lon:int}(?P<datetime>{TIME6})`,
 		Fields: []string{"time", "origin", "dest", "lat_dir", "lat", "lon_dir", "lon", "datetime"},
 	},
 	// Header: 02D (same layout as 02A)
 	{
 		Name: "h2_header_02D",
 		Pattern: `^02D(?P<time>{TIME6})(?P<origin>{ICAO})(?P<dest>{ICAO})` +
-			`(?P<lat_dir>{LAT_DIR})(?P<lat>{LAT_5D})` +
-			`(?P<lon_dir>{LON_DIR})(?P<lon>{LON_6D})(?P<datetime>{TIME6})`,
+			`(?P<lat_dir>{LAT_DIR}){LAT_5D:lat:int}` +
+			`(?P<lon_dir>{LON_DIR}){LON_6D:lon:int}(?P<datetime>{TIME6})`,
 		Fields: []string{"time", "origin", "dest", "lat_dir", "lat", "lon_dir", "lon", "datetime"},
 	},
 	// Header: 02E (cruise)
@@ -30,11 +31,16 @@ var Formats = []patterns.Format{
 	// Groups: day, origin, dest, lat_dir, lat, lon_dir, lon, eta, fl, temp_sign, temp, wind_dir, wind_spd, gust
 	{
 		Name: "h2_header_02E",
-		Pattern: `^02E(?P<day>\d{2})(?P<origin>{ICAO})(?P<dest>{ICAO})` +
-			`(?P<lat_dir>{LAT_DIR})(?P<lat>{LAT_5D})` +
-			`(?P<lon_dir>{LON_DIR})(?P<lon>{LON_6D})` +
-			`(?P<eta>\d{4})(?P<fl>\d{3,4})(?P<temp_sign>{TEMP_SIGN})(?P<temp>\d{3})` +
-			`(?P<wind_dir>\d{3})(?P<wind_spd>\d{3})(?P<gust>G?)`,
+		Pattern: `^02E{NUM2:day:int}(?P<origin>{ICAO})(?P<dest>{ICAO})` +
+			`(?P<lat_dir>{LAT_DIR}){LAT_5D:lat:int}` +
+			`(?P<lon_dir>{LON_DIR}){LON_6D:lon:int}` +
+			`{TIME4:eta:time}{FL10:fl:int}(?P<temp_sign>{TEMP_SIGN}){NUM3:temp:int}` +
+			`{WIND_DIR:wind_dir:int}{NUM3:wind_spd:int}(?P<gust>G?)`,
 		Fields: []string{"day", "origin", "dest", "lat_dir", "lat", "lon_dir", "lon", "eta", "fl", "temp_sign", "temp", "wind_dir", "wind_spd", "gust"},
 	},
 }
+
+// localPatterns are the placeholders only the H2 formats use.
+var localPatterns = map[string]string{
+	"FL10": `\d{3,4}`, // Flight level in tenths (3500 = FL350)
+}
@@ -2,11 +2,13 @@
 package h2wind
 
 import (
+	"fmt"
 	"math"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"acars_parser/internal/acars"
 	"acars_parser/internal/airports"
@@ -23,7 +25,7 @@ var (
 
 func getCompiler() (*patterns.Compiler, error) {
 	grokOnce.Do(func() {
-		grokCompiler = patterns.NewCompiler(Formats, nil)
+		grokCompiler = patterns.NewCompiler(Formats, localPatterns)
 		grokErr = grokCompiler.Compile()
 	})
 	return grokCompiler, grokErr
@@ -91,6 +93,22 @@ type Result struct {
 func (r *Result) Type() string     { return "h2_wind" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter with the position the
+// report starts from; the wind layers and points are forecasts.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{Latitude: r.Latitude, Longitude: r.Longitude}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "origin", Present: r.Origin != ""},
+		registry.Field{Name: "destination", Present: r.Destination != ""},
+	)
+}
+
 // Parser parses H2 wind/weather messages.
 type Parser struct{}
 
@@ -98,9 +116,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "h2_wind" }
-func (p *Parser) Labels() []string { return []string{"H2"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "h2_wind" }
+func (p *Parser) Labels() []string           { return []string{"H2"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	text = strings.TrimSpace(text)
@@ -143,8 +162,10 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 
 	// Parse lat/lon. For H2, the common encoding is degrees * 1000
 	// (e.g. 42540 -> 42.540). This matches the examples in your logs.
-	result.Latitude = parseDegThousandths(match.Captures["lat"], match.Captures["lat_dir"])
-	result.Longitude = parseDegThousandths(match.Captures["lon"], match.Captures["lon_dir"])
+	lat, _ := match.Int("lat")
+	lon, _ := match.Int("lon")
+	result.Latitude = degThousandths(lat, match.Captures["lat_dir"])
+	result.Longitude = degThousandths(lon, match.Captures["lon_dir"])
 
 	// Branch by header flavor.
 	switch match.FormatName {
@@ -167,16 +188,14 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 
 	case "h2_header_02E":
 		result.Phase = "02E"
-		if d, err := strconv.Atoi(match.Captures["day"]); err == nil {
-			result.Day = d
-		}
+		result.Day, _ = match.Int("day")
 		// For 02E there is no 6-digit "time"; ETA is usually the most useful timestamp.
-		if result.ReportTime == "" {
-			result.ReportTime = fmtHHMM(match.Captures["eta"])
+		if eta, ok := match.Time("eta"); ok && result.ReportTime == "" {
+			result.ReportTime = clock(eta)
 		}
 
 		// First point is embedded in the header for 02E.
-		if pt, ok := buildPointFrom02EHeader(match.Captures); ok {
+		if pt, ok := buildPointFrom02EHeader(match); ok {
 			result.Points = append(result.Points, pt)
 			// Prefer the point position as the "main" position too.
 			result.Latitude = pt.Latitude
@@ -204,6 +223,11 @@ func parseDegThousandths(v string, dir string) float64 {
 	if err != nil {
 		return 0
 	}
+	return degThousandths(n, dir)
+}
+
+// degThousandths returns n thousandths of a degree, negative for S and W.
+func degThousandths(n int, dir string) float64 {
 	out := float64(n) / 1000.0
 	switch strings.ToUpper(dir) {
 	case "S", "W":
@@ -212,6 +236,11 @@ func parseDegThousandths(v string, dir string) float64 {
 	return out
 }
 
+// clock formats a time of day as HH:MM.
+func clock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
 func fmtHHMM(s string) string {
 	if len(s) != 4 {
 		return s
@@ -294,27 +323,32 @@ func headerLen02E(text string) int {
 	return 0
 }
 
-func buildPointFrom02EHeader(c map[string]string) (WindPoint, bool) {
-	lat := parseDegThousandths(c["lat"], c["lat_dir"])
-	lon := parseDegThousandths(c["lon"], c["lon_dir"])
+func buildPointFrom02EHeader(m *patterns.Match) (WindPoint, bool) {
+	latV, _ := m.Int("lat")
+	lonV, _ := m.Int("lon")
+	lat := degThousandths(latV, m.Captures["lat_dir"])
+	lon := degThousandths(lonV, m.Captures["lon_dir"])
 	if lat == 0 && lon == 0 {
 		return WindPoint{}, false
 	}
+	fl, _ := m.Int("fl")
+	temp, _ := m.Int("temp")
 	pt := WindPoint{
 		Marker:      "H",
 		Latitude:    lat,
 		Longitude:   lon,
-		ETA:         fmtHHMM(c["eta"]),
-		FlightLevel: parseFL10(c["fl"]),
-		Temperature: parseTemp10(c["temp_sign"], c["temp"]),
-		Gusting:     c["gust"] == "G",
+		FlightLevel: float64(fl) / 10.0,
+		Temperature: float64(temp) / 10.0,
+		Gusting:     m.Captures["gust"] == "G",
 	}
-	if d, err := strconv.Atoi(c["wind_dir"]); err == nil {
-		pt.WindDir = d
+	if m.Captures["temp_sign"] == "M" {
+		pt.Temperature = -pt.Temperature
 	}
-	if s, err := strconv.Atoi(c["wind_spd"]); err == nil {
-		pt.WindSpeed = s
+	if eta, ok := m.Time("eta"); ok {
+		pt.ETA = clock(eta)
 	}
+	pt.WindDir, _ = m.Int("wind_dir")
+	pt.WindSpeed, _ = m.Int("wind_spd")
 	return pt, true
 }
 
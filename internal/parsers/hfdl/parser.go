@@ -25,6 +25,20 @@ type Result struct {
 func (r *Result) Type() string     { return "hfdl_data" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{Latitude: r.Latitude, Longitude: r.Longitude}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "flight", Present: r.Flight != "" || r.FlightID != ""},
+	)
+}
+
 // Parser handles synthetic HFDL data messages.
 type Parser struct{}
 
@@ -32,9 +46,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "hfdl_data" }
-func (p *Parser) Labels() []string { return []string{"HFDL"} }
-func (p *Parser) Priority() int    { return 5 }
+func (p *Parser) Name() string               { return "hfdl_data" }
+func (p *Parser) Labels() []string           { return []string{"HFDL"} }
+func (p *Parser) Priority() int              { return 5 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(strings.ToUpper(strings.TrimSpace(text)), " DATA")
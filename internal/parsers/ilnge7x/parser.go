@@ -38,9 +38,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "ilnge7x" }
-func (p *Parser) Labels() []string { return nil }
-func (p *Parser) Priority() int    { return 450 }
+func (p *Parser) Name() string               { return "ilnge7x" }
+func (p *Parser) Labels() []string           { return nil }
+func (p *Parser) Priority() int              { return 450 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.HasPrefix(strings.TrimSpace(text), "/ILNGE7X.")
@@ -38,9 +38,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "ini" }
-func (p *Parser) Labels() []string { return nil }
-func (p *Parser) Priority() int    { return 40 }
+func (p *Parser) Name() string               { return "ini" }
+func (p *Parser) Labels() []string           { return nil }
+func (p *Parser) Priority() int              { return 40 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
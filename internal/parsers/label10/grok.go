@@ -7,11 +7,14 @@ import "acars_parser/internal/patterns"
 var Formats = []patterns.Format{
 	// Rich position format with slash-delimited fields.
 	// Example: /N33.123/W117.456/10/0.84/270/350/KLAX/1234/12000/500/WAYP1/1230/WAYP2/1245
-	// Groups: lat_dir, lat, lon_dir, lon, rest
+	// Groups: lat, lon, mach, heading, fl, dest, eta, fuel, distance, waypoints
+	// The fields after the position are only read when there are at least
+	// five of them.
 	{
 		Name: "rich_position",
-		Pattern: `^/(?P<lat_dir>{LAT_DIR})(?P<lat>[\d.]+)/` +
-			`(?P<lon_dir>{LON_DIR})(?P<lon>[\d.]+)/(?P<rest>.*)`,
-		Fields: []string{"lat_dir", "lat", "lon_dir", "lon", "rest"},
+		Pattern: `^/{LAT_DEC_DIR:lat:dec_coord}/{LON_DEC_DIR:lon:dec_coord}/` +
+			`(?:{FIELD}/{FIELD:mach:float}/{FIELD:heading:int}/{FIELD:fl:int}/{FIELD:dest}` +
+			`(?:/{FIELD:eta}(?:/{FIELD:fuel:int}(?:/{FIELD:distance:int}(?:/(?P<waypoints>.*))?)?)?)?|.*)`,
+		Fields: []string{"lat", "lon", "mach", "heading", "fl", "dest", "eta", "fuel", "distance", "waypoints"},
 	},
 }
@@ -39,6 +39,26 @@ type Result struct {
 func (r *Result) Type() string     { return "label10_position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+		Altitude:  r.FlightLevel * 100,
+		Track:     r.Heading,
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+		registry.Field{Name: "destination", Present: r.Destination != ""},
+	)
+}
+
 // Parser parses Label 10 position/route messages.
 type Parser struct{}
 
@@ -66,6 +86,9 @@ func (p *Parser) Name() string     { return "label10" }
 func (p *Parser) Labels() []string { return []string{"10"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"label10_position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.HasPrefix(text, "/N") || strings.HasPrefix(text, "/S")
 }
@@ -94,72 +117,23 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 		Tail:      msg.Tail,
 	}
 
-	// Parse latitude.
-	if lat, err := strconv.ParseFloat(match.Captures["lat"], 64); err == nil {
-		if match.Captures["lat_dir"] == "S" {
-			lat = -lat
-		}
-		result.Latitude = lat
+	result.Latitude, _ = match.Float("lat")
+	result.Longitude, _ = match.Float("lon")
+	result.Mach, _ = match.Float("mach")
+	result.Heading, _ = match.Int("heading")
+	result.FlightLevel, _ = match.Int("fl")
+	result.Fuel, _ = match.Int("fuel")
+	result.Distance, _ = match.Int("distance")
+
+	if dest := match.Captures["dest"]; len(dest) == 4 {
+		result.Destination = dest
+		result.DestinationName = airports.GetName(dest)
 	}
-
-	// Parse longitude.
-	if lon, err := strconv.ParseFloat(match.Captures["lon"], 64); err == nil {
-		if match.Captures["lon_dir"] == "W" {
-			lon = -lon
-		}
-		result.Longitude = lon
+	if eta := match.Captures["eta"]; len(eta) == 4 {
+		result.ETA = eta
 	}
-
-	// Parse remaining slash-delimited fields.
-	rest := match.Captures["rest"]
-	fields := strings.Split(rest, "/")
-
-	if len(fields) >= 5 {
-		// Field 0: unknown (often "10").
-		// Field 1: Mach number.
-		if mach, err := strconv.ParseFloat(fields[1], 64); err == nil {
-			result.Mach = mach
-		}
-
-		// Field 2: Heading.
-		if hdg, err := strconv.Atoi(fields[2]); err == nil {
-			result.Heading = hdg
-		}
-
-		// Field 3: Flight level.
-		if fl, err := strconv.Atoi(fields[3]); err == nil {
-			result.FlightLevel = fl
-		}
-
-		// Field 4: Destination ICAO.
-		if len(fields[4]) == 4 {
-			result.Destination = fields[4]
-			result.DestinationName = airports.GetName(result.Destination)
-		}
-
-		// Field 5: ETA.
-		if len(fields) > 5 && len(fields[5]) == 4 {
-			result.ETA = fields[5]
-		}
-
-		// Field 6: Fuel.
-		if len(fields) > 6 {
-			if fuel, err := strconv.Atoi(fields[6]); err == nil {
-				result.Fuel = fuel
-			}
-		}
-
-		// Field 7: Distance.
-		if len(fields) > 7 {
-			if dist, err := strconv.Atoi(fields[7]); err == nil {
-				result.Distance = dist
-			}
-		}
-
-		// Remaining fields are waypoint/ETA pairs.
-		if len(fields) > 8 {
-			result.Waypoints = parseWaypoints(fields[8:])
-		}
+	if waypoints := match.Captures["waypoints"]; waypoints != "" {
+		result.Waypoints = parseWaypoints(strings.Split(waypoints, "/"))
 	}
 
 	return result
@@ -7,29 +7,29 @@ import "acars_parser/internal/patterns"
 var Formats = []patterns.Format{
 	// CSV position format (most common).
 	// Example: 221942,35989,2346, 118,N 47.983 E  9.626
-	// Fields: time (HHMMSS), altitude (feet), speed, track, lat_dir, lat, lon_dir, lon
+	// Fields: time (HHMMSS), altitude (feet), speed, track, lat, lon
 	{
 		Name: "csv_position",
-		Pattern: `^(?P<time>\d{6}),(?P<altitude>[+M]?\d+),(?P<speed>\d+),\s*(?P<track>\d+),` +
-			`(?P<lat_dir>[NS])\s*(?P<lat>[\d.]+)[,\s]+(?P<lon_dir>[EW])\s*(?P<lon>[\d.]+)`,
-		Fields: []string{"time", "altitude", "speed", "track", "lat_dir", "lat", "lon_dir", "lon"},
+		Pattern: `^(?P<time>\d{6}),[+M]?{NUM:altitude:int},{NUM:speed:int},\s*{NUM:track:int},` +
+			`{LAT_DEC_DIR:lat:dec_coord}[,\s]+{LON_DEC_DIR:lon:dec_coord}`,
+		Fields: []string{"time", "altitude", "speed", "track", "lat", "lon"},
 	},
 	// CSV position with missing altitude (still has valid coords).
 	// Example: 221641,,2249,  84,N 46.753 W122.356
 	{
 		Name: "csv_position_no_alt",
-		Pattern: `^(?P<time>\d{6}),,(?P<speed>\d+),\s*(?P<track>\d+),` +
-			`(?P<lat_dir>[NS])\s*(?P<lat>[\d.]+)[,\s]+(?P<lon_dir>[EW])\s*(?P<lon>[\d.]+)`,
-		Fields: []string{"time", "speed", "track", "lat_dir", "lat", "lon_dir", "lon"},
+		Pattern: `^(?P<time>\d{6}),,{NUM:speed:int},\s*{NUM:track:int},` +
+			`{LAT_DEC_DIR:lat:dec_coord}[,\s]+{LON_DEC_DIR:lon:dec_coord}`,
+		Fields: []string{"time", "speed", "track", "lat", "lon"},
 	},
 	// Extended CSV with flight number.
 	// Example: 221737,+20995,2233,9160,N 50.0547,E 8.2408,SXS67A  ,5,7,4,925760,/,
 	{
 		Name: "csv_position_extended",
-		Pattern: `^(?P<time>\d{6}),(?P<altitude>[+M]?\d+),(?P<speed>\d+),(?P<track>\d+),` +
-			`(?P<lat_dir>[NS])\s*(?P<lat>[\d.]+),(?P<lon_dir>[EW])\s*(?P<lon>[\d.]+),` +
+		Pattern: `^(?P<time>\d{6}),[+M]?{NUM:altitude:int},{NUM:speed:int},{NUM:track:int},` +
+			`{LAT_DEC_DIR:lat:dec_coord},{LON_DEC_DIR:lon:dec_coord},` +
 			`(?P<flight>\w+)`,
-		Fields: []string{"time", "altitude", "speed", "track", "lat_dir", "lat", "lon_dir", "lon", "flight"},
+		Fields: []string{"time", "altitude", "speed", "track", "lat", "lon", "flight"},
 	},
 	// Waypoint position format with M##A prefix.
 	// Format: M{seq:2}A{airline:2}{flight:4}{waypoint}  ,{coords}
@@ -40,37 +40,46 @@ var Formats = []patterns.Format{
 	{
 		Name: "waypoint_position_prefixed",
 		Pattern: `^M(?P<msg_seq>\d{2})A(?P<prefix_airline>[A-Z0-9]{2})(?P<prefix_flight>[A-Z0-9]{4})` +
-			`(?P<waypoint>[A-Z][A-Z0-9]*)\s*,(?P<lat_dir>[NS])\s*(?P<lat>[\d.]+),` +
-			`(?P<lon_dir>[EW])\s*(?P<lon>[\d.]+),(?P<altitude>\d+),\s*(?P<ground_speed>\d+),` +
-			`(?P<eta>\d+),\s*(?P<track>\d+)`,
-		Fields: []string{"msg_seq", "prefix_airline", "prefix_flight", "waypoint", "lat_dir", "lat", "lon_dir", "lon", "altitude", "ground_speed", "eta", "track"},
+			`(?P<waypoint>[A-Z][A-Z0-9]*)\s*,{LAT_DEC_DIR:lat:dec_coord},` +
+			`{LON_DEC_DIR:lon:dec_coord},{NUM:altitude:int},\s*{NUM:ground_speed:int},` +
+			`(?P<eta>\d+),\s*{NUM:track:int}`,
+		Fields: []string{"msg_seq", "prefix_airline", "prefix_flight", "waypoint", "lat", "lon", "altitude", "ground_speed", "eta", "track"},
 	},
 	// Plain waypoint position format (no M##A prefix).
 	// Example: BEGLA  ,N 47.555,E 18.028,40025,490,1934,030\TS180357,311225
 	// Waypoints are typically 2-5 letter ICAO identifiers, but some are numeric (e.g., 10000 on T932 near HKJK).
-	// Groups: waypoint, lat_dir, lat, lon_dir, lon, altitude, ground_speed, eta, track
+	// Groups: waypoint, lat, lon, altitude, ground_speed, eta, track
 	{
 		Name: "waypoint_position",
-		Pattern: `^(?P<waypoint>[A-Z0-9]{2,8})\s*,(?P<lat_dir>[NS])\s*(?P<lat>[\d.]+),` +
-			`(?P<lon_dir>[EW])\s*(?P<lon>[\d.]+),(?P<altitude>\d+),\s*(?P<ground_speed>\d+),` +
-			`(?P<eta>\d+),\s*(?P<track>\d+)`,
-		Fields: []string{"waypoint", "lat_dir", "lat", "lon_dir", "lon", "altitude", "ground_speed", "eta", "track"},
+		Pattern: `^(?P<waypoint>[A-Z0-9]{2,8})\s*,{LAT_DEC_DIR:lat:dec_coord},` +
+			`{LON_DEC_DIR:lon:dec_coord},{NUM:altitude:int},\s*{NUM:ground_speed:int},` +
+			`(?P<eta>\d+),\s*{NUM:track:int}`,
+		Fields: []string{"waypoint", "lat", "lon", "altitude", "ground_speed", "eta", "track"},
 	},
 	// POSA position format with two waypoint references and aircraft state data.
+	// Coordinates are thousandths of a degree without a decimal point.
 	// Example: POSA1N42851E 16405,GIS40  ,092609,380,ROTAR  ,100331,,-58, 22, 306,844
 	{
 		Name: "posa_position",
-		Pattern: `^(?P<reference>POSA)(?P<variant>\d)(?P<lat_dir>[NS])(?P<lat>\d{5})(?P<lon_dir>[EW])\s*(?P<lon>\d{5,6}),` +
-			`(?P<current_waypoint>[A-Z0-9-]{2,8})\s*,(?P<current_eta>\d{6}),(?P<altitude>\d+),` +
+		Pattern: `^(?P<reference>POSA)(?P<variant>\d)(?P<lat_dir>{LAT_DIR}){LAT_5D:lat:int}(?P<lon_dir>{LON_DIR})\s*{POSA_LON:lon:int},` +
+			`(?P<current_waypoint>[A-Z0-9-]{2,8})\s*,(?P<current_eta>\d{6}),{NUM:altitude:int},` +
 			`(?P<next_waypoint>[A-Z0-9-]{2,8})\s*,(?P<next_eta>\d{6}),,(?P<temperature>-?\d+|\*{5}),\s*` +
-			`(?P<wind>[-\d.]+),\s*(?P<fuel_on_board>\d+),\s*(?P<mach>\d{1,3})$`,
+			`{DEC:wind:int},\s*{NUM:fuel_on_board:int},\s*{POSA_MACH:mach:int}$`,
 		Fields: []string{"reference", "variant", "lat_dir", "lat", "lon_dir", "lon", "current_waypoint", "current_eta", "altitude", "next_waypoint", "next_eta", "temperature", "wind", "fuel_on_board", "mach"},
 	},
 	// AUTPOS format.
 	// Example: 035234/AUTPOS/LLD N440853 W0915239
 	{
-		Name: "autpos",
-		Pattern: `^(?P<time>\d{6})/AUTPOS/LLD\s+(?P<lat_dir>[NS])(?P<lat>\d{6})\s+(?P<lon_dir>[EW])(?P<lon>\d{7})`,
-		Fields: []string{"time", "lat_dir", "lat", "lon_dir", "lon"},
+		Name:    "autpos",
+		Pattern: `^(?P<time>\d{6})/AUTPOS/LLD\s+{AUTPOS_LAT:lat:coord}\s+{AUTPOS_LON:lon:coord}`,
+		Fields:  []string{"time", "lat", "lon"},
 	},
 }
+
+// localPatterns are the placeholders only the Label 16 formats use.
+var localPatterns = map[string]string{
+	"POSA_LON":   `\d{5,6}`,           // Thousandths of a degree
+	"POSA_MACH":  `\d{1,3}`,           // Thousandths of Mach
+	"AUTPOS_LAT": `{LAT_DIR}{LAT_6D}`, // N440853 (DDMMSS)
+	"AUTPOS_LON": `{LON_DIR}{LON_7D}`, // W0915239 (DDDMMSS)
+}
@@ -56,6 +56,34 @@ type Result struct {
 func (r *Result) Type() string     { return "waypoint_position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.  The reports give a wind
+// speed without a direction, so the position has no wind.
+func (r *Result) Position() (registry.Position, bool) {
+	p := registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.AltitudeFeet,
+		ReportTime:  registry.ReportTime(r.Time),
+		GroundSpeed: r.GroundSpeed,
+		Track:       r.Track,
+		Temperature: registry.ParseTemperature(r.Temperature),
+	}
+	if p.Altitude == 0 {
+		p.Altitude = r.FlightLevel * 100
+	}
+	return p, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.  The level may come as an
+// altitude in feet or a flight level.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "altitude", Present: r.AltitudeFeet != 0 || r.FlightLevel != 0},
+	)
+}
+
 // Parser parses Label 16 waypoint position messages.
 type Parser struct{}
 
@@ -71,7 +99,7 @@ var (
 // getCompiler returns the singleton grok compiler.
 func getCompiler() (*patterns.Compiler, error) {
 	grokOnce.Do(func() {
-		grokCompiler = patterns.NewCompiler(Formats, nil)
+		grokCompiler = patterns.NewCompiler(Formats, localPatterns)
 		grokErr = grokCompiler.Compile()
 	})
 	return grokCompiler, grokErr
@@ -85,6 +113,9 @@ func (p *Parser) Name() string     { return "label16" }
 func (p *Parser) Labels() []string { return []string{"16"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"waypoint_position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return true // Label check is sufficient for 16.
 }
@@ -118,57 +149,25 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 	// Handle different format types.
 	switch match.FormatName {
 	case "csv_position", "csv_position_no_alt", "csv_position_extended":
-		result.Latitude = patterns.ParseDecimalCoord(match.Captures["lat"], match.Captures["lat_dir"])
-		result.Longitude = patterns.ParseDecimalCoord(match.Captures["lon"], match.Captures["lon_dir"])
+		result.Latitude, _ = match.Float("lat")
+		result.Longitude, _ = match.Float("lon")
 		result.Time = match.Captures["time"]
-
-		// Parse altitude (may have + or M prefix).
-		if altStr := match.Captures["altitude"]; altStr != "" {
-			altStr = strings.TrimPrefix(altStr, "+")
-			altStr = strings.TrimPrefix(altStr, "M")
-			if alt, err := strconv.Atoi(altStr); err == nil {
-				if alt > 1000 {
-					result.FlightLevel = alt / 100
-				} else {
-					result.FlightLevel = alt
-				}
-			}
-		}
-
-		// Parse speed.
-		if speed, err := strconv.Atoi(match.Captures["speed"]); err == nil {
-			result.GroundSpeed = speed
-		}
-
-		// Parse track.
-		if track, err := strconv.Atoi(match.Captures["track"]); err == nil {
-			result.Track = track
+		if alt, ok := match.Int("altitude"); ok {
+			result.FlightLevel = flightLevel(alt)
 		}
+		result.GroundSpeed, _ = match.Int("speed")
+		result.Track, _ = match.Int("track")
 
 	case "waypoint_position", "waypoint_position_prefixed":
 		result.Waypoint = match.Captures["waypoint"]
-		result.Latitude = patterns.ParseDecimalCoord(match.Captures["lat"], match.Captures["lat_dir"])
-		result.Longitude = patterns.ParseDecimalCoord(match.Captures["lon"], match.Captures["lon_dir"])
+		result.Latitude, _ = match.Float("lat")
+		result.Longitude, _ = match.Float("lon")
 		result.ETA = match.Captures["eta"]
-
-		// Convert altitude to flight level.
-		if alt, err := strconv.Atoi(match.Captures["altitude"]); err == nil {
-			if alt > 1000 {
-				result.FlightLevel = alt / 100
-			} else {
-				result.FlightLevel = alt
-			}
-		}
-
-		// Parse ground speed.
-		if gs, err := strconv.Atoi(match.Captures["ground_speed"]); err == nil {
-			result.GroundSpeed = gs
-		}
-
-		// Parse track.
-		if track, err := strconv.Atoi(match.Captures["track"]); err == nil {
-			result.Track = track
+		if alt, ok := match.Int("altitude"); ok {
+			result.FlightLevel = flightLevel(alt)
 		}
+		result.GroundSpeed, _ = match.Int("ground_speed")
+		result.Track, _ = match.Int("track")
 
 		// For prefixed format, extract and flatten the flight identifier.
 		// Flattening removes leading zeros (e.g., "007K" -> "7K") to match ACARS envelope format.
@@ -190,36 +189,26 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 			{Name: result.NextWaypoint, ETA: result.NextWaypointETA},
 		}
 		result.ETA = result.NextWaypointETA
-		result.Latitude = parsePOSADecimalCoord(match.Captures["lat"], match.Captures["lat_dir"])
-		result.Longitude = parsePOSADecimalCoord(match.Captures["lon"], match.Captures["lon_dir"])
+		lat, _ := match.Int("lat")
+		lon, _ := match.Int("lon")
+		result.Latitude = thousandths(lat, match.Captures["lat_dir"])
+		result.Longitude = thousandths(lon, match.Captures["lon_dir"])
 		result.Temperature = strings.TrimSpace(match.Captures["temperature"])
 		result.Wind = strings.TrimSpace(match.Captures["wind"])
-
-		if alt, err := strconv.Atoi(match.Captures["altitude"]); err == nil {
-			if alt > 1000 {
-				result.FlightLevel = alt / 100
-			} else {
-				result.FlightLevel = alt
-			}
-		}
-
-		if windSpeed, err := strconv.Atoi(result.Wind); err == nil {
-			result.WindSpeed = windSpeed
-		}
-
-		if fuelOnBoard, err := strconv.Atoi(strings.TrimSpace(match.Captures["fuel_on_board"])); err == nil {
-			result.FuelOnBoard = fuelOnBoard
+		if alt, ok := match.Int("altitude"); ok {
+			result.FlightLevel = flightLevel(alt)
 		}
-
-		if mach, err := strconv.Atoi(match.Captures["mach"]); err == nil {
+		result.WindSpeed, _ = match.Int("wind")
+		result.FuelOnBoard, _ = match.Int("fuel_on_board")
+		if mach, ok := match.Int("mach"); ok {
 			result.Mach = float64(mach) / 1000.0
 		}
 
 	case "autpos":
 		// AUTPOS has compact lat/lon format: N440853 W0915239 = N44°08'53" W091°52'39"
 		result.Time = match.Captures["time"]
-		result.Latitude = parseCompactCoord(match.Captures["lat"], match.Captures["lat_dir"])
-		result.Longitude = parseCompactCoord(match.Captures["lon"], match.Captures["lon_dir"])
+		result.Latitude, _ = match.Float("lat")
+		result.Longitude, _ = match.Float("lon")
 
 	default:
 		return nil
@@ -316,34 +305,22 @@ func parsePOS02(msg *acars.Message) *Result {
 	return result
 }
 
-// parseCompactCoord parses compact format like "440853" (44°08'53") to decimal degrees.
-func parseCompactCoord(coord, dir string) float64 {
-	if coord == "" {
-		return 0
+// flightLevel reads a reported altitude as a flight level: values above 1000
+// are in feet.
+func flightLevel(alt int) int {
+	if alt > 1000 {
+		return alt / 100
 	}
+	return alt
+}
 
-	var deg, min, sec float64
-
-	switch len(coord) {
-	case 6: // DDMMSS (latitude).
-		deg, _ = strconv.ParseFloat(coord[0:2], 64)
-		min, _ = strconv.ParseFloat(coord[2:4], 64)
-		sec, _ = strconv.ParseFloat(coord[4:6], 64)
-	case 7: // DDDMMSS (longitude).
-		deg, _ = strconv.ParseFloat(coord[0:3], 64)
-		min, _ = strconv.ParseFloat(coord[3:5], 64)
-		sec, _ = strconv.ParseFloat(coord[5:7], 64)
-	default:
-		return 0
-	}
-
-	result := deg + min/60 + sec/3600
-
+// thousandths reads a POSA coordinate given in thousandths of a degree.
+func thousandths(value int, dir string) float64 {
+	decimal := float64(value) / 1000.0
 	if dir == "S" || dir == "W" {
-		result = -result
+		return -decimal
 	}
-
-	return result
+	return decimal
 }
 
 // parsePOSADecimalCoord parses POSA coordinates encoded as thousandths of a degree without a decimal point.
@@ -360,13 +337,7 @@ func parsePOSADecimalCoord(coord, dir string) float64 {
 	if err != nil {
 		return 0
 	}
-
-	decimal := float64(value) / 1000.0
-	if dir == "S" || dir == "W" {
-		return -decimal
-	}
-
-	return decimal
+	return thousandths(value, dir)
 }
 
 // formatETA converts HHMMSS values to HH:MM:SS and leaves other formats unchanged.
@@ -2,6 +2,7 @@
 package label17
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"sync"
@@ -49,6 +50,30 @@ type Result struct {
 func (r *Result) Type() string     { return "label17" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.AltitudeFt,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		GroundSpeed: r.GroundSpeedKts,
+		Track:       int(math.Round(r.TrackDeg)),
+		Temperature: registry.Temperature(float64(r.TemperatureC)),
+		Wind:        registry.NewWind(int(math.Round(r.WindDirectionDeg)), r.WindSpeedKts),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "time", Present: r.ReportTime != ""},
+		registry.Field{Name: "altitude_ft", Present: r.AltitudeFt != 0},
+	)
+}
+
 // Parser parses Label 17 messages.
 type Parser struct{}
 
@@ -71,9 +96,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "label17" }
-func (p *Parser) Labels() []string { return []string{"17"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "label17" }
+func (p *Parser) Labels() []string           { return []string{"17"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	// Label-based dispatch is already strong; keep this cheap.
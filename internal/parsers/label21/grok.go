@@ -11,7 +11,7 @@ var Formats = []patterns.Format{
 	{
 		Name: "posn_report",
 		Pattern: `POSN\s+(?P<lat>{LAT_DEC})(?P<lon_dir>{LON_DIR})(?P<lon>[\d.]+),\s*` +
-			`(?P<heading>\d+),(?P<time>\d+),(?P<altitude>\d+),(?P<fob>\d+),\s*` +
+			`{NUM:heading:int},(?P<time>\d+),{NUM:altitude:int},{NUM:fob:int},\s*` +
 			`(?P<wind>[-\d ]+),\s*(?P<temp>[-\d ]+),(?P<eta>\d+),(?P<dest>{ICAO})`,
 		Fields: []string{"lat", "lon_dir", "lon", "heading", "time", "altitude", "fob", "wind", "temp", "eta", "dest"},
 	},
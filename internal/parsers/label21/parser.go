@@ -2,7 +2,6 @@
 package label21
 
 import (
-	"strconv"
 	"strings"
 	"sync"
 
@@ -30,6 +29,28 @@ type Result struct {
 func (r *Result) Type() string     { return "position_report" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.Altitude,
+		Track:       r.Heading,
+		Temperature: registry.ParseTemperature(r.Temperature),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "altitude", Present: r.Altitude != 0},
+		registry.Field{Name: "heading", Present: r.Heading != 0},
+		registry.Field{Name: "destination", Present: r.Destination != ""},
+	)
+}
+
 // Parser parses Label 21 position report messages.
 type Parser struct{}
 
@@ -57,6 +78,9 @@ func (p *Parser) Name() string     { return "label21" }
 func (p *Parser) Labels() []string { return []string{"21"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"position_report"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(text, "POSN")
 }
@@ -89,20 +113,9 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 		Destination: match.Captures["dest"],
 	}
 
-	// Parse heading.
-	if hdg, err := strconv.Atoi(match.Captures["heading"]); err == nil {
-		result.Heading = hdg
-	}
-
-	// Parse altitude.
-	if alt, err := strconv.Atoi(match.Captures["altitude"]); err == nil {
-		result.Altitude = alt
-	}
-
-	// Parse fuel on board.
-	if fob, err := strconv.Atoi(match.Captures["fob"]); err == nil {
-		result.FuelOnBoard = fob
-	}
+	result.Heading, _ = match.Int("heading")
+	result.Altitude, _ = match.Int("altitude")
+	result.FuelOnBoard, _ = match.Int("fob")
 
 	return result
 }
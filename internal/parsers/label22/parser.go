@@ -30,6 +30,33 @@ type Result struct {
 func (r *Result) Type() string     { return "label22_position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	p := registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.Altitude,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		GroundSpeed: r.GroundSpeed,
+		Track:       r.Track,
+	}
+	if p.Altitude == 0 {
+		p.Altitude = r.FlightLevel * 100
+	}
+	return p, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.  The level may come as an
+// altitude or a flight level.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "altitude", Present: r.Altitude != 0 || r.FlightLevel != 0},
+	)
+}
+
 // Parser parses Label 22 detailed position messages.
 type Parser struct{}
 
@@ -57,6 +84,9 @@ func (p *Parser) Name() string     { return "label22" }
 func (p *Parser) Labels() []string { return []string{"22"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"label22_position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.HasPrefix(text, "N ") || strings.HasPrefix(text, "S ")
 }
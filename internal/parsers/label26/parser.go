@@ -40,6 +40,30 @@ type Result struct {
 func (r *Result) Type() string     { return "eta_report" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		Temperature: registry.Temperature(float64(r.Temperature)),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeed),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.  An ETA report is about the
+// route, so its position counts for less.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "origin_icao", Present: r.OriginICAO != "", Weight: 2},
+		registry.Field{Name: "dest_icao", Present: r.DestICAO != "", Weight: 2},
+		registry.Field{Name: "eta", Present: r.ETA != ""},
+		registry.Field{Name: "latitude", Present: r.Latitude != 0},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0},
+	)
+}
+
 // Parser parses Label 26 ETA messages.
 type Parser struct{}
 
@@ -92,6 +116,9 @@ func (p *Parser) Name() string     { return "label26" }
 func (p *Parser) Labels() []string { return []string{"26"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"eta_report"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
 	// Check for ETA or ALT format identifier at start
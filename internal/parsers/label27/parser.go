@@ -34,12 +34,38 @@ type Result struct {
 	ETA         string  `json:"eta,omitempty"`            // ETA time
 	Waypoint    string  `json:"waypoint,omitempty"`       // Waypoint identifier
 
-	AltitudeM int `json:"altitude_m,omitempty"` // ALT value in meters
+	AltitudeM  int `json:"altitude_m,omitempty"`  // ALT value in meters
+	AltitudeFt int `json:"altitude_ft,omitempty"` // ALT value in feet, as reported
 }
 
 func (r *Result) Type() string     { return "position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.  The altitude is ALT's;
+// the AFL number is not used, since it is often an Aeroflot flight number.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.AltitudeFt,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		Temperature: registry.Temperature(float64(r.Temperature)),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeed),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "altitude", Present: r.AltitudeFt != 0},
+		registry.Field{Name: "origin_icao", Present: r.OriginICAO != ""},
+		registry.Field{Name: "dest_icao", Present: r.DestICAO != ""},
+	)
+}
+
 // Parser parses Label 27 position messages.
 type Parser struct{}
 
@@ -92,6 +118,9 @@ func (p *Parser) Name() string     { return "label27" }
 func (p *Parser) Labels() []string { return []string{"27"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
 	// Check for POS format identifier at start
@@ -214,6 +243,7 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 	// Parse altitude
 	if m := altRe.FindStringSubmatch(upper); m != nil {
 		if alt, err := strconv.Atoi(m[1]); err == nil {
+			result.AltitudeFt = alt
 			// Convert feet to meters (1 foot = 0.3048 meters) - truncate
 			result.AltitudeM = int(float64(alt) * 0.3048)
 			// Calculate flight level from altitude in feet (divide by 100)
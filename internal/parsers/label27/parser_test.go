@@ -251,6 +251,28 @@ func TestLabel27Parser(t *testing.T) {
 	}
 }
 
+func TestLabel27PositionAltitude(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"POS01AFL1866 /16180720UUEEUDYZ LATN 55.164 LONE 38.545 ALT 21728", 21728},
+		{"POS01AFL1866 /16180720UUEEUDYZ LATN 55.164 LONE 38.545", 0},
+		{"POS01AFL250 /06060606KSFOKLAX LATN 37.600 LONW 122.400", 0},
+	}
+
+	p := &Parser{}
+	for _, tt := range tests {
+		r, ok := p.Parse(&acars.Message{Label: "27", Text: tt.text}).(*Result)
+		if !ok {
+			t.Fatalf("Parse(%q) returned no result", tt.text)
+		}
+		if pos, ok := r.Position(); !ok || pos.Altitude != tt.want {
+			t.Errorf("Parse(%q).Position() altitude = %d (ok %v), want %d", tt.text, pos.Altitude, ok, tt.want)
+		}
+	}
+}
+
 func TestLabel27QuickCheck(t *testing.T) {
 	p := &Parser{}
 
@@ -41,6 +41,31 @@ type Result struct {
 func (r *Result) Type() string     { return "position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		ReportTime:  registry.ReportTime(r.Time),
+		GroundSpeed: r.GroundSpeed,
+		Temperature: registry.Temperature(float64(r.Temperature)),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeedKts),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "time", Present: r.Time != ""},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+		registry.Field{Name: "origin_icao", Present: r.OriginICAO != ""},
+		registry.Field{Name: "dest_icao", Present: r.DestICAO != ""},
+	)
+}
+
 // Parser parses Label 33 position messages.
 type Parser struct{}
 
@@ -52,6 +77,9 @@ func (p *Parser) Name() string     { return "label33" }
 func (p *Parser) Labels() []string { return []string{"33"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	// Quick check: should contain date format and coordinates
 	return strings.Contains(text, ",N") || strings.Contains(text, ",S")
@@ -31,6 +31,23 @@ type Result struct {
 func (r *Result) Type() string     { return "position_status" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{Latitude: r.Latitude, Longitude: r.Longitude}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.  These are route and fuel
+// reports, so their position counts for less.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "origin_icao", Present: r.OriginICAO != "", Weight: 2},
+		registry.Field{Name: "dest_icao", Present: r.DestICAO != "", Weight: 2},
+		registry.Field{Name: "eta", Present: r.ETA != ""},
+		registry.Field{Name: "latitude", Present: r.Latitude != 0},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0},
+	)
+}
+
 // Parser parses Label 39 messages.
 type Parser struct{}
 
@@ -63,6 +80,9 @@ func (p *Parser) Name() string     { return "label39" }
 func (p *Parser) Labels() []string { return []string{"39"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"position_status"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
 	// Check for known header patterns followed by AFL
@@ -15,30 +15,36 @@ var Formats = []patterns.Format{
 	},
 	// FB (Flight Brief) position format.
 	// Example: /FB 01/AD YSSY/S 33.50,E 151.30,QFA123,INA03,YMML,1234
-	// Groups: fb, airport, lat_dir, lat, lon_dir, lon, callsign, unknown, dest, time
+	// Groups: fb, airport, lat, lon, callsign, unknown, dest, time
 	{
 		Name: "fb_position",
 		Pattern: `/FB\s*(?P<fb>\d+)/AD\s*(?P<airport>{ICAO})/` +
-			`(?P<lat_dir>{LAT_DIR})\s*(?P<lat>[\d.]+),` +
-			`(?P<lon_dir>{LON_DIR})\s*(?P<lon>[\d.]+),` +
+			`{LAT_DEC_DIR:lat:dec_coord},{LON_DEC_DIR:lon:dec_coord},` +
 			`(?P<callsign>[A-Z0-9]+),(?P<unknown>[^,]+),(?P<dest>{ICAO}),(?P<time>{TIME4})`,
-		Fields: []string{"fb", "airport", "lat_dir", "lat", "lon_dir", "lon", "callsign", "unknown", "dest", "time"},
+		Fields: []string{"fb", "airport", "lat", "lon", "callsign", "unknown", "dest", "time"},
 	},
 	// POS position report format.
 	// Example: POS01,S33561E151234,350,YSSY,YMML,1234,1530
-	// Groups: unknown, lat_dir, lat, lon_dir, lon, fl, origin, dest, time1, time2
+	// Groups: unknown, lat, lon, fl, origin, dest, time1, time2
 	{
 		Name: "pos_report",
-		Pattern: `^POS(?P<unknown>\d{2}),(?P<lat_dir>{LAT_DIR})(?P<lat>\d{5,6})` +
-			`(?P<lon_dir>{LON_DIR})(?P<lon>\d{5,6}),(?P<fl>\d{1,3}),` +
+		Pattern: `^POS(?P<unknown>\d{2}),{POS_LAT:lat:coord}{POS_LON:lon:coord},{POS_FL:fl:int},` +
 			`(?P<origin>{ICAO}),(?P<dest>{ICAO}),(?P<time1>{TIME4}),(?P<time2>{TIME4})`,
-		Fields: []string{"unknown", "lat_dir", "lat", "lon_dir", "lon", "fl", "origin", "dest", "time1", "time2"},
+		Fields: []string{"unknown", "lat", "lon", "fl", "origin", "dest", "time1", "time2"},
 	},
 	// Individual runway line pattern.
 	// Groups: runway, suffix, distance
 	{
 		Name:    "runway_line",
-		Pattern: `^(?P<runway>\d{2})(?:/(?P<suffix>[A-Z0-9]+))?\s+.*?(?P<distance>\d{4,5})\s*$`,
+		Pattern: `^(?P<runway>\d{2})(?:/(?P<suffix>[A-Z0-9]+))?\s+.*?{DISTANCE:distance:int}\s*$`,
 		Fields:  []string{"runway", "suffix", "distance"},
 	},
 }
+
+// localPatterns are the placeholders only the Label 44 formats use.
+var localPatterns = map[string]string{
+	"POS_LAT":  `{LAT_DIR}\d{5,6}`, // S33561 (DDMMD)
+	"POS_LON":  `{LON_DIR}\d{5,6}`, // E151234 (DDDMMD)
+	"POS_FL":   `\d{1,3}`,
+	"DISTANCE": `\d{4,5}`, // Runway length
+}
@@ -21,7 +21,7 @@ var (
 
 func getCompiler() (*patterns.Compiler, error) {
 	grokOnce.Do(func() {
-		grokCompiler = patterns.NewCompiler(Formats, nil)
+		grokCompiler = patterns.NewCompiler(Formats, localPatterns)
 		grokErr = grokCompiler.Compile()
 	})
 	return grokCompiler, grokErr
@@ -59,6 +59,41 @@ type Result struct {
 func (r *Result) Type() string     { return "label44" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:   r.Latitude,
+		Longitude:  r.Longitude,
+		Altitude:   r.FlightLevel * 100,
+		ReportTime: registry.ReportTime(r.ReportTime),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter for each kind of message.
+func (r *Result) Quality() registry.Quality {
+	if r.MessageType == "runway" {
+		return registry.Assess(
+			registry.Field{Name: "airport", Present: r.Airport != "", Weight: 2},
+			registry.Field{Name: "runways", Present: len(r.Runways) > 0, Weight: 2},
+		)
+	}
+	fields := []registry.Field{
+		{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		{Name: "report_time", Present: r.ReportTime != ""},
+		{Name: "destination", Present: r.Destination != ""},
+	}
+	switch r.MessageType {
+	case "fb":
+		fields = append(fields, registry.Field{Name: "airport", Present: r.Airport != ""})
+	case "pos":
+		fields = append(fields,
+			registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+			registry.Field{Name: "origin", Present: r.Origin != ""})
+	}
+	return registry.Assess(fields...)
+}
+
 // Parser parses Label 44 messages.
 type Parser struct{}
 
@@ -66,9 +101,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "label44" }
-func (p *Parser) Labels() []string { return []string{"44"} }
-func (p *Parser) Priority() int    { return 100 }
+func (p *Parser) Name() string               { return "label44" }
+func (p *Parser) Labels() []string           { return []string{"44"} }
+func (p *Parser) Priority() int              { return 100 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	// Skip encoded/binary messages
@@ -180,9 +216,7 @@ func (p *Parser) parseRunwayInfo(msg *acars.Message, text string) *Result {
 				Runway: lineMatch.Captures["runway"],
 				Suffix: lineMatch.Captures["suffix"],
 			}
-			if dist, err := strconv.Atoi(lineMatch.Captures["distance"]); err == nil {
-				rwy.Distance = dist
-			}
+			rwy.Distance, _ = lineMatch.Int("distance")
 			result.Runways = append(result.Runways, rwy)
 		}
 	}
@@ -215,21 +249,8 @@ func (p *Parser) parseFBPosition(msg *acars.Message, text string) *Result {
 		RawData:         match.Captures["unknown"], // Unknown field (INA03, INR03, etc.)
 	}
 
-	// Parse latitude.
-	if lat, err := strconv.ParseFloat(match.Captures["lat"], 64); err == nil {
-		if match.Captures["lat_dir"] == "S" {
-			lat = -lat
-		}
-		result.Latitude = lat
-	}
-
-	// Parse longitude.
-	if lon, err := strconv.ParseFloat(match.Captures["lon"], 64); err == nil {
-		if match.Captures["lon_dir"] == "W" {
-			lon = -lon
-		}
-		result.Longitude = lon
-	}
+	result.Latitude, _ = match.Float("lat")
+	result.Longitude, _ = match.Float("lon")
 
 	return result
 }
@@ -257,16 +278,10 @@ func (p *Parser) parsePOSReport(msg *acars.Message, text string) *Result {
 		ReportTime:      match.Captures["time1"],
 	}
 
-	// Parse latitude (format: DDMMD - 2 degree digits, tenths of minutes).
-	result.Latitude = patterns.ParseLatitude(match.Captures["lat"], match.Captures["lat_dir"])
-
-	// Parse longitude (format: DDDMMD - 3 degree digits, tenths of minutes).
-	result.Longitude = patterns.ParseLongitude(match.Captures["lon"], match.Captures["lon_dir"])
-
-	// Parse flight level.
-	if fl, err := strconv.Atoi(match.Captures["fl"]); err == nil {
-		result.FlightLevel = fl
-	}
+	// Coordinates are DDMMD and DDDMMD, in tenths of minutes.
+	result.Latitude, _ = match.Float("lat")
+	result.Longitude, _ = match.Float("lon")
+	result.FlightLevel, _ = match.Int("fl")
 
 	return result
 }
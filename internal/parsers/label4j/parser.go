@@ -30,6 +30,27 @@ type Result struct {
 func (r *Result) Type() string     { return "pos_weather" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.Altitude,
+		Track:       r.Heading,
+		Temperature: registry.ParseTemperature(r.Temperature),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "altitude", Present: r.Altitude != 0},
+		registry.Field{Name: "heading", Present: r.Heading != 0},
+	)
+}
+
 // Parser parses Label 4J position + weather messages.
 type Parser struct{}
 
@@ -57,6 +78,9 @@ func (p *Parser) Name() string     { return "label4j" }
 func (p *Parser) Labels() []string { return []string{"4J"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"pos_weather"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.HasPrefix(text, "POS")
 }
@@ -34,6 +34,15 @@ type Result struct {
 func (r *Result) Type() string     { return "route" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "callsign", Present: r.Callsign != ""},
+		registry.Field{Name: "origin_icao", Present: r.OriginICAO != "", Weight: 2},
+		registry.Field{Name: "dest_icao", Present: r.DestICAO != "", Weight: 2},
+	)
+}
+
 // Parser parses Label 5L route messages.
 type Parser struct{}
 
@@ -61,6 +70,9 @@ func (p *Parser) Name() string     { return "label5l" }
 func (p *Parser) Labels() []string { return []string{"5L"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"route"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	// 5L messages have comma-delimited format.
 	return strings.Contains(text, ",")
@@ -48,6 +48,44 @@ type Result struct {
 func (r *Result) Type() string     { return "position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.  Altitude holds the flight
+// level ALT or FL gives, so it is multiplied out to feet.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.Altitude * 100,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		Temperature: registry.Temperature(float64(r.OAT)),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeed),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.  Position reports are
+// expected to carry a position and altitude, OOOI reports their event time.
+func (r *Result) Quality() registry.Quality {
+	fields := []registry.Field{
+		{Name: "origin_icao", Present: r.OriginICAO != ""},
+		{Name: "dest_icao", Present: r.DestICAO != ""},
+	}
+	switch r.MsgType {
+	case "POS", "POSRPT", "FLT":
+		fields = append(fields,
+			registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+			registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+			registry.Field{Name: "altitude", Present: r.Altitude != 0})
+	case "OUTRP":
+		fields = append(fields, registry.Field{Name: "out_time", Present: r.OutTime != ""})
+	case "OFFRP":
+		fields = append(fields, registry.Field{Name: "off_time", Present: r.OffTime != ""})
+	case "ONRP":
+		fields = append(fields, registry.Field{Name: "on_time", Present: r.OnTime != ""})
+	case "INRP":
+		fields = append(fields, registry.Field{Name: "in_time", Present: r.InTime != ""})
+	}
+	return registry.Assess(fields...)
+}
+
 // Parser parses Label 80 and Label 23 position/status messages.
 type Parser struct{}
 
@@ -75,6 +113,9 @@ func (p *Parser) Name() string     { return "label80" }
 func (p *Parser) Labels() []string { return []string{"80", "23"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return true // Label check is sufficient for 80.
 }
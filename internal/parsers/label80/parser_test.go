@@ -148,3 +148,33 @@ func TestPOSShortForm(t *testing.T) {
 		t.Errorf("TAS: got %d, want 0 (not present)", result.TAS)
 	}
 }
+
+// TestPOSPosition tests that the flight level is reported in feet, with the
+// report time, temperature and wind.
+func TestPOSPosition(t *testing.T) {
+	parser := &Parser{}
+	msg := &acars.Message{
+		Text: "POSHAAB/DGAA/LATN08406/LONE037312/ALT282/FOB32171/TME0636/WND -34 7/OAT-24/TAS469/ETA1121",
+	}
+
+	result, ok := parser.Parse(msg).(*Result)
+	if !ok {
+		t.Fatal("Parse returned no *Result")
+	}
+	pos, ok := result.Position()
+	if !ok {
+		t.Fatal("Position: ok = false")
+	}
+	if pos.Altitude != 28200 {
+		t.Errorf("Altitude: got %d, want 28200 ft (FL282)", pos.Altitude)
+	}
+	if pos.ReportTime != "06:36:00" {
+		t.Errorf("ReportTime: got %q, want %q", pos.ReportTime, "06:36:00")
+	}
+	if pos.Temperature == nil || *pos.Temperature != -24 {
+		t.Errorf("Temperature: got %v, want -24", pos.Temperature)
+	}
+	if pos.Wind == nil || pos.Wind.Direction != 326 || pos.Wind.Speed != 7 {
+		t.Errorf("Wind: got %+v, want 326° at 7 kt", pos.Wind)
+	}
+}
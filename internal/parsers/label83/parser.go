@@ -2,6 +2,7 @@
 package label83
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,6 +41,34 @@ type Result struct {
 func (r *Result) Type() string     { return "label83_position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.  The six-digit time of
+// ZSPD reports starts with the day, so they are left to the message time.
+func (r *Result) Position() (registry.Position, bool) {
+	p := registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		GroundSpeed: int(math.Round(r.GroundSpeed)),
+		Track:       r.Heading,
+		Temperature: registry.Temperature(float64(r.TemperatureC)),
+		Wind:        registry.NewWind(r.WindDirection, r.WindSpeedKts),
+	}
+	if r.MessageType != "ZSPD" {
+		p.ReportTime = registry.ReportTime(r.ReportTime)
+	}
+	return p, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+	)
+}
+
 // Parser parses Label 83 position report messages.
 type Parser struct{}
 
@@ -67,6 +96,9 @@ func (p *Parser) Name() string     { return "label83" }
 func (p *Parser) Labels() []string { return []string{"83"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"label83_position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	t := strings.TrimSpace(text)
 	if strings.Contains(t, "PR") || strings.Contains(t, "ZSPD") || strings.Contains(t, "POSRPT") {
@@ -163,4 +163,10 @@ func TestLabel83POSRPT(t *testing.T) {
 	if math.Abs(result.GroundSpeed-495) > 0.1 {
 		t.Errorf("ground_speed = %.1f, want 495", result.GroundSpeed)
 	}
+
+	p, ok := result.Position()
+	if !ok || p.Altitude != 40000 || p.ReportTime != "03:40:00" || p.GroundSpeed != 495 || p.Track != 65 ||
+		p.Temperature == nil || *p.Temperature != -52 || p.Wind == nil || p.Wind.Speed != 67 {
+		t.Errorf("Position = %+v, %v", p, ok)
+	}
 }
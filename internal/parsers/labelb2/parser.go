@@ -27,6 +27,17 @@ type Result struct {
 func (r *Result) Type() string     { return "oceanic_clearance" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "flight_num", Present: r.FlightNum != "", Weight: 2},
+		registry.Field{Name: "destination", Present: r.Destination != ""},
+		registry.Field{Name: "oceanic_fixes", Present: len(r.OceanicFix) > 0, Weight: 2},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != ""},
+		registry.Field{Name: "mach", Present: r.Mach != ""},
+	)
+}
+
 // Parser parses Label B2 oceanic clearance messages.
 type Parser struct{}
 
@@ -54,6 +65,9 @@ func (p *Parser) Name() string     { return "labelb2" }
 func (p *Parser) Labels() []string { return []string{"B2"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"oceanic_clearance"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return true // Label check is sufficient for B2.
 }
@@ -56,6 +56,9 @@ func (p *Parser) Name() string     { return "labelb3" }
 func (p *Parser) Labels() []string { return []string{"B3"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"gate_info"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	return true // Label check is sufficient for B3.
 }
@@ -44,6 +44,9 @@ func (p *Parser) Name() string     { return "landingdata" }
 func (p *Parser) Labels() []string { return []string{"C1"} }
 func (p *Parser) Priority() int    { return 70 } // High priority for specific messages.
 
+func (p *Parser) ResultTypes() []string      { return []string{"landing_data"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 // QuickCheck looks for landing data keywords.
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
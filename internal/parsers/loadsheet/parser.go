@@ -47,9 +47,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "loadsheet" }
-func (p *Parser) Labels() []string { return nil }
-func (p *Parser) Priority() int    { return 60 } // Higher priority than weather.
+func (p *Parser) Name() string               { return "loadsheet" }
+func (p *Parser) Labels() []string           { return nil }
+func (p *Parser) Priority() int              { return 60 } // Higher priority than weather.
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 // QuickCheck looks for loadsheet keywords.
 func (p *Parser) QuickCheck(text string) bool {
@@ -80,6 +80,9 @@ func (p *Parser) Name() string     { return "mediaadv" }
 func (p *Parser) Labels() []string { return []string{"SA"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"media_advisory"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	// Media Advisory format: 0[E/L][link]HHMMSS[links]/[text]
 	// Minimum length: 10 chars (0EV123456V).
@@ -0,0 +1,319 @@
+package miam
+
+// Native decoding of raw MIAM single-transfer frames, for decoders that pass
+// the label "MA" text through without libacars (acarsdec, dumpvdl2/dumphfdl
+// built without it, or a JAERO line without its decoded block).
+//
+// A single-transfer frame is the frame ID 'T', two ACF characters, the MIAM
+// CORE PDU header in base-85 ended by '|', and the body:
+//
+//	T-2!<<+4/K&,Z:0(8\!!,VF6Q/C3ZKWQ@|A350,000038,1,1,TB000000/REP502,...
+//
+// The header is padded with zero bytes to a whole base-85 group.  An
+// uncompressed body is the inner message text; a deflate body is base-85
+// encoded.
+//
+// Data PDU header (version 1):
+//
+//	byte 0       PDU type (high nibble: 0 data, 1 ack, 2 aloha, 3 aloha reply)
+//	             and version (low nibble)
+//	bytes 1-3    PDU length, header and body
+//	bytes 4-10   aircraft ID
+//	byte 11      message number (7 bits), ACK required (1 bit)
+//	bytes 12-13  compression (3 bits), encoding (2 bits), application type (4 bits)
+//	             application ID: 2, 4 or 6 characters by application type
+//	             (ACARS label, sublabel)
+//	             CRC-32 ARINC 665 of the inner message
+//
+// Ack and aloha PDUs are reported with their common header fields only.
+// Version 2 lays its headers out differently and is rejected as unsupported.
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/crc"
+)
+
+const (
+	pduData       = 0
+	pduAck        = 1
+	pduAloha      = 2
+	pduAlohaReply = 3
+)
+
+const (
+	compressionNone    = 0
+	compressionDeflate = 1
+)
+
+var errShortHeader = errors.New("miam: PDU header too short")
+
+// isCoreFrame reports whether text looks like a raw single-transfer frame: 'T',
+// two ACF characters and a base-85 header ended by '|'.
+func isCoreFrame(text string) bool {
+	text = strings.TrimSpace(text)
+	end := strings.IndexByte(text, '|')
+	if len(text) < 4 || text[0] != 'T' || end < 8 {
+		return false
+	}
+	for i := 3; i < end; i++ {
+		if !isBase85(text[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isBase85(c byte) bool {
+	return (c >= '!' && c <= 'u') || c == 'z'
+}
+
+// decodeBase85 decodes the base-85 encoding used by MIAM: groups of five
+// characters '!'..'u' for four bytes, 'z' for four zero bytes, and a short
+// final group of n characters for n-1 bytes.  Line breaks are ignored.
+func decodeBase85(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s)*4/5+4)
+	var group [5]byte
+	n := 0
+	flush := func() error {
+		var v uint64
+		for i := 0; i < 5; i++ {
+			c := byte('u')
+			if i < n {
+				c = group[i]
+			}
+			v = v*85 + uint64(c-'!')
+		}
+		if n == 5 && v > 0xFFFFFFFF {
+			return fmt.Errorf("miam: base-85 group %q out of range", group[:])
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(v))
+		out = append(out, b[:n-1]...)
+		n = 0
+		return nil
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\r' || c == '\n' || c == ' ':
+			continue
+		case c == 'z' && n == 0:
+			out = append(out, 0, 0, 0, 0)
+			continue
+		case c < '!' || c > 'u':
+			return nil, fmt.Errorf("miam: invalid base-85 character %q", c)
+		}
+		group[n] = c
+		n++
+		if n == 5 {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	switch n {
+	case 0:
+	case 1:
+		return nil, errors.New("miam: truncated base-85 group")
+	default:
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// DecodeFrame decodes a raw single-transfer MIAM frame.  It returns nil and an
+// error when the frame or its PDU header cannot be read.  A Data PDU whose
+// inner message fails to decompress is returned together with the error,
+// holding what could be recovered; a CRC mismatch is reported in CRCOK only.
+func DecodeFrame(text string) (*Result, error) {
+	text = strings.TrimSpace(text)
+	if !isCoreFrame(text) {
+		return nil, errors.New("miam: not a single-transfer frame")
+	}
+	hdrText, body, _ := strings.Cut(text[3:], "|")
+	hdr, err := decodeBase85(hdrText)
+	if err != nil {
+		return nil, err
+	}
+	if len(hdr) < 11 {
+		return nil, errShortHeader
+	}
+
+	r := &Result{
+		TransferType: "Single Transfer",
+		Version:      int(hdr[0] & 0x0f),
+		PDULength:    int(hdr[1])<<16 | int(hdr[2])<<8 | int(hdr[3]),
+		AircraftID:   string(hdr[4:11]),
+	}
+	if r.Version != 1 {
+		return nil, fmt.Errorf("miam: unsupported MIAM CORE version %d", r.Version)
+	}
+	switch hdr[0] >> 4 {
+	case pduData:
+		r.MessageType = "miam_data"
+		if err := decodeDataHeader(r, hdr); err != nil {
+			return nil, err
+		}
+		if err := decodeDataBody(r, body); err != nil {
+			r.DecodeError = err.Error()
+			return r, err
+		}
+	case pduAck:
+		r.MessageType = "miam_ack"
+		if len(hdr) > 11 {
+			r.MsgACKNum = int(hdr[11] >> 1)
+		}
+	case pduAloha, pduAlohaReply:
+		r.MessageType = "miam_aloha"
+	default:
+		return nil, fmt.Errorf("miam: unknown PDU type %d", hdr[0]>>4)
+	}
+	return r, nil
+}
+
+// DecodeAssembled returns the miam_assembled result for a MIAM transfer that
+// was split over several frames: first is the first frame's message and
+// continuations are the payloads of the others, in order.  The joined
+// payload is decoded as a single-transfer frame where possible.
+func DecodeAssembled(first *acars.Message, continuations []string) *Result {
+	payload := first.Text + strings.Join(continuations, "")
+	r, _ := DecodeFrame(payload)
+	if r == nil {
+		r = &Result{}
+	}
+	r.Timestamp = first.Timestamp
+	r.MessageType = "miam_assembled"
+	r.SegmentCount = 1 + len(continuations)
+	r.AssembledPayload = payload
+	return r
+}
+
+// decodeDataHeader reads the Data PDU fields of hdr into r and keeps the
+// header length and CRC for decodeDataBody.
+func decodeDataHeader(r *Result, hdr []byte) error {
+	if len(hdr) < 14 {
+		return errShortHeader
+	}
+	r.MsgNum = int(hdr[11] >> 1)
+	r.ACKRequired = hdr[11]&1 != 0
+	compression := (hdr[12]<<1 | hdr[13]>>7) & 0x07
+	encoding := (hdr[13] >> 4) & 0x03
+	appType := hdr[13] & 0x0f
+
+	var idLen int
+	switch appType {
+	case 0:
+		idLen = 2
+	case 1:
+		idLen = 4
+	case 2:
+		idLen = 6
+	default:
+		return fmt.Errorf("miam: unknown application type %d", appType)
+	}
+	hdrLen := 14 + idLen + 4
+	if len(hdr) < hdrLen {
+		return errShortHeader
+	}
+	appID := string(hdr[14 : 14+idLen])
+	r.InnerLabel = appID[:2]
+	r.InnerSublabel = strings.TrimSpace(appID[2:])
+	r.hdrLen = hdrLen
+	r.crc = hdr[14+idLen : hdrLen]
+
+	switch encoding {
+	case 0:
+		r.Encoding = "ISO #5"
+	default:
+		r.Encoding = fmt.Sprintf("unknown (%d)", encoding)
+	}
+	switch compression {
+	case compressionNone:
+		r.Compression = "none"
+	case compressionDeflate:
+		r.Compression = "deflate"
+	default:
+		r.Compression = fmt.Sprintf("unknown (%d)", compression)
+	}
+	return nil
+}
+
+// decodeDataBody decompresses the inner message of a Data PDU and checks its
+// CRC.
+func decodeDataBody(r *Result, body string) error {
+	var inner []byte
+	var err error
+	switch r.Compression {
+	case "none":
+		inner = []byte(body)
+	case "deflate":
+		inner, err = inflateBody(body, r.PDULength-r.hdrLen)
+	default:
+		return fmt.Errorf("miam: unsupported compression %s", r.Compression)
+	}
+	r.InnerMessage = string(inner)
+	if err != nil {
+		return err
+	}
+
+	ok := crc.CRC32Arinc(inner, 0xFFFFFFFF)^0xFFFFFFFF == binary.BigEndian.Uint32(r.crc)
+	r.CRCOK = &ok
+	return nil
+}
+
+// inflateBody decodes a base-85 deflate body.  size is the compressed length
+// from the PDU header, used to drop the base-85 padding; the stream is tried
+// as raw deflate first and as zlib second.  On failure the text inflated so
+// far is returned with the error.
+func inflateBody(body string, size int) ([]byte, error) {
+	raw, err := decodeBase85(body)
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 && size < len(raw) {
+		raw = raw[:size]
+	}
+	out, err := io.ReadAll(flate.NewReader(bytes.NewReader(raw)))
+	if err == nil {
+		return out, nil
+	}
+	if zr, zerr := zlib.NewReader(bytes.NewReader(raw)); zerr == nil {
+		if zout, zerr := io.ReadAll(zr); zerr == nil {
+			return zout, nil
+		}
+	}
+	return out, fmt.Errorf("miam: decompression failed: %w", err)
+}
+
+// Intact reports whether r was decoded from a raw frame with its inner
+// message decompressed and its CRC verified.
+func (r *Result) Intact() bool {
+	return r.CRCOK != nil && *r.CRCOK && r.DecodeError == ""
+}
+
+// InnerACARS returns the ACARS message carried by a Data PDU that was decoded
+// from a raw frame, with the metadata of outer, so it can be dispatched like
+// any other message.  It returns nil unless the frame is Intact, since the
+// inner text of a frame that failed its CRC check or was read from a printed
+// block may be garbled, and for PDUs without a message.  A failed CRC check
+// stays visible in the miam_data result only.
+func (r *Result) InnerACARS(outer *acars.Message) *acars.Message {
+	if !r.Intact() || r.InnerLabel == "" || strings.TrimSpace(r.InnerMessage) == "" {
+		return nil
+	}
+	inner := *outer
+	inner.Label = r.InnerLabel
+	inner.Text = r.InnerMessage
+	return &inner
+}
@@ -0,0 +1,180 @@
+package miam
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/crc"
+)
+
+// encodeBase85 is the inverse of decodeBase85, padding the last group with
+// zero bytes as MIAM does for headers when pad is true.
+func encodeBase85(b []byte, pad bool) string {
+	var out []byte
+	for i := 0; i < len(b); i += 4 {
+		var g [4]byte
+		n := copy(g[:], b[i:])
+		if pad {
+			n = 4
+		}
+		v := binary.BigEndian.Uint32(g[:])
+		if v == 0 && n == 4 {
+			out = append(out, 'z')
+			continue
+		}
+		var c [5]byte
+		for j := 4; j >= 0; j-- {
+			c[j] = byte(v%85) + '!'
+			v /= 85
+		}
+		out = append(out, c[:n+1]...)
+	}
+	return string(out)
+}
+
+// buildFrame builds a single-transfer Data frame carrying an H1/DF message,
+// in the version 1 layout whatever version it is given.
+func buildFrame(t *testing.T, version byte, deflate bool, text string) string {
+	t.Helper()
+	body := []byte(text)
+	compression := byte(0)
+	if deflate {
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.BestCompression)
+		w.Write(body)
+		w.Close()
+		body = buf.Bytes()
+		compression = 1
+	}
+	hdr := []byte{version, 0, 0, 0}
+	hdr = append(hdr, ".D-AIXL"...)
+	hdr = append(hdr, 42<<1|1, compression>>1, compression<<7|0x01)
+	hdr = append(hdr, "H1DF"...)
+	hdr = binary.BigEndian.AppendUint32(hdr, crc.CRC32Arinc([]byte(text), 0xFFFFFFFF)^0xFFFFFFFF)
+	n := len(hdr) + len(body)
+	hdr[1], hdr[2], hdr[3] = byte(n>>16), byte(n>>8), byte(n)
+
+	acf := "-2"
+	if deflate {
+		acf = "02"
+	}
+	frame := "T" + acf + encodeBase85(hdr, true) + "|"
+	if deflate {
+		return frame + encodeBase85(body, false)
+	}
+	return frame + text
+}
+
+func TestDecodeBase85(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []byte
+	}{
+		{"z", []byte{0, 0, 0, 0}},
+		{"!!!!\"", []byte{0, 0, 0, 1}},
+		{"87cURD]i,\"Ebo80", []byte("Hello World!")},
+		{"87cUR\r\nD]i,\"Ebo", []byte("Hello Worl")}, // short final group
+	}
+	for _, tt := range tests {
+		got, err := decodeBase85(tt.in)
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("decodeBase85(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"!!z!!", "s8W-\"", "!!!!!!", "ab{"} {
+		if _, err := decodeBase85(bad); err == nil {
+			t.Errorf("decodeBase85(%q): expected an error", bad)
+		}
+	}
+}
+
+// TestDecodeFrameUncompressed uses a frame heard on C-Band, with the letter
+// case of its header restored (the log had it upper-cased).
+func TestDecodeFrameUncompressed(t *testing.T) {
+	frame := `T-2!<<+4/k&,Z:0(8\!!,VF6q/C3ZkWQ@|A350,000038,1,1,TB000000/REP502,41,00;POSDMU,170524,+409977,-0147570,048485,390/:`
+	r, err := DecodeFrame(frame)
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	if r.MessageType != "miam_data" || r.Version != 1 || r.PDULength != 103 || r.AircraftID != ".EC-NVR" ||
+		r.MsgNum != 76 || !r.ACKRequired || r.Compression != "none" || r.Encoding != "ISO #5" {
+		t.Fatalf("header = %+v", r)
+	}
+	if r.InnerLabel != "H1" || r.InnerSublabel != "DF" || !r.Intact() {
+		t.Fatalf("inner = %q/%q, crc ok %v", r.InnerLabel, r.InnerSublabel, r.CRCOK)
+	}
+
+	// Any change to the message text breaks the CRC, and the garbled inner
+	// message is neither dispatched nor used for a position.
+	r, err = DecodeFrame(frame[:len(frame)-2] + "1/:")
+	if err != nil || r.CRCOK == nil || *r.CRCOK {
+		t.Fatalf("altered text: crc ok %v, err %v", r.CRCOK, err)
+	}
+	if inner := r.InnerACARS(&acars.Message{Label: "MA"}); inner != nil {
+		t.Errorf("altered text: InnerACARS = %+v", inner)
+	}
+}
+
+func TestDecodeFrameDeflate(t *testing.T) {
+	text := "A350,000436,1,1,TB000000/REP081,01,01;A06/NX,EDDM VHHH/0,7,+151498,+226713,1011415,3901,-566,305,032,X,3,5,0,0,51,48,87,47,88/:"
+	r, err := DecodeFrame(buildFrame(t, 1, true, text))
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	if r.Version != 1 || r.Compression != "deflate" || r.MsgNum != 42 || r.InnerMessage != text || !r.Intact() {
+		t.Errorf("got %+v", r)
+	}
+
+	// Version 2 headers are laid out differently and not decoded.
+	if r, err := DecodeFrame(buildFrame(t, 2, true, text)); r != nil || err == nil {
+		t.Errorf("v2: got %+v, %v; want an unsupported version error", r, err)
+	}
+
+	// A truncated body fails to decompress but keeps the header.
+	frame := buildFrame(t, 1, true, text)
+	r, err = DecodeFrame(frame[:len(frame)-20])
+	if err == nil || r == nil || r.DecodeError == "" || r.AircraftID != ".D-AIXL" || r.Intact() {
+		t.Fatalf("truncated: %+v, %v", r, err)
+	}
+}
+
+func TestParseCoreFrame(t *testing.T) {
+	text := "A350,000436,1,1,TB000000/REP081,01,01;A06/NX,EDDM VHHH/0,7,+151498,+226713,1011415,3901,-566,305,032,X,3,5,0,0,51,48,87,47,88/:"
+	msg := &acars.Message{ID: 7, Label: "MA", Timestamp: "2026-05-12T17:07:39Z", Text: buildFrame(t, 1, false, text)}
+
+	p := &Parser{}
+	if !p.QuickCheck(msg.Text) {
+		t.Fatal("QuickCheck rejected a raw frame")
+	}
+	r, ok := p.Parse(msg).(*Result)
+	if !ok || r.MsgID != 7 || r.OriginICAO != "EDDM" || r.DestICAO != "VHHH" || r.Latitude == 0 {
+		t.Fatalf("Parse = %+v", r)
+	}
+
+	inner := r.InnerACARS(msg)
+	if inner == nil || inner.Label != "H1" || inner.Text != text || inner.Timestamp != msg.Timestamp || msg.Label != "MA" {
+		t.Fatalf("InnerACARS = %+v", inner)
+	}
+
+	// A frame that fails its CRC check keeps crc_ok false on the result but
+	// reports no position and dispatches nothing.
+	msg.Text = strings.Replace(msg.Text, "+151498", "+151499", 1)
+	r, ok = p.Parse(msg).(*Result)
+	if !ok || r.CRCOK == nil || *r.CRCOK {
+		t.Fatalf("altered frame: Parse = %+v", r)
+	}
+	if _, ok := r.Position(); ok || r.InnerACARS(msg) != nil {
+		t.Errorf("altered frame: position or inner message reported")
+	}
+
+	// A frame ID other than single transfer, or a broken header, is left alone.
+	for _, text := range []string{"F042009218260502040256", "T-2!<<|A350", "T-2s8W-!s8W-!|A350"} {
+		if r := p.Parse(&acars.Message{Label: "MA", Text: text}); r != nil {
+			t.Errorf("Parse(%q) = %+v", text, r)
+		}
+	}
+}
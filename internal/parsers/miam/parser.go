@@ -2,7 +2,8 @@
 // "MA".  JAERO/libacars decodes the MIAM envelope and writes a human-readable
 // block below the raw (deflate-compressed) payload.  The Go parser
 // (cmd/acars_parser) substitutes that decoded block as the message text; this
-// parser then reads the structured fields from it.
+// parser then reads the structured fields from it.  Raw single-transfer frames
+// without a decoded block, e.g. from acarsdec, are decoded natively (core.go).
 //
 // MIAM has two primary PDU types:
 //   - MIAM CORE Data  – carries an inner ACARS message, possibly compressed.
@@ -47,7 +48,7 @@ import (
 type Result struct {
 	MsgID          int64  `json:"message_id"`
 	Timestamp      string `json:"timestamp,omitempty"`
-	MessageType    string `json:"message_type"` // "miam_ack" or "miam_data"
+	MessageType    string `json:"message_type"` // "miam_ack", "miam_data" or "miam_aloha"
 	Version        int    `json:"version,omitempty"`
 	TransferType   string `json:"transfer_type,omitempty"` // e.g. "Single Transfer"
 	PDULength      int    `json:"pdu_length,omitempty"`
@@ -73,6 +74,14 @@ type Result struct {
 	AssembledPayload string `json:"assembled_payload,omitempty"`
 	SegmentCount     int    `json:"segment_count,omitempty"`
 
+	// CRCOK and DecodeError are only set when the PDU was decoded from the raw
+	// frame (DecodeFrame) rather than read from a printed block.  CRCOK is nil
+	// when the inner message could not be recovered.
+	CRCOK       *bool  `json:"crc_ok,omitempty"`
+	DecodeError string `json:"decode_error,omitempty"`
+	hdrLen      int
+	crc         []byte
+
 	// OriginICAO and DestICAO are extracted from the /H02 segment of REP inner
 	// messages (e.g. "/H02,ZGSZ FAOR,CCA867 ,...").  These use the same JSON
 	// keys as other route-bearing parsers so the state extractor picks them up
@@ -100,9 +109,43 @@ type Result struct {
 
 func (r *Result) Type() string     { return r.MessageType }
 func (r *Result) MessageID() int64 { return r.MsgID }
+
+// Position implements registry.PositionReporter.  A frame that failed its CRC
+// check reports no position, since its figures may be garbled.
+func (r *Result) Position() (registry.Position, bool) {
+	if r.CRCOK != nil && !*r.CRCOK {
+		return registry.Position{}, false
+	}
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.FlightLevel * 100,
+		Temperature: registry.Temperature(r.Temperature),
+		Wind:        registry.NewWind(r.WindDir, r.WindSpeed),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter for frames that carry a
+// position report; a frame that failed its CRC check counts as incomplete.
+func (r *Result) Quality() registry.Quality {
+	if r.Latitude == 0 && r.Longitude == 0 {
+		return registry.Quality{}
+	}
+	return registry.Assess(
+		registry.Field{Name: "crc_ok", Present: r.Intact(), Weight: 2},
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+		registry.Field{Name: "origin_icao", Present: r.OriginICAO != ""},
+		registry.Field{Name: "dest_icao", Present: r.DestICAO != ""},
+	)
+}
+
 func (r *Result) HumanReadableText() string {
 	var sb strings.Builder
-	if r.MessageType == "miam_ack" {
+	if r.MessageType == "miam_aloha" {
+		sb.WriteString("MIAM ALOHA")
+	} else if r.MessageType == "miam_ack" {
 		sb.WriteString("MIAM ACK")
 		if r.TransferResult != "" {
 			sb.WriteString(": ")
@@ -138,17 +181,44 @@ func (p *Parser) Name() string     { return "miam" }
 func (p *Parser) Labels() []string { return []string{"MA"} }
 func (p *Parser) Priority() int    { return 10 }
 
-// QuickCheck returns true when the text looks like a JAERO-decoded MIAM block.
+func (p *Parser) ResultTypes() []string      { return []string{"miam_data", "miam_ack", "miam_aloha"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
+// QuickCheck returns true when the text looks like a JAERO-decoded MIAM block
+// or a raw single-transfer frame.
 func (p *Parser) QuickCheck(text string) bool {
-	return strings.HasPrefix(strings.TrimSpace(text), "MIAM:")
+	return strings.HasPrefix(strings.TrimSpace(text), "MIAM:") || isCoreFrame(text)
 }
 
-// Parse extracts structured fields from a JAERO-decoded MIAM block.
+// Parse extracts structured fields from a JAERO-decoded MIAM block, or
+// decodes a raw frame when no block is present.
 func (p *Parser) Parse(msg *acars.Message) registry.Result {
 	if msg == nil {
 		return nil
 	}
-	return parseMIAMBlock(msg)
+	var result *Result
+	if isCoreFrame(msg.Text) {
+		result = parseCoreFrame(msg)
+	} else {
+		result = parseMIAMBlock(msg)
+	}
+	if result == nil {
+		return nil // not a nil *Result, which the registry would keep
+	}
+	return result
+}
+
+// parseCoreFrame decodes the raw frame in msg.Text.  Frames whose PDU header
+// cannot be read give no result.
+func parseCoreFrame(msg *acars.Message) *Result {
+	result, _ := DecodeFrame(msg.Text)
+	if result == nil {
+		return nil
+	}
+	result.MsgID = int64(msg.ID)
+	result.Timestamp = msg.Timestamp
+	parseInnerMessage(result, msg)
+	return result
 }
 
 // parseMIAMBlock reads the line-based MIAM block format that JAERO/libacars
@@ -293,7 +363,17 @@ func parseMIAMBlock(msg *acars.Message) *Result {
 	if len(innerMessageLines) > 0 {
 		result.InnerMessage = strings.TrimSpace(strings.Join(innerMessageLines, "\n"))
 	}
+	parseInnerMessage(result, msg)
+
+	// Store the full decoded block as-is for the viewer's raw text expansion.
+	result.FormattedText = strings.TrimSpace(msg.Text)
+
+	return result
+}
 
+// parseInnerMessage fills the route, flight and position fields of result from
+// its inner message.
+func parseInnerMessage(result *Result, msg *acars.Message) {
 	// Attempt to extract route and flight data from the inner message.
 	// REP messages (/REP marker) are tried first; RTR (XML <RTR> root element)
 	// is the fallback for route-report messages that use the XML-based format.
@@ -321,11 +401,6 @@ func parseMIAMBlock(msg *acars.Message) *Result {
 			result.WindSpeed = windSpeed
 		}
 	}
-
-	// Store the full decoded block as-is for the viewer's raw text expansion.
-	result.FormattedText = strings.TrimSpace(msg.Text)
-
-	return result
 }
 
 // parseREPRoute extracts the origin, destination, and flight number from a
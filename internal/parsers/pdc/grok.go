@@ -2,8 +2,11 @@
 package pdc
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+
+	"acars_parser/internal/patterns"
 )
 
 // BasePatterns defines reusable regex components for PDC parsing.
@@ -205,7 +208,10 @@ func NewCompiler() *Compiler {
 // Compile expands all {PLACEHOLDER} references and compiles regexes.
 func (c *Compiler) Compile() error {
 	for i := range c.formats {
-		expanded := c.expand(c.formats[i].Pattern)
+		expanded, _, err := patterns.Expand(c.formats[i].Pattern, c.basePatterns)
+		if err != nil {
+			return fmt.Errorf("format %s: %w", c.formats[i].Name, err)
+		}
 		re, err := regexp.Compile(expanded)
 		if err != nil {
 			return err
@@ -215,14 +221,13 @@ func (c *Compiler) Compile() error {
 	return nil
 }
 
-// expand replaces {PLACEHOLDER} with actual regex patterns.
+// expand replaces {PLACEHOLDER} with actual regex patterns, for display.
 func (c *Compiler) expand(pattern string) string {
-	result := pattern
-	for name, regex := range c.basePatterns {
-		placeholder := "{" + name + "}"
-		result = strings.ReplaceAll(result, placeholder, regex)
+	expanded, _, err := patterns.Expand(pattern, c.basePatterns)
+	if err != nil {
+		return pattern
 	}
-	return result
+	return expanded
 }
 
 // PDCResult contains the extracted fields from a PDC message.
@@ -246,6 +251,9 @@ type PDCResult struct {
 // Returns the first successful match.
 func (c *Compiler) Parse(text string) *PDCResult {
 	upperText := strings.ToUpper(text)
+	if patterns.Recording() {
+		c.record(text)
+	}
 
 	for _, format := range c.formats {
 		if format.Compiled == nil {
@@ -326,13 +334,10 @@ func (c *Compiler) Parse(text string) *PDCResult {
 	return nil
 }
 
-// PDCFormatTrace contains debug information about a PDC format match attempt.
-type PDCFormatTrace struct {
-	Name     string            // Format name
-	Matched  bool              // Whether the pattern matched
-	Pattern  string            // The expanded regex pattern
-	Captures map[string]string // Captured groups (if matched)
-}
+// PDCFormatTrace contains debug information about a PDC format match
+// attempt.  It is the shared grok trace, so PDC formats show up in
+// patterns.Record alongside the others.
+type PDCFormatTrace = patterns.FormatTrace
 
 // PDCParseTrace contains complete trace information for a PDC parse attempt.
 type PDCParseTrace struct {
@@ -369,7 +374,7 @@ func (c *Compiler) ParseWithTrace(text string) *PDCParseTrace {
 
 		match := format.Compiled.FindStringSubmatch(upperText)
 		if match == nil {
-			ft.Matched = false
+			ft.Partial, ft.Reached = patterns.PartialMatch(format.Pattern, c.expand, upperText)
 			trace.Formats = append(trace.Formats, ft)
 			continue
 		}
@@ -430,6 +435,19 @@ func (c *Compiler) ParseWithTrace(text string) *PDCParseTrace {
 	return trace
 }
 
+// record reports the formats Parse tries on text to patterns.Record: every
+// format up to and including the first one that matches.
+func (c *Compiler) record(text string) {
+	formats := c.ParseWithTrace(text).Formats
+	for i, ft := range formats {
+		if ft.Matched {
+			formats = formats[:i+1]
+			break
+		}
+	}
+	patterns.AddCall(text, formats)
+}
+
 func traceExtractor(name, pattern string, match []string) PDCExtractorTrace {
 	t := PDCExtractorTrace{
 		Name:    name,
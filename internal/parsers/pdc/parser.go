@@ -63,9 +63,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "pdc" }
-func (p *Parser) Labels() []string { return nil } // Content-based, checks all labels.
-func (p *Parser) Priority() int    { return 500 } // Run after label-specific parsers.
+func (p *Parser) Name() string               { return "pdc" }
+func (p *Parser) Labels() []string           { return nil } // Content-based, checks all labels.
+func (p *Parser) Priority() int              { return 500 } // Run after label-specific parsers.
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	upper := strings.ToUpper(text)
@@ -217,47 +218,24 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 	}
 
 	// Calculate confidence.
-	result.ParseConfidence = calculateConfidence(result)
+	result.ParseConfidence = result.Quality().Confidence
 
 	return result
 }
 
-func calculateConfidence(pdc *Result) float64 {
-	score := 0.0
-	maxScore := 11.0
-
-	// Core fields worth more.
-	if pdc.FlightNumber != "" {
-		score += 2
-	}
-	if pdc.Origin != "" {
-		score += 2
-	}
-	if pdc.Destination != "" {
-		score += 2
-	}
-
-	// Supporting fields.
-	if pdc.Runway != "" {
-		score += 1
-	}
-	if pdc.SID != "" {
-		score += 1
-	}
-	if pdc.Squawk != "" {
-		score += 1
-	}
-	if pdc.DepartureFreq != "" {
-		score += 0.5
-	}
-	if pdc.AircraftType != "" {
-		score += 0.5
-	}
-
-	// Bonus for grok format match (indicates high-confidence structured parse).
-	if pdc.PDCFormat != "" {
-		score += 1
-	}
-
-	return score / maxScore
+// Quality implements registry.QualityReporter.  Core fields are worth
+// more, and a grok format match counts as a field, as it marks a
+// high-confidence structured parse.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "flight_number", Present: r.FlightNumber != "", Weight: 2},
+		registry.Field{Name: "origin", Present: r.Origin != "", Weight: 2},
+		registry.Field{Name: "destination", Present: r.Destination != "", Weight: 2},
+		registry.Field{Name: "runway", Present: r.Runway != ""},
+		registry.Field{Name: "sid", Present: r.SID != ""},
+		registry.Field{Name: "squawk", Present: r.Squawk != ""},
+		registry.Field{Name: "departure_freq", Present: r.DepartureFreq != "", Weight: 0.5},
+		registry.Field{Name: "aircraft_type", Present: r.AircraftType != "", Weight: 0.5},
+		registry.Field{Name: "pdc_format", Present: r.PDCFormat != ""},
+	)
 }
\ No newline at end of file
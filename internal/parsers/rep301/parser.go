@@ -31,15 +31,38 @@ type Result struct {
 func (r *Result) Type() string     { return "rep301" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    int(math.Round(r.FlightLevel * 100)),
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		Temperature: registry.Temperature(float64(r.TemperatureC)),
+		Wind:        registry.NewWind(r.WindDirection, r.WindSpeedKts),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "flight_level", Present: r.FlightLevel != 0},
+	)
+}
+
 type Parser struct{}
 
 func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "rep301" }
-func (p *Parser) Labels() []string { return nil }
-func (p *Parser) Priority() int    { return 420 }
+func (p *Parser) Name() string               { return "rep301" }
+func (p *Parser) Labels() []string           { return nil }
+func (p *Parser) Priority() int              { return 420 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.Contains(strings.ToUpper(text), "REP301")
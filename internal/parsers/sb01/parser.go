@@ -33,6 +33,29 @@ type Result struct {
 func (r *Result) Type() string     { return "sb01" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// Position implements registry.PositionReporter.
+func (r *Result) Position() (registry.Position, bool) {
+	return registry.Position{
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Altitude:    r.AltitudeFt,
+		ReportTime:  registry.ReportTime(r.ReportTime),
+		Temperature: registry.Temperature(r.TemperatureC),
+		Wind:        registry.NewWind(r.WindDirection, r.WindSpeedKts),
+	}, r.Latitude != 0 || r.Longitude != 0
+}
+
+// Quality implements registry.QualityReporter.  The altitude may come in
+// feet or metres.
+func (r *Result) Quality() registry.Quality {
+	return registry.Assess(
+		registry.Field{Name: "latitude", Present: r.Latitude != 0, Weight: 2},
+		registry.Field{Name: "longitude", Present: r.Longitude != 0, Weight: 2},
+		registry.Field{Name: "report_time", Present: r.ReportTime != ""},
+		registry.Field{Name: "altitude", Present: r.AltitudeFt != 0 || r.AltitudeM != 0},
+	)
+}
+
 // Parser parses SB01 H1 messages.
 type Parser struct{}
 
@@ -40,9 +63,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "sb01" }
-func (p *Parser) Labels() []string { return []string{"H1"} }
-func (p *Parser) Priority() int    { return 15 }
+func (p *Parser) Name() string               { return "sb01" }
+func (p *Parser) Labels() []string           { return []string{"H1"} }
+func (p *Parser) Priority() int              { return 15 }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 func (p *Parser) QuickCheck(text string) bool {
 	return strings.HasPrefix(strings.TrimSpace(text), "SB01")
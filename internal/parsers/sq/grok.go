@@ -7,13 +7,19 @@ import "acars_parser/internal/patterns"
 var Formats = []patterns.Format{
 	// ARINC squitter position format.
 	// Example: 02XASYDYSSY03341S14959EV136975...
-	// Groups: msg_type, iata, icao, lat, lat_dir, lon, lon_dir, freq_band, freq
+	// The latitude is a check/flag digit then DDMM, the longitude DDDMM.
+	// Groups: msg_type, iata, icao, lat_deg, lat_min, lat_dir, lon_deg, lon_min, lon_dir, freq_band, freq
 	{
 		Name: "arinc_position",
 		Pattern: `^02X(?P<msg_type>[AS])(?P<iata>{IATA})(?P<icao>{ICAO})` +
-			`(?P<lat>{LAT_5D})(?P<lat_dir>{LAT_DIR})` +
-			`(?P<lon>{LON_5D})(?P<lon_dir>{LON_DIR})` +
-			`(?P<freq_band>[VB])(?P<freq>{LON_6D})`,
-		Fields: []string{"msg_type", "iata", "icao", "lat", "lat_dir", "lon", "lon_dir", "freq_band", "freq"},
+			`\d{NUM2:lat_deg:int}{NUM2:lat_min:int}(?P<lat_dir>{LAT_DIR})` +
+			`{LON_3D:lon_deg:int}{NUM2:lon_min:int}(?P<lon_dir>{LON_DIR})` +
+			`(?P<freq_band>[VB]){FREQ_KHZ:freq:int}`,
+		Fields: []string{"msg_type", "iata", "icao", "lat_deg", "lat_min", "lat_dir", "lon_deg", "lon_min", "lon_dir", "freq_band", "freq"},
 	},
 }
+
+// localPatterns are the placeholders only the SQ formats use.
+var localPatterns = map[string]string{
+	"FREQ_KHZ": `\d{6}`, // 136975 = 136.975 MHz
+}
@@ -2,7 +2,6 @@
 package sq
 
 import (
-	"strconv"
 	"strings"
 	"sync"
 
@@ -29,6 +28,12 @@ type Result struct {
 func (r *Result) Type() string     { return "sq_position" }
 func (r *Result) MessageID() int64 { return r.MsgID }
 
+// StationPosition implements registry.StationPositionReporter with the
+// position of the ground station sending the squitter.
+func (r *Result) StationPosition() (registry.Position, bool) {
+	return registry.Position{Latitude: r.Latitude, Longitude: r.Longitude}, r.Latitude != 0 || r.Longitude != 0
+}
+
 // Parser parses SQ (Squitter) ARINC position messages.
 // Format: 02XA/02XS + IATA(3) + ICAO(4) + lat(5) + N/S + lon(5) + E/W + band+freq + suffix
 type Parser struct{}
@@ -43,7 +48,7 @@ var (
 // getCompiler returns the singleton grok compiler.
 func getCompiler() (*patterns.Compiler, error) {
 	grokOnce.Do(func() {
-		grokCompiler = patterns.NewCompiler(Formats, nil)
+		grokCompiler = patterns.NewCompiler(Formats, localPatterns)
 		grokErr = grokCompiler.Compile()
 	})
 	return grokCompiler, grokErr
@@ -57,6 +62,9 @@ func (p *Parser) Name() string     { return "sq" }
 func (p *Parser) Labels() []string { return []string{"SQ"} }
 func (p *Parser) Priority() int    { return 100 }
 
+func (p *Parser) ResultTypes() []string      { return []string{"sq_position"} }
+func (p *Parser) NewResult() registry.Result { return &Result{} }
+
 func (p *Parser) QuickCheck(text string) bool {
 	// Fast check for the 02X prefix.
 	return strings.HasPrefix(text, "02X")
@@ -95,86 +103,33 @@ func (p *Parser) Parse(msg *acars.Message) registry.Result {
 		FreqBand:    match.Captures["freq_band"],
 	}
 
-	// Parse latitude: 5 digits where first is a check/flag digit, remaining 4 are DDMM.
-	if lat, ok := parseLatitude(match.Captures["lat"], match.Captures["lat_dir"]); ok {
+	latDeg, _ := match.Int("lat_deg")
+	latMin, _ := match.Int("lat_min")
+	if lat, ok := degreesMinutes(latDeg, latMin, 90, match.Captures["lat_dir"] == "S"); ok {
 		result.Latitude = lat
 	}
-
-	// Parse longitude: 5 digits where first is hundreds digit, remaining 4 are DDMM.
-	if lon, ok := parseLongitude(match.Captures["lon"], match.Captures["lon_dir"]); ok {
+	lonDeg, _ := match.Int("lon_deg")
+	lonMin, _ := match.Int("lon_min")
+	if lon, ok := degreesMinutes(lonDeg, lonMin, 180, match.Captures["lon_dir"] == "W"); ok {
 		result.Longitude = lon
 	}
 
-	// Parse frequency: 6 digits like 136975 = 136.975 MHz.
-	if freq, err := strconv.ParseFloat(match.Captures["freq"], 64); err == nil {
-		result.FreqMHz = freq / 1000.0
+	if freq, ok := match.Int("freq"); ok {
+		result.FreqMHz = float64(freq) / 1000.0
 	}
 
 	return result
 }
 
-// parseLatitude parses the 5-digit latitude format used in SQ messages.
-// The first digit appears to be a check/flag digit; the remaining 4 are DDMM.
-// Returns latitude in decimal degrees (negative for south).
-func parseLatitude(digits string, hemisphere string) (float64, bool) {
-	if len(digits) != 5 {
-		return 0, false
-	}
-
-	// Skip first digit (check/flag), parse remaining DDMM.
-	deg, err := strconv.Atoi(digits[1:3])
-	if err != nil || deg > 90 {
-		return 0, false
-	}
-
-	min, err := strconv.Atoi(digits[3:5])
-	if err != nil || min > 59 {
-		return 0, false
-	}
-
-	lat := float64(deg) + float64(min)/60.0
-
-	if hemisphere == "S" {
-		lat = -lat
-	}
-
-	return lat, true
-}
-
-// parseLongitude parses the 5-digit longitude format used in SQ messages.
-// The first digit is the hundreds digit (0 or 1), remaining 4 are DDMM.
-// Returns longitude in decimal degrees (negative for west).
-func parseLongitude(digits string, hemisphere string) (float64, bool) {
-	if len(digits) != 5 {
+// degreesMinutes returns deg degrees and min minutes in decimal degrees,
+// negative when negate is set, or false when either is out of range.
+func degreesMinutes(deg, min, maxDeg int, negate bool) (float64, bool) {
+	if deg > maxDeg || min > 59 {
 		return 0, false
 	}
-
-	// First digit is hundreds (0 or 1), next 2 are tens/units, last 2 are minutes.
-	hundreds, err := strconv.Atoi(digits[0:1])
-	if err != nil || hundreds > 1 {
-		return 0, false
+	v := float64(deg) + float64(min)/60.0
+	if negate {
+		v = -v
 	}
-
-	tensUnits, err := strconv.Atoi(digits[1:3])
-	if err != nil {
-		return 0, false
-	}
-
-	deg := hundreds*100 + tensUnits
-	if deg > 180 {
-		return 0, false
-	}
-
-	min, err := strconv.Atoi(digits[3:5])
-	if err != nil || min > 59 {
-		return 0, false
-	}
-
-	lon := float64(deg) + float64(min)/60.0
-
-	if hemisphere == "W" {
-		lon = -lon
-	}
-
-	return lon, true
+	return v, true
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
 )
 
 func TestParser(t *testing.T) {
@@ -126,3 +127,14 @@ func TestRejectsInvalid(t *testing.T) {
 		}
 	}
 }
+
+func TestStationPosition(t *testing.T) {
+	r := (&Parser{}).Parse(&acars.Message{Text: "02XAORDKORD04158N08754WV136975/ARINC"})
+	if _, ok := registry.PositionOf(r); ok {
+		t.Error("squitter reports an aircraft position")
+	}
+	p, ok := registry.StationPositionOf(r)
+	if !ok || math.Abs(p.Latitude-41.9667) > 0.1 || math.Abs(p.Longitude+87.9) > 0.1 {
+		t.Errorf("StationPositionOf = %+v, %v, want KORD", p, ok)
+	}
+}
@@ -37,9 +37,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "turbulence" }
-func (p *Parser) Labels() []string { return []string{"C1"} }
-func (p *Parser) Priority() int    { return 65 } // Higher priority than weather.
+func (p *Parser) Name() string               { return "turbulence" }
+func (p *Parser) Labels() []string           { return []string{"C1"} }
+func (p *Parser) Priority() int              { return 65 } // Higher priority than weather.
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 // QuickCheck looks for turbulence keywords.
 func (p *Parser) QuickCheck(text string) bool {
@@ -69,9 +69,10 @@ func init() {
 	registry.Register(&Parser{})
 }
 
-func (p *Parser) Name() string     { return "weather" }
-func (p *Parser) Labels() []string { return []string{"RA", "C1"} }
-func (p *Parser) Priority() int    { return 50 } // Lower priority, run after more specific parsers.
+func (p *Parser) Name() string               { return "weather" }
+func (p *Parser) Labels() []string           { return []string{"RA", "C1"} }
+func (p *Parser) Priority() int              { return 50 } // Lower priority, run after more specific parsers.
+func (p *Parser) NewResult() registry.Result { return &Result{} }
 
 // QuickCheck looks for weather keywords.
 func (p *Parser) QuickCheck(text string) bool {
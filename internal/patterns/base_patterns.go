@@ -4,7 +4,8 @@
 package patterns
 
 // BasePatterns defines reusable regex components for grok-style pattern composition.
-// These are referenced in format patterns using {PATTERN_NAME} syntax.
+// These are referenced in format patterns using {PATTERN_NAME} syntax, and may
+// reference each other the same way.
 var BasePatterns = map[string]string{
 	// Airport codes.
 	"ICAO": `[KYEPCZLRVOSWUABDFGHMNT][A-Z]{3}`,
@@ -31,6 +32,8 @@ var BasePatterns = map[string]string{
 	"LAT_DM":   `\d{4}\.\d`,        // DDMM.D (degrees minutes decimal)
 	"LAT_DMS":  `\d{6}`,            // DDMMSS
 	"LAT_DEC":  `[-\d.]+`,          // Decimal latitude
+	"LAT":      `{LAT_DIR}\d{4,6}(?:\.\d{1,2})?`, // N4512.3, S22336; use as {LAT:name:coord}
+	"LAT_DEC_DIR": `{LAT_DIR}\s*[\d.]+`,  // N33.123, N 33.123; use as {LAT_DEC_DIR:name:dec_coord}
 
 	// Coordinates - longitude formats.
 	"LON_DIR":  `[EW]`,
@@ -41,6 +44,8 @@ var BasePatterns = map[string]string{
 	"LON_DM":   `\d{5}\.\d`,        // DDDMM.D (degrees minutes decimal)
 	"LON_DMS":  `\d{6,7}`,          // DDMMSS or DDDMMSS
 	"LON_DEC":  `[-\d.]+`,          // Decimal longitude
+	"LON":      `{LON_DIR}\d{5,7}(?:\.\d{1,2})?`, // W07330.5, E006589; use as {LON:name:coord}
+	"LON_DEC_DIR": `{LON_DIR}\s*[\d.]+`,  // W117.456, W 117.456; use as {LON_DEC_DIR:name:dec_coord}
 
 	// Altitude and flight level.
 	"FL":       `\d{2,3}`,          // Flight level digits (e.g., 350, 41)
@@ -72,6 +77,12 @@ var BasePatterns = map[string]string{
 	"SID": `[A-Z]{2,}[0-9][A-Z0-9]*`,
 
 	// Misc.
+	"NUM":      `\d+`,              // Unsigned integer; use as {NUM:name:int}
+	"NUM2":     `\d{2}`,            // Two digits, e.g. a day of month or a temperature
+	"NUM3":     `\d{3}`,            // Three digits, e.g. a wind direction or speed
+	"NUM4":     `\d{4}`,            // Four digits, e.g. a fuel figure
+	"DEC":      `[-\d.]+`,          // Signed decimal; use as {DEC:name:float}
+	"FIELD":    `[^/]*`,            // One slash-delimited field
 	"ATIS":     `[A-Z]`,
 	"PDCNUM":   `\d{1,6}`,
 	"CALLSIGN": `[A-Z0-9]{3,8}`,    // Generic callsign
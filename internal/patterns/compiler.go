@@ -4,8 +4,12 @@
 package patterns
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Format represents a message format with named capture groups.
@@ -14,6 +18,10 @@ type Format struct {
 	Pattern  string         // Pattern with {PLACEHOLDER} syntax
 	Compiled *regexp.Regexp // Compiled regex (populated by Compile)
 	Fields   []string       // Field names in capture order (for documentation)
+
+	// Types of the typed captures, {NAME:capture:type}, by capture name
+	// (populated by Compile).
+	Types map[string]string
 }
 
 // Compiler manages pattern compilation and parsing for a set of formats.
@@ -50,36 +58,81 @@ func NewCompiler(formats []Format, localPatterns map[string]string) *Compiler {
 // Compile expands all {PLACEHOLDER} references and compiles regexes.
 func (c *Compiler) Compile() error {
 	for i := range c.formats {
-		expanded := c.expand(c.formats[i].Pattern)
+		expanded, types, err := Expand(c.formats[i].Pattern, c.basePatterns)
+		if err != nil {
+			return fmt.Errorf("format %s: %w", c.formats[i].Name, err)
+		}
 		re, err := regexp.Compile(expanded)
 		if err != nil {
-			return err
+			return fmt.Errorf("format %s: %w", c.formats[i].Name, err)
 		}
 		c.formats[i].Compiled = re
+		c.formats[i].Types = types
 	}
 	return nil
 }
 
-// expand replaces {PLACEHOLDER} with actual regex patterns.
+// Formats returns a copy of the compiler's formats, with Compiled set once
+// Compile has succeeded.
+func (c *Compiler) Formats() []Format {
+	out := make([]Format, len(c.formats))
+	copy(out, c.formats)
+	return out
+}
+
+// expand replaces {PLACEHOLDER} with actual regex patterns, for display.
+// A pattern that does not expand is returned as is; Compile reports why.
 func (c *Compiler) expand(pattern string) string {
-	result := pattern
-	for name, regex := range c.basePatterns {
-		placeholder := "{" + name + "}"
-		result = strings.ReplaceAll(result, placeholder, regex)
+	expanded, _, err := Expand(pattern, c.basePatterns)
+	if err != nil {
+		return pattern
 	}
-	return result
+	return expanded
 }
 
 // Match represents a successful pattern match with extracted fields.
 type Match struct {
 	FormatName string            // Name of the matched format
 	Captures   map[string]string // Named capture group values
+	Values     map[string]any    // Converted typed captures; absent when empty or unreadable
+}
+
+// newMatch builds the Match for a successful match of format.
+func newMatch(format Format, captures map[string]string) *Match {
+	m := &Match{FormatName: format.Name, Captures: captures}
+	for name, typ := range format.Types {
+		if captures[name] == "" {
+			continue
+		}
+		if v, ok := captureTypes[typ](captures[name]); ok {
+			if m.Values == nil {
+				m.Values = make(map[string]any)
+			}
+			m.Values[name] = v
+		}
+	}
+	return m
+}
+
+// captures returns the named groups of a FindStringSubmatch result.
+func captures(re *regexp.Regexp, match []string) map[string]string {
+	out := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		out[name] = match[i]
+	}
+	return out
 }
 
 // Parse attempts to parse text using all compiled formats.
 // Returns the first successful match, or nil if no format matches.
 func (c *Compiler) Parse(text string) *Match {
 	upperText := strings.ToUpper(text)
+	if Recording() {
+		c.record(text, upperText, "", true)
+	}
 
 	for _, format := range c.formats {
 		if format.Compiled == nil {
@@ -91,18 +144,7 @@ func (c *Compiler) Parse(text string) *Match {
 			continue
 		}
 
-		result := &Match{
-			FormatName: format.Name,
-			Captures:   make(map[string]string),
-		}
-
-		// Extract named groups.
-		for i, name := range format.Compiled.SubexpNames() {
-			if i == 0 || name == "" {
-				continue
-			}
-			result.Captures[name] = match[i]
-		}
+		result := newMatch(format, captures(format.Compiled, match))
 
 		return result
 	}
@@ -114,6 +156,9 @@ func (c *Compiler) Parse(text string) *Match {
 // Returns all successful matches (useful when formats extract different fields).
 func (c *Compiler) ParseAll(text string) []*Match {
 	upperText := strings.ToUpper(text)
+	if Recording() {
+		c.record(text, upperText, "", false)
+	}
 	var results []*Match
 
 	for _, format := range c.formats {
@@ -126,18 +171,7 @@ func (c *Compiler) ParseAll(text string) []*Match {
 			continue
 		}
 
-		result := &Match{
-			FormatName: format.Name,
-			Captures:   make(map[string]string),
-		}
-
-		// Extract named groups.
-		for i, name := range format.Compiled.SubexpNames() {
-			if i == 0 || name == "" {
-				continue
-			}
-			result.Captures[name] = match[i]
-		}
+		result := newMatch(format, captures(format.Compiled, match))
 
 		results = append(results, result)
 	}
@@ -149,6 +183,9 @@ func (c *Compiler) ParseAll(text string) []*Match {
 // Useful for patterns that can match multiple times (e.g., oceanic fixes, waypoints).
 func (c *Compiler) FindAllMatches(text string, formatName string) []map[string]string {
 	upperText := strings.ToUpper(text)
+	if Recording() {
+		c.record(text, upperText, formatName, false)
+	}
 	var results []map[string]string
 
 	for _, format := range c.formats {
@@ -158,14 +195,7 @@ func (c *Compiler) FindAllMatches(text string, formatName string) []map[string]s
 
 		matches := format.Compiled.FindAllStringSubmatch(upperText, -1)
 		for _, match := range matches {
-			captures := make(map[string]string)
-			for i, name := range format.Compiled.SubexpNames() {
-				if i == 0 || name == "" {
-					continue
-				}
-				captures[name] = match[i]
-			}
-			results = append(results, captures)
+			results = append(results, captures(format.Compiled, match))
 		}
 		break
 	}
@@ -184,12 +214,46 @@ func (m *Match) GetCapture(name string, defaultVal string) string {
 	return defaultVal
 }
 
+// Int returns the value of an int capture.
+func (m *Match) Int(name string) (int, bool) {
+	if m == nil {
+		return 0, false
+	}
+	v, ok := m.Values[name].(int)
+	return v, ok
+}
+
+// Float returns the value of a float, coord or dec_coord capture.
+func (m *Match) Float(name string) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	v, ok := m.Values[name].(float64)
+	return v, ok
+}
+
+// Time returns the value of a time capture, the time of day as the time
+// since midnight.
+func (m *Match) Time(name string) (time.Duration, bool) {
+	if m == nil {
+		return 0, false
+	}
+	v, ok := m.Values[name].(time.Duration)
+	return v, ok
+}
+
 // FormatTrace contains debug information about a format match attempt.
 type FormatTrace struct {
 	Name     string            // Format name
 	Matched  bool              // Whether the pattern matched
 	Pattern  string            // The expanded regex pattern
 	Captures map[string]string // Captured groups (if matched)
+
+	// For a format that did not match: the longest leading part of its
+	// unexpanded pattern that did, and where in the upper-cased text that
+	// match ended.  Reached is -1 when not even the first element matched.
+	Partial string
+	Reached int
 }
 
 // ParseTrace contains complete trace information for a parse attempt.
@@ -207,44 +271,246 @@ func (c *Compiler) ParseWithTrace(text string) *ParseTrace {
 	}
 
 	for _, format := range c.formats {
-		ft := FormatTrace{
-			Name:    format.Name,
-			Pattern: c.expand(format.Pattern),
+		ft := c.trace(format, upperText)
+		trace.Formats = append(trace.Formats, ft)
+
+		// Set the first match result.
+		if ft.Matched && trace.Match == nil {
+			trace.Match = newMatch(format, ft.Captures)
 		}
+	}
 
-		if format.Compiled == nil {
-			trace.Formats = append(trace.Formats, ft)
+	return trace
+}
+
+// trace tries one format against upperText.
+func (c *Compiler) trace(format Format, upperText string) FormatTrace {
+	ft := FormatTrace{
+		Name:    format.Name,
+		Pattern: c.expand(format.Pattern),
+	}
+	if format.Compiled == nil {
+		return ft
+	}
+
+	match := format.Compiled.FindStringSubmatch(upperText)
+	if match == nil {
+		ft.Partial, ft.Reached = PartialMatch(format.Pattern, c.expand, upperText)
+		return ft
+	}
+
+	// Pattern matched.
+	ft.Matched = true
+	ft.Captures = captures(format.Compiled, match)
+	return ft
+}
+
+// record adds the formats a Parse, ParseAll or FindAllMatches call tries to
+// the running Record.  only limits it to one format; firstMatch stops after
+// the first format that matches, as Parse does.
+func (c *Compiler) record(text, upperText, only string, firstMatch bool) {
+	var tried []FormatTrace
+	for _, format := range c.formats {
+		if only != "" && format.Name != only {
 			continue
 		}
+		ft := c.trace(format, upperText)
+		tried = append(tried, ft)
+		if ft.Matched && firstMatch {
+			break
+		}
+	}
+	AddCall(text, tried)
+}
 
-		match := format.Compiled.FindStringSubmatch(upperText)
-		if match == nil {
-			ft.Matched = false
-			trace.Formats = append(trace.Formats, ft)
+// CallTrace is one compiler call seen by Record.
+type CallTrace struct {
+	Text    string        // The text as passed in, before upper-casing
+	Formats []FormatTrace // The formats tried, in order
+}
+
+// recorder collects compiler calls while Record runs.
+type recorder struct {
+	mu    sync.Mutex
+	calls []CallTrace
+}
+
+var (
+	recordMu sync.Mutex // one Record at a time
+	active   atomic.Pointer[recorder]
+)
+
+// Record runs fn and returns every format any compiler tried while it ran,
+// one CallTrace per Parse, ParseAll or FindAllMatches call.  Calls from other
+// goroutines are recorded too, so it is meant for looking at one message at
+// a time, as the explain command does.
+func Record(fn func()) []CallTrace {
+	recordMu.Lock()
+	defer recordMu.Unlock()
+
+	rec := &recorder{}
+	active.Store(rec)
+	defer active.Store(nil)
+	fn()
+	return rec.calls
+}
+
+// Recording reports whether a Record call is running.  Compilers outside
+// this package check it before building a trace for AddCall.
+func Recording() bool {
+	return active.Load() != nil
+}
+
+// AddCall adds a compiler call to the running Record, if any.
+func AddCall(text string, formats []FormatTrace) {
+	rec := active.Load()
+	if rec == nil {
+		return
+	}
+	rec.mu.Lock()
+	rec.calls = append(rec.calls, CallTrace{Text: text, Formats: formats})
+	rec.mu.Unlock()
+}
+
+var (
+	placeholderRe = regexp.MustCompile(`^` + placeholderRef.String())
+	repeatRe      = regexp.MustCompile(`^\{\d+(?:,\d*)?\}`)
+)
+
+// PartialMatch finds how far text gets through a pattern that does not
+// match it.  It tries ever shorter runs of the pattern's top-level elements
+// (literals, escapes, classes, groups and {PLACEHOLDER}s, with their
+// quantifiers) and returns the longest one that matches, with the end of
+// that match in text.  expand resolves placeholders.  A pattern with a
+// top-level alternation, or whose first element already fails, gives
+// ("", -1).
+func PartialMatch(pattern string, expand func(string) string, text string) (string, int) {
+	elems := splitPattern(pattern)
+	for n := len(elems) - 1; n > 0; n-- {
+		prefix := strings.Join(elems[:n], "")
+		re, err := regexp.Compile(expand(prefix))
+		if err != nil {
 			continue
 		}
+		if loc := re.FindStringIndex(text); loc != nil {
+			return prefix, loc[1]
+		}
+	}
+	return "", -1
+}
 
-		// Pattern matched.
-		ft.Matched = true
-		ft.Captures = make(map[string]string)
-
-		for i, name := range format.Compiled.SubexpNames() {
-			if i == 0 || name == "" {
-				continue
+// splitPattern splits a pattern into its top-level elements.  It returns nil
+// for a top-level alternation, where a leading run of elements says nothing
+// about progress.
+func splitPattern(p string) []string {
+	var elems []string
+	for i := 0; i < len(p); {
+		start := i
+		switch p[i] {
+		case '|':
+			return nil
+		case '\\':
+			i += 2
+			// \pL is one letter; \p{Greek} and \x{263a} carry braces.
+			if i < len(p) && p[i] == '{' && strings.ContainsRune("pPx", rune(p[i-1])) {
+				if j := strings.IndexByte(p[i:], '}'); j >= 0 {
+					i += j + 1
+				}
 			}
-			ft.Captures[name] = match[i]
+		case '[':
+			i = classEnd(p, i)
+		case '(':
+			i = groupEnd(p, i)
+		case '{':
+			if m := placeholderRe.FindString(p[i:]); m != "" {
+				i += len(m)
+			} else {
+				i++
+			}
+		default:
+			i++
+		}
+		if i > len(p) {
+			i = len(p)
 		}
+		i = quantifierEnd(p, i)
+		elems = append(elems, p[start:i])
+	}
+	return elems
+}
 
-		trace.Formats = append(trace.Formats, ft)
+// classEnd returns the index just past the character class starting at i.
+func classEnd(p string, i int) int {
+	j := i + 1
+	if j < len(p) && p[j] == '^' {
+		j++
+	}
+	if j < len(p) && p[j] == ']' {
+		j++
+	}
+	for j < len(p) {
+		switch {
+		case p[j] == '\\':
+			j += 2
+		case strings.HasPrefix(p[j:], "[:"):
+			if k := strings.Index(p[j:], ":]"); k >= 0 {
+				j += k + 2
+			} else {
+				j++
+			}
+		case p[j] == ']':
+			return j + 1
+		default:
+			j++
+		}
+	}
+	return len(p)
+}
 
-		// Set the first match result.
-		if trace.Match == nil {
-			trace.Match = &Match{
-				FormatName: format.Name,
-				Captures:   ft.Captures,
+// groupEnd returns the index just past the group starting at i.
+func groupEnd(p string, i int) int {
+	depth := 0
+	for j := i; j < len(p); {
+		switch p[j] {
+		case '\\':
+			j += 2
+		case '[':
+			j = classEnd(p, j)
+		case '(':
+			depth++
+			j++
+		case ')':
+			depth--
+			j++
+			if depth == 0 {
+				return j
 			}
+		default:
+			j++
 		}
 	}
+	return len(p)
+}
 
-	return trace
+// quantifierEnd returns the index past any quantifier starting at i.
+func quantifierEnd(p string, i int) int {
+	if i >= len(p) {
+		return i
+	}
+	switch p[i] {
+	case '*', '+', '?':
+		i++
+	case '{':
+		m := repeatRe.FindString(p[i:])
+		if m == "" {
+			return i
+		}
+		i += len(m)
+	default:
+		return i
+	}
+	if i < len(p) && p[i] == '?' {
+		i++
+	}
+	return i
 }
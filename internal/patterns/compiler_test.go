@@ -0,0 +1,95 @@
+package patterns
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPartialMatch(t *testing.T) {
+	expand := NewCompiler(nil, nil).expand
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		partial string
+		reached int
+	}{
+		{
+			name:    "stops at the failing placeholder",
+			pattern: `^POS(?P<lat>{LAT_DIR}\d{5}),(?P<lon>{LON_DIR}\d{6})`,
+			text:    "POSN51234,X001234",
+			partial: `^POS(?P<lat>{LAT_DIR}\d{5}),`,
+			reached: 10,
+		},
+		{
+			name:    "quantifiers stay with their element",
+			pattern: `^FL\s+\d{3}[A-Z]+/`,
+			text:    "FL  350KT",
+			partial: `^FL\s+\d{3}[A-Z]+`,
+			reached: 9,
+		},
+		{
+			name:    "class with a bracket and escaped parenthesis",
+			pattern: `[]/]\(A\)B`,
+			text:    "/(A)C",
+			partial: `[]/]\(A\)`,
+			reached: 4,
+		},
+		{
+			name:    "first element fails",
+			pattern: `POS\d`,
+			text:    "ETA1234",
+			reached: -1,
+		},
+		{
+			name:    "top-level alternation",
+			pattern: `AB|CD`,
+			text:    "AX",
+			reached: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partial, reached := PartialMatch(tt.pattern, expand, tt.text)
+			if partial != tt.partial || reached != tt.reached {
+				t.Errorf("got (%q, %d), want (%q, %d)", partial, reached, tt.partial, tt.reached)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	c := NewCompiler([]Format{
+		{Name: "eta", Pattern: `^ETA(?P<eta>{TIME4})`},
+		{Name: "pos", Pattern: `^POS(?P<lat>{LAT_DIR}\d+)`},
+		{Name: "never", Pattern: `^NEVER`},
+	}, nil)
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Parse("posn123") == nil {
+		t.Fatal("no match outside Record")
+	}
+	calls := Record(func() {
+		c.Parse("posn123")
+		c.FindAllMatches("ETA12", "eta")
+	})
+	if len(calls) != 2 {
+		t.Fatalf("got %d calls", len(calls))
+	}
+	// Parse stops at the first format that matches.
+	first := calls[0]
+	if first.Text != "posn123" || len(first.Formats) != 2 || first.Formats[0].Matched ||
+		!first.Formats[1].Matched || first.Formats[1].Captures["lat"] != "N123" {
+		t.Errorf("Parse call = %+v", first)
+	}
+	eta := calls[1].Formats
+	if len(eta) != 1 || eta[0].Matched || eta[0].Partial != "^ETA" || eta[0].Reached != 3 ||
+		!strings.Contains(eta[0].Pattern, `\d{4}`) {
+		t.Errorf("FindAllMatches call = %+v", eta)
+	}
+	if Recording() {
+		t.Error("still recording after Record returned")
+	}
+}
@@ -0,0 +1,202 @@
+// Package patterns provides shared regex patterns and helper functions for ACARS parsing.
+// This file contains placeholder expansion and typed capture conversion.
+
+package patterns
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// placeholderRef matches {NAME}, {NAME:capture} and {NAME:capture:type}.
+var placeholderRef = regexp.MustCompile(`\{([A-Z][A-Z0-9_]*)(?::([A-Za-z_][A-Za-z0-9_]*)(?::([a-z_]+))?)?\}`)
+
+// captureTypes converts the value of a typed capture, {NAME:capture:type}.
+// A converter returns false for a value it cannot read.
+var captureTypes = map[string]func(string) (any, bool){
+	"int":       convertInt,
+	"float":     convertFloat,
+	"coord":     convertCoord,
+	"dec_coord": convertDecCoord,
+	"time":      convertTime,
+}
+
+// Expand resolves the placeholders in pattern against base.  Placeholders
+// inside base patterns are resolved too, so the result does not depend on
+// map order.  {NAME:capture} wraps the pattern in a named group, and
+// {NAME:capture:type} also records the capture's type in the returned map.
+// An unknown placeholder, an unknown type or a cycle between base patterns
+// is an error.
+func Expand(pattern string, base map[string]string) (string, map[string]string, error) {
+	x := &expander{base: base, done: make(map[string]string), types: make(map[string]string)}
+	re, err := x.expand(pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	return re, x.types, nil
+}
+
+// expander holds the state of one Expand call.
+type expander struct {
+	base  map[string]string
+	done  map[string]string // Fully resolved base patterns.
+	stack []string          // Base patterns being resolved, for cycle detection.
+	types map[string]string
+}
+
+func (x *expander) expand(pattern string) (string, error) {
+	var err error
+	out := placeholderRef.ReplaceAllStringFunc(pattern, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		m := placeholderRef.FindStringSubmatch(ref)
+		var re string
+		if re, err = x.resolve(m[1]); err != nil {
+			return ""
+		}
+		if m[2] == "" {
+			return re
+		}
+		if m[3] != "" {
+			if captureTypes[m[3]] == nil {
+				err = fmt.Errorf("unknown capture type %q in %s", m[3], ref)
+				return ""
+			}
+			x.types[m[2]] = m[3]
+		}
+		return "(?P<" + m[2] + ">" + re + ")"
+	})
+	return out, err
+}
+
+func (x *expander) resolve(name string) (string, error) {
+	if re, ok := x.done[name]; ok {
+		return re, nil
+	}
+	raw, ok := x.base[name]
+	if !ok {
+		if len(x.stack) > 0 {
+			return "", fmt.Errorf("unknown placeholder {%s} in {%s}", name, x.stack[len(x.stack)-1])
+		}
+		return "", fmt.Errorf("unknown placeholder {%s}", name)
+	}
+	for i, n := range x.stack {
+		if n == name {
+			return "", fmt.Errorf("placeholder cycle %s -> %s", strings.Join(x.stack[i:], " -> "), name)
+		}
+	}
+
+	x.stack = append(x.stack, name)
+	re, err := x.expand(raw)
+	x.stack = x.stack[:len(x.stack)-1]
+	if err != nil {
+		return "", err
+	}
+	x.done[name] = re
+	return re, nil
+}
+
+func convertInt(s string) (any, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	return n, err == nil
+}
+
+func convertFloat(s string) (any, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// splitDir splits a N/S/E/W direction off either end of a coordinate.
+func splitDir(s string) (value, dir string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ""
+	}
+	if strings.ContainsRune("NSEW", rune(s[0])) {
+		return strings.TrimSpace(s[1:]), s[:1]
+	}
+	if last := s[len(s)-1]; strings.ContainsRune("NSEW", rune(last)) {
+		return strings.TrimSpace(s[:len(s)-1]), string(last)
+	}
+	return s, ""
+}
+
+// convertCoord reads a degrees-minutes coordinate with its direction, such
+// as N4512.3, S22336 or W0065890, into decimal degrees.  N/S values have two
+// degree digits, E/W values three; a value in any other layout ParseDMSCoord
+// knows is rejected.
+func convertCoord(s string) (any, bool) {
+	value, dir := splitDir(s)
+	if value == "" || dir == "" {
+		return nil, false
+	}
+	degDigits := 3
+	if dir == "N" || dir == "S" {
+		degDigits = 2
+	}
+	if !dmsLayout(value, degDigits) {
+		return nil, false
+	}
+	return ParseDMSCoord(value, degDigits, dir), true
+}
+
+// dmsLayout reports whether value is laid out as ParseDMSCoord reads it with
+// degDigits degree digits: degrees and whole minutes with decimal minutes
+// after a point, or without a point, degrees, minutes and either tenths of a
+// minute or seconds.
+func dmsLayout(value string, degDigits int) bool {
+	whole, frac, hasPoint := strings.Cut(value, ".")
+	if !allDigits(whole) || (hasPoint && !allDigits(frac)) {
+		return false
+	}
+	if hasPoint {
+		return len(whole) == degDigits+2
+	}
+	return len(whole) == degDigits+3 || len(whole) == degDigits+4
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// convertDecCoord reads a decimal-degrees coordinate, with an optional
+// direction, such as N45.123, -33.5 or 151.2E.
+func convertDecCoord(s string) (any, bool) {
+	value, dir := splitDir(s)
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return nil, false
+	}
+	return ParseDecimalCoord(value, dir), true
+}
+
+// convertTime reads HHMM or HHMMSS, with an optional trailing Z, as a time
+// of day: the time.Duration since midnight.
+func convertTime(s string) (any, bool) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "Z")
+	if len(s) != 4 && len(s) != 6 {
+		return nil, false
+	}
+	limits := []int{23, 59, 59}
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i := 0; i < len(s); i += 2 {
+		n, err := strconv.Atoi(s[i : i+2])
+		if err != nil || n < 0 || n > limits[i/2] {
+			return nil, false
+		}
+		d += time.Duration(n) * units[i/2]
+	}
+	return d, true
+}
@@ -0,0 +1,127 @@
+package patterns
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpand(t *testing.T) {
+	base := map[string]string{
+		"DIGIT": `\d`,
+		"PAIR":  `{DIGIT}{DIGIT}`,
+		"HHMM":  `{PAIR}{PAIR}`,
+		"LOOP":  `A{LOOP2}`,
+		"LOOP2": `B{LOOP}`,
+		"BAD":   `{MISSING}`,
+	}
+	tests := []struct {
+		name, pattern, want, err string
+		types                    map[string]string
+	}{
+		{name: "nested", pattern: `^T{HHMM}`, want: `^T\d\d\d\d`},
+		{name: "named", pattern: `{PAIR:day}/{HHMM:eta:time}`, want: `(?P<day>\d\d)/(?P<eta>\d\d\d\d)`,
+			types: map[string]string{"eta": "time"}},
+		{name: "quantifiers left alone", pattern: `[A-Z]{3}\d{2,4}`, want: `[A-Z]{3}\d{2,4}`},
+		{name: "unknown", pattern: `{HHMM}{HHMN}`, err: "unknown placeholder {HHMN}"},
+		{name: "unknown inside a base pattern", pattern: `{BAD}`, err: "unknown placeholder {MISSING} in {BAD}"},
+		{name: "cycle", pattern: `X{LOOP}`, err: "placeholder cycle LOOP -> LOOP2 -> LOOP"},
+		{name: "unknown type", pattern: `{PAIR:day:date}`, err: `unknown capture type "date" in {PAIR:day:date}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order varies, so expand a few times.
+			for i := 0; i < 5; i++ {
+				got, types, err := Expand(tt.pattern, base)
+				if tt.err != "" {
+					if err == nil || err.Error() != tt.err {
+						t.Fatalf("error = %v, want %q", err, tt.err)
+					}
+					continue
+				}
+				if err != nil || got != tt.want {
+					t.Fatalf("got %q, %v; want %q", got, err, tt.want)
+				}
+				if len(types) != len(tt.types) || types["eta"] != tt.types["eta"] {
+					t.Fatalf("types = %v", types)
+				}
+			}
+		})
+	}
+}
+
+func TestTypedCaptures(t *testing.T) {
+	c := NewCompiler([]Format{{
+		Name:    "pos",
+		Pattern: `^POS{LAT:lat:coord}{LON:lon:coord},{NUM:alt:int},{LAT_DEC:dec:dec_coord},{TIME4:eta:time},{TIME4:bad:time}(?:,{NUM:opt:int})?`,
+	}}, nil)
+	if err := c.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	m := c.Parse("POSS22336W0065830,35000,-33.5,1530,2590")
+	if m == nil {
+		t.Fatal("no match")
+	}
+	if lat, ok := m.Float("lat"); !ok || !almostEqual(lat, -22.56, 0.0001) {
+		t.Errorf("lat = %v, %v", lat, ok)
+	}
+	if lon, ok := m.Float("lon"); !ok || !almostEqual(lon, -6.975, 0.0001) {
+		t.Errorf("lon = %v, %v", lon, ok)
+	}
+	if alt, ok := m.Int("alt"); !ok || alt != 35000 {
+		t.Errorf("alt = %v, %v", alt, ok)
+	}
+	if dec, ok := m.Float("dec"); !ok || dec != -33.5 {
+		t.Errorf("dec = %v, %v", dec, ok)
+	}
+	if eta, ok := m.Time("eta"); !ok || eta != 15*time.Hour+30*time.Minute {
+		t.Errorf("eta = %v, %v", eta, ok)
+	}
+	// Unreadable and empty captures keep their raw text but have no value.
+	if _, ok := m.Values["bad"]; ok || m.Captures["bad"] != "2590" {
+		t.Errorf("bad = %v, %q", m.Values["bad"], m.Captures["bad"])
+	}
+	if _, ok := m.Int("opt"); ok {
+		t.Error("empty optional capture has a value")
+	}
+	if f := c.Formats()[0]; f.Types["lat"] != "coord" || f.Types["alt"] != "int" || len(f.Types) != 7 {
+		t.Errorf("types = %v", f.Types)
+	}
+
+	err := NewCompiler([]Format{{Name: "typo", Pattern: `^{TIME4:eta:time}{TIMEE4}`}}, nil).Compile()
+	if err == nil || !strings.Contains(err.Error(), "format typo: unknown placeholder {TIMEE4}") {
+		t.Errorf("unknown placeholder: %v", err)
+	}
+}
+
+func TestConvertCoord(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"N4512.3", 45.205, true},
+		{"S22336", -22.56, true},
+		{"N223348", 22.5633, true},
+		{"E151235", 151.3917, true},
+		{"W0065830", -6.975, true},
+		{"00630.0W", -6.5, true},
+		{"N4512", 0, false},    // DDMM
+		{"E1512", 0, false},    // too short for three degree digits
+		{"S2233480", 0, false}, // one digit too many
+		{"N45123.4", 0, false}, // minutes point in the wrong place
+		{"W1e5", 0, false},     // not digits
+		{"N-4512.3", 0, false}, // sign
+		{"4512.3", 0, false},   // no direction
+		{"N", 0, false},        // no value
+	} {
+		v, ok := convertCoord(tt.in)
+		if ok != tt.ok {
+			t.Errorf("convertCoord(%q) = %v, %v; want ok %v", tt.in, v, ok, tt.ok)
+			continue
+		}
+		if ok && !almostEqual(v.(float64), tt.want, 0.0001) {
+			t.Errorf("convertCoord(%q) = %v, want %v", tt.in, v, tt.want)
+		}
+	}
+}
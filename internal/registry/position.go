@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Position is a position report in canonical units, whatever the field
+// names and units of the result that carried it.  Zero values mean the
+// report did not give the value, as in the results themselves.
+type Position struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Altitude   int     `json:"altitude_ft,omitempty"` // Feet; flight levels are multiplied out.
+	ReportTime string  `json:"report_time,omitempty"` // UTC time of day of the report, HH:MM:SS; see At.
+
+	GroundSpeed int      `json:"ground_speed_kts,omitempty"`
+	Track       int      `json:"track_deg,omitempty"`     // Degrees; the heading when the report gives no track.
+	Temperature *float64 `json:"temperature_c,omitempty"` // Static air temperature; results that keep it as a plain number cannot report 0 °C.
+	Wind        *Wind    `json:"wind,omitempty"`
+}
+
+// Wind is the wind at a reported position.
+type Wind struct {
+	Direction int `json:"direction_deg"` // Degrees true, where the wind blows from.
+	Speed     int `json:"speed_kts"`
+}
+
+// PositionReporter is implemented by results that carry the aircraft's
+// position, so the state tracker, the track exporters and map output can use
+// any of them without knowing their fields.  ok is false when the parse found
+// no position.
+type PositionReporter interface {
+	Position() (p Position, ok bool)
+}
+
+// MultiPositionReporter is implemented by results that report several
+// positions, such as a block of position rows.  Their Position is the first.
+type MultiPositionReporter interface {
+	PositionReporter
+	ReportedPositions() []Position
+}
+
+// StationPositionReporter is implemented by results that carry the position
+// of a ground station rather than of the aircraft, such as squitters.  They
+// are not PositionReporters, so station positions stay out of tracks.
+type StationPositionReporter interface {
+	StationPosition() (p Position, ok bool)
+}
+
+// PositionOf returns r's position, if r reports one.
+func PositionOf(r Result) (Position, bool) {
+	if p, ok := r.(PositionReporter); ok {
+		return p.Position()
+	}
+	return Position{}, false
+}
+
+// StationPositionOf returns the ground station position r reports, if any.
+func StationPositionOf(r Result) (Position, bool) {
+	if s, ok := r.(StationPositionReporter); ok {
+		return s.StationPosition()
+	}
+	return Position{}, false
+}
+
+// PositionsOf returns every position r reports, in report order.
+func PositionsOf(r Result) []Position {
+	if m, ok := r.(MultiPositionReporter); ok {
+		return m.ReportedPositions()
+	}
+	if p, ok := PositionOf(r); ok {
+		return []Position{p}
+	}
+	return nil
+}
+
+// At returns the time of the report: the instant nearest ref with the
+// report's time of day, or ref when the report gives none.  ref is usually
+// the message time.
+func (p Position) At(ref time.Time) time.Time {
+	tod, ok := parseTimeOfDay(p.ReportTime)
+	if !ok || ref.IsZero() {
+		return ref
+	}
+	ref = ref.UTC()
+	at := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.UTC).Add(tod)
+	switch d := at.Sub(ref); {
+	case d > 12*time.Hour:
+		at = at.AddDate(0, 0, -1)
+	case d < -12*time.Hour:
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// ReportTime returns the canonical HH:MM:SS form of a reported time of day
+// given as HH:MM, HHMM, HH:MM:SS or HHMMSS, with or without a trailing Z, or "" if s is none of them.
+func ReportTime(s string) string {
+	tod, ok := parseTimeOfDay(s)
+	if !ok {
+		return ""
+	}
+	sec := int(tod / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", sec/3600, sec/60%60, sec%60)
+}
+
+// parseTimeOfDay parses the time of day formats ReportTime accepts.
+func parseTimeOfDay(s string) (time.Duration, bool) {
+	s = strings.ReplaceAll(strings.TrimSuffix(strings.TrimSpace(s), "Z"), ":", "")
+	if len(s) != 4 && len(s) != 6 {
+		return 0, false
+	}
+	var parts [3]int
+	for i := 0; i < len(s); i += 2 {
+		n, err := strconv.Atoi(s[i : i+2])
+		if err != nil {
+			return 0, false
+		}
+		parts[i/2] = n
+	}
+	if parts[0] > 23 || parts[1] > 59 || parts[2] > 59 {
+		return 0, false
+	}
+	return time.Duration(parts[0])*time.Hour + time.Duration(parts[1])*time.Minute + time.Duration(parts[2])*time.Second, true
+}
+
+// Temperature returns a Position temperature, or nil for the zero the
+// results use when none was reported.  A reported 0 °C is lost with it;
+// results that know the reading was present should take its address instead.
+func Temperature(c float64) *float64 {
+	if c == 0 {
+		return nil
+	}
+	return &c
+}
+
+// ParseTemperature returns a Position temperature from a reported one such
+// as "-52", "+05", "M52" or "P05", or nil if s is none of them.
+func ParseTemperature(s string) *float64 {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "M") {
+		s = "-" + s[1:]
+	} else if strings.HasPrefix(s, "P") {
+		s = s[1:]
+	}
+	c, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &c
+}
+
+// NewWind returns a Position wind, or nil when neither part was reported.
+func NewWind(direction, speed int) *Wind {
+	if direction == 0 && speed == 0 {
+		return nil
+	}
+	return &Wind{Direction: direction, Speed: speed}
+}
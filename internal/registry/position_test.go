@@ -0,0 +1,84 @@
+package registry
+
+import (
+	"testing"
+	"time"
+)
+
+type positionResult struct {
+	testResult
+	positions []Position
+}
+
+func (r *positionResult) Position() (Position, bool) {
+	if len(r.positions) == 0 {
+		return Position{}, false
+	}
+	return r.positions[0], true
+}
+
+func (r *positionResult) ReportedPositions() []Position { return r.positions }
+
+func TestReportTime(t *testing.T) {
+	for in, want := range map[string]string{
+		"17:04":    "17:04:00",
+		"1704":     "17:04:00",
+		"1704Z":    "17:04:00",
+		"17:04:16": "17:04:16",
+		"170416":   "17:04:16",
+		"2460":     "",
+		"03A03:40": "",
+		"":         "",
+	} {
+		if got := ReportTime(in); got != want {
+			t.Errorf("ReportTime(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPositionAt(t *testing.T) {
+	ref := time.Date(2026, 5, 12, 0, 5, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		report string
+		want   time.Time
+	}{
+		{"", ref},
+		{"00:01:30", time.Date(2026, 5, 12, 0, 1, 30, 0, time.UTC)},
+		// Reported just before midnight, heard just after.
+		{"23:58:00", time.Date(2026, 5, 11, 23, 58, 0, 0, time.UTC)},
+	} {
+		if got := (Position{ReportTime: tc.report}).At(ref); !got.Equal(tc.want) {
+			t.Errorf("At(%q) = %v, want %v", tc.report, got, tc.want)
+		}
+	}
+	if got := (Position{ReportTime: "12:00:00"}).At(time.Time{}); !got.IsZero() {
+		t.Errorf("At(zero) = %v", got)
+	}
+}
+
+func TestParseTemperature(t *testing.T) {
+	for in, want := range map[string]float64{"-52": -52, "+05": 5, "M52": -52, "P05": 5, " -43 ": -43} {
+		if got := ParseTemperature(in); got == nil || *got != want {
+			t.Errorf("ParseTemperature(%q) = %v, want %v", in, got, want)
+		}
+	}
+	if got := ParseTemperature("*****"); got != nil {
+		t.Errorf("ParseTemperature(*****) = %v", *got)
+	}
+}
+
+func TestPositionsOf(t *testing.T) {
+	if got := PositionsOf(&testResult{}); got != nil {
+		t.Errorf("PositionsOf(no position) = %v", got)
+	}
+	if _, ok := PositionOf(&testResult{}); ok {
+		t.Error("PositionOf reported a position for a result without one")
+	}
+	r := &positionResult{positions: []Position{{Latitude: 53.4, Longitude: -6.2}, {Latitude: 53.1, Longitude: -4.5}}}
+	if got := PositionsOf(r); len(got) != 2 || got[1].Longitude != -4.5 {
+		t.Errorf("PositionsOf(multi) = %v", got)
+	}
+	if p, ok := PositionOf(r); !ok || p.Latitude != 53.4 {
+		t.Errorf("PositionOf(multi) = %v, %v", p, ok)
+	}
+}
@@ -0,0 +1,56 @@
+package registry
+
+// Quality says how complete a parse is.
+type Quality struct {
+	Expected   []string `json:"expected"`          // Fields a full parse of this kind fills.
+	Missing    []string `json:"missing,omitempty"` // Expected fields left empty.
+	Confidence float64  `json:"confidence"`        // Weighted share of expected fields found, 0-1.
+}
+
+// QualityReporter is implemented by results that can say how complete they
+// are, so weak parses can be ranked and sent for review.  A result with
+// nothing to assess, such as an ADS-C contract request, returns a Quality
+// without expected fields.
+type QualityReporter interface {
+	Quality() Quality
+}
+
+// Field is one expected field of a result, for Assess.
+type Field struct {
+	Name    string  // JSON name of the field
+	Present bool    // Whether the parse filled it
+	Weight  float64 // Share of the confidence; 0 counts as 1
+}
+
+// Assess builds a Quality from a result's expected fields.
+func Assess(fields ...Field) Quality {
+	q := Quality{Expected: make([]string, 0, len(fields))}
+	var found, total float64
+	for _, f := range fields {
+		w := f.Weight
+		if w == 0 {
+			w = 1
+		}
+		total += w
+		q.Expected = append(q.Expected, f.Name)
+		if f.Present {
+			found += w
+		} else {
+			q.Missing = append(q.Missing, f.Name)
+		}
+	}
+	if total > 0 {
+		q.Confidence = found / total
+	}
+	return q
+}
+
+// QualityOf returns r's Quality, if r reports one with expected fields.
+func QualityOf(r Result) (Quality, bool) {
+	if q, ok := r.(QualityReporter); ok {
+		if q := q.Quality(); len(q.Expected) > 0 {
+			return q, true
+		}
+	}
+	return Quality{}, false
+}
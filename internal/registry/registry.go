@@ -3,10 +3,16 @@
 package registry
 
 import (
+	"fmt"
+	"runtime/debug"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"acars_parser/internal/acars"
+	"acars_parser/internal/patterns"
 )
 
 // Result is the common interface for all parse results.
@@ -22,6 +28,19 @@ type HumanReadableResult interface {
 	HumanReadableText() string
 }
 
+// ErrorResult records a parser that panicked on a message.  Dispatch returns
+// it in place of that parser's result and carries on with the others.
+type ErrorResult struct {
+	MsgID  int64  `json:"message_id"`
+	Parser string `json:"parser"`
+	Label  string `json:"label"`
+	Error  string `json:"error"`
+	Stack  string `json:"stack,omitempty"` // The innermost frames of the panic.
+}
+
+func (r *ErrorResult) Type() string     { return "parser_error" }
+func (r *ErrorResult) MessageID() int64 { return r.MsgID }
+
 // Parser is implemented by each message parser.
 type Parser interface {
 	// Name returns the parser's unique identifier.
@@ -44,6 +63,20 @@ type Parser interface {
 	Parse(msg *acars.Message) Result
 }
 
+// ResultTyper is implemented by parsers whose results report a Type() other
+// than the parser's Name().  A parser without it is taken to produce results
+// of type Name().
+type ResultTyper interface {
+	ResultTypes() []string
+}
+
+// ResultPrototyper is implemented by parsers that can hand out an empty
+// result, so tools such as the schema generator can reflect over the Go type
+// of what they produce.
+type ResultPrototyper interface {
+	NewResult() Result
+}
+
 // Registry holds all registered parsers organised for efficient dispatch.
 type Registry struct {
 	mu sync.RWMutex
@@ -59,6 +92,27 @@ type Registry struct {
 
 	// sorted tracks whether parsers have been sorted
 	sorted bool
+
+	// counters holds per-parser metrics by name; nil until EnableMetrics
+	counters map[string]*counters
+}
+
+// ParserMetrics reports what one parser did in Dispatch and DispatchFirst
+// since EnableMetrics was called.
+type ParserMetrics struct {
+	Name     string        `json:"name"`
+	Calls    int64         `json:"calls"`           // Messages offered to the parser.
+	Hits     int64         `json:"quickcheck_hits"` // QuickCheck passed (every call for catch-alls).
+	Misses   int64         `json:"false_positives"` // QuickCheck passed but Parse returned nil.
+	Parsed   int64         `json:"parsed"`
+	Panics   int64         `json:"panics"`
+	Duration time.Duration `json:"duration_ns"` // Time spent in QuickCheck and Parse.
+}
+
+// counters is the live form of ParserMetrics, updated from concurrent
+// Dispatch calls.
+type counters struct {
+	calls, hits, misses, parsed, panics, nanos atomic.Int64
 }
 
 // New creates a new Registry instance.
@@ -92,6 +146,7 @@ func (r *Registry) Register(p Parser) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.addCounters(p)
 	labels := p.Labels()
 	if len(labels) == 0 {
 		// Content-based parser - checks all messages
@@ -108,6 +163,7 @@ func (r *Registry) Register(p Parser) {
 func (r *Registry) RegisterCatchAll(p Parser) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.addCounters(p)
 	r.catchAll = append(r.catchAll, p)
 	r.sorted = false
 }
@@ -141,41 +197,37 @@ func (r *Registry) Sort() {
 
 // Dispatch routes a message to appropriate parsers and returns all results.
 // Multiple parsers can match the same message (e.g., PDC + route info).
+// A parser that panics yields an *ErrorResult and does not stop the others.
 func (r *Registry) Dispatch(msg *acars.Message) []Result {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var results []Result
+	matched := false
+	add := func(result Result, _ bool) {
+		if result == nil {
+			return
+		}
+		if _, failed := result.(*ErrorResult); !failed {
+			matched = true
+		}
+		results = append(results, result)
+	}
 
 	// 1. Try label-specific parsers first (most efficient path)
-	if parsers, ok := r.byLabel[msg.Label]; ok {
-		for _, p := range parsers {
-			// Quick check before expensive parse
-			if !p.QuickCheck(msg.Text) {
-				continue
-			}
-			if result := p.Parse(msg); result != nil {
-				results = append(results, result)
-			}
-		}
+	for _, p := range r.byLabel[msg.Label] {
+		add(r.run(p, msg, true))
 	}
 
 	// 2. Try global (content-based) parsers
 	for _, p := range r.global {
-		if !p.QuickCheck(msg.Text) {
-			continue
-		}
-		if result := p.Parse(msg); result != nil {
-			results = append(results, result)
-		}
+		add(r.run(p, msg, true))
 	}
 
 	// 3. If nothing matched, try catch-all parsers
-	if len(results) == 0 && len(r.catchAll) > 0 {
+	if !matched {
 		for _, p := range r.catchAll {
-			if result := p.Parse(msg); result != nil {
-				results = append(results, result)
-			}
+			add(r.run(p, msg, false))
 		}
 	}
 
@@ -183,41 +235,175 @@ func (r *Registry) Dispatch(msg *acars.Message) []Result {
 }
 
 // DispatchFirst returns only the first successful parse result.
-// Useful when you only need one result per message.
+// Useful when you only need one result per message.  When every parser that
+// tried the message panicked, the first *ErrorResult is returned.
 func (r *Registry) DispatchFirst(msg *acars.Message) Result {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	// Try label-specific parsers
-	if parsers, ok := r.byLabel[msg.Label]; ok {
-		for _, p := range parsers {
-			if !p.QuickCheck(msg.Text) {
-				continue
-			}
-			if result := p.Parse(msg); result != nil {
-				return result
+	var failure Result
+	try := func(p Parser, check bool) Result {
+		result, _ := r.run(p, msg, check)
+		if _, failed := result.(*ErrorResult); failed {
+			if failure == nil {
+				failure = result
 			}
+			return nil
+		}
+		return result
+	}
+
+	// Try label-specific parsers
+	for _, p := range r.byLabel[msg.Label] {
+		if result := try(p, true); result != nil {
+			return result
 		}
 	}
 
 	// Try global parsers
 	for _, p := range r.global {
-		if !p.QuickCheck(msg.Text) {
-			continue
-		}
-		if result := p.Parse(msg); result != nil {
+		if result := try(p, true); result != nil {
 			return result
 		}
 	}
 
 	// Try catch-all
 	for _, p := range r.catchAll {
-		if result := p.Parse(msg); result != nil {
+		if result := try(p, false); result != nil {
 			return result
 		}
 	}
 
-	return nil
+	return failure
+}
+
+// ParserTrace records how one parser handled a message in DispatchWithTrace.
+type ParserTrace struct {
+	Parser     string
+	Stage      string // "label", "global" or "catch_all"
+	Priority   int
+	Ran        bool   // False for catch-all parsers skipped because another parser matched.
+	QuickCheck bool   // QuickCheck passed; catch-all parsers have none.
+	Result     Result // Nil when Parse found nothing.
+
+	// Calls lists the grok formats the parser tried, one entry per
+	// compiler call.  Parsers without grok formats leave it empty.
+	Calls []patterns.CallTrace
+}
+
+// DispatchWithTrace dispatches msg like Dispatch and also returns a trace of
+// every parser considered for it, in dispatch order.  Grok formats are
+// captured with patterns.Record, so it is meant for looking at one message
+// while nothing else is being dispatched.
+func (r *Registry) DispatchWithTrace(msg *acars.Message) ([]Result, []ParserTrace) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []Result
+	var traces []ParserTrace
+	matched := false
+	try := func(p Parser, stage string, check bool) {
+		t := ParserTrace{Parser: p.Name(), Stage: stage, Priority: p.Priority(), Ran: true}
+		t.Calls = patterns.Record(func() {
+			t.Result, t.QuickCheck = r.run(p, msg, check)
+		})
+		if t.Result != nil {
+			if _, failed := t.Result.(*ErrorResult); !failed {
+				matched = true
+			}
+			results = append(results, t.Result)
+		}
+		traces = append(traces, t)
+	}
+
+	for _, p := range r.byLabel[msg.Label] {
+		try(p, "label", true)
+	}
+	for _, p := range r.global {
+		try(p, "global", true)
+	}
+	for _, p := range r.catchAll {
+		if matched {
+			traces = append(traces, ParserTrace{Parser: p.Name(), Stage: "catch_all", Priority: p.Priority()})
+			continue
+		}
+		try(p, "catch_all", false)
+	}
+	return results, traces
+}
+
+// run calls p on msg through the package-level run and records the call in
+// p's counters when metrics are enabled.
+func (r *Registry) run(p Parser, msg *acars.Message, check bool) (Result, bool) {
+	c := r.counters[p.Name()]
+	if c == nil {
+		return run(p, msg, check)
+	}
+	start := time.Now()
+	result, called := run(p, msg, check)
+	c.nanos.Add(int64(time.Since(start)))
+	c.calls.Add(1)
+	if called {
+		c.hits.Add(1)
+	}
+	switch result.(type) {
+	case nil:
+		if called {
+			c.misses.Add(1)
+		}
+	case *ErrorResult:
+		c.panics.Add(1)
+	default:
+		c.parsed.Add(1)
+	}
+	return result, called
+}
+
+// run calls p on msg, after its QuickCheck when check is set, and turns a
+// panic in either into an *ErrorResult.  called reports whether Parse was
+// reached.
+func run(p Parser, msg *acars.Message, check bool) (result Result, called bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			result = &ErrorResult{
+				MsgID:  int64(msg.ID),
+				Parser: p.Name(),
+				Label:  msg.Label,
+				Error:  fmt.Sprintf("panic: %v", v),
+				Stack:  stackExcerpt(debug.Stack(), 5),
+			}
+		}
+	}()
+	if check && !p.QuickCheck(msg.Text) {
+		return nil, false
+	}
+	called = true
+	return p.Parse(msg), true
+}
+
+// stackExcerpt returns up to frames frames of a debug.Stack trace, starting
+// with the one that panicked.
+func stackExcerpt(stack []byte, frames int) string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	// Skip the goroutine line, then everything up to runtime's panic frame.
+	start := 1
+	for i := 1; i+1 < len(lines); i += 2 {
+		if strings.HasPrefix(lines[i], "panic(") {
+			start = i + 2
+			break
+		}
+	}
+	end := start + 2*frames
+	if end > len(lines) {
+		end = len(lines)
+	}
+	if start >= end {
+		return ""
+	}
+	for i := start; i < end; i++ {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines[start:end], "\n")
 }
 
 // RegisteredLabels returns all labels that have parsers registered.
@@ -244,3 +430,208 @@ func (r *Registry) ParserCount() int {
 	}
 	return count
 }
+
+// Has reports whether a parser named name is registered.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found := false
+	r.each(func(p Parser) {
+		found = found || p.Name() == name
+	})
+	return found
+}
+
+// Parsers returns every registered parser once, sorted by name.
+func (r *Registry) Parsers() []Parser {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var out []Parser
+	r.each(func(p Parser) {
+		if !seen[p.Name()] {
+			seen[p.Name()] = true
+			out = append(out, p)
+		}
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// EnableMetrics starts counting QuickCheck hits, false positives, parses,
+// panics and time for every parser.  Counting costs two clock reads per
+// parser call, so it is off by default.  Calling it again resets the counts.
+func (r *Registry) EnableMetrics() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters = make(map[string]*counters)
+	r.each(r.addCounters)
+}
+
+// addCounters gives p a set of counters if metrics are enabled.  A parser
+// registered for several labels shares one set.  Callers hold r.mu.
+func (r *Registry) addCounters(p Parser) {
+	if r.counters != nil && r.counters[p.Name()] == nil {
+		r.counters[p.Name()] = &counters{}
+	}
+}
+
+// Metrics returns a snapshot of the per-parser counts, most time-consuming
+// parser first.  It returns nil unless EnableMetrics has been called.
+func (r *Registry) Metrics() []ParserMetrics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.counters == nil {
+		return nil
+	}
+	out := make([]ParserMetrics, 0, len(r.counters))
+	for name, c := range r.counters {
+		out = append(out, ParserMetrics{
+			Name:     name,
+			Calls:    c.calls.Load(),
+			Hits:     c.hits.Load(),
+			Misses:   c.misses.Load(),
+			Parsed:   c.parsed.Load(),
+			Panics:   c.panics.Load(),
+			Duration: time.Duration(c.nanos.Load()),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Duration != out[j].Duration {
+			return out[i].Duration > out[j].Duration
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Selection picks and reorders the parsers of a cloned registry.  Entries in
+// Enable and Disable match a parser's Name() or any of its result types.
+type Selection struct {
+	Enable   []string       `json:"enable,omitempty"`   // Keep only these parsers; empty keeps all.
+	Disable  []string       `json:"disable,omitempty"`  // Drop these, even when enabled.
+	Priority map[string]int `json:"priority,omitempty"` // Priority overrides by parser name.
+}
+
+// Clone returns a new registry holding the parsers of r that sel keeps, with
+// its priority overrides applied, sorted and ready to dispatch.  Names that
+// match no parser are an error, so a typo does not silently select nothing.
+// Metrics are not carried over.
+func (r *Registry) Clone(sel Selection) (*Registry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	known := make(map[string]bool)
+	names := make(map[string]bool)
+	r.each(func(p Parser) {
+		names[p.Name()] = true
+		known[p.Name()] = true
+		for _, t := range ResultTypes(p) {
+			known[t] = true
+		}
+	})
+	for _, list := range [][]string{sel.Enable, sel.Disable} {
+		for _, name := range list {
+			if !known[name] {
+				return nil, fmt.Errorf("unknown parser or result type %q", name)
+			}
+		}
+	}
+	for name := range sel.Priority {
+		if !names[name] {
+			return nil, fmt.Errorf("priority override for unknown parser %q", name)
+		}
+	}
+
+	keep := func(p Parser) Parser {
+		if len(sel.Enable) > 0 && !matches(p, sel.Enable) || matches(p, sel.Disable) {
+			return nil
+		}
+		if prio, ok := sel.Priority[p.Name()]; ok {
+			return &reprioritised{Parser: p, priority: prio}
+		}
+		return p
+	}
+	c := New()
+	for label, parsers := range r.byLabel {
+		for _, p := range parsers {
+			if p = keep(p); p != nil {
+				c.byLabel[label] = append(c.byLabel[label], p)
+			}
+		}
+	}
+	for _, p := range r.global {
+		if p = keep(p); p != nil {
+			c.global = append(c.global, p)
+		}
+	}
+	for _, p := range r.catchAll {
+		if p = keep(p); p != nil {
+			c.catchAll = append(c.catchAll, p)
+		}
+	}
+	c.Sort()
+	return c, nil
+}
+
+// each calls fn for every parser slot; a parser registered for several
+// labels is visited once per label.  Callers hold r.mu.
+func (r *Registry) each(fn func(Parser)) {
+	for _, parsers := range r.byLabel {
+		for _, p := range parsers {
+			fn(p)
+		}
+	}
+	for _, p := range r.global {
+		fn(p)
+	}
+	for _, p := range r.catchAll {
+		fn(p)
+	}
+}
+
+// ResultTypes returns the result types p produces.
+func ResultTypes(p Parser) []string {
+	if t, ok := p.(ResultTyper); ok {
+		return t.ResultTypes()
+	}
+	return []string{p.Name()}
+}
+
+// NewResult returns an empty result of the type p produces, or nil if p does
+// not implement ResultPrototyper.
+func NewResult(p Parser) Result {
+	if rp, ok := p.(ResultPrototyper); ok {
+		return rp.NewResult()
+	}
+	return nil
+}
+
+// matches reports whether p's name or one of its result types is in names.
+func matches(p Parser, names []string) bool {
+	for _, name := range names {
+		if name == p.Name() {
+			return true
+		}
+		for _, t := range ResultTypes(p) {
+			if name == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reprioritised overrides the priority of a cloned parser.
+type reprioritised struct {
+	Parser
+	priority int
+}
+
+func (p *reprioritised) Priority() int         { return p.priority }
+func (p *reprioritised) ResultTypes() []string { return ResultTypes(p.Parser) }
+func (p *reprioritised) NewResult() Result     { return NewResult(p.Parser) }
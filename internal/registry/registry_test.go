@@ -0,0 +1,269 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/patterns"
+)
+
+type testResult struct{ id int64 }
+
+func (r *testResult) Type() string     { return "test" }
+func (r *testResult) MessageID() int64 { return r.id }
+
+type testParser struct {
+	name     string
+	priority int
+	labels   []string
+	parse    func(msg *acars.Message) Result
+}
+
+func (p *testParser) Name() string                    { return p.name }
+func (p *testParser) Labels() []string                { return p.labels }
+func (p *testParser) QuickCheck(string) bool          { return true }
+func (p *testParser) Priority() int                   { return p.priority }
+func (p *testParser) Parse(msg *acars.Message) Result { return p.parse(msg) }
+
+func outOfRange(msg *acars.Message) Result {
+	b := []byte(msg.Text)
+	return &testResult{id: int64(b[len(b)+3])}
+}
+
+func TestDispatchRecoversFromPanics(t *testing.T) {
+	r := New()
+	r.Register(&testParser{name: "broken", priority: 1, labels: []string{"B6"}, parse: outOfRange})
+	r.Register(&testParser{name: "working", priority: 2, labels: []string{"B6"}, parse: func(msg *acars.Message) Result {
+		return &testResult{id: int64(msg.ID)}
+	}})
+	r.RegisterCatchAll(&testParser{name: "fallback", parse: func(msg *acars.Message) Result {
+		return &testResult{}
+	}})
+	r.Sort()
+
+	msg := &acars.Message{ID: 42, Label: "B6", Text: "/BOGUS"}
+	results := r.Dispatch(msg)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want the error and the working parser's result", len(results))
+	}
+	e, ok := results[0].(*ErrorResult)
+	if !ok || e.Parser != "broken" || e.Label != "B6" || e.MsgID != 42 || e.Type() != "parser_error" ||
+		!strings.Contains(e.Error, "index out of range") {
+		t.Fatalf("error result = %+v", results[0])
+	}
+	if !strings.Contains(e.Stack, "registry.outOfRange") {
+		t.Errorf("stack excerpt does not start at the panicking frame:\n%s", e.Stack)
+	}
+	if results[1].MessageID() != 42 {
+		t.Errorf("second result = %+v", results[1])
+	}
+
+	// A panic alone does not count as a match, so the catch-all still runs,
+	// and DispatchFirst skips past it.
+	r = New()
+	r.Register(&testParser{name: "broken", labels: []string{"B6"}, parse: outOfRange})
+	r.RegisterCatchAll(&testParser{name: "fallback", parse: func(msg *acars.Message) Result {
+		return &testResult{id: 7}
+	}})
+	r.Sort()
+	if results := r.Dispatch(msg); len(results) != 2 || results[1].MessageID() != 7 {
+		t.Fatalf("catch-all: %+v", results)
+	}
+	if first := r.DispatchFirst(msg); first == nil || first.MessageID() != 7 {
+		t.Fatalf("DispatchFirst = %+v", first)
+	}
+}
+
+type pickyParser struct{ testParser }
+
+func (p *pickyParser) QuickCheck(text string) bool { return strings.HasPrefix(text, "/") }
+
+func TestMetrics(t *testing.T) {
+	r := New()
+	r.Register(&pickyParser{testParser{name: "picky", labels: []string{"B6", "H1"}, parse: func(msg *acars.Message) Result {
+		if msg.Text == "/EMPTY" {
+			return nil
+		}
+		return &testResult{}
+	}}})
+	r.Register(&testParser{name: "broken", parse: outOfRange})
+	r.Sort()
+
+	msgs := []*acars.Message{{Label: "B6", Text: "/OK"}, {Label: "H1", Text: "/EMPTY"}, {Label: "B6", Text: "NOPE"}}
+	r.Dispatch(msgs[0])
+	if r.Metrics() != nil {
+		t.Fatal("metrics recorded before EnableMetrics")
+	}
+
+	r.EnableMetrics()
+	for _, msg := range msgs {
+		r.Dispatch(msg)
+	}
+	got := map[string]ParserMetrics{}
+	for _, m := range r.Metrics() {
+		got[m.Name] = m
+	}
+	if m := got["picky"]; m.Calls != 3 || m.Hits != 2 || m.Misses != 1 || m.Parsed != 1 || m.Panics != 0 {
+		t.Errorf("picky = %+v", m)
+	}
+	if m := got["broken"]; m.Calls != 3 || m.Hits != 3 || m.Misses != 0 || m.Panics != 3 || m.Duration <= 0 {
+		t.Errorf("broken = %+v", m)
+	}
+}
+
+type typedParser struct {
+	testParser
+	types []string
+}
+
+func (p *typedParser) ResultTypes() []string { return p.types }
+
+func TestClone(t *testing.T) {
+	found := func(name string) func(*acars.Message) Result {
+		return func(msg *acars.Message) Result { return &testResult{id: int64(len(name))} }
+	}
+	r := New()
+	r.Register(&typedParser{testParser{name: "label80", priority: 100, labels: []string{"80"}, parse: found("label80")}, []string{"position"}})
+	r.Register(&testParser{name: "envelope", priority: 10, labels: []string{"80"}, parse: found("envelope")})
+	r.Register(&testParser{name: "pdc", priority: 50, parse: found("pdc")})
+	r.Sort()
+	msg := &acars.Message{Label: "80", Text: "POS"}
+	ids := func(reg *Registry) []int64 {
+		var out []int64
+		for _, res := range reg.Dispatch(msg) {
+			out = append(out, res.MessageID())
+		}
+		return out
+	}
+
+	c, err := r.Clone(Selection{Enable: []string{"position", "pdc"}, Disable: []string{"pdc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(c); len(got) != 1 || got[0] != int64(len("label80")) {
+		t.Errorf("enable by result type: %v", got)
+	}
+	if got := ids(r); len(got) != 3 {
+		t.Errorf("the original registry changed: %v", got)
+	}
+
+	c, err = r.Clone(Selection{Priority: map[string]int{"label80": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(c); len(got) != 3 || got[0] != int64(len("label80")) {
+		t.Errorf("priority override: %v", got)
+	}
+	if c, _ := c.Clone(Selection{Enable: []string{"position"}}); len(ids(c)) != 1 {
+		t.Error("a reprioritised parser lost its result types")
+	}
+
+	if _, err := r.Clone(Selection{Disable: []string{"enveloppe"}}); err == nil {
+		t.Error("unknown name accepted")
+	}
+	if _, err := r.Clone(Selection{Priority: map[string]int{"position": 1}}); err == nil {
+		t.Error("priority override by result type accepted")
+	}
+}
+
+type prototypeParser struct{ testParser }
+
+func (p *prototypeParser) NewResult() Result { return &testResult{} }
+
+func TestParsers(t *testing.T) {
+	r := New()
+	r.Register(&prototypeParser{testParser{name: "label80", labels: []string{"80", "16"}}})
+	r.Register(&testParser{name: "envelope", labels: []string{"80"}})
+	c, err := r.Clone(Selection{Priority: map[string]int{"label80": 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := c.Parsers()
+	if len(ps) != 2 || ps[0].Name() != "envelope" || ps[1].Name() != "label80" {
+		t.Fatalf("parsers = %v", ps)
+	}
+	if NewResult(ps[0]) != nil {
+		t.Error("a parser without ResultPrototyper returned a result")
+	}
+	if _, ok := NewResult(ps[1]).(*testResult); !ok {
+		t.Error("a reprioritised parser lost its NewResult")
+	}
+}
+
+func TestDispatchWithTrace(t *testing.T) {
+	grok := patterns.NewCompiler([]patterns.Format{{Name: "eta", Pattern: `^ETA(?P<eta>{TIME4})`}}, nil)
+	if err := grok.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	r.Register(&pickyParser{testParser{name: "picky", labels: []string{"5Z"}, parse: func(msg *acars.Message) Result {
+		return &testResult{}
+	}}})
+	r.Register(&testParser{name: "eta", priority: 1, labels: []string{"5Z"}, parse: func(msg *acars.Message) Result {
+		if grok.Parse(msg.Text) == nil {
+			return nil
+		}
+		return &testResult{id: 5}
+	}})
+	r.Register(&testParser{name: "global", priority: 2, parse: func(*acars.Message) Result { return nil }})
+	r.RegisterCatchAll(&testParser{name: "fallback", parse: func(*acars.Message) Result { return &testResult{} }})
+	r.Sort()
+
+	msg := &acars.Message{Label: "5Z", Text: "ETA1234"}
+	results, traces := r.DispatchWithTrace(msg)
+	if len(results) != 1 || results[0].MessageID() != 5 {
+		t.Fatalf("results = %+v", results)
+	}
+	var got []string
+	for _, tr := range traces {
+		got = append(got, fmt.Sprintf("%s/%s ran=%v qc=%v result=%v calls=%d",
+			tr.Stage, tr.Parser, tr.Ran, tr.QuickCheck, tr.Result != nil, len(tr.Calls)))
+	}
+	want := []string{
+		"label/picky ran=true qc=false result=false calls=0",
+		"label/eta ran=true qc=true result=true calls=1",
+		"global/global ran=true qc=true result=false calls=0",
+		"catch_all/fallback ran=false qc=false result=false calls=0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("traces:\n%s", strings.Join(got, "\n"))
+	}
+	if f := traces[1].Calls[0].Formats; len(f) != 1 || f[0].Captures["eta"] != "1234" {
+		t.Errorf("eta formats = %+v", f)
+	}
+}
+
+func TestAssess(t *testing.T) {
+	q := Assess(
+		Field{Name: "latitude", Present: true, Weight: 2},
+		Field{Name: "longitude", Present: true, Weight: 2},
+		Field{Name: "altitude"},
+		Field{Name: "eta", Present: true, Weight: 0.5},
+		Field{Name: "squawk", Weight: 0.5},
+	)
+	if strings.Join(q.Expected, ",") != "latitude,longitude,altitude,eta,squawk" ||
+		strings.Join(q.Missing, ",") != "altitude,squawk" || q.Confidence != 0.75 {
+		t.Errorf("Assess = %+v", q)
+	}
+	if q := Assess(); q.Confidence != 0 || len(q.Expected) != 0 {
+		t.Errorf("no fields = %+v", q)
+	}
+	if _, ok := QualityOf(&testResult{}); ok {
+		t.Error("QualityOf reported a quality for a result without one")
+	}
+	if _, ok := QualityOf(qualityResult{}); ok {
+		t.Error("QualityOf reported a quality without expected fields")
+	}
+	if q, ok := QualityOf(qualityResult{{Name: "latitude"}}); !ok || q.Missing[0] != "latitude" {
+		t.Errorf("QualityOf = %+v, %v", q, ok)
+	}
+}
+
+// qualityResult assesses its fields.
+type qualityResult []Field
+
+func (r qualityResult) Type() string     { return "test" }
+func (r qualityResult) MessageID() int64 { return 0 }
+func (r qualityResult) Quality() Quality { return Assess(r...) }
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// docDirs are the directories, relative to the module root, whose comments
+// GenerateDocs collects: the parsers and the other packages records are
+// built from.
+var docDirs = []string{"internal/acars", "internal/decode", "internal/parsers", "internal/registry", "cmd/acars_parser"}
+
+// GenerateDocs reads the comments of the exported structs and their fields
+// under the module at root and returns the source of docs_gen.go.
+func GenerateDocs(root string) ([]byte, error) {
+	docs, err := ReadDocs(root)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(docs))
+	for k := range docs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by go generate; DO NOT EDIT.\n\npackage schema\n\n")
+	b.WriteString("// docs holds the comments of structs and their fields, by import path,\n")
+	b.WriteString("// type name and field name.\n")
+	b.WriteString("var docs = map[string]string{\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\t%q: %q,\n", k, docs[k])
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// ReadDocs returns the comments of the exported structs and their fields
+// under the module at root, keyed like docKey: "import/path.Type" and
+// "import/path.Type.Field".  A field's own comment wins over a trailing one.
+// Types of main packages are keyed by "main", as reflection reports them.
+func ReadDocs(root string) (map[string]string, error) {
+	module, err := modulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	docs := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, dir := range docDirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); name == "testdata" || strings.HasPrefix(name, ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			pkg := module + "/" + filepath.ToSlash(rel)
+			if f.Name.Name == "main" {
+				pkg = "main"
+			}
+			readFileDocs(f, pkg, docs)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+func readFileDocs(f *ast.File, pkg string, docs map[string]string) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || !ts.Name.IsExported() {
+				continue
+			}
+			key := pkg + "." + ts.Name.Name
+			doc := ts.Doc
+			if doc == nil && len(gd.Specs) == 1 {
+				doc = gd.Doc
+			}
+			if text := commentText(doc); text != "" {
+				docs[key] = text
+			}
+			for _, field := range st.Fields.List {
+				text := commentText(field.Doc)
+				if text == "" {
+					text = commentText(field.Comment)
+				}
+				if text == "" {
+					continue
+				}
+				for _, name := range field.Names {
+					if name.IsExported() {
+						docs[key+"."+name.Name] = text
+					}
+				}
+			}
+		}
+	}
+}
+
+// commentText returns the text of cg on one line.
+func commentText(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
+
+// modulePath reads the module path from root's go.mod.
+func modulePath(root string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("%s: no module line", filepath.Join(root, "go.mod"))
+}
@@ -0,0 +1,475 @@
+// Code generated by go generate; DO NOT EDIT.
+
+package schema
+
+// docs holds the comments of structs and their fields, by import path,
+// type name and field name.
+var docs = map[string]string{
+	"acars_parser/internal/acars.Airframe":                                  "Airframe contains aircraft identification data.",
+	"acars_parser/internal/acars.Flight":                                    "Flight contains flight identification and route data.",
+	"acars_parser/internal/acars.Message":                                   "Message represents the inner message from an ACARS feed. This can be populated directly from flat JSON or extracted from NATSWrapper.",
+	"acars_parser/internal/acars.Message.Airframe":                          "These may be present in the message itself (old format) or at wrapper level (NATS)",
+	"acars_parser/internal/acars.Message.EnrichedBy":                        "EnrichedBy maps each field the enrich package filled in to the enricher that found it.",
+	"acars_parser/internal/acars.NATSInner":                                 "NATSInner is the inner message structure from NATS feed.",
+	"acars_parser/internal/acars.NATSSource":                                "NATSSource contains source metadata from the NATS feed.",
+	"acars_parser/internal/acars.NATSWrapper":                               "NATSWrapper represents the NATS feed message format where the ACARS message is nested inside a \"message\" field with metadata at the top level.",
+	"acars_parser/internal/acars.Station":                                   "Station contains ground station data.",
+	"acars_parser/internal/decode.BlockAssembler":                           "BlockAssembler joins the blocks of multi-block ACARS messages before they are dispatched, so parsers see an FPN, loadsheet or PWI message whole. Blocks are grouped by tail, label and message number and ordered by their sequence letter. A set is handed on as soon as every block up to the final one has arrived; a set that receives no block for timeout, by message time or by the wall clock (Tick), is handed on with what it has and marked incomplete. Copies of a block, e.g. a retransmission or the same block heard by a second receiver, are dropped, also for a while after their set has been completed.",
+	"acars_parser/internal/decode.JAEROAssembler":                           "JAEROAssembler hands on JAERO blocks, tracking per-ICAO MIAM assembly state for multi-segment transfers. The MIAM protocol can split a large compressed payload across multiple consecutive ACARS frames. The first frame starts with a \"T<n>!\" marker (miamFirstSegRe); subsequent frames do not. JAERO/libacars outputs a decoded MIAM block only when it can fully reassemble the transfer. When it cannot (e.g. the file starts mid-transfer), those frames appear with no decoded block. Strategy: T<n>! frame + decoded MIAM block → single transfer, emit immediately. T<n>! frame + no decoded block → first segment of a multi-packet transfer; start a per-ICAO assembly. Non-T frame + no decoded block → continuation segment: append to the active assembly for this ICAO if within miamReassemblyWindow, otherwise treat as an orphan.",
+	"acars_parser/internal/decode.JAEROBlock":                               "JAEROBlock is one block of a JAERO log: a header line and the body lines up to the next header.",
+	"acars_parser/internal/decode.JAEROBlock.Line":                          "input line of the header, 0 when not known",
+	"acars_parser/internal/decode.ReassemblyInfo":                           "ReassemblyInfo is attached to a record whose text was joined from several ACARS blocks.",
+	"acars_parser/internal/decode.ReassemblyInfo.Missing":                   "sequence letters of known gaps",
+	"acars_parser/internal/parsers/abs.Parser":                              "Parser extracts route hints from ABS0 blocks in H1 messages.",
+	"acars_parser/internal/parsers/abs.Position":                            "Position represents one ABS0 position row.",
+	"acars_parser/internal/parsers/abs.Result":                              "Result represents a parsed ABS0 route hint.",
+	"acars_parser/internal/parsers/adsc.AirRef":                             "AirRef contains air-referenced velocity data (heading/mach).",
+	"acars_parser/internal/parsers/adsc.AirRef.Heading":                     "True heading in degrees.",
+	"acars_parser/internal/parsers/adsc.AirRef.HeadingInvalid":              "True if heading is invalid.",
+	"acars_parser/internal/parsers/adsc.AirRef.Mach":                        "Mach number.",
+	"acars_parser/internal/parsers/adsc.AirRef.VertSpeed":                   "Vertical speed in ft/min.",
+	"acars_parser/internal/parsers/adsc.ContractRequest":                    "ContractRequest contains uplink contract request data.",
+	"acars_parser/internal/parsers/adsc.ContractRequestGroup":               "ContractRequestGroup contains one decoded ADS-C uplink request tag.",
+	"acars_parser/internal/parsers/adsc.EarthRef":                           "EarthRef contains earth-referenced velocity data (ground track).",
+	"acars_parser/internal/parsers/adsc.EarthRef.GroundSpeed":               "Ground speed in knots.",
+	"acars_parser/internal/parsers/adsc.EarthRef.Track":                     "True track in degrees.",
+	"acars_parser/internal/parsers/adsc.EarthRef.TrackInvalid":              "True if track is invalid.",
+	"acars_parser/internal/parsers/adsc.EarthRef.VertSpeed":                 "Vertical speed in ft/min.",
+	"acars_parser/internal/parsers/adsc.MeteoData":                          "MeteoData contains meteorological information.",
+	"acars_parser/internal/parsers/adsc.MeteoData.Temperature":              "Temperature in Celsius (0.25 °C resolution).",
+	"acars_parser/internal/parsers/adsc.MeteoData.TemperatureInvalid":       "True if temperature data is not available.",
+	"acars_parser/internal/parsers/adsc.MeteoData.WindDirInvalid":           "True if wind direction is invalid.",
+	"acars_parser/internal/parsers/adsc.MeteoData.WindDirection":            "True wind direction in degrees.",
+	"acars_parser/internal/parsers/adsc.MeteoData.WindSpeed":                "Wind speed in knots.",
+	"acars_parser/internal/parsers/adsc.NackInfo":                           "NackInfo holds data from a negative acknowledgment (tag 0x04).",
+	"acars_parser/internal/parsers/adsc.NoncomplianceGroup":                 "NoncomplianceGroup describes a single non-compliant request group within a noncompliance notification.",
+	"acars_parser/internal/parsers/adsc.NoncomplianceNotification":          "NoncomplianceNotification holds data from a noncompliance notification (tag 0x05).",
+	"acars_parser/internal/parsers/adsc.Parser":                             "Parser parses ADS-C B6 messages.",
+	"acars_parser/internal/parsers/adsc.PredictedRoute":                     "PredictedRoute contains the predicted route waypoints.",
+	"acars_parser/internal/parsers/adsc.Result":                             "Result represents a decoded ADS-C message (Label B6 or A6).",
+	"acars_parser/internal/parsers/adsc.Result.ADSCFlightID":                "Flight ID from tag 12.",
+	"acars_parser/internal/parsers/adsc.Result.Accuracy":                    "Position accuracy (0-7).",
+	"acars_parser/internal/parsers/adsc.Result.AirRef":                      "Air reference data.",
+	"acars_parser/internal/parsers/adsc.Result.AirframeID":                  "ICAO hex address.",
+	"acars_parser/internal/parsers/adsc.Result.Direction":                   "\"uplink\" or \"downlink\"",
+	"acars_parser/internal/parsers/adsc.Result.EarthRef":                    "Earth reference data.",
+	"acars_parser/internal/parsers/adsc.Result.Meteo":                       "Meteorological data.",
+	"acars_parser/internal/parsers/adsc.Result.NAVRedundancy":               "NAV unit redundancy OK.",
+	"acars_parser/internal/parsers/adsc.Result.PayloadBytes":                "Length of decoded payload.",
+	"acars_parser/internal/parsers/adsc.Result.PredictedRoute":              "Predicted route.",
+	"acars_parser/internal/parsers/adsc.Result.ReportTime":                  "Enhanced fields from tag parsing.",
+	"acars_parser/internal/parsers/adsc.Result.TCASAvailable":               "TCAS available.",
+	"acars_parser/internal/parsers/adsc.Waypoint":                           "Waypoint contains predicted waypoint data.",
+	"acars_parser/internal/parsers/adsc.Waypoint.ETA":                       "ETA in seconds.",
+	"acars_parser/internal/parsers/agfsr.Parser":                            "Parser parses AGFSR flight status messages.",
+	"acars_parser/internal/parsers/agfsr.Result":                            "Result represents a parsed AGFSR flight status report.",
+	"acars_parser/internal/parsers/agfsr.Result.Destination":                "ICAO destination code (normalised from IATA)",
+	"acars_parser/internal/parsers/agfsr.Result.FuelRemain":                 "In hundreds of lbs or kg",
+	"acars_parser/internal/parsers/agfsr.Result.Origin":                     "ICAO origin code (normalised from IATA)",
+	"acars_parser/internal/parsers/agfsr.Result.Phase":                      "CRUISE, CLIMB, etc.",
+	"acars_parser/internal/parsers/agfsr.Result.Route":                      "ICAO pair like CYYZ-LIRF (dash-separated)",
+	"acars_parser/internal/parsers/agfsr.Result.Unknown1":                   "Field after time (110)",
+	"acars_parser/internal/parsers/agfsr.Result.Unknown2":                   "Unknown field",
+	"acars_parser/internal/parsers/atis.Parser":                             "Parser extracts ATIS information using token-based parsing.",
+	"acars_parser/internal/parsers/atis.Result":                             "Result represents parsed ATIS data.",
+	"acars_parser/internal/parsers/atis.Result.ATISTime":                    "Zulu time of ATIS.",
+	"acars_parser/internal/parsers/atis.Result.ATISType":                    "ARR, DEP, or empty for combined.",
+	"acars_parser/internal/parsers/atis.Result.Approaches":                  "ILS, RNAV, etc.",
+	"acars_parser/internal/parsers/atis.Result.RawText":                     "Full raw ATIS text.",
+	"acars_parser/internal/parsers/atncm.Parser":                            "Parser parses synthetic ATN CM logon messages.",
+	"acars_parser/internal/parsers/atncm.Result":                            "Result represents an ATN CM logon request with route fields.",
+	"acars_parser/internal/parsers/cpdlc.ATNDecoder":                        "ATNDecoder decodes ATN B1 CPDLC APDUs. It shares the FANS-1/A helpers for the encodings both message sets have in common.",
+	"acars_parser/internal/parsers/cpdlc.ATNPDU":                            "ATNPDU is a decoded ATN B1 CPDLC APDU.",
+	"acars_parser/internal/parsers/cpdlc.ATNPDU.AbortReason":                "Abort PDUs only.",
+	"acars_parser/internal/parsers/cpdlc.ATNPDU.Message":                    "Nil for aborts and for a start without a message.",
+	"acars_parser/internal/parsers/cpdlc.ATNPDU.Type":                       "One of the atnPDU* values.",
+	"acars_parser/internal/parsers/cpdlc.ATNParser":                         "ATNParser parses ATN B1 CPDLC APDUs that the extractor lifts out of VDL2 X.25/CLNP frames into synthetic \"ATNCPDLC\" messages, with the text \"ATN CPDLC UPLINK <hex>\" or \"ATN CPDLC DOWNLINK <hex>\".",
+	"acars_parser/internal/parsers/cpdlc.ATWAltitude":                       "ATWAltitude represents a single altitude constraint with a tolerance qualifier. It corresponds to the FANSATWAltitude ASN.1 type in the FANS-1/A specification.",
+	"acars_parser/internal/parsers/cpdlc.ATWAltitude.Tolerance":             "\"at\", \"atorabove\", or \"atorbelow\".",
+	"acars_parser/internal/parsers/cpdlc.Altitude":                          "Altitude represents an altitude value with its type.",
+	"acars_parser/internal/parsers/cpdlc.Altitude.Type":                     "\"flight_level\", \"feet\", \"meters\", etc.",
+	"acars_parser/internal/parsers/cpdlc.Altitude.Value":                    "The altitude value.",
+	"acars_parser/internal/parsers/cpdlc.BeaconCode":                        "BeaconCode represents a transponder code.",
+	"acars_parser/internal/parsers/cpdlc.BeaconCode.Code":                   "4-digit octal code.",
+	"acars_parser/internal/parsers/cpdlc.BitReader":                         "BitReader reads bits from a byte slice using UPER (Unaligned PER) rules.",
+	"acars_parser/internal/parsers/cpdlc.Decoder":                           "Decoder decodes FANS-1/A CPDLC messages.",
+	"acars_parser/internal/parsers/cpdlc.Degrees":                           "Degrees represents a heading or track value.",
+	"acars_parser/internal/parsers/cpdlc.Degrees.Magnetic":                  "True if magnetic, false if true.",
+	"acars_parser/internal/parsers/cpdlc.Degrees.Value":                     "Degrees 0-359.",
+	"acars_parser/internal/parsers/cpdlc.DistanceOffset":                    "DistanceOffset represents a lateral offset from route.",
+	"acars_parser/internal/parsers/cpdlc.DistanceOffset.Direction":          "\"left\" or \"right\".",
+	"acars_parser/internal/parsers/cpdlc.DistanceOffset.Distance":           "Distance value.",
+	"acars_parser/internal/parsers/cpdlc.DistanceOffset.Unit":               "\"nm\" or \"km\".",
+	"acars_parser/internal/parsers/cpdlc.ErrorInfo":                         "ErrorInfo represents CPDLC error information.",
+	"acars_parser/internal/parsers/cpdlc.FreeText":                          "FreeText represents free-form text.",
+	"acars_parser/internal/parsers/cpdlc.Frequency":                         "Frequency represents a radio frequency.",
+	"acars_parser/internal/parsers/cpdlc.Frequency.Type":                    "\"vhf\", \"uhf\", \"hf\", \"satcom\".",
+	"acars_parser/internal/parsers/cpdlc.Frequency.Value":                   "For VHF/UHF value is MHz; for HF value is kHz; for satcom channel it's an integer channel number.",
+	"acars_parser/internal/parsers/cpdlc.ICAOUnitName":                      "ICAOUnitName represents an ICAO unit name (facility designation + function).",
+	"acars_parser/internal/parsers/cpdlc.Message":                           "Message represents a decoded CPDLC message.",
+	"acars_parser/internal/parsers/cpdlc.MessageElement":                    "MessageElement represents a single message element (uplink or downlink).",
+	"acars_parser/internal/parsers/cpdlc.MessageElement.Data":               "Element-specific data.",
+	"acars_parser/internal/parsers/cpdlc.MessageElement.ID":                 "Element ID (uM0-uM182 for uplink, dM0-dM128 for downlink).",
+	"acars_parser/internal/parsers/cpdlc.MessageElement.Label":              "Human-readable message template.",
+	"acars_parser/internal/parsers/cpdlc.MessageElement.Text":               "Formatted message text.",
+	"acars_parser/internal/parsers/cpdlc.MessageHeader":                     "MessageHeader contains the CPDLC message header fields.",
+	"acars_parser/internal/parsers/cpdlc.MessageHeader.Date":                "ATN B1 only: message date (YYYY-MM-DD) and \"required\"/\"not_required\".",
+	"acars_parser/internal/parsers/cpdlc.MessageHeader.MsgID":               "Message identification number.",
+	"acars_parser/internal/parsers/cpdlc.MessageHeader.MsgRef":              "Reference number (optional).",
+	"acars_parser/internal/parsers/cpdlc.MessageHeader.Timestamp":           "Timestamp (optional).",
+	"acars_parser/internal/parsers/cpdlc.Parser":                            "Parser parses CPDLC messages (Labels AA, BA).",
+	"acars_parser/internal/parsers/cpdlc.PlaceBearingDistance":              "PlaceBearingDistance represents a position defined by fix, bearing, and distance.",
+	"acars_parser/internal/parsers/cpdlc.PlaceBearingDistance.Bearing":      "Degrees 1-360.",
+	"acars_parser/internal/parsers/cpdlc.PlaceBearingDistance.Distance":     "Distance value.",
+	"acars_parser/internal/parsers/cpdlc.PlaceBearingDistance.DistanceUnit": "\"nm\" or \"km\".",
+	"acars_parser/internal/parsers/cpdlc.PlaceBearingDistance.Magnetic":     "True if bearing is magnetic.",
+	"acars_parser/internal/parsers/cpdlc.Position":                          "Position represents a geographic position.",
+	"acars_parser/internal/parsers/cpdlc.Position.Bearing":                  "Bearing in degrees (for place_bearing_distance).",
+	"acars_parser/internal/parsers/cpdlc.Position.Distance":                 "Distance value (for place_bearing_distance).",
+	"acars_parser/internal/parsers/cpdlc.Position.DistanceUnit":             "\"nm\" or \"km\" (for place_bearing_distance).",
+	"acars_parser/internal/parsers/cpdlc.Position.Latitude":                 "Decimal degrees.",
+	"acars_parser/internal/parsers/cpdlc.Position.Longitude":                "Decimal degrees.",
+	"acars_parser/internal/parsers/cpdlc.Position.Name":                     "Fix/navaid name.",
+	"acars_parser/internal/parsers/cpdlc.Position.Type":                     "\"latlon\", \"fix\", \"navaid\", \"place_bearing_distance\", etc.",
+	"acars_parser/internal/parsers/cpdlc.PositionReport":                    "PositionReport represents a downlink POSITION REPORT (dM48). Field set matches what is commonly seen in FANS-1/A position reports.",
+	"acars_parser/internal/parsers/cpdlc.ProcedureName":                     "ProcedureName represents a procedure (SID/STAR/approach).",
+	"acars_parser/internal/parsers/cpdlc.ProcedureName.Name":                "Procedure name.",
+	"acars_parser/internal/parsers/cpdlc.ProcedureName.Transition":          "Optional transition.",
+	"acars_parser/internal/parsers/cpdlc.ProcedureName.Type":                "\"arrival\", \"approach\", \"departure\".",
+	"acars_parser/internal/parsers/cpdlc.Result":                            "Result represents a decoded CPDLC message for the ACARS parser framework.",
+	"acars_parser/internal/parsers/cpdlc.Result.Direction":                  "\"uplink\" or \"downlink\".",
+	"acars_parser/internal/parsers/cpdlc.Result.FormattedText":              "Human-readable message.",
+	"acars_parser/internal/parsers/cpdlc.Result.MessageType":                "\"cpdlc\", \"connect_request\", \"connect_confirm\", \"disconnect\", \"atn_cpdlc\".",
+	"acars_parser/internal/parsers/cpdlc.Result.Origin":                     "Origin and Destination are the ICAO departure/arrival airport codes extracted from the first RouteClearance element (dM40/dM41). They are exposed at the top level so that the viewer and state extractor can read them without having to navigate the elements array.",
+	"acars_parser/internal/parsers/cpdlc.Result.PDUType":                    "PDUType and AbortReason are set for ATN B1 messages only: the APDU (\"start\", \"send\", \"user_abort\", ...) and the reason of an abort.",
+	"acars_parser/internal/parsers/cpdlc.RouteClearance":                    "RouteClearance represents a route clearance with departure/arrival information.",
+	"acars_parser/internal/parsers/cpdlc.Runway":                            "Runway represents a runway designation.",
+	"acars_parser/internal/parsers/cpdlc.Runway.Configuration":              "\"left\", \"right\", \"center\", \"none\".",
+	"acars_parser/internal/parsers/cpdlc.Runway.Direction":                  "1-36.",
+	"acars_parser/internal/parsers/cpdlc.Speed":                             "Speed represents a speed value with its type.",
+	"acars_parser/internal/parsers/cpdlc.Speed.Type":                        "\"knots\", \"mach\", etc.",
+	"acars_parser/internal/parsers/cpdlc.Speed.Value":                       "The speed value (mach is scaled by 1000).",
+	"acars_parser/internal/parsers/cpdlc.Temperature":                       "Temperature represents an air temperature.",
+	"acars_parser/internal/parsers/cpdlc.Temperature.Type":                  "\"C\" or \"F\"",
+	"acars_parser/internal/parsers/cpdlc.Temperature.Value":                 "degrees",
+	"acars_parser/internal/parsers/cpdlc.Time":                              "Time represents a FANS timestamp (hours, minutes).",
+	"acars_parser/internal/parsers/cpdlc.VerticalChange":                    "VerticalChange represents a FANSVerticalChange: a direction (up/down) and an optional rate.",
+	"acars_parser/internal/parsers/cpdlc.VerticalChange.Direction":          "\"up\" or \"down\"",
+	"acars_parser/internal/parsers/cpdlc.VerticalRate":                      "VerticalRate represents a climb/descent rate.",
+	"acars_parser/internal/parsers/cpdlc.VerticalRate.Value":                "ft/min.",
+	"acars_parser/internal/parsers/cpdlc.WaypointSpeedAltitude":             "WaypointSpeedAltitude represents a waypoint with optional speed and altitude constraints. It corresponds to FANSWaypointSpeedAltitude in FANS-1/A.",
+	"acars_parser/internal/parsers/cpdlc.WindSpeed":                         "WindSpeed represents wind speed.",
+	"acars_parser/internal/parsers/cpdlc.WindSpeed.Type":                    "\"kts\" or \"kmh\"",
+	"acars_parser/internal/parsers/cpdlc.WindSpeed.Value":                   "speed",
+	"acars_parser/internal/parsers/cpdlc.Winds":                             "Winds represents wind direction and speed.",
+	"acars_parser/internal/parsers/cpdlc.Winds.Direction":                   "degrees",
+	"acars_parser/internal/parsers/dis.Parser":                              "Parser parses RA DIS OFP INFO messages.",
+	"acars_parser/internal/parsers/dis.Result":                              "Result represents a parsed DIS acknowledgement or OFP summary message.",
+	"acars_parser/internal/parsers/eb00.Parser":                             "Parser parses EB00 H1 messages.",
+	"acars_parser/internal/parsers/eb00.Result":                             "Result represents a parsed EB00 report.",
+	"acars_parser/internal/parsers/envelope.Parser":                         "Parser extracts tail numbers from envelope headers.",
+	"acars_parser/internal/parsers/envelope.Result":                         "Result represents extracted envelope data.",
+	"acars_parser/internal/parsers/envelope.Result.Altitude":                "Flight level from ADS-C.",
+	"acars_parser/internal/parsers/envelope.Result.Latitude":                "From ADS-C position reports.",
+	"acars_parser/internal/parsers/envelope.Result.Longitude":               "From ADS-C position reports.",
+	"acars_parser/internal/parsers/envelope.Result.MessageType":             "AT1, CR1, ADS",
+	"acars_parser/internal/parsers/eta.Parser":                              "Parser parses ETA/timing messages.",
+	"acars_parser/internal/parsers/eta.Result":                              "Result represents a parsed ETA message.",
+	"acars_parser/internal/parsers/eta.Result.MessageType":                  "ET, IR, B6, OS, C3",
+	"acars_parser/internal/parsers/eta.Result.Mode":                         "AUTO, etc.",
+	"acars_parser/internal/parsers/fst.Parser":                              "Parser parses FST flight status messages.",
+	"acars_parser/internal/parsers/fst.Result":                              "Result represents a parsed FST flight status report.",
+	"acars_parser/internal/parsers/fst.Result.GroundSpeed":                  "Legacy generic speed field kept for internal compatibility only.",
+	"acars_parser/internal/parsers/fst.Result.Track":                        "Actual ground track",
+	"acars_parser/internal/parsers/fst.Result.Unknown1":                     "Nepoznati parametar (možda TAS, IAS, Mach*100)",
+	"acars_parser/internal/parsers/fst.Result.WindSpeed":                    "Legacy generic speed field kept for internal compatibility only.",
+	"acars_parser/internal/parsers/gateassign.Parser":                       "Parser parses gate assignment messages.",
+	"acars_parser/internal/parsers/gateassign.Result":                       "Result represents parsed gate assignment data.",
+	"acars_parser/internal/parsers/gateassign.Result.BagBelt":               "Baggage belt.",
+	"acars_parser/internal/parsers/gateassign.Result.PPOS":                  "Parking position.",
+	"acars_parser/internal/parsers/grokfile.File":                           "File is the content of one format file.",
+	"acars_parser/internal/parsers/grokfile.FormatDef":                      "FormatDef is one grok format of a file.",
+	"acars_parser/internal/parsers/grokfile.FormatDef.Fields":               "Output field -> capture name.",
+	"acars_parser/internal/parsers/grokfile.Parser":                         "Parser runs the formats of one file.",
+	"acars_parser/internal/parsers/grokfile.Result":                         "Result is what a format file parser produces.",
+	"acars_parser/internal/parsers/h1.AltitudeWind":                         "AltitudeWind represents wind at a specific altitude.",
+	"acars_parser/internal/parsers/h1.FPNParser":                            "FPNParser parses H1 FPN flight plan messages.",
+	"acars_parser/internal/parsers/h1.FPNResult":                            "FPNResult represents a parsed H1 FPN flight plan message.",
+	"acars_parser/internal/parsers/h1.FPNTokens":                            "FPNTokens represents the tokenised sections of an FPN message.",
+	"acars_parser/internal/parsers/h1.FPNTokens.FlightNum":                  "FlightNum is extracted from /FNxxxx in the header.",
+	"acars_parser/internal/parsers/h1.FPNTokens.Header":                     "Header contains the portion before the first section marker. Example: \"FPN/SN2993/FNQFA401/RI\" from \"FPN/SN2993/FNQFA401/RI:DA:YSSY...\"",
+	"acars_parser/internal/parsers/h1.FPNTokens.Sections":                   "Sections maps section marker to its value. Example: {\"DA\": \"YSSY\", \"AA\": \"YMML\", \"F\": \"WOL..LEECE\"}",
+	"acars_parser/internal/parsers/h1.FPNTokens.SerialNum":                  "SerialNum is extracted from /SNxxxx in the header.",
+	"acars_parser/internal/parsers/h1.H1PosParser":                          "H1PosParser parses H1 POS position messages.",
+	"acars_parser/internal/parsers/h1.H1PosResult":                          "H1PosResult represents a parsed H1 position message.",
+	"acars_parser/internal/parsers/h1.PWIParser":                            "PWIParser parses H1 PWI predicted wind messages.",
+	"acars_parser/internal/parsers/h1.PWIResult":                            "PWIResult represents predicted wind information along a route.",
+	"acars_parser/internal/parsers/h1.RouteWaypoint":                        "RouteWaypoint represents a waypoint with its geographic coordinates.",
+	"acars_parser/internal/parsers/h1.RouteWindLayer":                       "RouteWindLayer represents wind data at waypoints for a flight level.",
+	"acars_parser/internal/parsers/h1.WaypointWind":                         "WaypointWind represents wind and temperature at a waypoint.",
+	"acars_parser/internal/parsers/h2wind.InitialWindLayer":                 "InitialWindLayer represents a short 02A/02D start-position layer block.",
+	"acars_parser/internal/parsers/h2wind.Parser":                           "Parser parses H2 wind/weather messages.",
+	"acars_parser/internal/parsers/h2wind.Result":                           "Result represents a parsed H2 wind/weather report.",
+	"acars_parser/internal/parsers/h2wind.Result.Day":                       "only for 02E (2-digit day)",
+	"acars_parser/internal/parsers/h2wind.Result.Phase":                     "02A climb/descend, 02E cruise",
+	"acars_parser/internal/parsers/h2wind.WindLayer":                        "WindLayer represents wind data at a specific flight level.",
+	"acars_parser/internal/parsers/h2wind.WindLayer.Temperature":            "Celsius (could be SAT or ISA deviation)",
+	"acars_parser/internal/parsers/h2wind.WindPoint":                        "WindPoint represents wind/weather at a specific (lat,lon) along a route. H2 examples: N42540E0185553400M485278094G QN44191E02136210483802M517273066G",
+	"acars_parser/internal/parsers/h2wind.WindPoint.ETA":                    "HH:MM",
+	"acars_parser/internal/parsers/h2wind.WindPoint.FlightLevel":            "e.g. 340.0",
+	"acars_parser/internal/parsers/h2wind.WindPoint.Marker":                 "\"N\" or \"Q\" (when present in raw)",
+	"acars_parser/internal/parsers/h2wind.WindPoint.Temperature":            "Celsius (can include tenths)",
+	"acars_parser/internal/parsers/hfdl.Parser":                             "Parser handles synthetic HFDL data messages.",
+	"acars_parser/internal/parsers/hfdl.Result":                             "Result represents synthetic HFDL data with a flight identifier and coordinates.",
+	"acars_parser/internal/parsers/ilnge7x.Parser":                          "Parser parses ILNGE7X messages regardless of the ACARS label.",
+	"acars_parser/internal/parsers/ilnge7x.Result":                          "Result represents the parsed ILNGE7X summary fields.",
+	"acars_parser/internal/parsers/ini.Parser":                              "Parser parses RA INI01 messages.",
+	"acars_parser/internal/parsers/ini.Result":                              "Result represents a parsed INI message.",
+	"acars_parser/internal/parsers/label10.Parser":                          "Parser parses Label 10 position/route messages.",
+	"acars_parser/internal/parsers/label10.Result":                          "Result represents a parsed Label 10 position/route message.",
+	"acars_parser/internal/parsers/label10.Result.Destination":              "ICAO code",
+	"acars_parser/internal/parsers/label10.Result.Distance":                 "Distance to destination",
+	"acars_parser/internal/parsers/label10.Result.Fuel":                     "Fuel remaining",
+	"acars_parser/internal/parsers/label10.WaypointETA":                     "WaypointETA represents a waypoint with its estimated time of arrival.",
+	"acars_parser/internal/parsers/label16.Parser":                          "Parser parses Label 16 waypoint position messages.",
+	"acars_parser/internal/parsers/label16.Result":                          "Result represents a waypoint position from label 16 messages.",
+	"acars_parser/internal/parsers/label16.WaypointETA":                     "WaypointETA represents a waypoint reference paired with its ETA.",
+	"acars_parser/internal/parsers/label17.Parser":                          "Parser parses Label 17 messages.",
+	"acars_parser/internal/parsers/label17.Result":                          "Result represents a decoded Label 17 report. Example input: 031324,37995,0413, 7360,N 46.943,E 18.634,06OCT25,25680, 19,- 47 Notes: - track_code and wind_dir_code are encoded with 2 decimals (value/100) - ground_speed and wind_speed are in knots; *_kmh fields provide km/h conversion - timestamp is the ACARS envelope timestamp; report_timestamp is derived from date+time in the payload (UTC)",
+	"acars_parser/internal/parsers/label21.Parser":                          "Parser parses Label 21 position report messages.",
+	"acars_parser/internal/parsers/label21.Result":                          "Result represents a position report from label 21 messages.",
+	"acars_parser/internal/parsers/label22.Parser":                          "Parser parses Label 22 detailed position messages.",
+	"acars_parser/internal/parsers/label22.Result":                          "Result represents a parsed Label 22 position message.",
+	"acars_parser/internal/parsers/label22.Result.Altitude":                 "Feet",
+	"acars_parser/internal/parsers/label22.Result.Mach":                     "Mach number",
+	"acars_parser/internal/parsers/label26.Parser":                          "Parser parses Label 26 ETA messages.",
+	"acars_parser/internal/parsers/label26.Result":                          "Result represents an ETA report from label 26 messages.",
+	"acars_parser/internal/parsers/label26.Result.AltitudeM":                "ALT value in meters",
+	"acars_parser/internal/parsers/label26.Result.DestICAO":                 "Last 4 chars of UUEEUDYZ",
+	"acars_parser/internal/parsers/label26.Result.DestName":                 "Airport name from ICAO",
+	"acars_parser/internal/parsers/label26.Result.ETA":                      "ETA time",
+	"acars_parser/internal/parsers/label26.Result.FlightLevel":              "Flight level in feet (AFL1866 = 18660 ft)",
+	"acars_parser/internal/parsers/label26.Result.FlightNum":                "Flight number (e.g., SU0245)",
+	"acars_parser/internal/parsers/label26.Result.Format":                   "ETA01, ETA02, etc.",
+	"acars_parser/internal/parsers/label26.Result.FuelOnBoard":              "FUEL value",
+	"acars_parser/internal/parsers/label26.Result.Latitude":                 "LATN/LATS",
+	"acars_parser/internal/parsers/label26.Result.Longitude":                "LONE/LONW",
+	"acars_parser/internal/parsers/label26.Result.OriginICAO":               "First 4 chars of UUEEUDYZ",
+	"acars_parser/internal/parsers/label26.Result.OriginName":               "Airport name from ICAO",
+	"acars_parser/internal/parsers/label26.Result.ReportTime":               "Time from /16180720 format",
+	"acars_parser/internal/parsers/label26.Result.Temperature":              "TEMP value in Celsius",
+	"acars_parser/internal/parsers/label26.Result.Waypoint":                 "Waypoint identifier",
+	"acars_parser/internal/parsers/label26.Result.WindDir":                  "Wind direction in degrees",
+	"acars_parser/internal/parsers/label26.Result.WindSpeed":                "Wind speed in knots",
+	"acars_parser/internal/parsers/label27.Parser":                          "Parser parses Label 27 position messages.",
+	"acars_parser/internal/parsers/label27.Result":                          "Result represents a position report from label 27 messages.",
+	"acars_parser/internal/parsers/label27.Result.AltitudeFt":               "ALT value in feet, as reported",
+	"acars_parser/internal/parsers/label27.Result.AltitudeM":                "ALT value in meters",
+	"acars_parser/internal/parsers/label27.Result.DestICAO":                 "Last 4 chars of UUEEUDYZ",
+	"acars_parser/internal/parsers/label27.Result.DestName":                 "Airport name from ICAO",
+	"acars_parser/internal/parsers/label27.Result.ETA":                      "ETA time",
+	"acars_parser/internal/parsers/label27.Result.FlightLevel":              "Flight level in feet (AFL1866 = 18660 ft)",
+	"acars_parser/internal/parsers/label27.Result.FlightNum":                "Flight number (e.g., SU0245)",
+	"acars_parser/internal/parsers/label27.Result.Format":                   "POS01, POS02, etc.",
+	"acars_parser/internal/parsers/label27.Result.FuelOnBoard":              "FUEL value",
+	"acars_parser/internal/parsers/label27.Result.Latitude":                 "LATN/LATS",
+	"acars_parser/internal/parsers/label27.Result.Longitude":                "LONE/LONW",
+	"acars_parser/internal/parsers/label27.Result.OriginICAO":               "First 4 chars of UUEEUDYZ",
+	"acars_parser/internal/parsers/label27.Result.OriginName":               "Airport name from ICAO",
+	"acars_parser/internal/parsers/label27.Result.ReportTime":               "Time from /16180720 format",
+	"acars_parser/internal/parsers/label27.Result.Temperature":              "TEMP value in Celsius",
+	"acars_parser/internal/parsers/label27.Result.Waypoint":                 "Waypoint identifier",
+	"acars_parser/internal/parsers/label27.Result.WindDir":                  "Wind direction in degrees",
+	"acars_parser/internal/parsers/label27.Result.WindSpeed":                "Wind speed in knots",
+	"acars_parser/internal/parsers/label33.Parser":                          "Parser parses Label 33 position messages.",
+	"acars_parser/internal/parsers/label33.Result":                          "Result represents a position report from label 33 messages.",
+	"acars_parser/internal/parsers/label33.Result.Date":                     "Report date (YYYY-MM-DD)",
+	"acars_parser/internal/parsers/label33.Result.DestICAO":                 "Destination airport",
+	"acars_parser/internal/parsers/label33.Result.DestName":                 "Airport name from ICAO",
+	"acars_parser/internal/parsers/label33.Result.FlightLevel":              "Flight level (e.g., 360 for FL360)",
+	"acars_parser/internal/parsers/label33.Result.FollowWaypoint":           "Following waypoint",
+	"acars_parser/internal/parsers/label33.Result.FuelOnBoard":              "Fuel on board",
+	"acars_parser/internal/parsers/label33.Result.GroundSpeed":              "Ground speed in knots",
+	"acars_parser/internal/parsers/label33.Result.Latitude":                 "Latitude from coordinates",
+	"acars_parser/internal/parsers/label33.Result.Longitude":                "Longitude from coordinates",
+	"acars_parser/internal/parsers/label33.Result.NextWaypoint":             "Next waypoint identifier",
+	"acars_parser/internal/parsers/label33.Result.NextWptETA":               "ETA to next waypoint (HH:MM)",
+	"acars_parser/internal/parsers/label33.Result.OriginICAO":               "Origin airport",
+	"acars_parser/internal/parsers/label33.Result.OriginName":               "Airport name from ICAO",
+	"acars_parser/internal/parsers/label33.Result.RawCoordinates":           "Raw coordinate string",
+	"acars_parser/internal/parsers/label33.Result.Temperature":              "Temperature in Celsius",
+	"acars_parser/internal/parsers/label33.Result.Time":                     "Report time (HH:MM:SS)",
+	"acars_parser/internal/parsers/label33.Result.WindDir":                  "Wind direction in degrees",
+	"acars_parser/internal/parsers/label33.Result.WindSpeedKmh":             "Wind speed in km/h",
+	"acars_parser/internal/parsers/label33.Result.WindSpeedKts":             "Wind speed in knots",
+	"acars_parser/internal/parsers/label39.Parser":                          "Parser parses Label 39 messages.",
+	"acars_parser/internal/parsers/label39.Result":                          "Result represents a position/status report from label 39 messages.",
+	"acars_parser/internal/parsers/label39.Result.DestICAO":                 "Last 4 chars of route",
+	"acars_parser/internal/parsers/label39.Result.DestName":                 "Airport name from ICAO",
+	"acars_parser/internal/parsers/label39.Result.ETA":                      "HH:MM:SS format",
+	"acars_parser/internal/parsers/label39.Result.FuelOnBoard":              "FOB value",
+	"acars_parser/internal/parsers/label39.Result.Header":                   "CDC01, ADC01, ODO01, PBS01, LDC01",
+	"acars_parser/internal/parsers/label39.Result.OriginICAO":               "First 4 chars of route",
+	"acars_parser/internal/parsers/label39.Result.OriginName":               "Airport name from ICAO",
+	"acars_parser/internal/parsers/label44.Parser":                          "Parser parses Label 44 messages.",
+	"acars_parser/internal/parsers/label44.Result":                          "Result represents a parsed Label 44 message.",
+	"acars_parser/internal/parsers/label44.Result.MessageType":              "\"runway\", \"fb\", \"pos\"",
+	"acars_parser/internal/parsers/label44.RunwayInfo":                      "RunwayInfo represents a runway entry from takeoff info.",
+	"acars_parser/internal/parsers/label44.RunwayInfo.Distance":             "Runway length in feet",
+	"acars_parser/internal/parsers/label44.RunwayInfo.Suffix":               "Y, AA, R, BHB, etc.",
+	"acars_parser/internal/parsers/label4j.Parser":                          "Parser parses Label 4J position + weather messages.",
+	"acars_parser/internal/parsers/label4j.Result":                          "Result represents position + weather data from label 4J messages.",
+	"acars_parser/internal/parsers/label5l.Parser":                          "Parser parses Label 5L route messages.",
+	"acars_parser/internal/parsers/label5l.Result":                          "Result represents a parsed route from label 5L messages.",
+	"acars_parser/internal/parsers/label80.Parser":                          "Parser parses Label 80 and Label 23 position/status messages.",
+	"acars_parser/internal/parsers/label80.Result":                          "Result represents position data from label 80 messages.",
+	"acars_parser/internal/parsers/label80.Result.OAT":                      "outside air temperature in °C",
+	"acars_parser/internal/parsers/label80.Result.ReportTime":               "UTC time of the position report (from TME field)",
+	"acars_parser/internal/parsers/label80.Result.TASKmh":                   "TAS converted from knots to km/h",
+	"acars_parser/internal/parsers/label80.Result.WindDir":                  "WindDir is the actual wind direction in degrees (0–359). Note: a value of 0° (exactly from North) is indistinguishable from \"not set\" in JSON output due to the omitempty tag, which is an accepted limitation.",
+	"acars_parser/internal/parsers/label80.Result.WindSpeed":                "wind speed in knots",
+	"acars_parser/internal/parsers/label80.Result.WindSpeedKmh":             "wind speed converted to km/h",
+	"acars_parser/internal/parsers/label83.Parser":                          "Parser parses Label 83 position report messages.",
+	"acars_parser/internal/parsers/label83.Result":                          "Result represents a parsed Label 83 position report.",
+	"acars_parser/internal/parsers/label83.Result.MessageType":              "PR, ZSPD, or POSRPT",
+	"acars_parser/internal/parsers/label83.Result.TemperatureC":             "POSRPT-only meteorological fields (SAT, SWND, DWND keys). Note: omitempty means a true 0 °C temperature will be absent from the JSON.",
+	"acars_parser/internal/parsers/label83.Result.WindDirection":            "DWND (wind direction) in degrees",
+	"acars_parser/internal/parsers/label83.Result.WindSpeedKmh":             "Computed from SWND",
+	"acars_parser/internal/parsers/label83.Result.WindSpeedKts":             "SWND (wind speed) in knots",
+	"acars_parser/internal/parsers/labelb2.Parser":                          "Parser parses Label B2 oceanic clearance messages.",
+	"acars_parser/internal/parsers/labelb2.Result":                          "Result represents an oceanic clearance from label B2 messages.",
+	"acars_parser/internal/parsers/labelb3.Parser":                          "Parser parses Label B3 gate info messages.",
+	"acars_parser/internal/parsers/labelb3.Result":                          "Result represents gate info from label B3 messages.",
+	"acars_parser/internal/parsers/landingdata.Parser":                      "Parser parses landing data messages.",
+	"acars_parser/internal/parsers/landingdata.Result":                      "Result represents parsed landing data.",
+	"acars_parser/internal/parsers/loadsheet.Parser":                        "Parser parses loadsheet messages.",
+	"acars_parser/internal/parsers/loadsheet.Result":                        "Result represents parsed loadsheet data.",
+	"acars_parser/internal/parsers/loadsheet.Result.Cargo":                  "Cargo weight",
+	"acars_parser/internal/parsers/loadsheet.Result.Crew":                   "Crew configuration",
+	"acars_parser/internal/parsers/loadsheet.Result.Edition":                "Loadsheet edition",
+	"acars_parser/internal/parsers/loadsheet.Result.LAW":                    "Landing Weight",
+	"acars_parser/internal/parsers/loadsheet.Result.MACTOW":                 "MAC at TOW",
+	"acars_parser/internal/parsers/loadsheet.Result.MACZFW":                 "MAC at ZFW",
+	"acars_parser/internal/parsers/loadsheet.Result.PAX":                    "Passenger count",
+	"acars_parser/internal/parsers/loadsheet.Result.TIF":                    "Trip Fuel (fuel burned during the trip)",
+	"acars_parser/internal/parsers/loadsheet.Result.TOF":                    "Take Off Fuel",
+	"acars_parser/internal/parsers/loadsheet.Result.TOW":                    "Take Off Weight",
+	"acars_parser/internal/parsers/loadsheet.Result.Trim":                   "Stabiliser trim",
+	"acars_parser/internal/parsers/loadsheet.Result.ZFW":                    "Zero Fuel Weight",
+	"acars_parser/internal/parsers/mediaadv.LinkType":                       "LinkType represents a data link type.",
+	"acars_parser/internal/parsers/mediaadv.Parser":                         "Parser parses Media Advisory messages.",
+	"acars_parser/internal/parsers/mediaadv.Result":                         "Result represents a Media Advisory message.",
+	"acars_parser/internal/parsers/mediaadv.Result.Established":             "true = link established, false = link lost",
+	"acars_parser/internal/parsers/mediaadv.Result.LinkTime":                "HH:MM:SS",
+	"acars_parser/internal/parsers/miam.Parser":                             "Parser handles MIAM messages (label \"MA\").",
+	"acars_parser/internal/parsers/miam.Result":                             "Result holds the parsed fields from a MIAM message block.",
+	"acars_parser/internal/parsers/miam.Result.ACKRequired":                 "Data only",
+	"acars_parser/internal/parsers/miam.Result.AssembledPayload":            "AssembledPayload and SegmentCount are populated for message_type \"miam_assembled\": transfers whose compressed segments were concatenated by the reassembly logic but whose MIAM block was not decoded by JAERO. AssembledPayload is the raw MIAM 6-bit ACARS encoding of all segments concatenated in chronological order.",
+	"acars_parser/internal/parsers/miam.Result.CRCOK":                       "CRCOK and DecodeError are only set when the PDU was decoded from the raw frame (DecodeFrame) rather than read from a printed block. CRCOK is nil when the inner message could not be recovered.",
+	"acars_parser/internal/parsers/miam.Result.Compression":                 "Data only",
+	"acars_parser/internal/parsers/miam.Result.Encoding":                    "Data only",
+	"acars_parser/internal/parsers/miam.Result.FlightLevel":                 "e.g. 400 for FL400",
+	"acars_parser/internal/parsers/miam.Result.FlightNum":                   "FlightNum holds the flight identifier from the /H02 segment of a REP inner message. It is only populated when the outer MIAM message does not already carry a flight number.",
+	"acars_parser/internal/parsers/miam.Result.FormattedText":               "FormattedText holds the full decoded MIAM block as printed by JAERO/libacars. The viewer uses this to expand the raw text display, keeping the original compressed payload in message.text for the default table view.",
+	"acars_parser/internal/parsers/miam.Result.InnerLabel":                  "Data only",
+	"acars_parser/internal/parsers/miam.Result.InnerMessage":                "Data only (may be garbled)",
+	"acars_parser/internal/parsers/miam.Result.InnerSublabel":               "Data only",
+	"acars_parser/internal/parsers/miam.Result.Latitude":                    "Latitude, Longitude, FlightLevel, Temperature, WindDir and WindSpeed are extracted from the /NX data segment of a REP inner message. The format is: /NX,<ORIG DEST>/<f0>,<f1>,<lat×10⁴>,<lon×10⁴>,<f4>,<FL×10>,<temp×10>,<winddir>,<windspeed>,... Latitude and Longitude use the same JSON keys as other position parsers so the state extractor picks them up automatically. FlightLevel uses the \"flight_level\" key, which the extractor converts to feet (× 100).",
+	"acars_parser/internal/parsers/miam.Result.MessageType":                 "\"miam_ack\", \"miam_data\" or \"miam_aloha\"",
+	"acars_parser/internal/parsers/miam.Result.MsgACKNum":                   "Ack only",
+	"acars_parser/internal/parsers/miam.Result.MsgNum":                      "Data only",
+	"acars_parser/internal/parsers/miam.Result.OriginICAO":                  "OriginICAO and DestICAO are extracted from the /H02 segment of REP inner messages (e.g. \"/H02,ZGSZ FAOR,CCA867 ,...\"). These use the same JSON keys as other route-bearing parsers so the state extractor picks them up automatically.",
+	"acars_parser/internal/parsers/miam.Result.Temperature":                 "°C (e.g. -54.4)",
+	"acars_parser/internal/parsers/miam.Result.TransferResult":              "Ack only",
+	"acars_parser/internal/parsers/miam.Result.TransferType":                "e.g. \"Single Transfer\"",
+	"acars_parser/internal/parsers/miam.Result.WindDir":                     "degrees true",
+	"acars_parser/internal/parsers/miam.Result.WindSpeed":                   "knots",
+	"acars_parser/internal/parsers/pdc.Compiler":                            "Compiler manages pattern compilation and caching.",
+	"acars_parser/internal/parsers/pdc.PDCExtractorTrace":                   "PDCExtractorTrace contains debug info about a field extractor.",
+	"acars_parser/internal/parsers/pdc.PDCExtractorTrace.Matched":           "Whether it matched",
+	"acars_parser/internal/parsers/pdc.PDCExtractorTrace.Name":              "Extractor name (e.g., \"ExtractSquawk\")",
+	"acars_parser/internal/parsers/pdc.PDCExtractorTrace.Pattern":           "The regex pattern used",
+	"acars_parser/internal/parsers/pdc.PDCExtractorTrace.Value":             "Extracted value (if matched)",
+	"acars_parser/internal/parsers/pdc.PDCFormat":                           "PDCFormat represents a specific PDC message format with named capture groups.",
+	"acars_parser/internal/parsers/pdc.PDCFormat.Compiled":                  "Compiled regex (populated by Compile)",
+	"acars_parser/internal/parsers/pdc.PDCFormat.Fields":                    "Field names in capture order",
+	"acars_parser/internal/parsers/pdc.PDCFormat.Pattern":                   "Pattern with {PLACEHOLDER} syntax",
+	"acars_parser/internal/parsers/pdc.PDCParseTrace":                       "PDCParseTrace contains complete trace information for a PDC parse attempt.",
+	"acars_parser/internal/parsers/pdc.PDCParseTrace.Extractors":            "Post-processing extractor results",
+	"acars_parser/internal/parsers/pdc.PDCParseTrace.Formats":               "All format match attempts",
+	"acars_parser/internal/parsers/pdc.PDCParseTrace.Result":                "The parse result (if any match succeeded)",
+	"acars_parser/internal/parsers/pdc.PDCResult":                           "PDCResult contains the extracted fields from a PDC message.",
+	"acars_parser/internal/parsers/pdc.Parser":                              "Parser parses Pre-Departure Clearance messages.",
+	"acars_parser/internal/parsers/pdc.Result":                              "Result represents a parsed Pre-Departure Clearance.",
+	"acars_parser/internal/parsers/sb01.Parser":                             "Parser parses SB01 H1 messages.",
+	"acars_parser/internal/parsers/sb01.Result":                             "Result represents a parsed SB01 report.",
+	"acars_parser/internal/parsers/sq.Parser":                               "Parser parses SQ (Squitter) ARINC position messages. Format: 02XA/02XS + IATA(3) + ICAO(4) + lat(5) + N/S + lon(5) + E/W + band+freq + suffix",
+	"acars_parser/internal/parsers/sq.Result":                               "Result represents a parsed SQ ARINC position message. These messages contain airport IATA/ICAO mapping and position data.",
+	"acars_parser/internal/parsers/sq.Result.FreqBand":                      "V=VHF, B=?",
+	"acars_parser/internal/parsers/sq.Result.FreqMHz":                       "Frequency in MHz (e.g., 136.975)",
+	"acars_parser/internal/parsers/sq.Result.IATACode":                      "3-char IATA airport code",
+	"acars_parser/internal/parsers/sq.Result.ICAOCode":                      "4-char ICAO airport code",
+	"acars_parser/internal/parsers/sq.Result.Latitude":                      "Decimal degrees, negative for south",
+	"acars_parser/internal/parsers/sq.Result.Longitude":                     "Decimal degrees, negative for west",
+	"acars_parser/internal/parsers/sq.Result.MessageType":                   "A or S from prefix",
+	"acars_parser/internal/parsers/turbulence.Parser":                       "Parser parses turbulence messages.",
+	"acars_parser/internal/parsers/turbulence.Result":                       "Result represents parsed turbulence data.",
+	"acars_parser/internal/parsers/turbulence.Result.AltitudeHi":            "FL380",
+	"acars_parser/internal/parsers/turbulence.Result.AltitudeLow":           "FL300",
+	"acars_parser/internal/parsers/turbulence.Result.ID":                    "SIGMET/advisory ID",
+	"acars_parser/internal/parsers/turbulence.Result.Severity":              "LGT, MOD, SEV, MDT",
+	"acars_parser/internal/parsers/turbulence.Result.TurbType":              "TURB CAT, TURB NORMAL, etc.",
+	"acars_parser/internal/parsers/weather.MetarReport":                     "MetarReport represents a single parsed METAR.",
+	"acars_parser/internal/parsers/weather.Parser":                          "Parser parses weather messages.",
+	"acars_parser/internal/parsers/weather.Result":                          "Result represents parsed weather data.",
+	"acars_parser/internal/parsers/weather.SigmetReport":                    "SigmetReport represents a parsed SIGMET.",
+	"acars_parser/internal/parsers/weather.TafReport":                       "TafReport represents a single parsed TAF.",
+	"acars_parser/internal/registry.ErrorResult":                            "ErrorResult records a parser that panicked on a message. Dispatch returns it in place of that parser's result and carries on with the others.",
+	"acars_parser/internal/registry.ErrorResult.Stack":                      "The innermost frames of the panic.",
+	"acars_parser/internal/registry.Field":                                  "Field is one expected field of a result, for Assess.",
+	"acars_parser/internal/registry.Field.Name":                             "JSON name of the field",
+	"acars_parser/internal/registry.Field.Present":                          "Whether the parse filled it",
+	"acars_parser/internal/registry.Field.Weight":                           "Share of the confidence; 0 counts as 1",
+	"acars_parser/internal/registry.ParserMetrics":                          "ParserMetrics reports what one parser did in Dispatch and DispatchFirst since EnableMetrics was called.",
+	"acars_parser/internal/registry.ParserMetrics.Calls":                    "Messages offered to the parser.",
+	"acars_parser/internal/registry.ParserMetrics.Duration":                 "Time spent in QuickCheck and Parse.",
+	"acars_parser/internal/registry.ParserMetrics.Hits":                     "QuickCheck passed (every call for catch-alls).",
+	"acars_parser/internal/registry.ParserMetrics.Misses":                   "QuickCheck passed but Parse returned nil.",
+	"acars_parser/internal/registry.ParserTrace":                            "ParserTrace records how one parser handled a message in DispatchWithTrace.",
+	"acars_parser/internal/registry.ParserTrace.Calls":                      "Calls lists the grok formats the parser tried, one entry per compiler call. Parsers without grok formats leave it empty.",
+	"acars_parser/internal/registry.ParserTrace.QuickCheck":                 "QuickCheck passed; catch-all parsers have none.",
+	"acars_parser/internal/registry.ParserTrace.Ran":                        "False for catch-all parsers skipped because another parser matched.",
+	"acars_parser/internal/registry.ParserTrace.Result":                     "Nil when Parse found nothing.",
+	"acars_parser/internal/registry.ParserTrace.Stage":                      "\"label\", \"global\" or \"catch_all\"",
+	"acars_parser/internal/registry.Position":                               "Position is a position report in canonical units, whatever the field names and units of the result that carried it. Zero values mean the report did not give the value, as in the results themselves.",
+	"acars_parser/internal/registry.Position.Altitude":                      "Feet; flight levels are multiplied out.",
+	"acars_parser/internal/registry.Position.ReportTime":                    "UTC time of day of the report, HH:MM:SS; see At.",
+	"acars_parser/internal/registry.Position.Temperature":                   "Static air temperature; results that keep it as a plain number cannot report 0 °C.",
+	"acars_parser/internal/registry.Position.Track":                         "Degrees; the heading when the report gives no track.",
+	"acars_parser/internal/registry.Quality":                                "Quality says how complete a parse is.",
+	"acars_parser/internal/registry.Quality.Confidence":                     "Weighted share of expected fields found, 0-1.",
+	"acars_parser/internal/registry.Quality.Expected":                       "Fields a full parse of this kind fills.",
+	"acars_parser/internal/registry.Quality.Missing":                        "Expected fields left empty.",
+	"acars_parser/internal/registry.Registry":                               "Registry holds all registered parsers organised for efficient dispatch.",
+	"acars_parser/internal/registry.Selection":                              "Selection picks and reorders the parsers of a cloned registry. Entries in Enable and Disable match a parser's Name() or any of its result types.",
+	"acars_parser/internal/registry.Selection.Disable":                      "Drop these, even when enabled.",
+	"acars_parser/internal/registry.Selection.Enable":                       "Keep only these parsers; empty keeps all.",
+	"acars_parser/internal/registry.Selection.Priority":                     "Priority overrides by parser name.",
+	"acars_parser/internal/registry.Wind":                                   "Wind is the wind at a reported position.",
+	"acars_parser/internal/registry.Wind.Direction":                         "Degrees true, where the wind blows from.",
+	"main.DedupInfo":                "DedupInfo is attached to a record when duplicate suppression is enabled. It lists every copy of the message that was heard, the first one included.",
+	"main.ExtractOut":               "ExtractOut is one output record: a message and the results the parsers produced for it.",
+	"main.OutputMessage":            "OutputMessage is the message of an ExtractOut record.",
+	"main.OutputMessage.EnrichedBy": "EnrichedBy names, for each field filled in from the text or the results rather than the feed, the enricher that found it.",
+	"main.Receiver":                 "Receiver describes one copy of a message: who heard it, where and when.",
+	"main.RecordOrigin":             "RecordOrigin says where an ExtractOut record came from: the input file and line for extract, the socket for listen. It is omitted for stdin and NATS.",
+	"main.RecordOrigin.File":        "input file as given or found",
+	"main.RecordOrigin.Line":        "1-based line the record starts on",
+	"main.RecordOrigin.Listener":    "configured socket name",
+	"main.RecordOrigin.Proto":       "\"udp\" or \"tcp\"",
+	"main.RecordOrigin.Remote":      "sender address",
+	"main.ResultQuality":            "ResultQuality is the registry.Quality of one result of a record, for ranking weak parses. Result is the result's index in Results.",
+	"main.Stats.ParserErrors":       "parser calls that panicked, reported as parser_error results",
+	"main.Stats.Partial":            "multi-block messages emitted with blocks missing",
+	"main.Stats.Reassembled":        "multi-block messages joined from all their blocks",
+}
@@ -0,0 +1,23 @@
+// Command gen writes internal/schema/docs_gen.go, the struct and field
+// comments the schema package uses as descriptions.  It is run by go
+// generate from internal/schema.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"acars_parser/internal/schema"
+)
+
+func main() {
+	src, err := schema.GenerateDocs("../..")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile("docs_gen.go", src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+}
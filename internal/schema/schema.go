@@ -0,0 +1,306 @@
+// Package schema builds JSON Schema documents for parse results by
+// reflecting over their Go types.  Reflection cannot see comments, so the
+// type and field descriptions are collected from the source into
+// docs_gen.go by go generate.
+package schema
+
+//go:generate go run ./gen
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"acars_parser/internal/registry"
+)
+
+// Draft is the JSON Schema dialect of the documents.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema node, limited to the keywords this package uses.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Version              int                `json:"x-schema-version,omitempty"` // Set on documents only.
+	ResultTypes          []string           `json:"x-result-types,omitempty"`   // The Type() values of a result struct.
+	Type                 any                `json:"type,omitempty"`             // A type name, or a list of them.
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Build returns a document describing envelope, the record struct results
+// are written in, with every struct it and the results of reg's parsers use
+// in $defs.  The items of envelope's resultsField property, a JSON array,
+// may be any result.  Each result struct lists the Type() values it is
+// produced under in x-result-types.  Every parser must implement
+// registry.ResultPrototyper.
+func Build(reg *registry.Registry, envelope any, resultsField string) (*Schema, error) {
+	r := newReflector(docs)
+	types := make(map[string][]string)
+	add := func(res registry.Result, ts []string) {
+		name := r.define(indirect(reflect.TypeOf(res)))
+		types[name] = append(types[name], ts...)
+	}
+	add(&registry.ErrorResult{}, []string{"parser_error"})
+	for _, p := range reg.Parsers() {
+		res := registry.NewResult(p)
+		if res == nil {
+			return nil, fmt.Errorf("parser %s does not implement registry.ResultPrototyper", p.Name())
+		}
+		add(res, registry.ResultTypes(p))
+	}
+
+	results := make([]*Schema, 0, len(types))
+	for _, name := range sortedKeys(types) {
+		r.defs[name].ResultTypes = unique(types[name])
+		results = append(results, &Schema{Ref: ref(name)})
+	}
+
+	t := indirect(reflect.TypeOf(envelope))
+	doc := r.object(t)
+	doc.Schema = Draft
+	doc.Description = r.docs[docKey(t)]
+	prop := doc.Properties[resultsField]
+	if prop == nil || prop.Type != "array" {
+		return nil, fmt.Errorf("%s has no array property %q", t, resultsField)
+	}
+	prop.Items = &Schema{AnyOf: results}
+	doc.Defs = r.defs
+	return doc, nil
+}
+
+// ForType returns a standalone document for the results of type typ in doc,
+// a document from Build, with only the $defs they need.
+func ForType(doc *Schema, typ string) (*Schema, error) {
+	var refs []*Schema
+	for _, name := range sortedKeys(doc.Defs) {
+		for _, t := range doc.Defs[name].ResultTypes {
+			if t == typ {
+				refs = append(refs, &Schema{Ref: ref(name)})
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("unknown result type %q", typ)
+	}
+
+	out := &Schema{Schema: Draft, Title: typ, Version: doc.Version, Defs: make(map[string]*Schema)}
+	if len(refs) == 1 {
+		out.Ref = refs[0].Ref
+	} else {
+		out.AnyOf = refs
+	}
+	var walk func(s *Schema)
+	walk = func(s *Schema) {
+		if name := strings.TrimPrefix(s.Ref, "#/$defs/"); s.Ref != "" && out.Defs[name] == nil {
+			out.Defs[name] = doc.Defs[name]
+			walk(doc.Defs[name])
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			walk(s.Properties[name])
+		}
+		for _, c := range append([]*Schema{s.Items, s.AdditionalProperties}, s.AnyOf...) {
+			if c != nil {
+				walk(c)
+			}
+		}
+	}
+	walk(out)
+	return out, nil
+}
+
+// ResultTypes returns the result types described in doc, sorted.
+func ResultTypes(doc *Schema) []string {
+	var types []string
+	for _, def := range doc.Defs {
+		types = append(types, def.ResultTypes...)
+	}
+	return unique(types)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// reflector turns Go types into schemas, collecting named structs in defs.
+type reflector struct {
+	docs  map[string]string       // Descriptions by docKey.
+	defs  map[string]*Schema      // Named structs by definition name.
+	names map[reflect.Type]string // Definition names of the structs in defs.
+}
+
+func newReflector(docs map[string]string) *reflector {
+	return &reflector{docs: docs, defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// schemaFor returns the schema of a value of type t, a $ref for named
+// structs.
+func (r *reflector) schemaFor(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return r.schemaFor(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		return &Schema{Ref: ref(r.define(t))}
+	}
+	return &Schema{} // Interfaces hold any value.
+}
+
+// define adds the named struct t to defs and returns its definition name.
+func (r *reflector) define(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	if t.PkgPath() == "main" {
+		name = t.Name()
+	}
+	for i := 2; r.defs[name] != nil; i++ {
+		name = fmt.Sprintf("%s.%s%d", path.Base(t.PkgPath()), t.Name(), i)
+	}
+	// Register before reflecting the fields, so recursive types end in a $ref.
+	s := &Schema{}
+	r.names[t] = name
+	r.defs[name] = s
+	*s = *r.object(t)
+	s.Description = r.docs[docKey(t)]
+	return name
+}
+
+// object returns the schema of struct t, laid out the way encoding/json
+// writes it.
+func (r *reflector) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.fields(s, t)
+	return s
+}
+
+// fields adds the fields of struct t to s.  Fields of embedded structs are
+// added after t's own, which take precedence.
+func (r *reflector) fields(s *Schema, t reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			if et := indirect(f.Type); et.Kind() == reflect.Struct {
+				embedded = append(embedded, et)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := s.Properties[name]; ok {
+			continue
+		}
+
+		p := r.schemaFor(f.Type)
+		if !hasOption(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+			switch f.Type.Kind() {
+			case reflect.Pointer, reflect.Slice, reflect.Map:
+				p = nullable(p)
+			}
+		}
+		if d := r.docs[docKey(t)+"."+f.Name]; d != "" {
+			p.Description = d
+		}
+		s.Properties[name] = p
+	}
+	for _, et := range embedded {
+		r.fields(s, et)
+	}
+}
+
+// nullable lets s also be null, as a nil pointer, slice or map is written.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	}
+	if t, ok := s.Type.(string); ok {
+		s.Type = []string{t, "null"}
+	}
+	return s
+}
+
+func hasOption(opts, name string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// docKey is the key of t's description: its import path and name.
+func docKey(t reflect.Type) string {
+	return t.PkgPath() + "." + t.Name()
+}
+
+func ref(name string) string {
+	return "#/$defs/" + name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unique(s []string) []string {
+	sort.Strings(s)
+	var out []string
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/registry"
+)
+
+type testLeg struct {
+	From string   `json:"from"`
+	Next *testLeg `json:"next,omitempty"`
+}
+
+type testMeta struct {
+	Source string `json:"source"`
+	Note   string `json:"note"` // Shadowed by testResult.Note.
+}
+
+type testResult struct {
+	testMeta
+	MsgID  int64             `json:"message_id"`
+	Note   int               `json:"note,omitempty"`
+	Legs   []testLeg         `json:"legs"`
+	Tags   map[string]string `json:"tags,omitempty"`
+	Raw    []byte            `json:"raw,omitempty"`
+	Hidden string            `json:"-"`
+	hidden string
+}
+
+func (r *testResult) Type() string     { return "test" }
+func (r *testResult) MessageID() int64 { return r.MsgID }
+
+type testParser struct{ name string }
+
+func (p *testParser) Name() string                         { return p.name }
+func (p *testParser) Labels() []string                     { return nil }
+func (p *testParser) QuickCheck(string) bool               { return false }
+func (p *testParser) Priority() int                        { return 0 }
+func (p *testParser) Parse(*acars.Message) registry.Result { return nil }
+func (p *testParser) ResultTypes() []string                { return []string{"test", "test_ack"} }
+func (p *testParser) NewResult() registry.Result           { return &testResult{} }
+
+type testRecord struct {
+	Results []any `json:"results,omitempty"`
+}
+
+func TestBuild(t *testing.T) {
+	reg := registry.New()
+	reg.Register(&testParser{name: "a"})
+	reg.Register(&testParser{name: "b"})
+	doc, err := Build(reg, testRecord{}, "results")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := doc.Defs["schema.testResult"]
+	if res == nil || strings.Join(res.ResultTypes, ",") != "test,test_ack" {
+		t.Fatalf("testResult def = %+v", res)
+	}
+	if got := strings.Join(res.Required, ","); got != "message_id,legs,source" {
+		t.Errorf("required = %s", got)
+	}
+	for name, want := range map[string]string{
+		"message_id": `{"type":"integer"}`,
+		"note":       `{"type":"integer"}`,
+		"legs":       `{"type":["array","null"],"items":{"$ref":"#/$defs/schema.testLeg"}}`,
+		"tags":       `{"type":"object","additionalProperties":{"type":"string"}}`,
+		"raw":        `{"type":"string","contentEncoding":"base64"}`,
+		"source":     `{"type":"string"}`,
+	} {
+		got, _ := json.Marshal(res.Properties[name])
+		if string(got) != want {
+			t.Errorf("%s = %s, want %s", name, got, want)
+		}
+	}
+	if len(res.Properties) != 6 {
+		t.Errorf("properties = %v", res.Properties)
+	}
+	if next := doc.Defs["schema.testLeg"].Properties["next"]; next.Ref != "#/$defs/schema.testLeg" {
+		t.Errorf("recursive next = %+v", next)
+	}
+	items := doc.Properties["results"].Items.AnyOf
+	if len(items) != 2 || items[0].Ref != "#/$defs/registry.ErrorResult" || items[1].Ref != "#/$defs/schema.testResult" {
+		t.Errorf("results items = %+v", items)
+	}
+
+	one, err := ForType(doc, "test_ack")
+	if err != nil || one.Ref != "#/$defs/schema.testResult" || len(one.Defs) != 2 {
+		t.Errorf("ForType = %+v, %v", one, err)
+	}
+	if _, err := ForType(doc, "nope"); err == nil {
+		t.Error("ForType accepted an unknown type")
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	r := newReflector(map[string]string{
+		"acars_parser/internal/schema.testLeg":      "One leg.",
+		"acars_parser/internal/schema.testLeg.From": "Departure point.",
+	})
+	r.schemaFor(reflect.TypeOf(testLeg{}))
+	leg := r.defs["schema.testLeg"]
+	if leg.Description != "One leg." || leg.Properties["from"].Description != "Departure point." {
+		t.Errorf("descriptions = %q, %q", leg.Description, leg.Properties["from"].Description)
+	}
+}
+
+// TestDocsUpToDate fails when a comment of a result or record type changed
+// without running go generate.
+func TestDocsUpToDate(t *testing.T) {
+	want, err := GenerateDocs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("docs_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("docs_gen.go is out of date; run go generate ./internal/schema")
+	}
+}
@@ -0,0 +1,207 @@
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Dump holds every row of the state tables, for export.
+type Dump struct {
+	Aircraft      []*Aircraft      `json:"aircraft"`
+	Routes        []*Route         `json:"routes"`
+	RouteAircraft []*RouteAircraft `json:"route_aircraft"`
+	Waypoints     []*Waypoint      `json:"waypoints"`
+	ATIS          []*ATIS          `json:"atis"`
+	ATISHistory   []*ATIS          `json:"atis_history"` // UpdatedAt is the time the letter was recorded.
+	Flights       []*FlightState   `json:"flights"`
+	Legs          []*FlightLeg     `json:"legs"` // With their messages and tracks.
+}
+
+// Dump reads every table of the database, including flight states too old
+// to be held in memory.
+func (t *Tracker) Dump() (*Dump, error) {
+	var d Dump
+	var err error
+	if d.Aircraft, err = t.dumpAircraft(); err != nil {
+		return nil, err
+	}
+	if d.Routes, err = t.dumpRoutes(); err != nil {
+		return nil, err
+	}
+	if d.RouteAircraft, err = t.dumpRouteAircraft(); err != nil {
+		return nil, err
+	}
+	if d.Waypoints, err = t.dumpWaypoints(); err != nil {
+		return nil, err
+	}
+	if d.ATIS, err = t.dumpATIS(`
+		SELECT airport_icao, letter, atis_type, atis_time, raw_text, runways, approaches,
+		       wind, visibility, clouds, temperature, dew_point, qnh, remarks, updated_at, synced_at
+		FROM atis_current ORDER BY airport_icao
+	`); err != nil {
+		return nil, err
+	}
+	if d.ATISHistory, err = t.dumpATIS(`
+		SELECT airport_icao, letter, atis_type, atis_time, raw_text, runways, approaches,
+		       wind, visibility, clouds, temperature, dew_point, qnh, remarks, recorded_at, NULL
+		FROM atis_history ORDER BY id
+	`); err != nil {
+		return nil, err
+	}
+	if d.Flights, err = t.queryFlightStates("ORDER BY key"); err != nil {
+		return nil, err
+	}
+	if d.Legs, err = t.queryLegs("ORDER BY id"); err != nil {
+		return nil, err
+	}
+	for _, leg := range d.Legs {
+		if err := t.loadLegHistory(leg); err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
+// timePtr returns the time of a nullable column.
+func timePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func (t *Tracker) dumpAircraft() ([]*Aircraft, error) {
+	rows, err := t.db.Query(`
+		SELECT icao_hex, registration, type_code, operator, first_seen, last_seen, msg_count, synced_at
+		FROM aircraft ORDER BY icao_hex
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*Aircraft
+	for rows.Next() {
+		var a Aircraft
+		var typeCode, operator sql.NullString
+		var synced sql.NullTime
+		if err := rows.Scan(&a.ICAOHex, &a.Registration, &typeCode, &operator,
+			&a.FirstSeen, &a.LastSeen, &a.MsgCount, &synced); err != nil {
+			return nil, err
+		}
+		a.TypeCode = typeCode.String
+		a.Operator = operator.String
+		a.SyncedAt = timePtr(synced)
+		result = append(result, &a)
+	}
+	return result, rows.Err()
+}
+
+func (t *Tracker) dumpRoutes() ([]*Route, error) {
+	rows, err := t.db.Query(`
+		SELECT id, flight_pattern, origin_icao, dest_icao,
+		       observation_count, first_seen, last_seen, synced_at
+		FROM routes ORDER BY flight_pattern, origin_icao, dest_icao
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*Route
+	for rows.Next() {
+		var r Route
+		var synced sql.NullTime
+		if err := rows.Scan(&r.ID, &r.FlightPattern, &r.OriginICAO, &r.DestICAO,
+			&r.ObservationCount, &r.FirstSeen, &r.LastSeen, &synced); err != nil {
+			return nil, err
+		}
+		r.SyncedAt = timePtr(synced)
+		result = append(result, &r)
+	}
+	return result, rows.Err()
+}
+
+func (t *Tracker) dumpRouteAircraft() ([]*RouteAircraft, error) {
+	rows, err := t.db.Query(`
+		SELECT route_id, registration, observation_count, first_seen, last_seen
+		FROM route_aircraft ORDER BY route_id, registration
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*RouteAircraft
+	for rows.Next() {
+		var ra RouteAircraft
+		if err := rows.Scan(&ra.RouteID, &ra.Registration, &ra.ObservationCount,
+			&ra.FirstSeen, &ra.LastSeen); err != nil {
+			return nil, err
+		}
+		result = append(result, &ra)
+	}
+	return result, rows.Err()
+}
+
+func (t *Tracker) dumpWaypoints() ([]*Waypoint, error) {
+	rows, err := t.db.Query(`
+		SELECT name, latitude, longitude, source_count, first_seen, last_seen, synced_at
+		FROM waypoints ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*Waypoint
+	for rows.Next() {
+		var w Waypoint
+		var synced sql.NullTime
+		if err := rows.Scan(&w.Name, &w.Latitude, &w.Longitude, &w.SourceCount,
+			&w.FirstSeen, &w.LastSeen, &synced); err != nil {
+			return nil, err
+		}
+		w.SyncedAt = timePtr(synced)
+		result = append(result, &w)
+	}
+	return result, rows.Err()
+}
+
+// dumpATIS reads ATIS rows from query, whose columns are those of
+// atis_current.
+func (t *Tracker) dumpATIS(query string) ([]*ATIS, error) {
+	rows, err := t.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*ATIS
+	for rows.Next() {
+		var a ATIS
+		var atisType, atisTime, raw, runways, approaches sql.NullString
+		var wind, vis, clouds, temp, dew, qnh, remarks sql.NullString
+		var synced sql.NullTime
+		if err := rows.Scan(&a.AirportICAO, &a.Letter, &atisType, &atisTime, &raw, &runways, &approaches,
+			&wind, &vis, &clouds, &temp, &dew, &qnh, &remarks, &a.UpdatedAt, &synced); err != nil {
+			return nil, err
+		}
+		a.ATISType = atisType.String
+		a.ATISTime = atisTime.String
+		a.RawText = raw.String
+		a.Wind = wind.String
+		a.Visibility = vis.String
+		a.Clouds = clouds.String
+		a.Temperature = temp.String
+		a.DewPoint = dew.String
+		a.QNH = qnh.String
+		_ = json.Unmarshal([]byte(runways.String), &a.Runways)
+		_ = json.Unmarshal([]byte(approaches.String), &a.Approaches)
+		_ = json.Unmarshal([]byte(remarks.String), &a.Remarks)
+		a.SyncedAt = timePtr(synced)
+		result = append(result, &a)
+	}
+	return result, rows.Err()
+}
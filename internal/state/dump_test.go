@@ -0,0 +1,46 @@
+package state
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	tracker, err := NewTracker(":memory:")
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	defer func() { _ = tracker.Close() }()
+
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", Registration: "EI-DEO", FlightNumber: "EIN123", Origin: "EIDW", Destination: "EGLL", Waypoint: "BAGSO"})
+	tracker.UpdateWaypoint("BAGSO", 53.41, -5.5)
+	tracker.UpdateATIS(&ATIS{AirportICAO: "EIDW", Letter: "K", Runways: []string{"28L"}})
+	tracker.UpdateATIS(&ATIS{AirportICAO: "EIDW", Letter: "L", Runways: []string{"28L"}})
+	tracker.MarkWaypointSynced("BAGSO")
+
+	d, err := tracker.Dump()
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	if len(d.Aircraft) != 1 || d.Aircraft[0].Registration != "EI-DEO" || d.Aircraft[0].FirstSeen.IsZero() {
+		t.Errorf("aircraft = %+v", d.Aircraft)
+	}
+	if len(d.Routes) != 1 || d.Routes[0].FlightPattern != "EIN123" || d.Routes[0].OriginICAO != "EIDW" || d.Routes[0].DestICAO != "EGLL" {
+		t.Errorf("routes = %+v", d.Routes)
+	}
+	if len(d.RouteAircraft) != 1 || d.RouteAircraft[0].Registration != "EI-DEO" {
+		t.Errorf("route aircraft = %+v", d.RouteAircraft)
+	}
+	if len(d.Waypoints) != 1 || d.Waypoints[0].SyncedAt == nil {
+		t.Errorf("waypoints = %+v", d.Waypoints)
+	}
+	if len(d.ATIS) != 1 || d.ATIS[0].Letter != "L" || len(d.ATIS[0].Runways) != 1 || len(d.ATISHistory) != 2 {
+		t.Errorf("atis = %+v, history %+v", d.ATIS, d.ATISHistory)
+	}
+	if len(d.Flights) != 1 || d.Flights[0].Key != "4CA7B5" || len(d.Flights[0].Waypoints) != 1 {
+		t.Errorf("flights = %+v", d.Flights)
+	}
+	if _, err := json.Marshal(d); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,275 @@
+package state
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// feetToMetres converts track altitudes for GeoJSON and KML, whose
+// coordinates are in metres.
+const feetToMetres = 0.3048
+
+// trackGroup is the legs exported as one feature: a single leg, or every leg
+// of an aircraft.
+type trackGroup []*FlightLeg
+
+// groupTracks drops the legs with fewer than minPoints positions, or fewer
+// than two since a single position makes no line, and groups the rest.
+func groupTracks(legs []*FlightLeg, minPoints int, byAircraft bool) []trackGroup {
+	minPoints = max(minPoints, 2)
+	var groups []trackGroup
+	index := make(map[string]int)
+	for _, leg := range legs {
+		if len(leg.Track) < minPoints {
+			continue
+		}
+		if !byAircraft {
+			groups = append(groups, trackGroup{leg})
+			continue
+		}
+		i, ok := index[leg.AircraftKey]
+		if !ok {
+			i = len(groups)
+			index[leg.AircraftKey] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], leg)
+	}
+	return groups
+}
+
+// name is the aircraft, and for a leg its flight number.
+func (g trackGroup) name(byAircraft bool) string {
+	leg := g[len(g)-1]
+	name := strings.TrimLeft(leg.Registration, ".")
+	if name == "" {
+		name = leg.AircraftKey
+	}
+	if !byAircraft && leg.FlightNumber != "" {
+		name += " " + leg.FlightNumber
+	}
+	return name
+}
+
+// description summarises the flights of the group.
+func (g trackGroup) description() string {
+	var parts []string
+	for _, leg := range g {
+		s := leg.FlightNumber
+		if leg.Origin != "" || leg.Destination != "" {
+			s = strings.TrimSpace(s + " " + leg.Origin + "-" + leg.Destination)
+		}
+		if s == "" {
+			s = fmt.Sprintf("leg %d", leg.ID)
+		}
+		parts = append(parts, fmt.Sprintf("%s, %d positions, %s to %s", s, len(leg.Track),
+			leg.FirstSeen.UTC().Format(time.RFC3339), leg.LastSeen.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// trackAltitudes returns the altitudes of track in metres, with those not
+// reported filled in: interpolated by time between two known altitudes, and
+// held at the nearest known one before the first or after the last.  ok is
+// false when no position of the track has an altitude.
+func trackAltitudes(track []TrackPoint) (alts []float64, ok bool) {
+	alts = make([]float64, len(track))
+	prev := -1
+	for i, p := range track {
+		if p.Altitude == 0 {
+			continue
+		}
+		alts[i] = float64(p.Altitude)
+		switch {
+		case prev < 0:
+			for j := 0; j < i; j++ {
+				alts[j] = alts[i]
+			}
+		case i-prev > 1:
+			from, to := track[prev], track[i]
+			span := to.Time.Sub(from.Time)
+			for j := prev + 1; j < i; j++ {
+				frac := float64(j-prev) / float64(i-prev)
+				if span > 0 {
+					frac = float64(track[j].Time.Sub(from.Time)) / float64(span)
+				}
+				alts[j] = alts[prev] + (alts[i]-alts[prev])*frac
+			}
+		}
+		prev = i
+	}
+	if prev < 0 {
+		return nil, false
+	}
+	for j := prev + 1; j < len(track); j++ {
+		alts[j] = alts[prev]
+	}
+	for i, a := range alts {
+		alts[i] = math.Round(a*feetToMetres*10) / 10
+	}
+	return alts, true
+}
+
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+// WriteGeoJSON writes the tracks of legs as a GeoJSON FeatureCollection: a
+// LineString per leg or, byAircraft, a MultiLineString per aircraft.  Legs
+// with fewer than minPoints positions are left out.  A geometry has an
+// altitude in every coordinate, filled in as trackAltitudes does, when each
+// of its legs reports one, and in none otherwise.  The position times,
+// altitudes in feet, sources and message IDs are parallel arrays in the
+// properties; coordTimes is the name GPX and KML converters use for the
+// times.
+func WriteGeoJSON(w io.Writer, legs []*FlightLeg, minPoints int, byAircraft bool) error {
+	features := []geoJSONFeature{}
+	for _, g := range groupTracks(legs, minPoints, byAircraft) {
+		var lines [][][]float64
+		var times [][]string
+		var altitudes [][]int
+		var sources [][]string
+		var messages [][]int64
+		var legIDs []int64
+		var flights []string
+		legAlts := make([][]float64, len(g))
+		threeD := true
+		for i, leg := range g {
+			var ok bool
+			legAlts[i], ok = trackAltitudes(leg.Track)
+			threeD = threeD && ok
+		}
+		for i, leg := range g {
+			var line [][]float64
+			var ts []string
+			var alts []int
+			var srcs []string
+			var ids []int64
+			for j, p := range leg.Track {
+				c := []float64{p.Longitude, p.Latitude}
+				if threeD {
+					c = append(c, legAlts[i][j])
+				}
+				line = append(line, c)
+				ts = append(ts, p.Time.UTC().Format(time.RFC3339))
+				alts = append(alts, p.Altitude)
+				srcs = append(srcs, p.Source)
+				ids = append(ids, p.MessageID)
+			}
+			lines = append(lines, line)
+			times = append(times, ts)
+			altitudes = append(altitudes, alts)
+			sources = append(sources, srcs)
+			messages = append(messages, ids)
+			legIDs = append(legIDs, leg.ID)
+			flights = append(flights, leg.FlightNumber)
+		}
+
+		first, last := g[0], g[len(g)-1]
+		props := map[string]any{
+			"name":         g.name(byAircraft),
+			"aircraft":     last.AircraftKey,
+			"registration": last.Registration,
+			"icao_hex":     last.ICAOHex,
+			"first_seen":   first.FirstSeen.UTC().Format(time.RFC3339),
+			"last_seen":    last.LastSeen.UTC().Format(time.RFC3339),
+		}
+		f := geoJSONFeature{Type: "Feature", Properties: props}
+		if byAircraft {
+			f.Geometry = geoJSONGeometry{Type: "MultiLineString", Coordinates: lines}
+			props["leg_ids"] = legIDs
+			props["flight_numbers"] = flights
+			props["coordTimes"] = times
+			props["altitudes_ft"] = altitudes
+			props["sources"] = sources
+			props["message_ids"] = messages
+		} else {
+			f.Geometry = geoJSONGeometry{Type: "LineString", Coordinates: lines[0]}
+			props["leg_id"] = first.ID
+			props["flight_number"] = first.FlightNumber
+			props["origin"] = first.Origin
+			props["destination"] = first.Destination
+			props["coordTimes"] = times[0]
+			props["altitudes_ft"] = altitudes[0]
+			props["sources"] = sources[0]
+			props["message_ids"] = messages[0]
+		}
+		features = append(features, f)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}{"FeatureCollection", features})
+}
+
+// WriteKML writes the tracks of legs as KML with a gx:Track per leg, so
+// Google Earth can play them back over time.  byAircraft, the legs of an
+// aircraft share a placemark as a gx:MultiTrack.  Legs with fewer than
+// minPoints positions are left out.
+func WriteKML(w io.Writer, legs []*FlightLeg, minPoints int, byAircraft bool) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">` + "\n<Document>\n")
+	b.WriteString("<name>ACARS tracks</name>\n")
+	for _, g := range groupTracks(legs, minPoints, byAircraft) {
+		b.WriteString("<Placemark>\n")
+		writeKMLElement(&b, "name", g.name(byAircraft))
+		writeKMLElement(&b, "description", g.description())
+		if byAircraft {
+			b.WriteString("<gx:MultiTrack>\n")
+		}
+		for _, leg := range g {
+			writeKMLTrack(&b, leg)
+		}
+		if byAircraft {
+			b.WriteString("</gx:MultiTrack>\n")
+		}
+		b.WriteString("</Placemark>\n")
+	}
+	b.WriteString("</Document>\n</kml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeKMLTrack writes the gx:Track of a leg.  Positions without an
+// altitude are given one as trackAltitudes does; tracks without any are
+// clamped to the ground.
+func writeKMLTrack(b *strings.Builder, leg *FlightLeg) {
+	mode := "clampToGround"
+	alts, ok := trackAltitudes(leg.Track)
+	if ok {
+		mode = "absolute"
+	} else {
+		alts = make([]float64, len(leg.Track))
+	}
+	b.WriteString("<gx:Track>\n")
+	writeKMLElement(b, "altitudeMode", mode)
+	for _, p := range leg.Track {
+		writeKMLElement(b, "when", p.Time.UTC().Format(time.RFC3339))
+	}
+	for i, p := range leg.Track {
+		fmt.Fprintf(b, "<gx:coord>%g %g %g</gx:coord>\n", p.Longitude, p.Latitude, alts[i])
+	}
+	b.WriteString("</gx:Track>\n")
+}
+
+func writeKMLElement(b *strings.Builder, name, value string) {
+	b.WriteString("<" + name + ">")
+	_ = xml.EscapeText(b, []byte(value))
+	b.WriteString("</" + name + ">\n")
+}
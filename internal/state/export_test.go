@@ -0,0 +1,139 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportTestLegs(t *testing.T) []*FlightLeg {
+	t.Helper()
+	tracker := newTestTracker(t, ":memory:")
+	at := func(min int) time.Time { return replayStart.Add(time.Duration(min) * time.Minute) }
+	update := func(min int, u FlightUpdate) {
+		u.ICAOHex, u.Registration, u.Time = "4CA7B5", ".EI-DEO", at(min)
+		tracker.UpdateFlight(u)
+	}
+
+	update(0, FlightUpdate{FlightNumber: "EIN123", Origin: "EIDW", Destination: "EGLL", Latitude: 53.4, Longitude: -6.2, MessageID: 1})
+	update(30, FlightUpdate{MessageID: 2, Positions: []TrackPoint{{Latitude: 53.1, Longitude: -4.5, Altitude: 35000, Source: "adsc"}}})
+	update(60, FlightUpdate{Event: EventIn, Latitude: 51.5, Longitude: -0.5, MessageID: 3})
+	update(110, FlightUpdate{FlightNumber: "EIN154", Latitude: 51.5, Longitude: -0.5, MessageID: 4})
+
+	legs, err := tracker.GetLegs("", time.Time{}, time.Time{})
+	if err != nil || len(legs) != 2 {
+		t.Fatalf("GetLegs = %+v, %v", legs, err)
+	}
+	return legs
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	legs := exportTestLegs(t)
+
+	var b bytes.Buffer
+	if err := WriteGeoJSON(&b, legs, 0, false); err != nil {
+		t.Fatalf("WriteGeoJSON: %v", err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates [][]float64
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &fc); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, b.String())
+	}
+	// The single position of EIN154 makes no line.
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 1 {
+		t.Fatalf("got %+v", fc)
+	}
+	f := fc.Features[0]
+	if f.Geometry.Type != "LineString" || len(f.Geometry.Coordinates) != 3 {
+		t.Fatalf("geometry = %+v", f.Geometry)
+	}
+	if c := f.Geometry.Coordinates[1]; len(c) != 3 || c[0] != -4.5 || c[1] != 53.1 || c[2] != 10668 {
+		t.Errorf("coordinate = %v", c)
+	}
+	// The positions either side report no altitude and take the known one.
+	for _, c := range f.Geometry.Coordinates {
+		if len(c) != 3 || c[2] != 10668 {
+			t.Errorf("coordinates = %v, want an altitude in each", f.Geometry.Coordinates)
+			break
+		}
+	}
+	times, _ := f.Properties["coordTimes"].([]any)
+	sources, _ := f.Properties["sources"].([]any)
+	if f.Properties["name"] != "EI-DEO EIN123" || len(times) != 3 || times[2] != "2026-05-12T17:54:24Z" || sources[1] != "adsc" {
+		t.Errorf("properties = %v", f.Properties)
+	}
+
+	b.Reset()
+	if err := WriteGeoJSON(&b, legs, 5, true); err != nil {
+		t.Fatalf("WriteGeoJSON: %v", err)
+	}
+	if !strings.Contains(b.String(), `"features": []`) {
+		t.Errorf("min points not applied:\n%s", b.String())
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	legs := exportTestLegs(t)
+	legs[0].Registration = "EI-<DEO>"
+
+	var b bytes.Buffer
+	if err := WriteKML(&b, legs, 0, true); err != nil {
+		t.Fatalf("WriteKML: %v", err)
+	}
+	got := b.String()
+	if err := xml.Unmarshal(b.Bytes(), new(struct{})); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, got)
+	}
+	for _, want := range []string{
+		"<name>EI-&lt;DEO&gt;</name>",
+		"<gx:MultiTrack>",
+		"<altitudeMode>absolute</altitudeMode>",
+		"<when>2026-05-12T16:54:24Z</when>",
+		"<gx:coord>-4.5 53.1 10668</gx:coord>",
+		"<gx:coord>-6.2 53.4 10668</gx:coord>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("KML lacks %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "<Placemark>"); n != 1 {
+		t.Errorf("%d placemarks, want 1", n)
+	}
+}
+
+func TestTrackAltitudes(t *testing.T) {
+	at := func(min int) time.Time { return replayStart.Add(time.Duration(min) * time.Minute) }
+	track := []TrackPoint{
+		{Time: at(0)},
+		{Time: at(10), Altitude: 10000},
+		{Time: at(15)},
+		{Time: at(40), Altitude: 30000},
+		{Time: at(50)},
+	}
+	alts, ok := trackAltitudes(track)
+	want := []float64{3048, 3048, 4064, 9144, 9144}
+	if !ok || len(alts) != len(want) {
+		t.Fatalf("trackAltitudes = %v, %v", alts, ok)
+	}
+	for i := range want {
+		if alts[i] != want[i] {
+			t.Errorf("altitudes = %v, want %v", alts, want)
+			break
+		}
+	}
+
+	if alts, ok := trackAltitudes([]TrackPoint{{Time: at(0)}, {Time: at(1)}}); ok || alts != nil {
+		t.Errorf("no altitudes: got %v, %v", alts, ok)
+	}
+}
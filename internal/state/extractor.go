@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"regexp"
 	"strings"
+	"time"
 
 	"acars_parser/internal/acars"
+	"acars_parser/internal/decode"
 	"acars_parser/internal/registry"
 )
 
@@ -19,11 +21,33 @@ func isValidAirportCode(code string) bool {
 	return airportCodeRe.MatchString(code)
 }
 
+// oooiLabels are the ARINC 620 downlink labels of the OOOI reports.
+var oooiLabels = map[string]string{
+	"QP": EventOut,
+	"QQ": EventOff,
+	"QR": EventOn,
+	"QS": EventIn,
+}
+
+// oooiFields are the result fields reporting OOOI event times, latest event
+// first: a report of several events stands for the latest.
+var oooiFields = []struct{ field, event string }{
+	{"in_time", EventIn},
+	{"on_time", EventOn},
+	{"off_time", EventOff},
+	{"take_off_time", EventOff},
+	{"out_time", EventOut},
+}
+
 // ExtractAndUpdate extracts relevant data from a message and its parsed results,
-// then updates the tracker accordingly.
+// then updates the tracker accordingly.  Everything learned is stamped with
+// the message timestamp; a message without one gets the tracker's clock.
 func ExtractAndUpdate(t *Tracker, msg *acars.Message, results []registry.Result) {
 	// Build the base flight update from the message metadata.
-	update := FlightUpdate{}
+	update := FlightUpdate{MessageID: int64(msg.ID), Label: msg.Label, Event: oooiLabels[msg.Label]}
+	if ts, ok := decode.ParseTime(msg.Timestamp); ok {
+		update.Time = ts
+	}
 
 	// Extract identity from the message/airframe.
 	if msg.Airframe != nil {
@@ -115,39 +139,40 @@ func extractFromResult(update *FlightUpdate, t *Tracker, result registry.Result)
 		}
 	}
 
-	// Extract position.
-	if v, ok := m["latitude"].(float64); ok && v != 0 {
-		update.Latitude = v
-	}
-	if v, ok := m["longitude"].(float64); ok && v != 0 {
-		update.Longitude = v
+	// Extract the OOOI event.
+	if update.Event == "" {
+		for _, f := range oooiFields {
+			if v, ok := m[f.field].(string); ok && v != "" {
+				update.Event = f.event
+				break
+			}
+		}
 	}
+
+	// Extract position, adding every reported position to the track.
 	if v, ok := m["report_time"].(string); ok && v != "" {
 		update.ReportTime = strings.TrimSpace(v)
 	}
-
-	// Extract altitude (could be int or float64 in JSON).
-	if v, ok := m["altitude_ft"].(float64); ok && v != 0 {
-		update.Altitude = int(v)
-	} else if v, ok := m["altitude"].(float64); ok && v != 0 {
-		update.Altitude = int(v)
-	} else if v, ok := m["flight_level"].(float64); ok && v != 0 {
-		update.Altitude = int(v) * 100 // Convert FL to feet.
-	}
-
-	// Extract ground speed and track.
-	if v, ok := m["ground_speed_kmh"].(float64); ok && v != 0 {
-		update.GroundSpeed = int(v)
-	} else if v, ok := m["ground_speed_kts"].(float64); ok && v != 0 {
-		update.GroundSpeed = int(v)
-	} else if v, ok := m["ground_speed"].(float64); ok && v != 0 {
-		update.GroundSpeed = int(v)
-	}
-	if v, ok := m["track"].(float64); ok && v != 0 {
-		update.Track = int(v)
-	}
-	if v, ok := m["heading"].(float64); ok && v != 0 && update.Track == 0 {
-		update.Track = int(v)
+	for i, p := range registry.PositionsOf(result) {
+		if i == 0 {
+			update.Latitude, update.Longitude = p.Latitude, p.Longitude
+			if p.Altitude != 0 {
+				update.Altitude = p.Altitude
+			}
+			if p.GroundSpeed != 0 {
+				update.GroundSpeed = p.GroundSpeed
+			}
+			if p.Track != 0 {
+				update.Track = p.Track
+			}
+		}
+		update.Positions = append(update.Positions, TrackPoint{
+			Time:      p.At(update.Time),
+			Latitude:  p.Latitude,
+			Longitude: p.Longitude,
+			Altitude:  p.Altitude,
+			Source:    result.Type(),
+		})
 	}
 
 	// Extract aircraft type.
@@ -163,7 +188,7 @@ func extractFromResult(update *FlightUpdate, t *Tracker, result registry.Result)
 		update.Waypoint = v
 		// If we have coordinates with the waypoint, record it.
 		if update.Latitude != 0 && update.Longitude != 0 {
-			t.UpdateWaypoint(v, update.Latitude, update.Longitude)
+			t.UpdateWaypointAt(v, update.Latitude, update.Longitude, update.Time)
 		}
 	}
 	if v, ok := m["current_waypoint"].(string); ok && v != "" {
@@ -182,7 +207,7 @@ func extractFromResult(update *FlightUpdate, t *Tracker, result registry.Result)
 					lat, _ := wpMap["latitude"].(float64)
 					lon, _ := wpMap["longitude"].(float64)
 					if lat != 0 && lon != 0 {
-						t.UpdateWaypoint(name, lat, lon)
+						t.UpdateWaypointAt(name, lat, lon, update.Time)
 					}
 				}
 			} else if wpStr, ok := wp.(string); ok && wpStr != "" {
@@ -204,12 +229,12 @@ func extractFromResult(update *FlightUpdate, t *Tracker, result registry.Result)
 
 	// Handle ATIS results.
 	if result.Type() == "atis" {
-		extractATIS(t, m)
+		extractATIS(t, m, update.Time)
 	}
 }
 
 // extractATIS extracts ATIS data and updates the tracker.
-func extractATIS(t *Tracker, m map[string]interface{}) {
+func extractATIS(t *Tracker, m map[string]interface{}, at time.Time) {
 	airport, _ := m["airport"].(string)
 	letter, _ := m["atis_letter"].(string)
 
@@ -220,6 +245,7 @@ func extractATIS(t *Tracker, m map[string]interface{}) {
 	atis := &ATIS{
 		AirportICAO: airport,
 		Letter:      letter,
+		UpdatedAt:   at,
 	}
 
 	if v, ok := m["atis_type"].(string); ok {
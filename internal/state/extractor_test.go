@@ -4,8 +4,11 @@ import (
 	"database/sql"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"acars_parser/internal/acars"
+	"acars_parser/internal/parsers/abs"
+	"acars_parser/internal/parsers/eb00"
 	"acars_parser/internal/parsers/sb01"
 	"acars_parser/internal/registry"
 	_ "modernc.org/sqlite"
@@ -59,6 +62,40 @@ func TestExtractAndUpdateFromSB01(t *testing.T) {
 	}
 }
 
+func TestExtractAndUpdateTrack(t *testing.T) {
+	tracker := newTestTracker(t, ":memory:")
+	msg := &acars.Message{
+		ID:        7,
+		Timestamp: "2026-05-12T16:54:24Z",
+		Label:     "H1",
+		Airframe:  &acars.Airframe{ICAO: "4CA7B5", Tail: "EI-DEO"},
+	}
+	result := &abs.Result{
+		Latitude: 53.1, Longitude: -4.5, AltitudeFt: 35000,
+		Positions: []abs.Position{
+			{Latitude: 53.1, Longitude: -4.5, AltitudeFt: 35000},
+			{Latitude: 52.9, Longitude: -3.8, AltitudeFt: 36000},
+		},
+	}
+	ExtractAndUpdate(tracker, msg, []registry.Result{result})
+
+	// A position with a report time is placed at that time.
+	msg.ID = 8
+	ExtractAndUpdate(tracker, msg, []registry.Result{&eb00.Result{Latitude: 53.4, Longitude: -6.2, ReportTime: "16:50", AltitudeFt: 8000}})
+
+	legs, err := tracker.GetLegs("EI-DEO", time.Time{}, time.Time{})
+	if err != nil || len(legs) != 1 {
+		t.Fatalf("GetLegs = %+v, %v", legs, err)
+	}
+	track := legs[0].Track
+	if len(track) != 3 || track[2].Altitude != 36000 || track[2].Source != "abs" || track[2].MessageID != 7 || !track[2].Time.Equal(replayStart) {
+		t.Errorf("track = %+v", track)
+	}
+	if track[0].Source != "eb00" || !track[0].Time.Equal(replayStart.Add(-4*time.Minute-24*time.Second)) {
+		t.Errorf("reported position = %+v", track[0])
+	}
+}
+
 func TestNewTrackerMigratesFlightStateReportTimeColumn(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "state.db")
 	db, err := sql.Open("sqlite", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
@@ -0,0 +1,350 @@
+package state
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// OOOI events: gate out, wheels off, wheels on and gate in.
+const (
+	EventOut = "out"
+	EventOff = "off"
+	EventOn  = "on"
+	EventIn  = "in"
+)
+
+// Reasons a flight leg was opened or closed, besides the OOOI events.
+const (
+	LegFirst   = "first"   // Opened by the first message of the aircraft.
+	LegGap     = "gap"     // Opened after a silence longer than LegIdle.
+	LegFlight  = "flight"  // Opened by a new flight number.
+	LegOrigin  = "origin"  // Opened by a new origin.
+	LegTimeout = "timeout" // Closed after LegIdle without a message.
+	LegNext    = "next"    // Closed because the next leg was opened.
+)
+
+// LegIdle is how long an aircraft may go unheard before its leg is closed;
+// its next message opens a new one.  Oceanic legs may go an hour or more
+// between reports, while a night on the ground is longer than this.
+const LegIdle = 3 * time.Hour
+
+// updateLeg records a message of the aircraft key in its flight legs,
+// opening and closing legs as needed.  The caller holds t.mu.
+func (t *Tracker) updateLeg(key string, u FlightUpdate, at time.Time) {
+	leg := t.legs[key]
+	if leg != nil && at.Before(leg.FirstSeen) {
+		// A late message belongs to an earlier leg, if any.
+		t.addLateToLeg(key, u, at)
+		return
+	}
+
+	// A late message stays with the current leg whatever it says.
+	late := leg != nil && at.Before(leg.LastSeen)
+	if reason := legReason(leg, u, at); reason != "" && !late {
+		if leg != nil && leg.ClosedBy == "" {
+			leg.ClosedBy = LegNext
+			if reason == LegGap {
+				leg.ClosedBy = LegTimeout
+			}
+			t.saveLeg(leg)
+		}
+		leg = &FlightLeg{AircraftKey: key, OpenedBy: reason, FirstSeen: at, LastSeen: at}
+		t.legs[key] = leg
+	}
+
+	set := func(field *string, v string) {
+		if v != "" && (!late || *field == "") {
+			*field = v
+		}
+	}
+	set(&leg.ICAOHex, u.ICAOHex)
+	set(&leg.Registration, u.Registration)
+	set(&leg.FlightNumber, u.FlightNumber)
+	set(&leg.Origin, u.Origin)
+	set(&leg.Destination, u.Destination)
+
+	stamp := func(event **time.Time) {
+		if *event == nil || at.Before(**event) {
+			ts := at
+			*event = &ts
+		}
+	}
+	switch u.Event {
+	case EventOut:
+		stamp(&leg.Out)
+	case EventOff:
+		stamp(&leg.Off)
+	case EventOn:
+		stamp(&leg.On)
+		if leg.ClosedBy != EventIn {
+			leg.ClosedBy = EventOn
+		}
+	case EventIn:
+		stamp(&leg.In)
+		leg.ClosedBy = EventIn
+	}
+	if !late {
+		leg.LastSeen = at
+	}
+	leg.MsgCount++
+
+	t.saveLeg(leg)
+	t.addLegMessage(leg.ID, u, at)
+}
+
+// legReason returns why u, sent at at, opens a new leg after leg, or "" if
+// it belongs to leg.  Messages after an on or in event still belong to the
+// leg until something opens the next one, so arrival reports are kept with
+// the flight they report on.
+func legReason(leg *FlightLeg, u FlightUpdate, at time.Time) string {
+	switch {
+	case leg == nil:
+		return LegFirst
+	case leg.ClosedBy == LegTimeout || at.Sub(leg.LastSeen) > LegIdle:
+		return LegGap
+	case u.FlightNumber != "" && leg.FlightNumber != "" && u.FlightNumber != leg.FlightNumber:
+		return LegFlight
+	case u.Origin != "" && leg.Origin != "" && u.Origin != leg.Origin:
+		return LegOrigin
+	case u.Event == EventOut && (leg.Out != nil || leg.Off != nil || leg.On != nil || leg.In != nil):
+		return EventOut
+	case u.Event == EventOff && (leg.Off != nil || leg.On != nil || leg.In != nil):
+		return EventOff
+	}
+	return ""
+}
+
+// addLateToLeg adds a message older than the aircraft's current leg to the
+// leg stored for that time, without changing the leg otherwise.
+func (t *Tracker) addLateToLeg(key string, u FlightUpdate, at time.Time) {
+	var id int64
+	err := t.db.QueryRow(`
+		SELECT id FROM flight_legs
+		WHERE aircraft_key = ? AND first_seen <= ?
+		ORDER BY first_seen DESC LIMIT 1
+	`, key, dbTime(at)).Scan(&id)
+	if err != nil {
+		return
+	}
+	_, _ = t.db.Exec("UPDATE flight_legs SET msg_count = msg_count + 1 WHERE id = ?", id)
+	t.addLegMessage(id, u, at)
+}
+
+// addLegMessage stores the message and any position of u in leg id.
+func (t *Tracker) addLegMessage(id int64, u FlightUpdate, at time.Time) {
+	if id == 0 {
+		return
+	}
+	_, _ = t.db.Exec(`
+		INSERT INTO leg_messages (leg_id, message_id, label, sent_at)
+		VALUES (?, ?, ?, ?)
+	`, id, u.MessageID, u.Label, dbTime(at))
+
+	positions := u.Positions
+	if len(positions) == 0 && (u.Latitude != 0 || u.Longitude != 0) {
+		positions = []TrackPoint{{Latitude: u.Latitude, Longitude: u.Longitude, Altitude: u.Altitude}}
+	}
+	for _, p := range positions {
+		if p.Time.IsZero() {
+			p.Time = at
+		}
+		_, _ = t.db.Exec(`
+			INSERT INTO leg_positions (leg_id, message_id, reported_at, latitude, longitude, altitude, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, id, u.MessageID, dbTime(p.Time), p.Latitude, p.Longitude, p.Altitude, nullString(p.Source))
+	}
+}
+
+// saveLeg inserts or updates the row of leg.
+func (t *Tracker) saveLeg(leg *FlightLeg) {
+	args := []any{
+		leg.AircraftKey, leg.ICAOHex, leg.Registration, leg.FlightNumber, leg.Origin, leg.Destination,
+		leg.OpenedBy, nullString(leg.ClosedBy),
+		nullTime(leg.Out), nullTime(leg.Off), nullTime(leg.On), nullTime(leg.In),
+		dbTime(leg.FirstSeen), dbTime(leg.LastSeen), leg.MsgCount,
+	}
+	if leg.ID == 0 {
+		result, err := t.db.Exec(`
+			INSERT INTO flight_legs (aircraft_key, icao_hex, registration, flight_number, origin, destination,
+			                         opened_by, closed_by, out_time, off_time, on_time, in_time,
+			                         first_seen, last_seen, msg_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, args...)
+		if err == nil {
+			leg.ID, _ = result.LastInsertId()
+		}
+		return
+	}
+	_, _ = t.db.Exec(`
+		UPDATE flight_legs SET
+			aircraft_key = ?, icao_hex = ?, registration = ?, flight_number = ?, origin = ?, destination = ?,
+			opened_by = ?, closed_by = ?, out_time = ?, off_time = ?, on_time = ?, in_time = ?,
+			first_seen = ?, last_seen = ?, msg_count = ?
+		WHERE id = ?
+	`, append(args, leg.ID)...)
+}
+
+// closeIdleLegs closes the legs not heard from for LegIdle before now and
+// forgets them.  The caller holds t.mu.
+func (t *Tracker) closeIdleLegs(now time.Time) {
+	for key, leg := range t.legs {
+		if now.Sub(leg.LastSeen) <= LegIdle {
+			continue
+		}
+		if leg.ClosedBy == "" {
+			leg.ClosedBy = LegTimeout
+			t.saveLeg(leg)
+		}
+		delete(t.legs, key)
+	}
+}
+
+// loadLegs loads the latest leg of every aircraft heard within LegIdle of
+// event time.
+func (t *Tracker) loadLegs() error {
+	legs, err := t.queryLegs(`
+		WHERE id IN (SELECT MAX(id) FROM flight_legs GROUP BY aircraft_key)
+		  AND last_seen > ?
+	`, dbTime(t.latest.Add(-LegIdle)))
+	if err != nil {
+		return err
+	}
+	for _, leg := range legs {
+		t.legs[leg.AircraftKey] = leg
+	}
+	return nil
+}
+
+// GetLegs returns the flight legs of an aircraft that overlap the time range
+// from-to, oldest first, each with its messages and position track.  tail is
+// a registration, with or without JAERO's leading dots, or an ICAO address;
+// "" returns the legs of every aircraft.  A zero from or to leaves that end
+// open.
+func (t *Tracker) GetLegs(tail string, from, to time.Time) ([]*FlightLeg, error) {
+	where := "WHERE 1"
+	var args []any
+	if tail = strings.ToUpper(strings.TrimLeft(strings.TrimSpace(tail), ".")); tail != "" {
+		where += ` AND (UPPER(LTRIM(registration, '.')) = ? OR UPPER(icao_hex) = ? OR UPPER(LTRIM(aircraft_key, '.')) = ?)`
+		args = append(args, tail, tail, tail)
+	}
+	if !from.IsZero() {
+		where += " AND last_seen >= ?"
+		args = append(args, dbTime(from))
+	}
+	if !to.IsZero() {
+		where += " AND first_seen < ?"
+		args = append(args, dbTime(to))
+	}
+	legs, err := t.queryLegs(where+" ORDER BY first_seen, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, leg := range legs {
+		if err := t.loadLegHistory(leg); err != nil {
+			return nil, err
+		}
+	}
+	return legs, nil
+}
+
+// queryLegs reads flight legs, without their messages and track; tail is
+// the SQL after the FROM clause.
+func (t *Tracker) queryLegs(tail string, args ...any) ([]*FlightLeg, error) {
+	rows, err := t.db.Query(`
+		SELECT id, aircraft_key, icao_hex, registration, flight_number, origin, destination,
+		       opened_by, closed_by, out_time, off_time, on_time, in_time,
+		       first_seen, last_seen, msg_count
+		FROM flight_legs
+		`+tail, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*FlightLeg
+	for rows.Next() {
+		var leg FlightLeg
+		var icaoHex, reg, flight, origin, dest, closedBy sql.NullString
+		var out, off, on, in sql.NullTime
+		if err := rows.Scan(&leg.ID, &leg.AircraftKey, &icaoHex, &reg, &flight, &origin, &dest,
+			&leg.OpenedBy, &closedBy, &out, &off, &on, &in,
+			&leg.FirstSeen, &leg.LastSeen, &leg.MsgCount); err != nil {
+			return nil, err
+		}
+		leg.ICAOHex = icaoHex.String
+		leg.Registration = reg.String
+		leg.FlightNumber = flight.String
+		leg.Origin = origin.String
+		leg.Destination = dest.String
+		leg.ClosedBy = closedBy.String
+		leg.Out = timePtr(out)
+		leg.Off = timePtr(off)
+		leg.On = timePtr(on)
+		leg.In = timePtr(in)
+		result = append(result, &leg)
+	}
+	return result, rows.Err()
+}
+
+// loadLegHistory reads the messages and track of leg.
+func (t *Tracker) loadLegHistory(leg *FlightLeg) error {
+	rows, err := t.db.Query(`
+		SELECT message_id, label, sent_at FROM leg_messages
+		WHERE leg_id = ? ORDER BY sent_at, rowid
+	`, leg.ID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var m LegMessage
+		var label sql.NullString
+		if err := rows.Scan(&m.ID, &label, &m.Time); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		m.Label = label.String
+		leg.Messages = append(leg.Messages, m)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = t.db.Query(`
+		SELECT reported_at, latitude, longitude, altitude, source, message_id FROM leg_positions
+		WHERE leg_id = ? ORDER BY reported_at, rowid
+	`, leg.ID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var p TrackPoint
+		var alt sql.NullInt64
+		var source sql.NullString
+		if err := rows.Scan(&p.Time, &p.Latitude, &p.Longitude, &alt, &source, &p.MessageID); err != nil {
+			return err
+		}
+		p.Altitude = int(alt.Int64)
+		p.Source = source.String
+		leg.Track = append(leg.Track, p)
+	}
+	return rows.Err()
+}
+
+// nullString returns s, or NULL when it is empty.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullTime returns the database form of *t, or NULL.
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return dbTime(*t)
+}
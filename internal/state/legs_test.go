@@ -0,0 +1,106 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"acars_parser/internal/acars"
+)
+
+func TestFlightLegs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	tracker, err := NewTracker(path)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	at := func(min int) time.Time { return replayStart.Add(time.Duration(min) * time.Minute) }
+	update := func(min int, u FlightUpdate) {
+		u.ICAOHex, u.Registration, u.Time = "4CA7B5", ".EI-DEO", at(min)
+		tracker.UpdateFlight(u)
+	}
+
+	// EIN123 Dublin-Heathrow, then EIN154 back after the turnaround.
+	update(0, FlightUpdate{FlightNumber: "EIN123", Origin: "EIDW", Destination: "EGLL", MessageID: 1})
+	update(5, FlightUpdate{Event: EventOut, MessageID: 2})
+	update(15, FlightUpdate{Event: EventOff, MessageID: 3})
+	update(30, FlightUpdate{Latitude: 53.1, Longitude: -4.5, Altitude: 35000, MessageID: 4})
+	update(60, FlightUpdate{Event: EventOn, MessageID: 5})
+	update(66, FlightUpdate{Event: EventIn, MessageID: 6})
+	update(20, FlightUpdate{Latitude: 53.3, Longitude: -5.8, Altitude: 12000, MessageID: 7}) // late
+	update(110, FlightUpdate{FlightNumber: "EIN154", Origin: "EGLL", Destination: "EIDW", MessageID: 8})
+	update(115, FlightUpdate{Event: EventOut, MessageID: 9})
+	// Next morning.
+	update(15*60, FlightUpdate{MessageID: 10})
+	_ = tracker.Close()
+
+	tracker = newTestTracker(t, path)
+	update(15*60+5, FlightUpdate{Event: EventOut, MessageID: 11})
+
+	legs, err := tracker.GetLegs("ei-deo", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetLegs: %v", err)
+	}
+	if len(legs) != 3 {
+		t.Fatalf("got %d legs, want 3", len(legs))
+	}
+
+	first := legs[0]
+	if first.FlightNumber != "EIN123" || first.Origin != "EIDW" || first.OpenedBy != LegFirst || first.ClosedBy != EventIn ||
+		!first.Off.Equal(at(15)) || !first.In.Equal(at(66)) || first.MsgCount != 7 {
+		t.Errorf("first leg = %+v", first)
+	}
+	if len(first.Messages) != 7 || first.Messages[3].ID != 7 {
+		t.Errorf("first leg messages = %+v", first.Messages)
+	}
+	if len(first.Track) != 2 || first.Track[0].Altitude != 12000 || first.Track[1].MessageID != 4 {
+		t.Errorf("first leg track = %+v", first.Track)
+	}
+
+	second := legs[1]
+	if second.FlightNumber != "EIN154" || second.OpenedBy != LegFlight || second.ClosedBy != LegTimeout || second.MsgCount != 2 {
+		t.Errorf("second leg = %+v", second)
+	}
+
+	// The morning leg stays open across a restart and takes the out event.
+	third := legs[2]
+	if third.OpenedBy != LegGap || third.ClosedBy != "" || third.Out == nil || third.MsgCount != 2 {
+		t.Errorf("third leg = %+v", third)
+	}
+
+	legs, err = tracker.GetLegs("4CA7B5", at(100), at(120))
+	if err != nil || len(legs) != 1 || legs[0].FlightNumber != "EIN154" {
+		t.Errorf("GetLegs(range) = %+v, %v", legs, err)
+	}
+}
+
+func TestLegOpenedByOutAfterOn(t *testing.T) {
+	tracker := newTestTracker(t, ":memory:")
+	at := func(min int) time.Time { return replayStart.Add(time.Duration(min) * time.Minute) }
+
+	// Without flight numbers, the out event after landing opens the next leg.
+	for i, ev := range []string{EventOff, EventOn, "", EventOut} {
+		tracker.UpdateFlight(FlightUpdate{ICAOHex: "3C65AD", Event: ev, Time: at(i * 30)})
+	}
+	legs, err := tracker.GetLegs("3C65AD", time.Time{}, time.Time{})
+	if err != nil || len(legs) != 2 {
+		t.Fatalf("GetLegs = %+v, %v", legs, err)
+	}
+	if legs[0].ClosedBy != EventOn || legs[0].MsgCount != 3 || legs[1].OpenedBy != EventOut {
+		t.Errorf("legs = %+v, %+v", legs[0], legs[1])
+	}
+}
+
+func TestExtractAndUpdateOOOILabel(t *testing.T) {
+	tracker := newTestTracker(t, ":memory:")
+	ExtractAndUpdate(tracker, &acars.Message{
+		ID:        9,
+		Timestamp: "2026-05-12T16:54:24Z",
+		Label:     "QQ",
+		Airframe:  &acars.Airframe{ICAO: "4CA7B5", Tail: "EI-DEO"},
+	}, nil)
+	legs, err := tracker.GetLegs("EI-DEO", time.Time{}, time.Time{})
+	if err != nil || len(legs) != 1 || legs[0].Off == nil || legs[0].Messages[0].Label != "QQ" || legs[0].Messages[0].ID != 9 {
+		t.Errorf("GetLegs = %+v, %v", legs, err)
+	}
+}
@@ -86,6 +86,48 @@ type FlightState struct {
 	MsgCount     int       `json:"msg_count"`
 }
 
+// FlightLeg is one flight of an aircraft: from the message that opened it,
+// such as the first after a new flight number or an OOOI out event, to an
+// on or in event, a silence of LegIdle or the next leg.
+type FlightLeg struct {
+	ID           int64      `json:"id"`
+	AircraftKey  string     `json:"aircraft_key"` // As FlightState.Key.
+	ICAOHex      string     `json:"icao_hex,omitempty"`
+	Registration string     `json:"registration,omitempty"`
+	FlightNumber string     `json:"flight_number,omitempty"`
+	Origin       string     `json:"origin,omitempty"`
+	Destination  string     `json:"destination,omitempty"`
+	OpenedBy     string     `json:"opened_by"`           // LegFirst, LegGap, LegFlight, LegOrigin, EventOut or EventOff.
+	ClosedBy     string     `json:"closed_by,omitempty"` // EventOn, EventIn, LegTimeout or LegNext; empty while open.
+	Out          *time.Time `json:"out,omitempty"`       // Times of the messages reporting the OOOI events.
+	Off          *time.Time `json:"off,omitempty"`
+	On           *time.Time `json:"on,omitempty"`
+	In           *time.Time `json:"in,omitempty"`
+	FirstSeen    time.Time  `json:"first_seen"`
+	LastSeen     time.Time  `json:"last_seen"`
+	MsgCount     int        `json:"msg_count"`
+
+	Messages []LegMessage `json:"messages,omitempty"` // In time order.
+	Track    []TrackPoint `json:"track,omitempty"`    // In time order.
+}
+
+// LegMessage is a message of a flight leg.
+type LegMessage struct {
+	ID    int64     `json:"id"` // 0 when the feed gave none.
+	Label string    `json:"label,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// TrackPoint is a reported position.
+type TrackPoint struct {
+	Time      time.Time `json:"time"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Altitude  int       `json:"altitude,omitempty"` // Feet.
+	Source    string    `json:"source,omitempty"`   // Result type that reported it.
+	MessageID int64     `json:"message_id"`
+}
+
 // HasPosition returns true if the flight state has valid position data.
 func (f *FlightState) HasPosition() bool {
 	return f.Latitude != 0 || f.Longitude != 0
@@ -126,4 +126,50 @@ CREATE TABLE IF NOT EXISTS flight_state (
 
 CREATE INDEX IF NOT EXISTS idx_flight_state_flight ON flight_state(flight_number);
 CREATE INDEX IF NOT EXISTS idx_flight_state_last_seen ON flight_state(last_seen);
+
+-- History: Flight legs, one per flight of an aircraft.
+CREATE TABLE IF NOT EXISTS flight_legs (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	aircraft_key  TEXT NOT NULL,  -- flight_state key: ICAO hex or registration.
+	icao_hex      TEXT,
+	registration  TEXT,
+	flight_number TEXT,
+	origin        TEXT,
+	destination   TEXT,
+	opened_by     TEXT NOT NULL,  -- first, gap, flight, origin, out or off.
+	closed_by     TEXT,           -- on, in, timeout or next; NULL while open.
+	out_time      DATETIME,
+	off_time      DATETIME,
+	on_time       DATETIME,
+	in_time       DATETIME,
+	first_seen    DATETIME NOT NULL,
+	last_seen     DATETIME NOT NULL,
+	msg_count     INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_flight_legs_aircraft ON flight_legs(aircraft_key, first_seen);
+CREATE INDEX IF NOT EXISTS idx_flight_legs_registration ON flight_legs(registration, first_seen);
+
+-- History: Messages of each flight leg.
+CREATE TABLE IF NOT EXISTS leg_messages (
+	leg_id     INTEGER NOT NULL REFERENCES flight_legs(id) ON DELETE CASCADE,
+	message_id INTEGER NOT NULL,  -- 0 when the feed gives none.
+	label      TEXT,
+	sent_at    DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_leg_messages_leg ON leg_messages(leg_id, sent_at);
+
+-- History: Position track of each flight leg.
+CREATE TABLE IF NOT EXISTS leg_positions (
+	leg_id     INTEGER NOT NULL REFERENCES flight_legs(id) ON DELETE CASCADE,
+	message_id  INTEGER NOT NULL,
+	reported_at DATETIME NOT NULL,
+	latitude    REAL NOT NULL,
+	longitude   REAL NOT NULL,
+	altitude    INTEGER,  -- Feet.
+	source      TEXT      -- Result type that reported the position.
+);
+
+CREATE INDEX IF NOT EXISTS idx_leg_positions_leg ON leg_positions(leg_id, reported_at);
 `
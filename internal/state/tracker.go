@@ -17,6 +17,15 @@ type Tracker struct {
 	// In-memory flight state cache for fast access.
 	flights map[string]*FlightState
 
+	// clock, when set, gives the current time.  Otherwise the tracker runs
+	// on event time: latest is the newest message time seen.
+	clock  func() time.Time
+	latest time.Time
+
+	// legs holds the latest flight leg of each aircraft heard within
+	// LegIdle, by flight state key.
+	legs map[string]*FlightLeg
+
 	// Callbacks for change notifications.
 	onAircraftNew func(*Aircraft)
 	onWaypointNew func(*Waypoint)
@@ -49,6 +58,7 @@ func NewTracker(dbPath string) (*Tracker, error) {
 	t := &Tracker{
 		db:      db,
 		flights: make(map[string]*FlightState),
+		legs:    make(map[string]*FlightLeg),
 	}
 
 	// Load existing flight states into memory.
@@ -56,10 +66,76 @@ func NewTracker(dbPath string) (*Tracker, error) {
 		_ = db.Close()
 		return nil, err
 	}
+	if err := t.loadLegs(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
 	return t, nil
 }
 
+// timeLayout is how times are written to the database: in UTC and fixed
+// width, so that SQL comparisons, MIN and MAX order them, and in a form
+// SQLite's date functions read.
+const timeLayout = "2006-01-02 15:04:05.000"
+
+// dbTime formats t for the database.
+func dbTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// SetClock sets the clock the tracker reads for updates that carry no time
+// and to judge which flights are active or stale.  Without one the tracker
+// runs on event time: the newest message time it has seen, or the wall
+// clock until it has seen one.  Replays of old logs need event time; a live
+// feed may use time.Now so that flights age out while the feed is quiet.
+func (t *Tracker) SetClock(now func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = now
+}
+
+// Now returns the tracker's current time.
+func (t *Tracker) Now() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.now()
+}
+
+func (t *Tracker) now() time.Time {
+	if t.clock != nil {
+		return t.clock()
+	}
+	if !t.latest.IsZero() {
+		return t.latest
+	}
+	return time.Now()
+}
+
+// maxClockSkew is how far ahead of the wall clock a message time may be.
+// A later one is a garbled timestamp: taken as event time, it would hold
+// every real message after it back as late.
+const maxClockSkew = 24 * time.Hour
+
+// plausible reports whether at is not beyond maxClockSkew past the wall
+// clock.
+func plausible(at time.Time) bool {
+	return !at.After(time.Now().Add(maxClockSkew))
+}
+
+// eventTime returns the time to stamp on an update made at at, or the
+// tracker's clock when at is zero or not plausible, and advances event time.
+// The caller holds t.mu.
+func (t *Tracker) eventTime(at time.Time) time.Time {
+	if at.IsZero() || !plausible(at) {
+		return t.now()
+	}
+	if at.After(t.latest) {
+		t.latest = at
+	}
+	return at
+}
+
 // Close closes the database connection.
 func (t *Tracker) Close() error {
 	return t.db.Close()
@@ -85,20 +161,45 @@ func (t *Tracker) OnATISChanged(fn func(*ATIS)) {
 	t.onATISChanged = fn
 }
 
-// loadFlightStates loads existing flight states from the database into memory.
+// loadFlightStates loads the flight states seen within an hour of the newest
+// one from the database into memory, and starts event time at the newest.
+// Measuring from the data rather than the wall clock keeps a replayed day
+// resumable.  States last seen beyond a plausible time, saved from a
+// garbled timestamp, are left in the database.
 func (t *Tracker) loadFlightStates() error {
+	flights, err := t.queryFlightStates("")
+	if err != nil {
+		return err
+	}
+	for _, fs := range flights {
+		if fs.LastSeen.After(t.latest) && plausible(fs.LastSeen) {
+			t.latest = fs.LastSeen
+		}
+	}
+	cutoff := t.latest.Add(-time.Hour)
+	for _, fs := range flights {
+		if fs.LastSeen.After(cutoff) && plausible(fs.LastSeen) {
+			t.flights[fs.Key] = fs
+		}
+	}
+	return nil
+}
+
+// queryFlightStates reads flight states from the database; tail is the SQL
+// after the FROM clause, such as a WHERE or ORDER BY.
+func (t *Tracker) queryFlightStates(tail string, args ...any) ([]*FlightState, error) {
 	rows, err := t.db.Query(`
 		SELECT key, icao_hex, registration, flight_number, origin, destination, report_time,
 		       latitude, longitude, altitude, ground_speed, track, waypoints,
 		       first_seen, last_seen, msg_count
 		FROM flight_state
-		WHERE last_seen > datetime('now', '-1 hour')
-	`)
+		`+tail, args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
+	var result []*FlightState
 	for rows.Next() {
 		var fs FlightState
 		var icaoHex, reg, flight, origin, dest, reportTime sql.NullString
@@ -131,14 +232,19 @@ func (t *Tracker) loadFlightStates() error {
 			_ = json.Unmarshal([]byte(waypoints.String), &fs.Waypoints)
 		}
 
-		t.flights[fs.Key] = &fs
+		result = append(result, &fs)
 	}
 
-	return rows.Err()
+	return result, rows.Err()
 }
 
 // UpdateFlight updates the flight state with new information.
 // Returns true if this is a new flight (flight number changed).
+//
+// The update is stamped with update.Time.  An update older than the flight's
+// last message never rolls the state back: it only fills in fields that are
+// still empty, and one carrying a different flight number belongs to an
+// earlier flight and leaves the state alone.
 func (t *Tracker) UpdateFlight(update FlightUpdate) (*FlightState, bool) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -152,65 +258,72 @@ func (t *Tracker) UpdateFlight(update FlightUpdate) (*FlightState, bool) {
 		return nil, false
 	}
 
-	now := time.Now()
+	at := t.eventTime(update.Time)
 	isNewFlight := false
+	t.updateLeg(key, update, at)
 
 	fs, exists := t.flights[key]
 	if !exists {
 		fs = &FlightState{
 			Key:       key,
-			FirstSeen: now,
+			FirstSeen: at,
+			LastSeen:  at,
 		}
 		t.flights[key] = fs
 		isNewFlight = true
 	}
+	late := at.Before(fs.LastSeen)
 
 	// Check if the flight number changed (indicates new flight).
 	if update.FlightNumber != "" && fs.FlightNumber != "" && update.FlightNumber != fs.FlightNumber {
+		if late {
+			// A late message from an earlier flight still tells us about
+			// the aircraft and the route it flew.
+			t.updateReference(update, at)
+			return fs, false
+		}
+
 		// Reset flight-specific data for the new flight.
 		fs.Origin = ""
 		fs.Destination = ""
 		fs.Waypoints = nil
-		fs.FirstSeen = now
+		fs.FirstSeen = at
 		fs.MsgCount = 0
 		isNewFlight = true
 	}
 
-	// Update identity.
-	if update.ICAOHex != "" {
-		fs.ICAOHex = update.ICAOHex
-	}
-	if update.Registration != "" {
-		fs.Registration = update.Registration
-	}
-	if update.FlightNumber != "" {
-		fs.FlightNumber = update.FlightNumber
+	// set replaces *field with v, but a late update only fills it in.
+	set := func(field *string, v string) {
+		if v != "" && (!late || *field == "") {
+			*field = v
+		}
 	}
 
-	// Update route.
-	if update.Origin != "" {
-		fs.Origin = update.Origin
-	}
-	if update.Destination != "" {
-		fs.Destination = update.Destination
-	}
-	if update.ReportTime != "" {
-		fs.ReportTime = update.ReportTime
-	}
+	// Update identity.
+	set(&fs.ICAOHex, update.ICAOHex)
+	set(&fs.Registration, update.Registration)
+	set(&fs.FlightNumber, update.FlightNumber)
 
-	// Update position (only if non-zero).
-	if update.Latitude != 0 || update.Longitude != 0 {
-		fs.Latitude = update.Latitude
-		fs.Longitude = update.Longitude
-	}
-	if update.Altitude != 0 {
-		fs.Altitude = update.Altitude
-	}
-	if update.GroundSpeed != 0 {
-		fs.GroundSpeed = update.GroundSpeed
-	}
-	if update.Track != 0 {
-		fs.Track = update.Track
+	// Update route.
+	set(&fs.Origin, update.Origin)
+	set(&fs.Destination, update.Destination)
+	set(&fs.ReportTime, update.ReportTime)
+
+	// Update position (only if non-zero, and not from a late message).
+	if !late {
+		if update.Latitude != 0 || update.Longitude != 0 {
+			fs.Latitude = update.Latitude
+			fs.Longitude = update.Longitude
+		}
+		if update.Altitude != 0 {
+			fs.Altitude = update.Altitude
+		}
+		if update.GroundSpeed != 0 {
+			fs.GroundSpeed = update.GroundSpeed
+		}
+		if update.Track != 0 {
+			fs.Track = update.Track
+		}
 	}
 
 	// Add waypoint if provided.
@@ -218,28 +331,46 @@ func (t *Tracker) UpdateFlight(update FlightUpdate) (*FlightState, bool) {
 		fs.AddWaypoint(update.Waypoint)
 	}
 
-	fs.LastSeen = now
+	if at.Before(fs.FirstSeen) {
+		fs.FirstSeen = at
+	}
+	if !late {
+		fs.LastSeen = at
+	}
 	fs.MsgCount++
 
 	// Persist to database.
 	t.saveFlightState(fs)
 
-	// Update reference data if we have enough info.
-	if fs.ICAOHex != "" && fs.Registration != "" {
-		t.updateAircraft(fs.ICAOHex, fs.Registration, update.TypeCode, update.Operator)
-	}
-
 	// Update route patterns if we have full route info.
 	// IMPORTANT: Only create routes when the current update includes a flight number.
 	// This prevents stale flight numbers from being associated with new routes when
 	// a message has origin/destination but no flight number (e.g., partial FPN messages).
-	if update.FlightNumber != "" && fs.Origin != "" && fs.Destination != "" {
-		t.updateRoute(fs.FlightNumber, fs.Origin, fs.Destination, fs.Registration)
+	ref := update
+	ref.ICAOHex = fs.ICAOHex
+	ref.Registration = fs.Registration
+	ref.Origin, ref.Destination = "", ""
+	if update.FlightNumber != "" {
+		ref.FlightNumber = fs.FlightNumber
+		ref.Origin, ref.Destination = fs.Origin, fs.Destination
 	}
+	t.updateReference(ref, at)
 
 	return fs, isNewFlight
 }
 
+// updateReference updates the aircraft and route tables from the identity
+// and route in update.
+func (t *Tracker) updateReference(update FlightUpdate, at time.Time) {
+	// Update reference data if we have enough info.
+	if update.ICAOHex != "" && update.Registration != "" {
+		t.updateAircraft(update.ICAOHex, update.Registration, update.TypeCode, update.Operator, at)
+	}
+	if update.FlightNumber != "" && update.Origin != "" && update.Destination != "" {
+		t.updateRoute(update.FlightNumber, update.Origin, update.Destination, update.Registration, at)
+	}
+}
+
 // FlightUpdate contains data to update a flight state.
 type FlightUpdate struct {
 	ICAOHex      string
@@ -256,6 +387,20 @@ type FlightUpdate struct {
 	Waypoint     string
 	TypeCode     string
 	Operator     string
+
+	// Time is when the message was sent.  Zero means now by the tracker's
+	// clock.
+	Time time.Time
+
+	// MessageID and Label identify the message in its flight leg, and
+	// Event is the OOOI event it reports (EventOut and so on), if any.
+	MessageID int64
+	Label     string
+	Event     string
+
+	// Positions are the positions reported by the results of the message,
+	// for the track.  Without any, Latitude and Longitude are used.
+	Positions []TrackPoint
 }
 
 // saveFlightState persists a flight state to the database.
@@ -280,25 +425,34 @@ func (t *Tracker) saveFlightState(fs *FlightState) {
 			ground_speed = excluded.ground_speed,
 			track = excluded.track,
 			waypoints = excluded.waypoints,
+			first_seen = excluded.first_seen,
 			last_seen = excluded.last_seen,
 			msg_count = excluded.msg_count
 	`,
 		fs.Key, fs.ICAOHex, fs.Registration, fs.FlightNumber, fs.Origin, fs.Destination, fs.ReportTime,
 		fs.Latitude, fs.Longitude, fs.Altitude, fs.GroundSpeed, fs.Track, string(waypoints),
-		fs.FirstSeen, fs.LastSeen, fs.MsgCount,
+		dbTime(fs.FirstSeen), dbTime(fs.LastSeen), fs.MsgCount,
 	)
 	// Silently ignore errors - flight state is best-effort.
 	_ = err
 }
 
 func ensureFlightStateColumns(db *sql.DB) error {
-	rows, err := db.Query("PRAGMA table_info(flight_state)")
+	if err := ensureColumn(db, "flight_state", "report_time", "TEXT"); err != nil {
+		return err
+	}
+	return ensureColumn(db, "leg_positions", "source", "TEXT")
+}
+
+// ensureColumn adds column to table in databases created before it existed.
+func ensureColumn(db *sql.DB, table, column, decl string) error {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
 	if err != nil {
 		return err
 	}
 	defer func() { _ = rows.Close() }()
 
-	hasReportTime := false
+	hasColumn := false
 	for rows.Next() {
 		var cid int
 		var name string
@@ -309,23 +463,23 @@ func ensureFlightStateColumns(db *sql.DB) error {
 		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
 			return err
 		}
-		if name == "report_time" {
-			hasReportTime = true
+		if name == column {
+			hasColumn = true
 		}
 	}
 	if err := rows.Err(); err != nil {
 		return err
 	}
-	if hasReportTime {
+	if hasColumn {
 		return nil
 	}
 
-	_, err = db.Exec("ALTER TABLE flight_state ADD COLUMN report_time TEXT")
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + decl)
 	return err
 }
 
 // updateAircraft updates or inserts an aircraft record.
-func (t *Tracker) updateAircraft(icaoHex, registration, typeCode, operator string) {
+func (t *Tracker) updateAircraft(icaoHex, registration, typeCode, operator string, at time.Time) {
 	// Check if this is a new aircraft.
 	var exists bool
 	_ = t.db.QueryRow("SELECT 1 FROM aircraft WHERE icao_hex = ?", icaoHex).Scan(&exists)
@@ -333,9 +487,9 @@ func (t *Tracker) updateAircraft(icaoHex, registration, typeCode, operator strin
 	if !exists {
 		// New aircraft - insert and trigger callback.
 		_, err := t.db.Exec(`
-			INSERT INTO aircraft (icao_hex, registration, type_code, operator)
-			VALUES (?, ?, ?, ?)
-		`, icaoHex, registration, typeCode, operator)
+			INSERT INTO aircraft (icao_hex, registration, type_code, operator, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, icaoHex, registration, typeCode, operator, dbTime(at), dbTime(at))
 
 		if err == nil && t.onAircraftNew != nil {
 			t.onAircraftNew(&Aircraft{
@@ -343,8 +497,8 @@ func (t *Tracker) updateAircraft(icaoHex, registration, typeCode, operator strin
 				Registration: registration,
 				TypeCode:     typeCode,
 				Operator:     operator,
-				FirstSeen:    time.Now(),
-				LastSeen:     time.Now(),
+				FirstSeen:    at,
+				LastSeen:     at,
 				MsgCount:     1,
 			})
 		}
@@ -355,15 +509,16 @@ func (t *Tracker) updateAircraft(icaoHex, registration, typeCode, operator strin
 				registration = COALESCE(NULLIF(?, ''), registration),
 				type_code = COALESCE(NULLIF(?, ''), type_code),
 				operator = COALESCE(NULLIF(?, ''), operator),
-				last_seen = CURRENT_TIMESTAMP,
+				first_seen = MIN(first_seen, ?),
+				last_seen = MAX(last_seen, ?),
 				msg_count = msg_count + 1
 			WHERE icao_hex = ?
-		`, registration, typeCode, operator, icaoHex)
+		`, registration, typeCode, operator, dbTime(at), dbTime(at), icaoHex)
 	}
 }
 
 // updateRoute updates or inserts a route pattern.
-func (t *Tracker) updateRoute(flightNumber, origin, dest, registration string) {
+func (t *Tracker) updateRoute(flightNumber, origin, dest, registration string, at time.Time) {
 	// Extract flight pattern (airline code + number, e.g., "QF1" from "QFA1").
 	pattern := flightNumber
 
@@ -378,16 +533,16 @@ func (t *Tracker) updateRoute(flightNumber, origin, dest, registration string) {
 	case sql.ErrNoRows:
 		// New route - insert and trigger callback.
 		result, err := t.db.Exec(`
-			INSERT INTO routes (flight_pattern, origin_icao, dest_icao)
-			VALUES (?, ?, ?)
-		`, pattern, origin, dest)
+			INSERT INTO routes (flight_pattern, origin_icao, dest_icao, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?)
+		`, pattern, origin, dest, dbTime(at), dbTime(at))
 
 		if err == nil {
 			newID, _ := result.LastInsertId()
 
 			// Add the aircraft to the junction table.
 			if registration != "" {
-				t.updateRouteAircraft(newID, registration)
+				t.updateRouteAircraft(newID, registration, at)
 			}
 
 			if t.onRouteNew != nil {
@@ -397,8 +552,8 @@ func (t *Tracker) updateRoute(flightNumber, origin, dest, registration string) {
 					OriginICAO:       origin,
 					DestICAO:         dest,
 					ObservationCount: 1,
-					FirstSeen:        time.Now(),
-					LastSeen:         time.Now(),
+					FirstSeen:        at,
+					LastSeen:         at,
 				})
 			}
 		}
@@ -407,14 +562,15 @@ func (t *Tracker) updateRoute(flightNumber, origin, dest, registration string) {
 		_, _ = t.db.Exec(`
 			UPDATE routes SET
 				observation_count = observation_count + 1,
-				last_seen = CURRENT_TIMESTAMP,
+				first_seen = MIN(first_seen, ?),
+				last_seen = MAX(last_seen, ?),
 				synced_at = NULL
 			WHERE id = ?
-		`, id)
+		`, dbTime(at), dbTime(at), id)
 
 		// Update the aircraft junction table.
 		if registration != "" {
-			t.updateRouteAircraft(id, registration)
+			t.updateRouteAircraft(id, registration, at)
 		}
 	default:
 		// Silently ignore query errors.
@@ -422,26 +578,35 @@ func (t *Tracker) updateRoute(flightNumber, origin, dest, registration string) {
 }
 
 // updateRouteAircraft updates or inserts an aircraft observation for a route.
-func (t *Tracker) updateRouteAircraft(routeID int64, registration string) {
+func (t *Tracker) updateRouteAircraft(routeID int64, registration string, at time.Time) {
 	_, err := t.db.Exec(`
-		INSERT INTO route_aircraft (route_id, registration)
-		VALUES (?, ?)
+		INSERT INTO route_aircraft (route_id, registration, first_seen, last_seen)
+		VALUES (?, ?, ?, ?)
 		ON CONFLICT(route_id, registration) DO UPDATE SET
 			observation_count = observation_count + 1,
-			last_seen = CURRENT_TIMESTAMP
-	`, routeID, registration)
+			first_seen = MIN(first_seen, excluded.first_seen),
+			last_seen = MAX(last_seen, excluded.last_seen)
+	`, routeID, registration, dbTime(at), dbTime(at))
 	// Silently ignore errors - route aircraft tracking is best-effort.
 	_ = err
 }
 
-// UpdateWaypoint records a waypoint with coordinates.
+// UpdateWaypoint records a waypoint with coordinates, seen now by the
+// tracker's clock.
 func (t *Tracker) UpdateWaypoint(name string, lat, lon float64) {
+	t.UpdateWaypointAt(name, lat, lon, time.Time{})
+}
+
+// UpdateWaypointAt records a waypoint with coordinates, seen in a message
+// sent at at.
+func (t *Tracker) UpdateWaypointAt(name string, lat, lon float64, at time.Time) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	if name == "" || (lat == 0 && lon == 0) {
 		return
 	}
+	at = t.eventTime(at)
 
 	// Check if waypoint exists.
 	var exists bool
@@ -450,9 +615,9 @@ func (t *Tracker) UpdateWaypoint(name string, lat, lon float64) {
 	if !exists {
 		// New waypoint.
 		_, err := t.db.Exec(`
-			INSERT INTO waypoints (name, latitude, longitude)
-			VALUES (?, ?, ?)
-		`, name, lat, lon)
+			INSERT INTO waypoints (name, latitude, longitude, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?)
+		`, name, lat, lon, dbTime(at), dbTime(at))
 
 		if err == nil && t.onWaypointNew != nil {
 			t.onWaypointNew(&Waypoint{
@@ -460,8 +625,8 @@ func (t *Tracker) UpdateWaypoint(name string, lat, lon float64) {
 				Latitude:    lat,
 				Longitude:   lon,
 				SourceCount: 1,
-				FirstSeen:   time.Now(),
-				LastSeen:    time.Now(),
+				FirstSeen:   at,
+				LastSeen:    at,
 			})
 		}
 	} else {
@@ -469,13 +634,16 @@ func (t *Tracker) UpdateWaypoint(name string, lat, lon float64) {
 		_, _ = t.db.Exec(`
 			UPDATE waypoints SET
 				source_count = source_count + 1,
-				last_seen = CURRENT_TIMESTAMP
+				first_seen = MIN(first_seen, ?),
+				last_seen = MAX(last_seen, ?)
 			WHERE name = ?
-		`, name)
+		`, dbTime(at), dbTime(at), name)
 	}
 }
 
-// UpdateATIS updates the current ATIS for an airport.
+// UpdateATIS updates the current ATIS for an airport.  atis.UpdatedAt is
+// the time the ATIS was received; zero means now by the tracker's clock.  An
+// ATIS older than the airport's current one is ignored.
 func (t *Tracker) UpdateATIS(atis *ATIS) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -483,6 +651,7 @@ func (t *Tracker) UpdateATIS(atis *ATIS) {
 	if atis.AirportICAO == "" || atis.Letter == "" {
 		return
 	}
+	atis.UpdatedAt = t.eventTime(atis.UpdatedAt)
 
 	// Check if ATIS changed.
 	var currentLetter sql.NullString
@@ -493,10 +662,10 @@ func (t *Tracker) UpdateATIS(atis *ATIS) {
 	remarksJSON, _ := json.Marshal(atis.Remarks)
 
 	// Update or insert current ATIS.
-	_, err := t.db.Exec(`
+	result, err := t.db.Exec(`
 		INSERT INTO atis_current (airport_icao, letter, atis_type, atis_time, raw_text, runways, approaches,
-		                          wind, visibility, clouds, temperature, dew_point, qnh, remarks)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                          wind, visibility, clouds, temperature, dew_point, qnh, remarks, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(airport_icao) DO UPDATE SET
 			letter = excluded.letter,
 			atis_type = excluded.atis_type,
@@ -511,30 +680,35 @@ func (t *Tracker) UpdateATIS(atis *ATIS) {
 			dew_point = excluded.dew_point,
 			qnh = excluded.qnh,
 			remarks = excluded.remarks,
-			updated_at = CURRENT_TIMESTAMP,
+			updated_at = excluded.updated_at,
 			synced_at = NULL
+		WHERE excluded.updated_at >= atis_current.updated_at
 	`,
 		atis.AirportICAO, atis.Letter, atis.ATISType, atis.ATISTime, atis.RawText,
 		string(runwaysJSON), string(approachesJSON),
 		atis.Wind, atis.Visibility, atis.Clouds, atis.Temperature, atis.DewPoint,
-		atis.QNH, string(remarksJSON),
+		atis.QNH, string(remarksJSON), dbTime(atis.UpdatedAt),
 	)
 
 	if err != nil {
 		return
 	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		// Older than the current ATIS.
+		return
+	}
 
 	// If the letter changed, archive to history and trigger callback.
 	if !currentLetter.Valid || currentLetter.String != atis.Letter {
 		_, _ = t.db.Exec(`
 			INSERT INTO atis_history (airport_icao, letter, atis_type, atis_time, raw_text, runways, approaches,
-			                          wind, visibility, clouds, temperature, dew_point, qnh, remarks)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			                          wind, visibility, clouds, temperature, dew_point, qnh, remarks, recorded_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
 			atis.AirportICAO, atis.Letter, atis.ATISType, atis.ATISTime, atis.RawText,
 			string(runwaysJSON), string(approachesJSON),
 			atis.Wind, atis.Visibility, atis.Clouds, atis.Temperature, atis.DewPoint,
-			atis.QNH, string(remarksJSON),
+			atis.QNH, string(remarksJSON), dbTime(atis.UpdatedAt),
 		)
 
 		if t.onATISChanged != nil {
@@ -562,12 +736,13 @@ func (t *Tracker) GetAllFlights() []*FlightState {
 	return result
 }
 
-// GetActiveFlights returns flights seen within the given duration.
+// GetActiveFlights returns flights seen within the given duration of the
+// tracker's current time.
 func (t *Tracker) GetActiveFlights(within time.Duration) []*FlightState {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	cutoff := time.Now().Add(-within)
+	cutoff := t.now().Add(-within)
 	result := make([]*FlightState, 0)
 	for _, fs := range t.flights {
 		if fs.LastSeen.After(cutoff) {
@@ -577,12 +752,13 @@ func (t *Tracker) GetActiveFlights(within time.Duration) []*FlightState {
 	return result
 }
 
-// CleanupStale removes flight states older than the given duration.
+// CleanupStale removes flight states not seen within the given duration of
+// the tracker's current time.
 func (t *Tracker) CleanupStale(olderThan time.Duration) int {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	cutoff := time.Now().Add(-olderThan)
+	cutoff := t.now().Add(-olderThan)
 	removed := 0
 
 	for key, fs := range t.flights {
@@ -593,7 +769,11 @@ func (t *Tracker) CleanupStale(olderThan time.Duration) int {
 	}
 
 	// Also cleanup database.
-	_, _ = t.db.Exec("DELETE FROM flight_state WHERE last_seen < ?", cutoff)
+	_, _ = t.db.Exec("DELETE FROM flight_state WHERE last_seen < ?", dbTime(cutoff))
+
+	// Flight legs are history and stay in the database; only idle ones
+	// are closed.
+	t.closeIdleLegs(t.now())
 
 	return removed
 }
@@ -0,0 +1,175 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"acars_parser/internal/acars"
+)
+
+var replayStart = time.Date(2026, 5, 12, 16, 54, 24, 0, time.UTC)
+
+func newTestTracker(t *testing.T, path string) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker(path)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	t.Cleanup(func() { _ = tracker.Close() })
+	return tracker
+}
+
+func TestUpdateFlightEventTime(t *testing.T) {
+	tracker := newTestTracker(t, ":memory:")
+	at := func(min int) time.Time { return replayStart.Add(time.Duration(min) * time.Minute) }
+
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", Registration: "EI-DEO", FlightNumber: "EIN123", Latitude: 53.4, Longitude: -6.2, Time: at(10)})
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", FlightNumber: "EIN123", Latitude: 53.5, Longitude: -5.9, Time: at(20)})
+
+	// A late message only fills in what is missing.
+	fs, isNew := tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", FlightNumber: "EIN123", Origin: "EIDW", Latitude: 53.0, Longitude: -6.0, Time: at(5)})
+	if isNew || fs.Origin != "EIDW" || fs.Latitude != 53.5 || !fs.FirstSeen.Equal(at(5)) || !fs.LastSeen.Equal(at(20)) {
+		t.Fatalf("after late message: new %v, %+v", isNew, fs)
+	}
+
+	// A late message from the previous flight does not start a new one.
+	fs, isNew = tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", FlightNumber: "EIN122", Origin: "EGLL", Destination: "EIDW", Time: at(1)})
+	if isNew || fs.FlightNumber != "EIN123" || fs.Origin != "EIDW" || fs.Destination != "" {
+		t.Fatalf("after previous flight: new %v, %+v", isNew, fs)
+	}
+
+	// A newer message with another flight number does.
+	fs, isNew = tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", FlightNumber: "EIN124", Time: at(90)})
+	if !isNew || fs.Origin != "" || !fs.FirstSeen.Equal(at(90)) {
+		t.Fatalf("after next flight: new %v, %+v", isNew, fs)
+	}
+
+	if got := tracker.Now(); !got.Equal(at(90)) {
+		t.Errorf("Now = %v, want %v", got, at(90))
+	}
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "3C65AD", Registration: "D-AIMM", Time: at(0)})
+	if n := len(tracker.GetActiveFlights(time.Hour)); n != 1 {
+		t.Errorf("active flights = %d, want 1", n)
+	}
+	if n := tracker.CleanupStale(time.Hour); n != 1 || tracker.GetFlight("3C65AD") != nil {
+		t.Errorf("CleanupStale removed %d", n)
+	}
+
+	d, err := tracker.Dump()
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	if len(d.Aircraft) != 2 || d.Aircraft[1].ICAOHex != "4CA7B5" || !d.Aircraft[1].FirstSeen.Equal(at(5)) || !d.Aircraft[1].LastSeen.Equal(at(90)) {
+		t.Errorf("aircraft = %+v", d.Aircraft)
+	}
+	if len(d.Routes) != 1 || d.Routes[0].FlightPattern != "EIN122" || !d.Routes[0].FirstSeen.Equal(at(1)) {
+		t.Errorf("routes = %+v", d.Routes)
+	}
+}
+
+func TestUpdateFlightIgnoresFutureTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	tracker, err := NewTracker(path)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	at := func(min int) time.Time { return replayStart.Add(time.Duration(min) * time.Minute) }
+
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", Latitude: 53.4, Longitude: -6.2, Time: at(10)})
+	// A garbled timestamp years ahead does not move event time.
+	fs, _ := tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", Time: time.Now().AddDate(10, 0, 0)})
+	if !fs.LastSeen.Equal(at(10)) {
+		t.Fatalf("LastSeen after future time = %v, want %v", fs.LastSeen, at(10))
+	}
+	fs, _ = tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", Latitude: 53.5, Longitude: -5.9, Time: at(20)})
+	if fs.Latitude != 53.5 || !fs.LastSeen.Equal(at(20)) {
+		t.Fatalf("after future time: %+v", fs)
+	}
+	if got := tracker.Now(); !got.Equal(at(20)) {
+		t.Errorf("Now = %v, want %v", got, at(20))
+	}
+
+	// A state saved with such a time before the fix is not restored.
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "3C65AD", Time: at(20)})
+	res, err := tracker.db.Exec(`UPDATE flight_state SET last_seen = ? WHERE key = ?`, dbTime(time.Now().AddDate(10, 0, 0)), "3C65AD")
+	if err != nil {
+		t.Fatalf("corrupting last_seen: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("corrupted %d flight states, want 1", n)
+	}
+	_ = tracker.Close()
+	tracker = newTestTracker(t, path)
+	if tracker.GetFlight("3C65AD") != nil || tracker.GetFlight("4CA7B5") == nil {
+		t.Errorf("loaded flights = %+v", tracker.GetAllFlights())
+	}
+	if got := tracker.Now(); !got.Equal(at(20)) {
+		t.Errorf("Now after reload = %v, want %v", got, at(20))
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	tracker := newTestTracker(t, ":memory:")
+	wall := replayStart.Add(24 * time.Hour)
+	tracker.SetClock(func() time.Time { return wall })
+
+	fs, _ := tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5"})
+	if !fs.LastSeen.Equal(wall) {
+		t.Errorf("untimed update stamped %v, want %v", fs.LastSeen, wall)
+	}
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "3C65AD", Time: replayStart})
+	if n := len(tracker.GetActiveFlights(time.Hour)); n != 1 {
+		t.Errorf("active flights by the clock = %d, want 1", n)
+	}
+}
+
+func TestLoadFlightStatesEventTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	tracker, err := NewTracker(path)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "4CA7B5", Time: replayStart})
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "3C65AD", Time: replayStart.Add(3 * time.Hour)})
+	tracker.UpdateFlight(FlightUpdate{ICAOHex: "75044A", Time: replayStart.Add(150 * time.Minute)})
+	_ = tracker.Close()
+
+	// Reopened days later, the flights within an hour of the newest are
+	// still loaded.
+	tracker = newTestTracker(t, path)
+	if n := len(tracker.GetAllFlights()); n != 2 || tracker.GetFlight("4CA7B5") != nil {
+		t.Errorf("loaded %d flights", n)
+	}
+	if got := tracker.Now(); !got.Equal(replayStart.Add(3 * time.Hour)) {
+		t.Errorf("Now = %v", got)
+	}
+}
+
+func TestUpdateATISIgnoresOlder(t *testing.T) {
+	tracker := newTestTracker(t, ":memory:")
+	tracker.UpdateATIS(&ATIS{AirportICAO: "EIDW", Letter: "L", UpdatedAt: replayStart.Add(time.Hour)})
+	tracker.UpdateATIS(&ATIS{AirportICAO: "EIDW", Letter: "K", UpdatedAt: replayStart})
+
+	d, err := tracker.Dump()
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	if len(d.ATIS) != 1 || d.ATIS[0].Letter != "L" || !d.ATIS[0].UpdatedAt.Equal(replayStart.Add(time.Hour)) || len(d.ATISHistory) != 1 {
+		t.Errorf("atis = %+v, history %+v", d.ATIS, d.ATISHistory)
+	}
+}
+
+func TestExtractAndUpdateMessageTime(t *testing.T) {
+	for _, ts := range []string{"2026-05-12T16:54:24Z", "1778604864", "16:54:24 12-05-26 UTC"} {
+		tracker := newTestTracker(t, ":memory:")
+		ExtractAndUpdate(tracker, &acars.Message{
+			Timestamp: ts,
+			Airframe:  &acars.Airframe{ICAO: "4CA7B5", Tail: "EI-DEO"},
+		}, nil)
+		fs := tracker.GetFlight("4CA7B5")
+		if fs == nil || !fs.FirstSeen.Equal(replayStart) {
+			t.Errorf("%s: flight = %+v", ts, fs)
+		}
+	}
+}
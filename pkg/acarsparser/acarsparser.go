@@ -0,0 +1,486 @@
+// Package acarsparser is the Go API of acars_parser.  It decodes the input
+// formats the acars_parser command reads and runs the same parsers over the
+// messages:
+//
+//	p, err := acarsparser.New(acarsparser.Enable("adsc", "cpdlc"))
+//	...
+//	err = p.ParseReader(os.Stdin, func(rec acarsparser.Record) error {
+//		for _, r := range rec.Results {
+//			fmt.Println(r.Type())
+//		}
+//		return nil
+//	})
+//
+// Parse runs the parsers over one message, ParseLine over one JSON line and
+// ParseReader over a JSONL stream or a JAERO log.  Decode and DecodeJAERO
+// only decode.  Results can be written with encoding/json in the layout the
+// command writes, or type-switched on the aliases in results.go.
+//
+// Like the command, ParseReader joins multi-block ACARS messages and MIAM
+// transfers split over several JAERO blocks before parsing them; ParseLine
+// and Parse see one line or message at a time.
+package acarsparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"acars_parser/internal/acars"
+	"acars_parser/internal/decode"
+	"acars_parser/internal/enrich"
+	_ "acars_parser/internal/parsers" // register all parsers via init()
+	"acars_parser/internal/parsers/grokfile"
+	miampkg "acars_parser/internal/parsers/miam"
+	"acars_parser/internal/registry"
+)
+
+// Message and its parts are the decoded form of one ACARS message.
+type (
+	Message  = acars.Message
+	Airframe = acars.Airframe
+	Flight   = acars.Flight
+	Station  = acars.Station
+)
+
+// Result is what a parser made of a message.  Type() names the kind of
+// result, such as "position", "cpdlc" or "flight_plan".
+type Result = registry.Result
+
+// ParserError is returned in place of the result of a parser that panicked.
+type ParserError = registry.ErrorResult
+
+// Quality says how complete a result is; see QualityOf.
+type Quality = registry.Quality
+
+// Position is a position report in canonical units, whichever parser made
+// it; see PositionOf and PositionsOf.
+type (
+	Position = registry.Position
+	Wind     = registry.Wind
+)
+
+// Selection picks and reorders parsers by name or result type.
+type Selection = registry.Selection
+
+// Record is one decoded message and its results.
+type Record struct {
+	Message    *Message
+	Results    []Result
+	Reassembly *ReassemblyInfo // Set when Message was joined from several ACARS blocks.
+}
+
+// ReassemblyInfo describes a message joined from several ACARS blocks: its
+// message number, the blocks found and the sequence letters of known gaps.
+type ReassemblyInfo = decode.ReassemblyInfo
+
+// ErrNoMessage is returned by Decode for a JSON line without an ACARS
+// message.
+var ErrNoMessage = errors.New("no ACARS message found")
+
+// Option configures a Parser.
+type Option func(*config)
+
+type config struct {
+	sel          registry.Selection
+	formats      string
+	enrich       bool
+	enrichers    []string
+	blockTimeout time.Duration
+}
+
+// Enable runs only the named parsers, by parser name or result type.
+func Enable(names ...string) Option {
+	return func(c *config) { c.sel.Enable = append(c.sel.Enable, names...) }
+}
+
+// Disable does not run the named parsers, by parser name or result type.
+func Disable(names ...string) Option {
+	return func(c *config) { c.sel.Disable = append(c.sel.Disable, names...) }
+}
+
+// WithPriority changes the priority of one parser; lower runs first.
+func WithPriority(name string, priority int) Option {
+	return func(c *config) {
+		if c.sel.Priority == nil {
+			c.sel.Priority = make(map[string]int)
+		}
+		c.sel.Priority[name] = priority
+	}
+}
+
+// WithSelection applies sel, as read from a -parser-config file.  It is
+// merged with the other selection options.
+func WithSelection(sel Selection) Option {
+	return func(c *config) {
+		c.sel.Enable = append(c.sel.Enable, sel.Enable...)
+		c.sel.Disable = append(c.sel.Disable, sel.Disable...)
+		for name, prio := range sel.Priority {
+			WithPriority(name, prio)(c)
+		}
+	}
+}
+
+// WithFormats adds the parsers of the grok format files in dir, or of the
+// one file dir names.
+func WithFormats(dir string) Option {
+	return func(c *config) { c.formats = dir }
+}
+
+// WithEnrichment turns filling in the flight number, tail and airports of a
+// message from its text and results on or off.  It is on by default, as in
+// the command.
+func WithEnrichment(on bool) Option {
+	return func(c *config) { c.enrich = on }
+}
+
+// WithEnrichers runs only the named enrichers, in the order given, as the
+// command's -enrich flag does.  See Enrichers for their names.
+func WithEnrichers(names ...string) Option {
+	return func(c *config) { c.enrichers = names }
+}
+
+// WithBlockTimeout sets how long ParseReader waits for the next block of a
+// multi-block ACARS message, by message time, before parsing what it has, as
+// the command's -block-timeout flag does.  0 parses every block on its own.
+// The default is two minutes.
+func WithBlockTimeout(d time.Duration) Option {
+	return func(c *config) { c.blockTimeout = d }
+}
+
+// Enrichers returns the names of the built-in enrichers in their default
+// order.
+func Enrichers() []string {
+	return enrich.Names()
+}
+
+// Parser runs a set of parsers over messages.  It is safe for concurrent
+// use.
+type Parser struct {
+	reg          *registry.Registry
+	enrichers    *enrich.Chain // nil when enrichment is off
+	blockTimeout time.Duration
+}
+
+// New returns a Parser running every built-in parser, or the selection the
+// options make.  Unknown parser and enricher names and bad format files are
+// errors.
+func New(opts ...Option) (*Parser, error) {
+	c := config{enrich: true, blockTimeout: decode.DefaultBlockTimeout}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	reg := registry.Default()
+	reg.Sort()
+	if c.formats != "" {
+		extra, err := grokfile.Load(c.formats)
+		if err != nil {
+			return nil, err
+		}
+		if reg, err = reg.Clone(registry.Selection{}); err != nil {
+			return nil, err
+		}
+		for _, p := range extra {
+			if reg.Has(p.Name()) {
+				return nil, errors.New("parser name " + p.Name() + " is already registered")
+			}
+			reg.Register(p)
+		}
+		reg.Sort()
+	}
+	if len(c.sel.Enable) > 0 || len(c.sel.Disable) > 0 || len(c.sel.Priority) > 0 {
+		var err error
+		if reg, err = reg.Clone(c.sel); err != nil {
+			return nil, err
+		}
+	}
+	p := &Parser{reg: reg, blockTimeout: c.blockTimeout}
+	if c.enrich {
+		p.enrichers = enrich.Default()
+		if c.enrichers != nil {
+			var err error
+			if p.enrichers, err = enrich.ByName(c.enrichers...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p, nil
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultParser *Parser
+)
+
+// Parse runs every built-in parser over msg; see Parser.Parse.
+func Parse(msg *Message) []Result {
+	defaultOnce.Do(func() {
+		defaultParser, _ = New() // Cannot fail without options.
+	})
+	return defaultParser.Parse(msg)
+}
+
+// Parse runs the parsers over msg and returns their results, none when no
+// parser matched.  With enrichment on, msg's flight fields are filled in from
+// its text and then from the results, and msg.EnrichedBy names the enricher
+// of each.  A MIAM frame is followed by the results for the message it
+// carries.
+func (p *Parser) Parse(msg *Message) []Result {
+	if msg == nil {
+		return nil
+	}
+	p.enrichers.Enrich(msg, nil)
+	results := p.dispatch(msg)
+	if len(results) > 0 {
+		p.enrichers.Enrich(msg, results)
+	}
+	return results
+}
+
+// dispatch runs the parsers over msg and the message in any MIAM frame.
+func (p *Parser) dispatch(msg *Message) []Result {
+	results := p.reg.Dispatch(msg)
+	for _, r := range results {
+		if m, ok := r.(*MIAMResult); ok {
+			if inner := m.InnerACARS(msg); inner != nil {
+				results = append(results, p.dispatch(inner)...)
+			}
+		}
+	}
+	return results
+}
+
+// ParseLine decodes one JSON line with Decode and parses every message in
+// it.
+func (p *Parser) ParseLine(line []byte) ([]Record, error) {
+	msgs, err := Decode(line)
+	if err != nil {
+		return nil, err
+	}
+	return p.records(msgs), nil
+}
+
+// records parses each of msgs.
+func (p *Parser) records(msgs []*Message) []Record {
+	recs := make([]Record, 0, len(msgs))
+	for _, msg := range msgs {
+		recs = append(recs, Record{Message: msg, Results: p.Parse(msg)})
+	}
+	return recs
+}
+
+// ParseReader reads r, a JSONL stream or a JAERO log told apart by the first
+// non-empty line, and calls fn with every message and its results, including
+// messages no parser matched.  JSON lines without a message are skipped.  It
+// stops at the first error fn returns.
+//
+// The blocks of a multi-block ACARS message are joined and parsed as one
+// message, whose Record has Reassembly set; see WithBlockTimeout.  A MIAM
+// transfer whose segments JAERO logged without decoding is joined to its
+// first segment, with a miam_assembled result.  Unlike the command, which
+// sorts a JAERO log by time first, ParseReader takes the blocks in the order
+// read, so a log should be in time order for its transfers to be joined.
+func (p *Parser) ParseReader(r io.Reader, fn func(Record) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 60*1024*1024)
+	first := ""
+	for first == "" && sc.Scan() {
+		first = strings.TrimSpace(sc.Text())
+	}
+	if first == "" {
+		return sc.Err()
+	}
+
+	// Joined messages are handed on from the assemblers' callbacks, which
+	// cannot return fn's error: it is kept in fnErr instead.
+	var fnErr error
+	emit := func(rec Record) {
+		if fnErr == nil {
+			fnErr = fn(rec)
+		}
+	}
+
+	if decode.IsJAEROHeader(first) {
+		asm := decode.NewJAEROAssembler(func(blk decode.JAEROBlock, continuations []string) {
+			if msg := decode.ParseJAEROBlock(blk.Header, blk.Body); msg != nil {
+				emit(Record{Message: msg, Results: p.parseJAERO(msg, blk.Body, continuations)})
+			}
+		})
+		err := scanJAERO(sc, first, func(header string, body []string) error {
+			blk := decode.NewJAEROBlock(header, body, 0)
+			asm.Expire(blk)
+			asm.Add(blk)
+			return fnErr
+		})
+		if err != nil {
+			return err
+		}
+		asm.Flush()
+		return fnErr
+	}
+
+	var blocks *decode.BlockAssembler
+	if p.blockTimeout > 0 {
+		blocks = decode.NewBlockAssembler(p.blockTimeout)
+	}
+	done := func(msg *Message, info *ReassemblyInfo) {
+		emit(Record{Message: msg, Results: p.Parse(msg), Reassembly: info})
+	}
+	for line := first; ; {
+		if line != "" {
+			var msgs []*Message
+			taken := false
+			if blocks != nil && decode.MayCarryBlock(line) {
+				msgs, _, taken = blocks.TakeLine(line, done)
+			} else {
+				msgs, _ = decode.JSON([]byte(line))
+			}
+			if !taken {
+				msgs, err := messages([]byte(line), msgs)
+				if err != nil && !errors.Is(err, ErrNoMessage) {
+					return err
+				}
+				for _, rec := range p.records(msgs) {
+					emit(rec)
+				}
+			}
+			if fnErr != nil {
+				return fnErr
+			}
+		}
+		if !sc.Scan() {
+			if blocks != nil {
+				blocks.Flush()
+			}
+			if fnErr != nil {
+				return fnErr
+			}
+			return sc.Err()
+		}
+		line = strings.TrimSpace(sc.Text())
+	}
+}
+
+// parseJAERO parses a JAERO message.  For a MIAM frame JAERO has already
+// decoded, the parsers run on the decoded block rather than the compressed
+// text, and the first segment of a transfer JAERO did not decode is parsed
+// with its continuations joined to it, as in the command.
+func (p *Parser) parseJAERO(msg *Message, body []string, continuations []string) []Result {
+	if msg.Label == "MA" {
+		if text := decode.JAEROMIAMBlock(body); text != "" {
+			p.enrichers.Enrich(msg, nil)
+			miam := *msg
+			miam.Text = text
+			if results := p.dispatch(&miam); len(results) > 0 {
+				p.enrichers.Enrich(msg, results)
+				return results
+			}
+		} else if len(continuations) > 0 {
+			p.enrichers.Enrich(msg, nil)
+			result := miampkg.DecodeAssembled(msg, continuations)
+			results := []Result{result}
+			if inner := result.InnerACARS(msg); inner != nil {
+				results = append(results, p.reg.Dispatch(inner)...)
+			}
+			p.enrichers.Enrich(msg, results)
+			return results
+		}
+	}
+	return p.Parse(msg)
+}
+
+// Decode decodes one JSON line: a flat acarsdec-style message, a NATS
+// wrapper, or a dumpvdl2 or dumphfdl frame, which may yield several
+// messages.  It returns ErrNoMessage when the line holds none, and the JSON
+// error when it is not JSON.
+func Decode(line []byte) ([]*Message, error) {
+	msgs, _ := decode.JSON(line)
+	return messages(line, msgs)
+}
+
+// messages returns the messages decoded from line that have a label or text,
+// with Decode's errors when there are none.
+func messages(line []byte, msgs []*Message) ([]*Message, error) {
+	var out []*Message
+	for _, msg := range msgs {
+		if msg != nil && (strings.TrimSpace(msg.Label) != "" || strings.TrimSpace(msg.Text) != "") {
+			out = append(out, msg)
+		}
+	}
+	if len(out) == 0 {
+		if !json.Valid(line) {
+			return nil, errors.New("not a JSON line")
+		}
+		return nil, ErrNoMessage
+	}
+	return out, nil
+}
+
+// DecodeJAERO decodes a JAERO log.  Blocks without a payload are skipped.
+func DecodeJAERO(r io.Reader) ([]*Message, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 60*1024*1024)
+	var msgs []*Message
+	err := scanJAERO(sc, "", func(header string, body []string) error {
+		if msg := decode.ParseJAEROBlock(header, body); msg != nil {
+			msgs = append(msgs, msg)
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+// scanJAERO splits the lines of sc into JAERO blocks, a header line and the
+// lines up to the next one, and calls fn with each.  header is a header line
+// already read, if any.  Lines before the first header are skipped.
+func scanJAERO(sc *bufio.Scanner, header string, fn func(header string, body []string) error) error {
+	var body []string
+	for sc.Scan() {
+		line := sc.Text()
+		if trimmed := strings.TrimSpace(line); decode.IsJAEROHeader(trimmed) {
+			if header != "" {
+				if err := fn(header, body); err != nil {
+					return err
+				}
+			}
+			header, body = trimmed, nil
+			continue
+		}
+		if header != "" {
+			body = append(body, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if header != "" {
+		return fn(header, body)
+	}
+	return nil
+}
+
+// QualityOf returns r's Quality, if r reports one.
+func QualityOf(r Result) (Quality, bool) {
+	return registry.QualityOf(r)
+}
+
+// PositionOf returns the aircraft position r reports, if any.
+func PositionOf(r Result) (Position, bool) {
+	return registry.PositionOf(r)
+}
+
+// PositionsOf returns every position r reports, in report order.
+func PositionsOf(r Result) []Position {
+	return registry.PositionsOf(r)
+}
+
+// StationPositionOf returns the ground station position r reports, if any,
+// as SQ squitters do.  It is not an aircraft position.
+func StationPositionOf(r Result) (Position, bool) {
+	return registry.StationPositionOf(r)
+}
@@ -0,0 +1,184 @@
+package acarsparser_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"acars_parser/pkg/acarsparser"
+)
+
+// An acarsdec message, one JSON object per line.
+func ExampleParse() {
+	line := `{"timestamp":1773393969.2,"station_id":"RX1","tail":"9A-CTG","flight":"OU4410","label":"16",` +
+		`"text":"POSA1N42851E 16405,GIS40  ,092609,380,ROTAR  ,100331,,-58, 22, 306,844"}`
+	msgs, err := acarsparser.Decode([]byte(line))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range acarsparser.Parse(msgs[0]) {
+		pos := r.(*acarsparser.Label16Result)
+		fmt.Println(r.Type(), pos.Latitude, pos.Longitude)
+	}
+	// Output:
+	// waypoint_position 42.851 16.405
+}
+
+// A message as published on NATS: the message and the airframe and source
+// it came from, wrapped in one object.
+func ExampleDecode_nats() {
+	line := `{"source":{"name":"test","application":"acars"},"airframe":{"tail":"9A-CTG","icao":"501C5A"},` +
+		`"message":{"id":7,"timestamp":"2026-03-13T09:26:09Z","label":"16",` +
+		`"text":"POSA1N42851E 16405,GIS40  ,092609,380,ROTAR  ,100331,,-58, 22, 306,844","tail":"9A-CTG"}}`
+	msgs, err := acarsparser.Decode([]byte(line))
+	if err != nil {
+		log.Fatal(err)
+	}
+	msg := msgs[0]
+	fmt.Println(msg.ID, msg.Tail, msg.Airframe.ICAO, msg.Label)
+	// Output:
+	// 7 9A-CTG 501C5A 16
+}
+
+// A dumpvdl2 frame carrying a MIAM-compressed flight plan.  The frame and the
+// flight plan inside it are both parsed.
+func ExampleParser_ParseLine_dumpvdl2() {
+	text, _ := json.Marshal(`T-2!<<+a/kT7u6:"3\!!#PE9poic|FPN/FNRJA111/RP:DA:OJAI:AA:EGLL:F:MUVIN,N31490E035327.L53..TAPUZ,N32020E034314.W13..VELOX,N33490E034050.N71..DESPO,N34269E034229`)
+	line := `{"vdl2":{"t":{"sec":1778604860},"avlc":{"src":{"addr":"4B1803"},"acars":{"reg":".JY-BAJ","label":"MA","msg_text":` + string(text) + `}}}}`
+
+	p, err := acarsparser.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	recs, err := p.ParseLine([]byte(line))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, rec := range recs {
+		for _, r := range rec.Results {
+			fmt.Println(rec.Message.Tail, r.Type())
+		}
+	}
+	// Output:
+	// .JY-BAJ miam_data
+	// .JY-BAJ flight_plan
+}
+
+// A dumphfdl frame with an ACARS message in it.
+func ExampleParser_ParseLine_dumphfdl() {
+	line := `{"hfdl":{"t":{"sec":1778604866},"freq":8927000,"lpdu":{"src":{"type":"Aircraft","id":12},` +
+		`"hfnpdu":{"acars":{"reg":".JY-BAJ","label":"H1","flight":"RJ0111","msg_text":"FPN/FNRJA111/RP:DA:OJAI:AA:EGLL:F:MUVIN,N31490E035327.L53..TAPUZ"}}}}}`
+
+	p, err := acarsparser.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	recs, err := p.ParseLine([]byte(line))
+	if err != nil {
+		log.Fatal(err)
+	}
+	msg := recs[0].Message
+	fmt.Println(msg.Tail, msg.Label)
+	for _, r := range recs[0].Results {
+		fmt.Println(r.Type())
+	}
+	// Output:
+	// .JY-BAJ H1
+	// flight_plan
+}
+
+// A JAERO log, as JAERO writes it.  ParseReader tells it apart from JSON
+// lines by its first line.
+func ExampleParser_ParseReader_jaero() {
+	jaero := `16:54:24 12-05-26 UTC AES:3C65AD GES:90 2 .D-AIMM ! B0 2 AIRBUS A380 841 LUFTHANSA FLIGHT LH8P
+
+	/PIKCPYA.AFN/FMHDLH8P,.D-AIMM,,165418/FRP05A3A
+
+16:54:25 12-05-26 UTC AES:75044A GES:90 2 .9M-MAC ! SA 5 AIRBUS A350 941 MALAYSIA AIRLINES FLIGHT MH3
+
+	0LV165411S/
+
+	MEDIA ADVISORY, VERSION 0:
+	 LINK VHF ACARS LOST AT 16:54:11 UTC
+	 AVAILABLE LINKS: DEFAULT SATCOM
+`
+	p, err := acarsparser.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = p.ParseReader(strings.NewReader(jaero), func(rec acarsparser.Record) error {
+		fmt.Print(rec.Message.Tail, " ", rec.Message.Label)
+		for _, r := range rec.Results {
+			fmt.Print(" ", r.Type())
+		}
+		fmt.Println()
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// .D-AIMM B0
+	// .9M-MAC SA media_advisory
+}
+
+// An H1 flight plan that acarsdec received in three blocks, the last two
+// out of order.  ParseReader joins them and parses the whole message once.
+func ExampleParser_ParseReader_blocks() {
+	jsonl := `{"timestamp":1778604860.5,"label":"H1","tail":".JY-BAJ","flight":"RJ0111","msgno":"D05A","end":false,"text":"FPN/FNRJA111/RP:DA:OJAI:AA:EGLL:F:MUVIN,N31490E035327"}
+{"timestamp":1778604864.5,"label":"H1","tail":".JY-BAJ","flight":"RJ0111","msgno":"D05C","end":true,"text":".N71..DESPO,N34269E034229"}
+{"timestamp":1778604862.5,"label":"H1","tail":".JY-BAJ","flight":"RJ0111","msgno":"D05B","end":false,"text":".L53..TAPUZ,N32020E034314.W13..VELOX,N33490E034050"}
+`
+	p, err := acarsparser.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = p.ParseReader(strings.NewReader(jsonl), func(rec acarsparser.Record) error {
+		if info := rec.Reassembly; info != nil {
+			fmt.Println(info.MsgNo, info.Blocks, info.Complete)
+		}
+		fmt.Println(rec.Message.Text)
+		for _, r := range rec.Results {
+			fmt.Println(r.Type())
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// D05 3 true
+	// FPN/FNRJA111/RP:DA:OJAI:AA:EGLL:F:MUVIN,N31490E035327.L53..TAPUZ,N32020E034314.W13..VELOX,N33490E034050.N71..DESPO,N34269E034229
+	// flight_plan
+}
+
+// Only the parsers that are needed, here those of ADS-C and CPDLC.
+func ExampleEnable() {
+	p, err := acarsparser.New(acarsparser.Enable("adsc", "cpdlc"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	msgs, _ := acarsparser.Decode([]byte(`{"label":"16","text":"POSA1N42851E 16405,GIS40  ,092609,380,ROTAR  ,100331,,-58, 22, 306,844"}`))
+	fmt.Println(len(p.Parse(msgs[0])))
+
+	_, err = acarsparser.New(acarsparser.Enable("nope"))
+	fmt.Println(err != nil)
+	// Output:
+	// 0
+	// true
+}
+
+// Enrichers fill in the flight fields a feed left out and record which of
+// them found each one.
+func ExampleWithEnrichers() {
+	p, err := acarsparser.New(acarsparser.WithEnrichers("afn", "results"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	msg := &acarsparser.Message{Label: "B0", Text: "/PIKCPYA.AFN/FMHDLH8P,.D-AIMM,,165418/FRP05A3A"}
+	p.Parse(msg)
+	fmt.Println(msg.Tail, msg.Flight.Flight, msg.EnrichedBy)
+	// Output:
+	// D-AIMM DLH8P map[flight:afn tail:afn]
+}
@@ -0,0 +1,99 @@
+package acarsparser
+
+import (
+	"acars_parser/internal/parsers/abs"
+	"acars_parser/internal/parsers/adsc"
+	"acars_parser/internal/parsers/agfsr"
+	"acars_parser/internal/parsers/atis"
+	"acars_parser/internal/parsers/atncm"
+	"acars_parser/internal/parsers/cpdlc"
+	"acars_parser/internal/parsers/dis"
+	"acars_parser/internal/parsers/eb00"
+	"acars_parser/internal/parsers/envelope"
+	"acars_parser/internal/parsers/eta"
+	"acars_parser/internal/parsers/fst"
+	"acars_parser/internal/parsers/gateassign"
+	"acars_parser/internal/parsers/grokfile"
+	"acars_parser/internal/parsers/h1"
+	"acars_parser/internal/parsers/h2wind"
+	"acars_parser/internal/parsers/hfdl"
+	"acars_parser/internal/parsers/ilnge7x"
+	"acars_parser/internal/parsers/ini"
+	"acars_parser/internal/parsers/label10"
+	"acars_parser/internal/parsers/label16"
+	"acars_parser/internal/parsers/label17"
+	"acars_parser/internal/parsers/label21"
+	"acars_parser/internal/parsers/label22"
+	"acars_parser/internal/parsers/label26"
+	"acars_parser/internal/parsers/label27"
+	"acars_parser/internal/parsers/label33"
+	"acars_parser/internal/parsers/label39"
+	"acars_parser/internal/parsers/label44"
+	"acars_parser/internal/parsers/label4j"
+	"acars_parser/internal/parsers/label5l"
+	"acars_parser/internal/parsers/label80"
+	"acars_parser/internal/parsers/label83"
+	"acars_parser/internal/parsers/labelb2"
+	"acars_parser/internal/parsers/labelb3"
+	"acars_parser/internal/parsers/landingdata"
+	"acars_parser/internal/parsers/loadsheet"
+	"acars_parser/internal/parsers/mediaadv"
+	"acars_parser/internal/parsers/miam"
+	"acars_parser/internal/parsers/pdc"
+	"acars_parser/internal/parsers/rep301"
+	"acars_parser/internal/parsers/sb01"
+	"acars_parser/internal/parsers/sq"
+	"acars_parser/internal/parsers/turbulence"
+	"acars_parser/internal/parsers/weather"
+)
+
+// The result types of the built-in parsers, for type switches on the results
+// of Parse.  FormatResult is the result of a parser loaded with WithFormats.
+type (
+	ABSResult          = abs.Result
+	ADSCResult         = adsc.Result
+	AGFSRResult        = agfsr.Result
+	ATISResult         = atis.Result
+	ATNCMResult        = atncm.Result
+	CPDLCResult        = cpdlc.Result
+	DISResult          = dis.Result
+	EB00Result         = eb00.Result
+	ETAResult          = eta.Result
+	EnvelopeResult     = envelope.Result
+	FSTResult          = fst.Result
+	FormatResult       = grokfile.Result
+	GateAssignResult   = gateassign.Result
+	H1FlightPlanResult = h1.FPNResult
+	H1PositionResult   = h1.H1PosResult
+	H1WindResult       = h1.PWIResult
+	H2WindResult       = h2wind.Result
+	HFDLResult         = hfdl.Result
+	ILNGE7XResult      = ilnge7x.Result
+	INIResult          = ini.Result
+	Label10Result      = label10.Result
+	Label16Result      = label16.Result
+	Label17Result      = label17.Result
+	Label21Result      = label21.Result
+	Label22Result      = label22.Result
+	Label26Result      = label26.Result
+	Label27Result      = label27.Result
+	Label33Result      = label33.Result
+	Label39Result      = label39.Result
+	Label44Result      = label44.Result
+	Label4JResult      = label4j.Result
+	Label5LResult      = label5l.Result
+	Label80Result      = label80.Result
+	Label83Result      = label83.Result
+	LabelB2Result      = labelb2.Result
+	LabelB3Result      = labelb3.Result
+	LandingDataResult  = landingdata.Result
+	LoadsheetResult    = loadsheet.Result
+	MIAMResult         = miam.Result
+	MediaAdvResult     = mediaadv.Result
+	PDCResult          = pdc.Result
+	Rep301Result       = rep301.Result
+	SB01Result         = sb01.Result
+	SQResult           = sq.Result
+	TurbulenceResult   = turbulence.Result
+	WeatherResult      = weather.Result
+)